@@ -0,0 +1,22 @@
+package accounts
+
+import (
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/hydra"
+
+	"gorm.io/gorm"
+)
+
+type Accounts struct {
+	db          *gorm.DB
+	userService *user.UserService
+	hydraClient *hydra.HydraClient
+}
+
+func Initialize(db *gorm.DB, us *user.UserService, hc *hydra.HydraClient) (*Accounts, error) {
+	return &Accounts{
+		db:          db,
+		userService: us,
+		hydraClient: hc,
+	}, nil
+}
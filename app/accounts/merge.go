@@ -0,0 +1,167 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"conformitea/domain/user"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Longest reason accepted for a merge.
+const maxReasonLength = 500
+
+// Merges a duplicate user into the user the same person keeps signing in as. The
+// source's identities, organization memberships and team memberships move to the
+// target and the source is deactivated and signed out, but kept so the history
+// of what they did stays intact. Only staff and operators can merge users.
+func (a *Accounts) MergeUsers(ctx context.Context, req types.MergeUsersRequest) (types.UserMerge, error) {
+	mergedBy, err := a.authorize(req.Actor)
+	if err != nil {
+		return types.UserMerge{}, err
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return types.UserMerge{}, fmt.Errorf("%w: a reason is required", types.ErrInvalidRequest)
+	}
+	if len(reason) > maxReasonLength {
+		return types.UserMerge{}, fmt.Errorf("%w: reason is longer than %d characters", types.ErrInvalidRequest, maxReasonLength)
+	}
+
+	source, err := a.user(req.Source)
+	if err != nil {
+		return types.UserMerge{}, err
+	}
+
+	target, err := a.user(req.Target)
+	if err != nil {
+		return types.UserMerge{}, err
+	}
+
+	if err := checkMergeable(source, target); err != nil {
+		return types.UserMerge{}, err
+	}
+
+	merge, err := a.userService.MergeUsers(a.db, user.Merge{
+		SourceUserID: source.ID,
+		TargetUserID: target.ID,
+		MergedBy:     mergedBy,
+		Reason:       reason,
+	}, req.DryRun)
+	if err != nil {
+		return types.UserMerge{}, fmt.Errorf("failed to merge users: %w", err)
+	}
+
+	// The source can no longer sign in, so sessions it still has are ended
+	if !req.DryRun {
+		if err := a.hydraClient.RevokeSubjectSessions(source.ID.String()); err != nil {
+			return types.UserMerge{}, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	return toUserMerge(merge, source, target, req.DryRun), nil
+}
+
+// Checks that the actor is staff or an operator, and returns the ID of the staff
+// user acting, or nil for operators.
+func (a *Accounts) authorize(actor types.Actor) (*uuid.UUID, error) {
+	switch actor.Kind {
+	case types.PrincipalOperator:
+		return nil, nil
+	case types.PrincipalUser:
+	default:
+		return nil, fmt.Errorf("%w: %s has no staff rights", types.ErrPermissionDenied, actor.Kind)
+	}
+
+	id, err := uuid.Parse(actor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actor ID %q: %w", actor.ID, err)
+	}
+
+	u, err := a.userService.GetUserByID(a.db, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, types.ErrPermissionDenied
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !u.IsStaff {
+		return nil, fmt.Errorf("%w: staff rights required", types.ErrPermissionDenied)
+	}
+
+	return &u.ID, nil
+}
+
+// Returns the user with the ID or email.
+func (a *Accounts) user(idOrEmail string) (user.User, error) {
+	idOrEmail = strings.TrimSpace(idOrEmail)
+
+	var u user.User
+	var err error
+
+	if id, parseErr := uuid.Parse(idOrEmail); parseErr == nil {
+		u, err = a.userService.GetUserByID(a.db, id)
+	} else {
+		u, err = a.userService.GetUserByEmail(a.db, idOrEmail)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user.User{}, fmt.Errorf("%w: %s", types.ErrUserNotFound, idOrEmail)
+	}
+	if err != nil {
+		return user.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return u, nil
+}
+
+// Checks that source can be merged into target. Staff cannot be merged away, so
+// merges cannot take staff rights from anyone, and users are merged only once.
+func checkMergeable(source, target user.User) error {
+	switch {
+	case source.ID == target.ID:
+		return fmt.Errorf("%w: a user cannot be merged into themself", types.ErrMergeConflict)
+	case source.MergedIntoID != nil:
+		return fmt.Errorf("%w: %s was already merged into %s", types.ErrMergeConflict, source.ID, *source.MergedIntoID)
+	case target.MergedIntoID != nil:
+		return fmt.Errorf("%w: %s was merged into %s", types.ErrMergeConflict, target.ID, *target.MergedIntoID)
+	case !target.Active():
+		return fmt.Errorf("%w: %s is deactivated", types.ErrMergeConflict, target.ID)
+	case source.IsStaff:
+		return fmt.Errorf("%w: %s is staff", types.ErrMergeConflict, source.ID)
+	}
+
+	return nil
+}
+
+func toUserMerge(merge user.Merge, source, target user.User, dryRun bool) types.UserMerge {
+	result := types.UserMerge{
+		SourceUserID: source.ID.String(),
+		SourceEmail:  source.Email,
+		TargetUserID: target.ID.String(),
+		TargetEmail:  target.Email,
+		Reason:       merge.Reason,
+		Identities:   merge.Identities,
+		Memberships:  merge.Memberships,
+		Teams:        merge.Teams,
+		DryRun:       dryRun,
+		CreatedAt:    merge.CreatedAt,
+	}
+
+	if merge.ID != uuid.Nil {
+		result.ID = merge.ID.String()
+	}
+
+	if merge.MergedBy != nil {
+		result.MergedBy = merge.MergedBy.String()
+	}
+
+	return result
+}
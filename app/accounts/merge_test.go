@@ -0,0 +1,370 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"conformitea/domain"
+	domainOrganization "conformitea/domain/organization"
+	"conformitea/domain/user"
+	infrastructureConfig "conformitea/infrastructure/config"
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/infrastructure/persistence/apitoken"
+	"conformitea/infrastructure/persistence/audit"
+	"conformitea/infrastructure/persistence/mfa"
+	"conformitea/infrastructure/persistence/organization"
+	"conformitea/infrastructure/persistence/persistencetest"
+	"conformitea/infrastructure/persistence/team"
+	persistenceUser "conformitea/infrastructure/persistence/user"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+)
+
+const testProvider = "oidc"
+
+// Hydra admin API recording the subjects whose sessions were revoked.
+type fakeHydra struct {
+	mu      sync.Mutex
+	revoked []string
+}
+
+func (h *fakeHydra) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r.Method != http.MethodDelete || r.URL.Path != "/admin/oauth2/auth/sessions/login" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.revoked = append(h.revoked, r.URL.Query().Get("subject"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Returns the subjects whose login sessions were revoked.
+func (h *fakeHydra) revokedSubjects() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return slices.Clone(h.revoked)
+}
+
+// Returns Accounts backed by an empty database and a fake Hydra.
+func newTestAccounts(t *testing.T) (*Accounts, *fakeHydra) {
+	t.Helper()
+
+	db := persistencetest.Open(t)
+
+	dc, err := domain.Initialize(&persistenceUser.UserRepository{}, &team.TeamRepository{}, &organization.OrganizationRepository{}, &mfa.MFARepository{}, &apitoken.APITokenRepository{}, &audit.AuditRepository{})
+	if err != nil {
+		t.Fatalf("failed to initialize domain: %v", err)
+	}
+
+	fake := &fakeHydra{}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	hc, err := hydra.Initialize(infrastructureConfig.HydraConfig{
+		AdminURL:  server.URL,
+		PublicURL: server.URL,
+		Client: infrastructureConfig.HydraClientConfig{
+			ClientID:     "conformitea",
+			ClientSecret: "secret",
+			RedirectURL:  "https://app.example.com/auth/session/callback",
+			Scopes:       []string{"openid", "offline_access"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize hydra client: %v", err)
+	}
+
+	a, err := Initialize(db, dc.GetUserService(), hc)
+	if err != nil {
+		t.Fatalf("failed to initialize accounts: %v", err)
+	}
+
+	return a, fake
+}
+
+// Creates a user signing in with an identity whose subject is their email.
+func createUser(t *testing.T, a *Accounts, email string) user.User {
+	t.Helper()
+
+	u, err := a.userService.ProvisionUser(a.db, user.ExternalProfile{
+		Provider:      testProvider,
+		Subject:       email,
+		Email:         email,
+		EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to provision user: %v", err)
+	}
+
+	return u
+}
+
+// Returns the actor of a new staff user.
+func createStaff(t *testing.T, a *Accounts) types.Actor {
+	t.Helper()
+
+	staff := createUser(t, a, "staff@conformitea.example.com")
+	if _, err := a.userService.SetStaff(a.db, staff.ID, true); err != nil {
+		t.Fatalf("failed to grant staff rights: %v", err)
+	}
+
+	return types.Actor{ID: staff.ID.String(), Kind: types.PrincipalUser}
+}
+
+func createOrganization(t *testing.T, a *Accounts, name string) uuid.UUID {
+	t.Helper()
+
+	org := organization.Organization{Name: name}
+	if err := a.db.Create(&org).Error; err != nil {
+		t.Fatalf("failed to create organization: %v", err)
+	}
+
+	return org.ID
+}
+
+func addMember(t *testing.T, a *Accounts, u user.User, orgID uuid.UUID, role string) {
+	t.Helper()
+
+	if _, err := a.userService.AddOrganizationMember(a.db, u, domainOrganization.Membership{
+		OrganizationID: orgID,
+		Role:           role,
+	}); err != nil {
+		t.Fatalf("failed to add member: %v", err)
+	}
+}
+
+// Returns the roles of the user's memberships by organization.
+func roles(t *testing.T, a *Accounts, userID uuid.UUID) map[uuid.UUID]string {
+	t.Helper()
+
+	memberships, err := a.userService.GetUserMemberships(a.db, userID)
+	if err != nil {
+		t.Fatalf("failed to get memberships: %v", err)
+	}
+
+	result := map[uuid.UUID]string{}
+	for _, m := range memberships {
+		result[m.OrganizationID] = m.Role
+	}
+
+	return result
+}
+
+// Returns the subjects of the user's identities, sorted.
+func identitySubjects(t *testing.T, a *Accounts, userID uuid.UUID) []string {
+	t.Helper()
+
+	identities, err := a.userService.GetUserIdentities(a.db, userID)
+	if err != nil {
+		t.Fatalf("failed to get identities: %v", err)
+	}
+
+	result := make([]string, 0, len(identities))
+	for _, i := range identities {
+		result = append(result, i.Subject)
+	}
+	slices.Sort(result)
+
+	return result
+}
+
+func TestMergeUsers(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+		// Merges the source into themself
+		self       bool
+		wantErr    error
+		wantMerged bool
+	}{
+		{
+			name:       "merge",
+			wantMerged: true,
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+		},
+		{
+			name:    "user into themself",
+			self:    true,
+			wantErr: types.ErrMergeConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, fake := newTestAccounts(t)
+			staff := createStaff(t, a)
+
+			source := createUser(t, a, "ada@gmail.com")
+			target := createUser(t, a, "ada@example.com")
+
+			// The source is the only member of one organization, and outranks the
+			// target in the organization they both belong to
+			example := createOrganization(t, a, "Example")
+			shared := createOrganization(t, a, "Shared")
+			addMember(t, a, source, example, domainOrganization.RoleMember)
+			addMember(t, a, source, shared, domainOrganization.RoleAdmin)
+			addMember(t, a, target, shared, domainOrganization.RoleMember)
+
+			req := types.MergeUsersRequest{
+				Actor:  staff,
+				Source: source.Email,
+				Target: target.ID.String(),
+				Reason: "Ticket 42",
+				DryRun: tt.dryRun,
+			}
+			if tt.self {
+				req.Target = source.ID.String()
+			}
+
+			merge, err := a.MergeUsers(context.Background(), req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MergeUsers() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && (merge.Identities != 1 || merge.Memberships != 2 || merge.DryRun != tt.dryRun) {
+				t.Errorf("MergeUsers() = %+v, want 1 identity and 2 memberships moved", merge)
+			}
+
+			wantSource := map[uuid.UUID]string{example: domainOrganization.RoleMember, shared: domainOrganization.RoleAdmin}
+			wantTarget := map[uuid.UUID]string{shared: domainOrganization.RoleMember}
+			wantSourceIdentities := []string{source.Email}
+			wantTargetIdentities := []string{target.Email}
+			var wantRevoked []string
+			var wantMerges int64
+			if tt.wantMerged {
+				wantSource = map[uuid.UUID]string{}
+				wantTarget = map[uuid.UUID]string{example: domainOrganization.RoleMember, shared: domainOrganization.RoleAdmin}
+				wantSourceIdentities = []string{}
+				wantTargetIdentities = []string{target.Email, source.Email}
+				wantRevoked = []string{source.ID.String()}
+				wantMerges = 1
+			}
+			slices.Sort(wantTargetIdentities)
+
+			if got := roles(t, a, source.ID); !maps.Equal(got, wantSource) {
+				t.Errorf("source memberships = %v, want %v", got, wantSource)
+			}
+			if got := roles(t, a, target.ID); !maps.Equal(got, wantTarget) {
+				t.Errorf("target memberships = %v, want %v", got, wantTarget)
+			}
+
+			if got := identitySubjects(t, a, source.ID); !slices.Equal(got, wantSourceIdentities) {
+				t.Errorf("source identities = %v, want %v", got, wantSourceIdentities)
+			}
+			if got := identitySubjects(t, a, target.ID); !slices.Equal(got, wantTargetIdentities) {
+				t.Errorf("target identities = %v, want %v", got, wantTargetIdentities)
+			}
+
+			merged, err := a.userService.GetUserByID(a.db, source.ID)
+			if err != nil {
+				t.Fatalf("failed to get source: %v", err)
+			}
+			if tt.wantMerged {
+				if merged.Active() || merged.MergedIntoID == nil || *merged.MergedIntoID != target.ID {
+					t.Errorf("source deactivated = %t, merged into %v, want deactivated and merged into %s", !merged.Active(), merged.MergedIntoID, target.ID)
+				}
+			} else if !merged.Active() || merged.MergedIntoID != nil {
+				t.Errorf("source deactivated = %t, merged into %v, want it unchanged", !merged.Active(), merged.MergedIntoID)
+			}
+
+			if got := fake.revokedSubjects(); !slices.Equal(got, wantRevoked) {
+				t.Errorf("revoked sessions of %v, want %v", got, wantRevoked)
+			}
+
+			var merges int64
+			if err := a.db.Model(&persistenceUser.UserMerge{}).Count(&merges).Error; err != nil {
+				t.Fatalf("failed to count merges: %v", err)
+			}
+			if merges != wantMerges {
+				t.Errorf("merges recorded = %d, want %d", merges, wantMerges)
+			}
+		})
+	}
+}
+
+func TestMergedUsersSignInAsTarget(t *testing.T) {
+	a, _ := newTestAccounts(t)
+	staff := createStaff(t, a)
+
+	first := createUser(t, a, "ada@gmail.com")
+	second := createUser(t, a, "ada@outlook.com")
+	last := createUser(t, a, "ada@example.com")
+
+	for _, req := range []types.MergeUsersRequest{
+		{Actor: staff, Source: first.ID.String(), Target: second.ID.String(), Reason: "Ticket 42"},
+		{Actor: staff, Source: second.ID.String(), Target: last.ID.String(), Reason: "Ticket 43"},
+	} {
+		if _, err := a.MergeUsers(context.Background(), req); err != nil {
+			t.Fatalf("MergeUsers() error = %v", err)
+		}
+	}
+
+	// An identity left linked to the first user, such as one linked while the
+	// merge ran
+	if _, err := a.userService.LinkIdentity(a.db, user.Identity{
+		UserID:   first.ID,
+		Provider: testProvider,
+		Subject:  "left behind",
+		Email:    first.Email,
+	}); err != nil {
+		t.Fatalf("failed to link identity: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		subject string
+		email   string
+		wantErr error
+	}{
+		{
+			name:    "identity moved by the merges",
+			subject: first.Email,
+			email:   first.Email,
+		},
+		{
+			name:    "identity left linked to a merged user",
+			subject: "left behind",
+			email:   first.Email,
+		},
+		{
+			// Merged users keep their email, which new identities cannot claim
+			name:    "new identity with the email of a merged user",
+			subject: "new",
+			email:   second.Email,
+			wantErr: user.ErrEmailTaken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := a.userService.ProvisionUser(a.db, user.ExternalProfile{
+				Provider:      testProvider,
+				Subject:       tt.subject,
+				Email:         tt.email,
+				EmailVerified: true,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProvisionUser() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && u.ID != last.ID {
+				t.Errorf("signed in as %s, want %s the users were merged into", u.ID, last.ID)
+			}
+		})
+	}
+}
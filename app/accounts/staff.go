@@ -0,0 +1,15 @@
+package accounts
+
+import (
+	"context"
+
+	"conformitea/server/types"
+)
+
+// Checks that the actor administers the installation: operators, and users with
+// staff rights. The admin routes check it before any of their use cases run.
+func (a *Accounts) AuthorizeStaff(ctx context.Context, actor types.Actor) error {
+	_, err := a.authorize(actor)
+
+	return err
+}
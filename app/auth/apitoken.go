@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"conformitea/app/internal/tokens"
+	"conformitea/domain/apitoken"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scopes a personal access token can be granted.
+var personalTokenScopes = []string{"openid", types.OrganizationsScope, types.AdminScope}
+
+// Authenticates a personal access token or service account token and returns
+// the principal it was issued to. Unknown, expired and revoked tokens yield
+// ErrTokenInactive.
+func (a *Auth) AuthenticateAPIToken(ctx context.Context, secret string) (types.Principal, error) {
+	token, err := a.apiTokenService.GetTokenByHash(a.db, apitoken.HashSecret(secret))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.Principal{}, types.ErrTokenInactive
+	}
+	if err != nil {
+		return types.Principal{}, fmt.Errorf("failed to get api token: %w", err)
+	}
+
+	if !token.Active(time.Now()) {
+		return types.Principal{}, fmt.Errorf("%w: api token expired or revoked", types.ErrTokenInactive)
+	}
+
+	principal := types.Principal{
+		Scopes:    token.Scopes,
+		ExpiresAt: token.ExpiresAt,
+		TokenID:   token.ID.String(),
+	}
+
+	switch {
+	case token.UserID != nil:
+		u, err := a.userService.GetUserByID(a.db, *token.UserID)
+		if err != nil {
+			return types.Principal{}, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if !u.Active() {
+			return types.Principal{}, fmt.Errorf("%w: user deactivated", types.ErrTokenInactive)
+		}
+
+		principal.Subject = token.UserID.String()
+		principal.Kind = types.PrincipalUser
+	case token.ServiceAccountID != nil:
+		account, err := a.apiTokenService.GetServiceAccount(a.db, *token.ServiceAccountID)
+		if err != nil {
+			return types.Principal{}, fmt.Errorf("failed to get service account: %w", err)
+		}
+
+		principal.Subject = account.ID.String()
+		principal.Kind = types.PrincipalServiceAccount
+		principal.OrganizationID = account.OrganizationID.String()
+	default:
+		return types.Principal{}, fmt.Errorf("%w: api token has no owner", types.ErrTokenInactive)
+	}
+
+	if err := a.apiTokenService.RecordTokenUse(a.db, token.ID); err != nil {
+		return types.Principal{}, fmt.Errorf("failed to record api token use: %w", err)
+	}
+
+	return principal, nil
+}
+
+// Issues a personal access token to the user.
+func (a *Auth) CreatePersonalToken(ctx context.Context, req types.CreateAPITokenRequest) (types.IssuedAPIToken, error) {
+	id, err := uuid.Parse(req.Actor.ID)
+	if err != nil {
+		return types.IssuedAPIToken{}, fmt.Errorf("invalid user ID %q: %w", req.Actor.ID, err)
+	}
+
+	name, expiresAt, err := tokens.ValidateRequest(req, personalTokenScopes)
+	if err != nil {
+		return types.IssuedAPIToken{}, err
+	}
+
+	token, secret, err := a.apiTokenService.IssueToken(a.db, apitoken.Token{
+		UserID:    &id,
+		Name:      name,
+		Scopes:    req.Scopes,
+		ExpiresAt: expiresAt,
+	}, types.PersonalTokenPrefix)
+	if err != nil {
+		return types.IssuedAPIToken{}, fmt.Errorf("failed to issue api token: %w", err)
+	}
+
+	return types.IssuedAPIToken{
+		Token:  tokens.ToAPIToken(token),
+		Secret: secret,
+	}, nil
+}
+
+// Returns the personal access tokens of the user, including expired and revoked ones.
+func (a *Auth) ListPersonalTokens(ctx context.Context, userID string) ([]types.APIToken, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	userTokens, err := a.apiTokenService.GetUserTokens(a.db, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api tokens: %w", err)
+	}
+
+	result := make([]types.APIToken, 0, len(userTokens))
+	for _, t := range userTokens {
+		result = append(result, tokens.ToAPIToken(t))
+	}
+
+	return result, nil
+}
+
+// Revokes a personal access token of the user.
+func (a *Auth) RevokePersonalToken(ctx context.Context, userID, tokenID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	tid, err := uuid.Parse(tokenID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", types.ErrAPITokenNotFound, err)
+	}
+
+	revoked, err := a.apiTokenService.RevokeUserToken(a.db, id, tid)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api token: %w", err)
+	}
+
+	if !revoked {
+		return types.ErrAPITokenNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"conformitea/app/config"
+	"conformitea/domain"
+	"conformitea/domain/user"
+	infrastructureConfig "conformitea/infrastructure/config"
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/infrastructure/gateway/idp"
+	"conformitea/infrastructure/persistence/apitoken"
+	"conformitea/infrastructure/persistence/audit"
+	"conformitea/infrastructure/persistence/mfa"
+	"conformitea/infrastructure/persistence/organization"
+	"conformitea/infrastructure/persistence/persistencetest"
+	"conformitea/infrastructure/persistence/team"
+	persistenceUser "conformitea/infrastructure/persistence/user"
+
+	"github.com/google/uuid"
+)
+
+const testProvider = "oidc"
+
+// Hydra admin API serving the login requests of the test and recording the
+// logins accepted.
+type fakeHydra struct {
+	mu       sync.Mutex
+	logins   map[string]hydra.HydraLoginSession
+	accepted map[string]hydra.AcceptLoginRequest
+}
+
+func (h *fakeHydra) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	challenge := r.URL.Query().Get("login_challenge")
+
+	switch r.URL.Path {
+	case "/admin/oauth2/auth/requests/login":
+		login, exists := h.logins[challenge]
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(login)
+	case "/admin/oauth2/auth/requests/login/accept":
+		var accept hydra.AcceptLoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&accept); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		h.accepted[challenge] = accept
+		_ = json.NewEncoder(w).Encode(hydra.AcceptLoginResponse{RedirectTo: "https://hydra.example.com/accepted/" + challenge})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Adds a pending login request for the challenge.
+func (h *fakeHydra) addLogin(challenge string, login hydra.HydraLoginSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	login.Challenge = challenge
+	h.logins[challenge] = login
+}
+
+// Returns the accepted login of the challenge.
+func (h *fakeHydra) acceptedLogin(challenge string) (hydra.AcceptLoginRequest, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	accept, accepted := h.accepted[challenge]
+
+	return accept, accepted
+}
+
+// Identity provider sending users to a fixed authorization URL.
+type stubProvider struct {
+	idp.IdentityProvider
+}
+
+func (stubProvider) Name() string {
+	return testProvider
+}
+
+func (stubProvider) GenerateAuthURL(state, nonce string) (string, error) {
+	return "https://idp.example.com/authorize?state=" + state, nil
+}
+
+// Returns an Auth backed by an empty database, a fake Hydra and a single
+// identity provider.
+func newTestAuth(t *testing.T) (*Auth, *fakeHydra) {
+	t.Helper()
+
+	db := persistencetest.Open(t)
+
+	dc, err := domain.Initialize(&persistenceUser.UserRepository{}, &team.TeamRepository{}, &organization.OrganizationRepository{}, &mfa.MFARepository{}, &apitoken.APITokenRepository{}, &audit.AuditRepository{})
+	if err != nil {
+		t.Fatalf("failed to initialize domain: %v", err)
+	}
+
+	fake := &fakeHydra{
+		logins:   map[string]hydra.HydraLoginSession{},
+		accepted: map[string]hydra.AcceptLoginRequest{},
+	}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	hc, err := hydra.Initialize(infrastructureConfig.HydraConfig{
+		AdminURL:  server.URL,
+		PublicURL: server.URL,
+		Client: infrastructureConfig.HydraClientConfig{
+			ClientID:     "conformitea",
+			ClientSecret: "secret",
+			RedirectURL:  "https://app.example.com/auth/session/callback",
+			Scopes:       []string{"openid", "offline_access"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize hydra client: %v", err)
+	}
+
+	providers := idp.NewRegistry()
+	if err := providers.Register(stubProvider{}); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+
+	return &Auth{
+		config:              config.AuthConfig{RememberLoginFor: 3600},
+		db:                  db,
+		userService:         dc.GetUserService(),
+		organizationService: dc.GetOrganizationService(),
+		mfaService:          dc.GetMFAService(),
+		providers:           providers,
+		hydraClient:         hc,
+	}, fake
+}
+
+// Creates a user signing in with the test provider.
+func createUser(t *testing.T, a *Auth, email string) user.User {
+	t.Helper()
+
+	u, err := a.userService.ProvisionUser(a.db, user.ExternalProfile{
+		Provider:      testProvider,
+		Subject:       uuid.NewString(),
+		Email:         email,
+		EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return u
+}
+
+// Deactivates the user.
+func deactivateUser(t *testing.T, a *Auth, id uuid.UUID) {
+	t.Helper()
+
+	if err := a.db.Model(&persistenceUser.User{ID: id}).Update("deactivated_at", time.Now()).Error; err != nil {
+		t.Fatalf("failed to deactivate user: %v", err)
+	}
+}
@@ -2,47 +2,220 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"slices"
 
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/infrastructure/gateway/idp"
 	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Authentication context class references of accepted logins.
+const (
+	acrSingleFactor = "urn:conformitea:acr:sso"
+	acrMultiFactor  = "urn:conformitea:acr:mfa"
+)
+
+// Authentication method references (RFC 8176) of accepted logins.
+const (
+	amrFederated   = "fed"
+	amrOneTimeCode = "otp"
+	amrHardwareKey = "hwk"
+	amrMultiFactor = "mfa"
 )
 
-// Process OAuth2 callback
+// Maps ID token verification failures onto the errors of the AppAuth contract.
+var idTokenErrors = []struct {
+	gateway error
+	app     error
+}{
+	{idp.ErrIDTokenMissing, types.ErrIDTokenMissing},
+	{idp.ErrIDTokenSignature, types.ErrIDTokenSignature},
+	{idp.ErrIDTokenExpired, types.ErrIDTokenExpired},
+	{idp.ErrIssuerMismatch, types.ErrIDTokenIssuer},
+	{idp.ErrAudienceMismatch, types.ErrIDTokenAudience},
+	{idp.ErrNonceMismatch, types.ErrIDTokenNonce},
+	{idp.ErrInvalidClaims, types.ErrIDTokenClaims},
+	{idp.ErrTenantNotAllowed, types.ErrTenantNotAllowed},
+}
+
+// Processes the identity provider's OAuth2 callback and completes the Hydra flow.
 func (a *Auth) ProcessCallback(ctx context.Context, req types.CallbackRequest) (types.CallbackResult, error) {
-	// Route to appropriate IdP handler
-	switch req.Provider {
-	case "microsoft":
-		return a.processMicrosoftCallback(ctx, req)
-	default:
-		return types.CallbackResult{}, fmt.Errorf("unsupported provider: %s", req.Provider)
+	// The login challenge is sent to the provider as the state parameter
+	if subtle.ConstantTimeCompare([]byte(req.State), []byte(req.HydraLoginChallenge)) != 1 {
+		return types.CallbackResult{}, types.ErrStateMismatch
+	}
+
+	provider, err := a.providers.Get(req.Provider)
+	if err != nil {
+		return types.CallbackResult{}, fmt.Errorf("%w: %w", types.ErrProviderNotSupported, err)
 	}
-}
 
-// Processes Microsoft OAuth2 callback and completes Hydra flow
-func (a *Auth) processMicrosoftCallback(ctx context.Context, req types.CallbackRequest) (types.CallbackResult, error) {
 	// Exchange authorization code for access token
-	token, err := a.msClient.ExchangeCodeForToken(ctx, req.Code)
+	token, err := provider.ExchangeCodeForToken(ctx, req.Code)
 	if err != nil {
 		return types.CallbackResult{}, fmt.Errorf("failed to exchange code for token: %w", err)
 	}
 
-	// Get user profile from Microsoft Graph
-	userProfile, err := a.msClient.GetUserProfile(ctx, token)
+	claims, err := provider.VerifyIDToken(ctx, token, req.Nonce)
+	if err != nil {
+		return types.CallbackResult{}, translateIDTokenError(err)
+	}
+
+	// The identity is keyed on the subject of the verified ID token, the profile
+	// only supplies the user's details
+	userProfile, err := provider.GetUserProfile(ctx, token)
 	if err != nil {
 		return types.CallbackResult{}, fmt.Errorf("failed to get user profile: %w", err)
 	}
 
-	result, err := a.hydraClient.AcceptLoginSession(req.HydraLoginChallenge, userProfile.ID)
+	if userProfile.Email == "" {
+		return types.CallbackResult{}, types.ErrEmailMissing
+	}
+
+	if err := a.checkDomainSSO(userProfile.Email, provider.Name()); err != nil {
+		return types.CallbackResult{}, err
+	}
+
+	tenantOrg, err := a.tenantOrganization(claims)
+	if err != nil {
+		return types.CallbackResult{}, err
+	}
+
+	profile := user.ExternalProfile{
+		Provider:      provider.Name(),
+		Subject:       claims.Subject,
+		Email:         userProfile.Email,
+		FirstName:     userProfile.GivenName,
+		LastName:      userProfile.Surname,
+		TenantID:      claims.TenantID,
+		EmailVerified: userProfile.EmailVerified,
+	}
+
+	// A linked tenant vouches for emails at the domains its organization verified
+	if tenantOrg != uuid.Nil && !profile.EmailVerified {
+		if profile.EmailVerified, err = a.domainVerifiedBy(profile.Email, tenantOrg); err != nil {
+			return types.CallbackResult{}, err
+		}
+	}
+
+	var u user.User
+	if tenantOrg != uuid.Nil {
+		// Users of a linked tenant are members of its organization
+		var member user.Member
+		member, err = a.userService.ProvisionOrganizationMember(a.db, tenantOrg, profile)
+		u = member.User
+	} else {
+		u, err = a.userService.ProvisionUser(a.db, profile)
+	}
+	if err != nil {
+		return types.CallbackResult{}, translateProvisionError(err, profile)
+	}
+
+	return a.completeLogin(req.HydraLoginChallenge, u, profile)
+}
+
+func translateProvisionError(err error, profile user.ExternalProfile) error {
+	if errors.Is(err, user.ErrEmailTaken) {
+		return fmt.Errorf("%w: %s", types.ErrEmailTaken, profile.Email)
+	}
+
+	return fmt.Errorf("failed to provision user: %w", err)
+}
+
+// Returns the organization the tenant of the claims is linked to, or uuid.Nil.
+// Tenants the provider only accepts when linked are rejected otherwise.
+func (a *Auth) tenantOrganization(claims idp.IDTokenClaims) (uuid.UUID, error) {
+	if claims.TenantID == "" {
+		return uuid.Nil, nil
+	}
+
+	org, err := a.organizationService.GetOrganizationByTenantID(a.db, claims.TenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if claims.TenantLinkRequired {
+			return uuid.Nil, fmt.Errorf("%w: %s is not linked to an organization", types.ErrTenantNotAllowed, claims.TenantID)
+		}
+
+		return uuid.Nil, nil
+	}
 	if err != nil {
-		return types.CallbackResult{}, fmt.Errorf("failed to accept hydra login session: %w", err)
+		return uuid.Nil, fmt.Errorf("failed to get organization: %w", err)
 	}
 
-	email := userProfile.Mail
-	if email == "" {
-		email = userProfile.UserPrincipalName
+	return org.ID, nil
+}
+
+// Accepts the Hydra login of a user the identity provider authenticated, unless
+// they still have to pass a second factor. Users are added to the organization
+// that verified their email domain first, so its MFA policy applies.
+func (a *Auth) completeLogin(loginChallenge string, u user.User, profile user.ExternalProfile) (types.CallbackResult, error) {
+	if !u.Active() {
+		return types.CallbackResult{}, fmt.Errorf("%w: %s", types.ErrUserDeactivated, u.ID)
+	}
+
+	if err := a.joinDomainOrganization(u, profile); err != nil {
+		return types.CallbackResult{}, err
+	}
+
+	enrolled, required, err := a.mfaPolicy(u.ID)
+	if err != nil {
+		return types.CallbackResult{}, err
+	}
+
+	// The login is accepted once the user passes the second factor
+	if enrolled || required {
+		return types.CallbackResult{
+			Subject:               u.ID.String(),
+			MFARequired:           true,
+			MFAEnrollmentRequired: !enrolled,
+		}, nil
+	}
+
+	redirectTo, err := a.acceptLogin(loginChallenge, u.ID.String(), []string{amrFederated})
+	if err != nil {
+		return types.CallbackResult{}, err
 	}
 
 	return types.CallbackResult{
-		RedirectTo: result.RedirectTo,
+		RedirectTo: redirectTo,
+		Subject:    u.ID.String(),
 	}, nil
 }
+
+// Accepts the Hydra login for subject, who signed in with the given methods.
+// Downstream clients see the internal user ID, not the provider's subject.
+func (a *Auth) acceptLogin(loginChallenge, subject string, amr []string) (string, error) {
+	acr := acrSingleFactor
+	if slices.Contains(amr, amrMultiFactor) {
+		acr = acrMultiFactor
+	}
+
+	result, err := a.hydraClient.AcceptLoginSession(loginChallenge, hydra.AcceptLoginRequest{
+		Subject:     subject,
+		Remember:    true,
+		RememberFor: a.config.RememberLoginFor,
+		ACR:         acr,
+		AMR:         amr,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to accept hydra login session: %w", err)
+	}
+
+	return result.RedirectTo, nil
+}
+
+func translateIDTokenError(err error) error {
+	for _, e := range idTokenErrors {
+		if errors.Is(err, e.gateway) {
+			return fmt.Errorf("%w: %w", e.app, err)
+		}
+	}
+
+	return fmt.Errorf("failed to verify id_token: %w", err)
+}
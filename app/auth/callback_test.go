@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"conformitea/infrastructure/gateway/idp"
+	"conformitea/server/types"
+)
+
+func TestProcessCallbackState(t *testing.T) {
+	a := &Auth{providers: idp.NewRegistry()}
+
+	tests := []struct {
+		name    string
+		state   string
+		wantErr error
+	}{
+		{name: "state of the login", state: "login-challenge", wantErr: types.ErrProviderNotSupported},
+		{name: "state of another login", state: "another-challenge", wantErr: types.ErrStateMismatch},
+		{name: "no state", state: "", wantErr: types.ErrStateMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := a.ProcessCallback(context.Background(), types.CallbackRequest{
+				Code:                "code",
+				State:               tt.state,
+				Nonce:               "nonce-value",
+				HydraLoginChallenge: "login-challenge",
+				Provider:            "microsoft",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ProcessCallback() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTranslateIDTokenError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "missing", err: idp.ErrIDTokenMissing, wantErr: types.ErrIDTokenMissing},
+		{name: "signature", err: idp.ErrIDTokenSignature, wantErr: types.ErrIDTokenSignature},
+		{name: "expired", err: idp.ErrIDTokenExpired, wantErr: types.ErrIDTokenExpired},
+		{name: "issuer", err: idp.ErrIssuerMismatch, wantErr: types.ErrIDTokenIssuer},
+		{name: "audience", err: idp.ErrAudienceMismatch, wantErr: types.ErrIDTokenAudience},
+		{name: "nonce", err: idp.ErrNonceMismatch, wantErr: types.ErrIDTokenNonce},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := translateIDTokenError(tt.err); !errors.Is(err, tt.wantErr) || !errors.Is(err, tt.err) {
+				t.Errorf("translateIDTokenError() = %v, want %v wrapping %v", err, tt.wantErr, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+)
+
+// Returns the third-party clients the user granted access to, most recently
+// granted first. The consents of a client are merged into one app.
+func (a *Auth) ListConnectedApps(ctx context.Context, userID string) ([]types.ConnectedApp, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	sessions, err := a.hydraClient.ListConsentSessions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consent sessions: %w", err)
+	}
+
+	apps := []types.ConnectedApp{}
+	indexes := map[string]int{}
+
+	for _, session := range sessions {
+		if a.firstPartyClient(&session.ConsentRequest) {
+			continue
+		}
+
+		client := session.ConsentRequest.Client
+
+		i, seen := indexes[client.ClientId]
+		if !seen {
+			i = len(apps)
+			indexes[client.ClientId] = i
+			apps = append(apps, types.ConnectedApp{
+				ClientID:   client.ClientId,
+				ClientName: client.ClientName,
+				ClientURI:  client.ClientURI,
+				LogoURI:    client.LogoURI,
+				Scopes:     []string{},
+				Audience:   []string{},
+			})
+		}
+
+		app := &apps[i]
+		app.Scopes = appendMissing(app.Scopes, session.GrantScope)
+		app.Audience = appendMissing(app.Audience, session.GrantAccessTokenAudience)
+		if session.HandledAt.After(app.GrantedAt) {
+			app.GrantedAt = session.HandledAt
+		}
+	}
+
+	sort.SliceStable(apps, func(i, j int) bool {
+		return apps[i].GrantedAt.After(apps[j].GrantedAt)
+	})
+
+	return apps, nil
+}
+
+// Revokes the consents the user granted to a third-party client, which also
+// revokes the tokens issued to it for the user.
+func (a *Auth) RevokeConnectedApp(ctx context.Context, userID, clientID string) error {
+	apps, err := a.ListConnectedApps(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(apps, func(app types.ConnectedApp) bool { return app.ClientID == clientID }) {
+		return fmt.Errorf("%w: %s", types.ErrConnectedAppNotFound, clientID)
+	}
+
+	if err := a.hydraClient.RevokeClientConsent(userID, clientID); err != nil {
+		return fmt.Errorf("failed to revoke consent: %w", err)
+	}
+
+	return nil
+}
+
+func appendMissing(values, more []string) []string {
+	for _, v := range more {
+		if !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
@@ -3,28 +3,130 @@ package auth
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
 	"conformitea/infrastructure/gateway/hydra"
 	"conformitea/server/types"
+
+	"github.com/google/uuid"
 )
 
+// Descriptions of the scopes shown on the consent page.
+var scopeDescriptions = map[string]string{
+	"openid":                 "Sign you in with your ConformiTea account",
+	"offline_access":         "Keep access while you are not using it",
+	"profile":                "See your name",
+	"email":                  "See your email address",
+	types.OrganizationsScope: "See and manage your organizations",
+	types.AdminScope:         "Administer this ConformiTea installation",
+}
+
+// Scopes granted whenever the user accepts a consent request that asks for them.
+var requiredScopes = []string{"openid"}
+
+// Accepts consent requests of first-party clients, and of third-party clients
+// whose scopes the user granted and asked to remember before. Other requests
+// require the user to decide on the consent page.
 func (a *Auth) ProcessConsent(ctx context.Context, req types.ConsentRequest) (types.ConsentResult, error) {
 	consentSession, err := a.hydraClient.GetConsentSession(req.ConsentChallenge)
 	if err != nil {
 		return types.ConsentResult{}, fmt.Errorf("failed to get consent session: %w", err)
 	}
 
-	// TODO: check if skip_consent is true
+	// Scopes granted before are re-accepted without asking the user again
+	if consentSession.Skip {
+		return a.acceptConsent(req.ConsentChallenge, consentSession, consentSession.RequestedScope, false)
+	}
+
+	if a.firstPartyClient(consentSession) {
+		return a.acceptConsent(req.ConsentChallenge, consentSession, consentSession.RequestedScope, true)
+	}
+
+	return types.ConsentResult{ConsentRequired: true}, nil
+}
+
+// Returns the client and scopes of a consent request for the consent page.
+func (a *Auth) GetConsentDetails(ctx context.Context, req types.ConsentRequest) (types.ConsentDetails, error) {
+	consentSession, err := a.hydraClient.GetConsentSession(req.ConsentChallenge)
+	if err != nil {
+		return types.ConsentDetails{}, fmt.Errorf("failed to get consent session: %w", err)
+	}
+
+	scopes := make([]types.ConsentScope, 0, len(consentSession.RequestedScope))
+	for _, scope := range consentSession.RequestedScope {
+		scopes = append(scopes, types.ConsentScope{
+			Name:        scope,
+			Description: scopeDescriptions[scope],
+			Required:    slices.Contains(requiredScopes, scope),
+		})
+	}
+
+	return types.ConsentDetails{
+		ClientID:   consentSession.Client.ClientId,
+		ClientName: consentSession.Client.ClientName,
+		ClientURI:  consentSession.Client.ClientURI,
+		LogoURI:    consentSession.Client.LogoURI,
+		Scopes:     scopes,
+		Audience:   consentSession.RequestedAccessTokenAudience,
+	}, nil
+}
+
+// Accepts a consent request for the scopes the user granted on the consent page.
+func (a *Auth) AcceptConsent(ctx context.Context, req types.AcceptConsentRequest) (types.ConsentResult, error) {
+	consentSession, err := a.hydraClient.GetConsentSession(req.ConsentChallenge)
+	if err != nil {
+		return types.ConsentResult{}, fmt.Errorf("failed to get consent session: %w", err)
+	}
+
+	for _, scope := range req.GrantScope {
+		if !slices.Contains(consentSession.RequestedScope, scope) {
+			return types.ConsentResult{}, fmt.Errorf("%w: %s", types.ErrConsentScopeInvalid, scope)
+		}
+	}
+
+	grantScope := []string{}
+	for _, scope := range consentSession.RequestedScope {
+		if slices.Contains(requiredScopes, scope) || slices.Contains(req.GrantScope, scope) {
+			grantScope = append(grantScope, scope)
+		}
+	}
+
+	return a.acceptConsent(req.ConsentChallenge, consentSession, grantScope, req.Remember)
+}
+
+// Reports whether the client of a consent request is operated by ConformiTea:
+// the server's own client, or a client marked first-party in its metadata.
+func (a *Auth) firstPartyClient(consentSession *hydra.HydraGetConsentResponse) bool {
+	if consentSession.Client.ClientId == a.hydraClient.FirstPartyClientID() {
+		return true
+	}
+
+	firstParty, _ := consentSession.Client.Metadata[hydra.ClientMetadataFirstParty].(bool)
+
+	return firstParty
+}
+
+// Accepts the consent request for grantScope, optionally remembering the decision.
+func (a *Auth) acceptConsent(consentChallenge string, consentSession *hydra.HydraGetConsentResponse, grantScope []string, remember bool) (types.ConsentResult, error) {
+	session, err := a.buildConsentSession(consentSession, grantScope)
+	if err != nil {
+		return types.ConsentResult{}, err
+	}
 
 	acceptReq := hydra.HydraPutAcceptConsentRequest{
-		GrantScope:               consentSession.RequestedScope,
+		GrantScope:               grantScope,
 		GrantAccessTokenAudience: consentSession.RequestedAccessTokenAudience,
-		Remember:                 true,
-		RememberFor:              3600, // Remember for 1 hour
-		Session:                  a.buildConsentSession(consentSession),
+		Remember:                 remember,
+		Session:                  session,
 	}
 
-	result, err := a.hydraClient.AcceptConsentSession(req.ConsentChallenge, acceptReq)
+	if remember {
+		acceptReq.RememberFor = a.config.RememberConsentFor
+	}
+
+	result, err := a.hydraClient.AcceptConsentSession(consentChallenge, acceptReq)
 	if err != nil {
 		return types.ConsentResult{}, fmt.Errorf("failed to accept consent session: %w", err)
 	}
@@ -34,23 +136,76 @@ func (a *Auth) ProcessConsent(ctx context.Context, req types.ConsentRequest) (ty
 	}, nil
 }
 
-func (a *Auth) buildConsentSession(consentSession *hydra.HydraGetConsentResponse) hydra.HydraConsentSessionTokens {
-	// TODO: In the future, fetch user details from database and add proper claims
-	// For now, we'll add basic claims based on the subject
+// Builds the token claims of the consenting user from the database. Profile and
+// email claims are only added when the matching scope is granted.
+func (a *Auth) buildConsentSession(consentSession *hydra.HydraGetConsentResponse, grantScope []string) (hydra.HydraConsentSessionTokens, error) {
+	userID, err := uuid.Parse(consentSession.Subject)
+	if err != nil {
+		return hydra.HydraConsentSessionTokens{}, fmt.Errorf("invalid consent subject %q: %w", consentSession.Subject, err)
+	}
+
+	u, err := a.userService.GetUserByID(a.db, userID)
+	if err != nil {
+		return hydra.HydraConsentSessionTokens{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	memberships, err := a.userService.GetUserMemberships(a.db, userID)
+	if err != nil {
+		return hydra.HydraConsentSessionTokens{}, fmt.Errorf("failed to get user memberships: %w", err)
+	}
+
+	orgIDs := make([]string, 0, len(memberships))
+	orgRoles := make(map[string]string, len(memberships))
+	permissions := []string{}
+
+	for _, m := range memberships {
+		if !m.Active() {
+			continue
+		}
+
+		orgIDs = append(orgIDs, m.OrganizationID.String())
+		orgRoles[m.OrganizationID.String()] = m.Role
+
+		rolePermissions, err := a.organizationService.RolePermissions(a.db, m.OrganizationID, m.Role)
+		if err != nil {
+			return hydra.HydraConsentSessionTokens{}, fmt.Errorf("failed to get role permissions: %w", err)
+		}
+
+		for _, p := range rolePermissions {
+			if !slices.Contains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	sort.Strings(permissions)
 
 	accessTokenClaims := map[string]any{
-		"roles":       []string{"user"}, // Default role
-		"permissions": []string{"read:profile", "write:profile"},
+		"org_ids":     orgIDs,
+		"org_roles":   orgRoles,
+		"permissions": permissions,
 	}
 
 	idTokenClaims := map[string]any{
-		// Email and name will be populated from database in future
-		// For now, we'll use the subject as a placeholder
-		"sub": consentSession.Subject,
+		"org_ids":   orgIDs,
+		"org_roles": orgRoles,
+	}
+
+	if slices.Contains(grantScope, "email") {
+		accessTokenClaims["email"] = u.Email
+		idTokenClaims["email"] = u.Email
+	}
+
+	if slices.Contains(grantScope, "profile") {
+		name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+
+		accessTokenClaims["name"] = name
+		idTokenClaims["name"] = name
+		idTokenClaims["given_name"] = u.FirstName
+		idTokenClaims["family_name"] = u.LastName
 	}
 
 	return hydra.HydraConsentSessionTokens{
 		AccessToken: accessTokenClaims,
 		IDToken:     idTokenClaims,
-	}
+	}, nil
 }
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/server/types"
+)
+
+// Accepts the device authorization request the user entered a user code for. The
+// user then signs in and consents as for any other client.
+func (a *Auth) VerifyDevice(ctx context.Context, req types.DeviceVerificationRequest) (types.DeviceVerificationResult, error) {
+	userCode := strings.TrimSpace(req.UserCode)
+	if req.DeviceChallenge == "" || userCode == "" {
+		return types.DeviceVerificationResult{}, fmt.Errorf("%w: device challenge and user code are required", types.ErrUserCodeInvalid)
+	}
+
+	result, err := a.hydraClient.AcceptDeviceRequest(req.DeviceChallenge, userCode)
+	if errors.Is(err, hydra.ErrUserCodeInvalid) {
+		return types.DeviceVerificationResult{}, fmt.Errorf("%w: %w", types.ErrUserCodeInvalid, err)
+	}
+	if err != nil {
+		return types.DeviceVerificationResult{}, err
+	}
+
+	return types.DeviceVerificationResult{
+		RedirectTo: result.RedirectTo,
+	}, nil
+}
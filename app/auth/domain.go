@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Checks that a user with the email may sign in with provider. Organizations can
+// require users at their verified domains to sign in with their SAML identity
+// provider. Unverified emails are checked as well, the policy only refuses sign ins.
+func (a *Auth) checkDomainSSO(email, provider string) error {
+	domain, org, found, err := a.verifiedDomain(email)
+	if err != nil || !found {
+		return err
+	}
+
+	required := types.SAMLProviderPrefix + domain.OrganizationID.String()
+	if org.RequireSSO && provider != required {
+		return fmt.Errorf("%w: %s must sign in with %s", types.ErrSSORequired, domain.Name, required)
+	}
+
+	return nil
+}
+
+// Adds the user to the organization that verified the domain of the email the
+// identity provider signed them in with, when it adds users at the domain
+// automatically. Users only join when the provider verified the email.
+func (a *Auth) joinDomainOrganization(u user.User, profile user.ExternalProfile) error {
+	if !profile.EmailVerified {
+		return nil
+	}
+
+	domain, _, found, err := a.verifiedDomain(profile.Email)
+	if err != nil || !found || !domain.AutoJoin {
+		return err
+	}
+
+	_, err = a.userService.GetOrganizationMember(a.db, domain.OrganizationID, u.ID)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if _, err := a.userService.AddOrganizationMember(a.db, u, organization.Membership{
+		OrganizationID: domain.OrganizationID,
+		Role:           organization.RoleMember,
+	}); err != nil {
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the organization whose SAML identity provider the login must use
+// because the login hint is an email at a domain the organization requires it
+// for, or uuid.Nil. Logins asking for a provider are left alone.
+func (a *Auth) requiredSSOOrganization(loginSession *hydra.HydraLoginSession, hint string) (uuid.UUID, error) {
+	if hint == "" {
+		hint = providerHintFromRequestURL(loginSession.RequestURL)
+	}
+
+	pinned, _ := loginSession.Client.Metadata[hydra.ClientMetadataIdentityProvider].(string)
+
+	if hint != "" || pinned != "" || loginSession.OIDCContext.LoginHint == "" {
+		return uuid.Nil, nil
+	}
+
+	domain, org, found, err := a.verifiedDomain(loginSession.OIDCContext.LoginHint)
+	if err != nil || !found || !org.RequireSSO {
+		return uuid.Nil, err
+	}
+
+	return domain.OrganizationID, nil
+}
+
+// Reports whether the email is at a domain the organization verified.
+func (a *Auth) domainVerifiedBy(email string, orgID uuid.UUID) (bool, error) {
+	domain, _, found, err := a.verifiedDomain(email)
+	if err != nil {
+		return false, err
+	}
+
+	return found && domain.OrganizationID == orgID, nil
+}
+
+// Returns the organization that verified the domain of the email, if any.
+func (a *Auth) verifiedDomain(email string) (organization.Domain, organization.Organization, bool, error) {
+	name := organization.EmailDomain(email)
+	if name == "" {
+		return organization.Domain{}, organization.Organization{}, false, nil
+	}
+
+	domain, err := a.organizationService.GetVerifiedDomain(a.db, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return organization.Domain{}, organization.Organization{}, false, nil
+	}
+	if err != nil {
+		return organization.Domain{}, organization.Organization{}, false, fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	org, err := a.organizationService.GetOrganizationByID(a.db, domain.OrganizationID)
+	if err != nil {
+		return organization.Domain{}, organization.Organization{}, false, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return domain, org, true, nil
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"conformitea/domain/user"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Returns the names of the identity providers users can sign in with.
+func (a *Auth) ListProviders(ctx context.Context) []string {
+	return a.providers.Names()
+}
+
+// Returns the identity provider accounts linked to the user, the most recently
+// used first.
+func (a *Auth) ListIdentities(ctx context.Context, userID string) ([]types.LinkedIdentity, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	identities, err := a.userService.GetUserIdentities(a.db, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identities: %w", err)
+	}
+
+	result := make([]types.LinkedIdentity, 0, len(identities))
+	for _, i := range identities {
+		result = append(result, toLinkedIdentity(i))
+	}
+
+	return result, nil
+}
+
+// Starts linking an account at provider to the user, who proves they control it
+// by signing in with it. The state and nonce are checked by CompleteIdentityLink.
+func (a *Auth) BeginIdentityLink(ctx context.Context, userID, provider string) (types.IdentityLinkResult, error) {
+	u, err := a.activeUser(userID)
+	if err != nil {
+		return types.IdentityLinkResult{}, err
+	}
+
+	p, err := a.providers.Get(provider)
+	if err != nil {
+		return types.IdentityLinkResult{}, fmt.Errorf("%w: %w", types.ErrProviderNotSupported, err)
+	}
+
+	state, err := a.generateNonce()
+	if err != nil {
+		return types.IdentityLinkResult{}, fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	nonce, err := a.generateNonce()
+	if err != nil {
+		return types.IdentityLinkResult{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	authURL, err := p.GenerateAuthURL(state, nonce)
+	if err != nil {
+		return types.IdentityLinkResult{}, fmt.Errorf("failed to generate %s OAuth URL for %s: %w", p.Name(), u.ID, err)
+	}
+
+	return types.IdentityLinkResult{
+		AuthURL:  authURL,
+		Provider: p.Name(),
+		State:    state,
+		Nonce:    nonce,
+	}, nil
+}
+
+// Links the account the user signed in with at the identity provider to them.
+// Accounts already linked to another user are refused: the users are duplicates
+// of the same person, which staff resolve by merging them.
+func (a *Auth) CompleteIdentityLink(ctx context.Context, req types.IdentityLinkCallbackRequest) (types.LinkedIdentity, error) {
+	if req.ExpectedState == "" || subtle.ConstantTimeCompare([]byte(req.State), []byte(req.ExpectedState)) != 1 {
+		return types.LinkedIdentity{}, types.ErrStateMismatch
+	}
+
+	u, err := a.activeUser(req.UserID)
+	if err != nil {
+		return types.LinkedIdentity{}, err
+	}
+
+	provider, err := a.providers.Get(req.Provider)
+	if err != nil {
+		return types.LinkedIdentity{}, fmt.Errorf("%w: %w", types.ErrProviderNotSupported, err)
+	}
+
+	token, err := provider.ExchangeCodeForToken(ctx, req.Code)
+	if err != nil {
+		return types.LinkedIdentity{}, fmt.Errorf("failed to exchange code for token: %w", err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, token, req.Nonce)
+	if err != nil {
+		return types.LinkedIdentity{}, translateIDTokenError(err)
+	}
+
+	userProfile, err := provider.GetUserProfile(ctx, token)
+	if err != nil {
+		return types.LinkedIdentity{}, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	if userProfile.Email == "" {
+		return types.LinkedIdentity{}, types.ErrEmailMissing
+	}
+
+	// Accounts that could not sign in on their own cannot be linked either
+	if err := a.checkDomainSSO(userProfile.Email, provider.Name()); err != nil {
+		return types.LinkedIdentity{}, err
+	}
+
+	if _, err := a.tenantOrganization(claims); err != nil {
+		return types.LinkedIdentity{}, err
+	}
+
+	identity, err := a.userService.GetIdentity(a.db, provider.Name(), claims.Subject)
+	switch {
+	case err == nil && identity.UserID == u.ID:
+		return toLinkedIdentity(identity), nil
+	case err == nil:
+		return types.LinkedIdentity{}, fmt.Errorf("%w: ask an administrator to merge %s into %s", types.ErrIdentityConflict, identity.UserID, u.ID)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return types.LinkedIdentity{}, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	identity, err = a.userService.LinkIdentity(a.db, user.Identity{
+		UserID:   u.ID,
+		Provider: provider.Name(),
+		Subject:  claims.Subject,
+		Email:    user.NormalizeEmail(userProfile.Email),
+		TenantID: claims.TenantID,
+	})
+	if err != nil {
+		return types.LinkedIdentity{}, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return toLinkedIdentity(identity), nil
+}
+
+// Unlinks an identity provider account from the user. Users keep at least one
+// account to sign in with.
+func (a *Auth) UnlinkIdentity(ctx context.Context, userID, identityID string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	id, err := uuid.Parse(identityID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid ID %q", types.ErrIdentityNotFound, identityID)
+	}
+
+	identities, err := a.userService.GetUserIdentities(a.db, uid)
+	if err != nil {
+		return fmt.Errorf("failed to get identities: %w", err)
+	}
+
+	if len(identities) <= 1 {
+		return types.ErrIdentityRequired
+	}
+
+	deleted, err := a.userService.UnlinkIdentity(a.db, uid, id)
+	if err != nil {
+		return fmt.Errorf("failed to unlink identity: %w", err)
+	}
+
+	if !deleted {
+		return fmt.Errorf("%w: %s", types.ErrIdentityNotFound, identityID)
+	}
+
+	return nil
+}
+
+// Returns the user, unless they were deactivated.
+func (a *Auth) activeUser(userID string) (user.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return user.User{}, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	u, err := a.userService.GetUserByID(a.db, id)
+	if err != nil {
+		return user.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !u.Active() {
+		return user.User{}, fmt.Errorf("%w: %s", types.ErrUserDeactivated, u.ID)
+	}
+
+	return u, nil
+}
+
+func toLinkedIdentity(identity user.Identity) types.LinkedIdentity {
+	return types.LinkedIdentity{
+		ID:         identity.ID.String(),
+		Provider:   identity.Provider,
+		Email:      identity.Email,
+		TenantID:   identity.TenantID,
+		LinkedAt:   identity.CreatedAt,
+		LastUsedAt: identity.UpdatedAt,
+	}
+}
@@ -1,25 +1,58 @@
 package auth
 
 import (
+	"fmt"
+
+	"conformitea/app/config"
+	"conformitea/domain/apitoken"
+	"conformitea/domain/mfa"
+	"conformitea/domain/organization"
 	"conformitea/domain/user"
 	"conformitea/infrastructure/gateway/hydra"
-	"conformitea/infrastructure/gateway/microsoft"
+	"conformitea/infrastructure/gateway/idp"
+	"conformitea/infrastructure/gateway/saml"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"gorm.io/gorm"
 )
 
 type Auth struct {
-	db          *gorm.DB
-	userService *user.UserService
-	msClient    *microsoft.OAuthClient
-	hydraClient *hydra.HydraClient
+	config              config.AuthConfig
+	db                  *gorm.DB
+	userService         *user.UserService
+	organizationService *organization.OrganizationService
+	mfaService          *mfa.MFAService
+	apiTokenService     *apitoken.APITokenService
+	providers           *idp.Registry
+	hydraClient         *hydra.HydraClient
+	samlServiceProvider *saml.ServiceProvider
+	webAuthn            *webauthn.WebAuthn
 }
 
-func Initialize(db *gorm.DB, us *user.UserService, mc *microsoft.OAuthClient, hc *hydra.HydraClient) *Auth {
-	return &Auth{
-		db:          db,
-		userService: us,
-		msClient:    mc,
-		hydraClient: hc,
+func Initialize(cfg config.AuthConfig, db *gorm.DB, us *user.UserService, os *organization.OrganizationService, ms *mfa.MFAService, as *apitoken.APITokenService, pr *idp.Registry, hc *hydra.HydraClient, sp *saml.ServiceProvider) (*Auth, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid auth configuration: %w", err)
+	}
+
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: cfg.WebAuthn.RPDisplayName,
+		RPOrigins:     cfg.WebAuthn.RPOrigins,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid webauthn configuration: %w", err)
 	}
+
+	return &Auth{
+		config:              cfg,
+		db:                  db,
+		userService:         us,
+		organizationService: os,
+		mfaService:          ms,
+		apiTokenService:     as,
+		providers:           pr,
+		hydraClient:         hc,
+		samlServiceProvider: sp,
+		webAuthn:            wa,
+	}, nil
 }
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"conformitea/server/types"
+)
+
+// Token use Hydra reports for access tokens.
+const tokenUseAccessToken = "access_token"
+
+// Introspects an access token with Hydra and returns the principal it was issued
+// to. Inactive tokens and refresh tokens yield ErrTokenInactive. Tokens of the
+// client credentials grant have the client as subject and act for no user.
+func (a *Auth) IntrospectToken(ctx context.Context, token string) (types.Principal, error) {
+	tokenInfo, err := a.hydraClient.IntrospectToken(token)
+	if err != nil {
+		return types.Principal{}, fmt.Errorf("failed to introspect token: %w", err)
+	}
+
+	if !tokenInfo.Active || tokenInfo.Sub == "" {
+		return types.Principal{}, types.ErrTokenInactive
+	}
+
+	if tokenInfo.TokenUse != "" && tokenInfo.TokenUse != tokenUseAccessToken {
+		return types.Principal{}, fmt.Errorf("%w: token is a %s", types.ErrTokenInactive, tokenInfo.TokenUse)
+	}
+
+	kind := types.PrincipalUser
+	if tokenInfo.Sub == tokenInfo.ClientID {
+		kind = types.PrincipalClient
+	}
+
+	return types.Principal{
+		Subject:   tokenInfo.Sub,
+		Kind:      kind,
+		ClientID:  tokenInfo.ClientID,
+		Scopes:    strings.Fields(tokenInfo.Scope),
+		ExpiresAt: time.Unix(tokenInfo.Exp, 0),
+		Claims:    tokenInfo.Ext,
+	}, nil
+}
@@ -1,10 +1,15 @@
 package auth
 
 import (
-	"conformitea/server/types"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"slices"
+
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
 )
 
 // Creates a secure random nonce for OAuth2 state.
@@ -30,10 +35,62 @@ func (a *Auth) InitiateLogin(req types.LoginRequest) (types.LoginResult, error)
 		return types.LoginResult{}, fmt.Errorf("failed to get Hydra login session: %w", err)
 	}
 
-	// Determine IdP based on client_id
-	provider := loginSession.Client.ClientId
-	if provider != "microsoft" {
-		return types.LoginResult{}, fmt.Errorf("unsupported provider: %s", provider)
+	// Hydra already authenticated the subject, so the login is accepted without
+	// bouncing the user through the identity provider again. Hydra does not keep
+	// how the remembered login was authenticated, so it is accepted as a single
+	// factor login and clients asking for a second factor get a full login.
+	if loginSession.Skip && !multiFactorRequested(loginSession) {
+		if err := a.checkUserActive(loginSession.Subject); err != nil {
+			return types.LoginResult{}, err
+		}
+
+		redirectTo, err := a.acceptLogin(req.LoginChallenge, loginSession.Subject, nil)
+		if err != nil {
+			return types.LoginResult{}, err
+		}
+
+		return types.LoginResult{
+			RedirectTo: redirectTo,
+		}, nil
+	}
+
+	passkey, err := passkeyLoginRequested(loginSession, req.ProviderHint)
+	if err != nil {
+		return types.LoginResult{}, err
+	}
+
+	// The user authenticates with a passkey on the frontend instead of at an
+	// identity provider
+	if passkey {
+		return types.LoginResult{
+			HydraLoginChallenge: req.LoginChallenge,
+			PasskeyLogin:        true,
+		}, nil
+	}
+
+	samlOrganization, err := samlLoginRequested(loginSession, req.ProviderHint)
+	if err != nil {
+		return types.LoginResult{}, err
+	}
+
+	if samlOrganization != uuid.Nil {
+		return a.initiateSAMLLogin(req.LoginChallenge, samlOrganization)
+	}
+
+	// Users at a domain whose organization requires its identity provider are
+	// sent straight to it
+	ssoOrganization, err := a.requiredSSOOrganization(loginSession, req.ProviderHint)
+	if err != nil {
+		return types.LoginResult{}, err
+	}
+
+	if ssoOrganization != uuid.Nil {
+		return a.initiateSAMLLogin(req.LoginChallenge, ssoOrganization)
+	}
+
+	provider, err := a.resolveProvider(loginSession, req.ProviderHint)
+	if err != nil {
+		return types.LoginResult{}, err
 	}
 
 	// Generate nonce for security
@@ -43,15 +100,39 @@ func (a *Auth) InitiateLogin(req types.LoginRequest) (types.LoginResult, error)
 	}
 
 	// Generate OAuth URL
-	authURL, err := a.msClient.GenerateAuthURL(req.LoginChallenge, nonce)
+	authURL, err := provider.GenerateAuthURL(req.LoginChallenge, nonce)
 	if err != nil {
-		return types.LoginResult{}, fmt.Errorf("failed to generate Microsoft OAuth URL: %w", err)
+		return types.LoginResult{}, fmt.Errorf("failed to generate %s OAuth URL: %w", provider.Name(), err)
 	}
 
 	return types.LoginResult{
 		AuthURL:             authURL,
 		HydraLoginChallenge: req.LoginChallenge,
-		IDPProvider:         provider,
+		IDPProvider:         provider.Name(),
 		AuthNonce:           nonce,
 	}, nil
 }
+
+// Reports whether the client asked for a login with a second factor.
+func multiFactorRequested(loginSession *hydra.HydraLoginSession) bool {
+	return slices.Contains(loginSession.OIDCContext.ACRValues, acrMultiFactor)
+}
+
+// Checks that the user Hydra remembered was not deactivated since they signed in.
+func (a *Auth) checkUserActive(subject string) error {
+	id, err := uuid.Parse(subject)
+	if err != nil {
+		return fmt.Errorf("invalid subject %q: %w", subject, err)
+	}
+
+	u, err := a.userService.GetUserByID(a.db, id)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !u.Active() {
+		return fmt.Errorf("%w: %s", types.ErrUserDeactivated, u.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/server/types"
+)
+
+func TestInitiateLoginRemembered(t *testing.T) {
+	tests := []struct {
+		name        string
+		acrValues   []string
+		deactivated bool
+		wantErr     error
+		wantSkip    bool
+	}{
+		{
+			name:     "remembered login",
+			wantSkip: true,
+		},
+		{
+			name:      "remembered login for a client asking for a single factor",
+			acrValues: []string{acrSingleFactor},
+			wantSkip:  true,
+		},
+		{
+			name:      "remembered login for a client asking for a second factor",
+			acrValues: []string{acrSingleFactor, acrMultiFactor},
+		},
+		{
+			name:        "remembered login of a deactivated user",
+			deactivated: true,
+			wantErr:     types.ErrUserDeactivated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, fake := newTestAuth(t)
+
+			u := createUser(t, a, "ada@example.com")
+			if tt.deactivated {
+				deactivateUser(t, a, u.ID)
+			}
+
+			login := hydra.HydraLoginSession{Skip: true, Subject: u.ID.String()}
+			login.OIDCContext.ACRValues = tt.acrValues
+			fake.addLogin("challenge", login)
+
+			result, err := a.InitiateLogin(types.LoginRequest{LoginChallenge: "challenge"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitiateLogin() error = %v, want %v", err, tt.wantErr)
+			}
+
+			accept, accepted := fake.acceptedLogin("challenge")
+			if accepted != tt.wantSkip {
+				t.Fatalf("login accepted = %t, want %t", accepted, tt.wantSkip)
+			}
+
+			switch {
+			case tt.wantSkip:
+				// Remembered logins are accepted like any other, never claiming
+				// methods the login may not have used
+				if accept.Subject != u.ID.String() || !accept.Remember || accept.ACR != acrSingleFactor || len(accept.AMR) != 0 {
+					t.Errorf("accepted login = %+v, want a remembered single factor login of %s", accept, u.ID)
+				}
+
+				if result.RedirectTo != "https://hydra.example.com/accepted/challenge" {
+					t.Errorf("InitiateLogin() redirect = %q, want the accepted login", result.RedirectTo)
+				}
+			case tt.wantErr == nil:
+				if result.AuthURL == "" || result.IDPProvider != testProvider || result.AuthNonce == "" {
+					t.Errorf("InitiateLogin() = %+v, want the user sent to the identity provider", result)
+				}
+			}
+		})
+	}
+}
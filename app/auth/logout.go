@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"conformitea/server/types"
+)
+
+// Returns Hydra's RP-initiated logout endpoint, where the browser ends its Hydra
+// session and Hydra notifies the other clients of the subject.
+func (a *Auth) InitiateLogout(ctx context.Context) (types.LogoutResult, error) {
+	return types.LogoutResult{
+		RedirectTo: a.hydraClient.LogoutURL(),
+	}, nil
+}
+
+// Accepts a Hydra logout request.
+func (a *Auth) ProcessLogout(ctx context.Context, req types.LogoutRequest) (types.LogoutResult, error) {
+	logoutRequest, err := a.hydraClient.GetLogoutRequest(req.LogoutChallenge)
+	if err != nil {
+		return types.LogoutResult{}, fmt.Errorf("failed to get logout request: %w", err)
+	}
+
+	result, err := a.hydraClient.AcceptLogoutRequest(req.LogoutChallenge)
+	if err != nil {
+		return types.LogoutResult{}, fmt.Errorf("failed to accept logout request: %w", err)
+	}
+
+	return types.LogoutResult{
+		RedirectTo: result.RedirectTo,
+		Subject:    logoutRequest.Subject,
+	}, nil
+}
+
+// Verifies a back-channel logout token sent by Hydra. The caller refuses tokens
+// whose identifier it saw before.
+func (a *Auth) ProcessBackChannelLogout(ctx context.Context, req types.BackChannelLogoutRequest) (types.BackChannelLogoutResult, error) {
+	claims, err := a.hydraClient.VerifyLogoutToken(ctx, req.LogoutToken)
+	if err != nil {
+		return types.BackChannelLogoutResult{}, fmt.Errorf("%w: %w", types.ErrInvalidLogoutToken, err)
+	}
+
+	// Sessions are tracked per subject, so a token naming only a Hydra session is of no use
+	if claims.Subject == "" {
+		return types.BackChannelLogoutResult{}, fmt.Errorf("%w: token has no subject", types.ErrInvalidLogoutToken)
+	}
+
+	return types.BackChannelLogoutResult{
+		Subject:    claims.Subject,
+		SessionID:  claims.SessionID,
+		TokenID:    claims.TokenID,
+		ValidUntil: claims.ValidUntil,
+	}, nil
+}
@@ -0,0 +1,376 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"time"
+
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+// Issuer authenticator apps show next to the account.
+const totpIssuer = "ConformiTea"
+
+// Validity of a TOTP code in seconds.
+const totpPeriod = 30
+
+// Number of recovery codes issued to a user.
+const recoveryCodeCount = 10
+
+// Returns the MFA status of the user.
+func (a *Auth) GetMFAStatus(ctx context.Context, userID string) (types.MFAStatus, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return types.MFAStatus{}, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	totpEnabled, passkeys, err := a.mfaFactors(id)
+	if err != nil {
+		return types.MFAStatus{}, err
+	}
+
+	required, err := a.organizationService.RequiresMFA(a.db, id)
+	if err != nil {
+		return types.MFAStatus{}, fmt.Errorf("failed to check organization MFA policy: %w", err)
+	}
+
+	remaining, err := a.mfaService.CountUnusedRecoveryCodes(a.db, id)
+	if err != nil {
+		return types.MFAStatus{}, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return types.MFAStatus{
+		TOTPEnabled:            totpEnabled,
+		Passkeys:               passkeys,
+		RecoveryCodesRemaining: remaining,
+		Required:               required,
+	}, nil
+}
+
+// Generates a new authenticator secret for the user. It is used to sign in once
+// the user confirms it with a code.
+func (a *Auth) BeginTOTPEnrollment(ctx context.Context, userID string) (types.TOTPEnrollment, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return types.TOTPEnrollment{}, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	factor, err := a.mfaService.GetTOTPFactor(a.db, id)
+	switch {
+	case err == nil && factor.Confirmed():
+		return types.TOTPEnrollment{}, types.ErrMFAAlreadyEnrolled
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return types.TOTPEnrollment{}, fmt.Errorf("failed to get authenticator: %w", err)
+	}
+
+	u, err := a.userService.GetUserByID(a.db, id)
+	if err != nil {
+		return types.TOTPEnrollment{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      totpIssuer,
+		AccountName: u.Email,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return types.TOTPEnrollment{}, fmt.Errorf("failed to generate authenticator secret: %w", err)
+	}
+
+	if _, err := a.mfaService.EnrollTOTPFactor(a.db, id, key.Secret()); err != nil {
+		return types.TOTPEnrollment{}, fmt.Errorf("failed to store authenticator: %w", err)
+	}
+
+	qrCode, err := qrCodeDataURL(key)
+	if err != nil {
+		return types.TOTPEnrollment{}, err
+	}
+
+	return types.TOTPEnrollment{
+		Secret: key.Secret(),
+		URL:    key.URL(),
+		QRCode: qrCode,
+	}, nil
+}
+
+// Confirms the user's new authenticator with a code and issues their recovery
+// codes. When enrolling while signing in, the pending login is accepted as well.
+func (a *Auth) ConfirmTOTPEnrollment(ctx context.Context, req types.TOTPConfirmRequest) (types.TOTPConfirmResult, error) {
+	id, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return types.TOTPConfirmResult{}, fmt.Errorf("invalid user ID %q: %w", req.UserID, err)
+	}
+
+	factor, err := a.mfaService.GetTOTPFactor(a.db, id)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return types.TOTPConfirmResult{}, types.ErrMFANotEnrolled
+	case err != nil:
+		return types.TOTPConfirmResult{}, fmt.Errorf("failed to get authenticator: %w", err)
+	case factor.Confirmed():
+		return types.TOTPConfirmResult{}, types.ErrMFAAlreadyEnrolled
+	}
+
+	step, ok := matchTOTPStep(factor.Secret, req.Code, time.Now())
+	if !ok {
+		return types.TOTPConfirmResult{}, types.ErrMFACodeInvalid
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return types.TOTPConfirmResult{}, err
+	}
+
+	if _, err := a.mfaService.ConfirmTOTPFactor(a.db, factor, step, hashes); err != nil {
+		return types.TOTPConfirmResult{}, fmt.Errorf("failed to confirm authenticator: %w", err)
+	}
+
+	result := types.TOTPConfirmResult{
+		RecoveryCodes: codes,
+	}
+
+	if req.LoginChallenge != "" {
+		result.RedirectTo, err = a.acceptLogin(req.LoginChallenge, req.UserID, []string{amrFederated, amrOneTimeCode, amrMultiFactor})
+		if err != nil {
+			return types.TOTPConfirmResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// Removes the user's authenticator after checking a current code. Users of an
+// organization requiring MFA cannot remove it unless they have a passkey.
+func (a *Auth) DisableTOTP(ctx context.Context, userID, code string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	required, err := a.organizationService.RequiresMFA(a.db, id)
+	if err != nil {
+		return fmt.Errorf("failed to check organization MFA policy: %w", err)
+	}
+
+	if required {
+		passkeys, err := a.mfaService.CountPasskeys(a.db, id)
+		if err != nil {
+			return fmt.Errorf("failed to count passkeys: %w", err)
+		}
+
+		if passkeys == 0 {
+			return types.ErrMFARequired
+		}
+	}
+
+	if err := a.verifyTOTP(id, code); err != nil {
+		return err
+	}
+
+	if err := a.mfaService.DisableTOTPFactor(a.db, id); err != nil {
+		return fmt.Errorf("failed to disable authenticator: %w", err)
+	}
+
+	return nil
+}
+
+// Replaces the user's recovery codes after checking a current code.
+func (a *Auth) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	if err := a.verifyTOTP(id, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.mfaService.ReplaceRecoveryCodes(a.db, id, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Checks the second factor of a pending login and accepts it.
+func (a *Auth) VerifyMFA(ctx context.Context, req types.MFAVerifyRequest) (types.MFAVerifyResult, error) {
+	id, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return types.MFAVerifyResult{}, fmt.Errorf("invalid user ID %q: %w", req.UserID, err)
+	}
+
+	switch req.Method {
+	case types.MFAMethodTOTP:
+		if err := a.verifyTOTP(id, req.Code); err != nil {
+			return types.MFAVerifyResult{}, err
+		}
+	case types.MFAMethodRecoveryCode:
+		used, err := a.mfaService.UseRecoveryCode(a.db, id, hashRecoveryCode(req.Code))
+		if err != nil {
+			return types.MFAVerifyResult{}, fmt.Errorf("failed to use recovery code: %w", err)
+		}
+
+		if !used {
+			return types.MFAVerifyResult{}, types.ErrMFACodeInvalid
+		}
+	default:
+		return types.MFAVerifyResult{}, fmt.Errorf("%w: unknown method %q", types.ErrMFACodeInvalid, req.Method)
+	}
+
+	redirectTo, err := a.acceptLogin(req.LoginChallenge, req.UserID, []string{amrFederated, amrOneTimeCode, amrMultiFactor})
+	if err != nil {
+		return types.MFAVerifyResult{}, err
+	}
+
+	return types.MFAVerifyResult{
+		RedirectTo: redirectTo,
+	}, nil
+}
+
+// Reports whether the user has a second factor and whether an organization of
+// theirs requires one.
+func (a *Auth) mfaPolicy(userID uuid.UUID) (enrolled bool, required bool, err error) {
+	totpEnabled, passkeys, err := a.mfaFactors(userID)
+	if err != nil {
+		return false, false, err
+	}
+
+	required, err = a.organizationService.RequiresMFA(a.db, userID)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to check organization MFA policy: %w", err)
+	}
+
+	return totpEnabled || passkeys > 0, required, nil
+}
+
+// Reports whether the user has a confirmed authenticator and how many passkeys
+// they have.
+func (a *Auth) mfaFactors(userID uuid.UUID) (totpEnabled bool, passkeys int, err error) {
+	factor, err := a.mfaService.GetTOTPFactor(a.db, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, 0, fmt.Errorf("failed to get authenticator: %w", err)
+	}
+
+	totpEnabled = err == nil && factor.Confirmed()
+
+	passkeys, err = a.mfaService.CountPasskeys(a.db, userID)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to count passkeys: %w", err)
+	}
+
+	return totpEnabled, passkeys, nil
+}
+
+// Checks a code of the user's confirmed authenticator. Each code is accepted once.
+func (a *Auth) verifyTOTP(userID uuid.UUID, code string) error {
+	factor, err := a.mfaService.GetTOTPFactor(a.db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !factor.Confirmed()) {
+		return types.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get authenticator: %w", err)
+	}
+
+	step, ok := matchTOTPStep(factor.Secret, code, time.Now())
+	if !ok {
+		return types.ErrMFACodeInvalid
+	}
+
+	fresh, err := a.mfaService.UseTOTPStep(a.db, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record authenticator use: %w", err)
+	}
+
+	if !fresh {
+		return fmt.Errorf("%w: code already used", types.ErrMFACodeInvalid)
+	}
+
+	return nil
+}
+
+// Returns the time step code is valid for, allowing one step of clock drift.
+func matchTOTPStep(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	current := now.Unix() / totpPeriod
+
+	for _, step := range []int64{current, current - 1, current + 1} {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// Returns new recovery codes and their hashes.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		code = code[:4] + "-" + code[4:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// Hashes a recovery code, ignoring case, spaces and dashes.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Renders the key's otpauth URL as a PNG QR code data URL.
+func qrCodeDataURL(key *otp.Key) (string, error) {
+	img, err := key.Image(200, 200)
+	if err != nil {
+		return "", fmt.Errorf("failed to render QR code: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
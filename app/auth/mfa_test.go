@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// Returns the authenticator code of secret for a time step.
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+
+	code, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+
+	return code
+}
+
+// Returns the current time step, waiting for the next one when the current one
+// ends too soon for the test to finish within it.
+func currentTOTPStep() int64 {
+	if time.Now().Unix()%totpPeriod >= totpPeriod-2 {
+		time.Sleep(2 * time.Second)
+	}
+
+	return time.Now().Unix() / totpPeriod
+}
+
+// Gives the user a confirmed authenticator with the recovery codes of the given
+// hashes. Returns its secret.
+func enrollTOTP(t *testing.T, a *Auth, userID uuid.UUID, confirmedStep int64, recoveryCodeHashes []string) string {
+	t.Helper()
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: totpIssuer, AccountName: userID.String(), Period: totpPeriod})
+	if err != nil {
+		t.Fatalf("failed to generate secret: %v", err)
+	}
+
+	factor, err := a.mfaService.EnrollTOTPFactor(a.db, userID, key.Secret())
+	if err != nil {
+		t.Fatalf("failed to enroll authenticator: %v", err)
+	}
+
+	if _, err := a.mfaService.ConfirmTOTPFactor(a.db, factor, confirmedStep, recoveryCodeHashes); err != nil {
+		t.Fatalf("failed to confirm authenticator: %v", err)
+	}
+
+	return key.Secret()
+}
+
+func TestVerifyMFATOTP(t *testing.T) {
+	type attempt struct {
+		// Time step of the code relative to the current one
+		step    int64
+		wantErr error
+	}
+
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name:     "current code",
+			attempts: []attempt{{step: 0}},
+		},
+		{
+			name:     "code of the previous step",
+			attempts: []attempt{{step: -1}},
+		},
+		{
+			name:     "code of a step outside the allowed drift",
+			attempts: []attempt{{step: -2, wantErr: types.ErrMFACodeInvalid}},
+		},
+		{
+			name:     "code replayed",
+			attempts: []attempt{{step: 0}, {step: 0, wantErr: types.ErrMFACodeInvalid}},
+		},
+		{
+			name:     "code of the next step replayed",
+			attempts: []attempt{{step: 1}, {step: 1, wantErr: types.ErrMFACodeInvalid}},
+		},
+		{
+			name:     "code of a later step after an earlier one",
+			attempts: []attempt{{step: -1}, {step: 0}},
+		},
+		{
+			name:     "code of an earlier step after a later one",
+			attempts: []attempt{{step: 0}, {step: -1, wantErr: types.ErrMFACodeInvalid}},
+		},
+		{
+			name:     "code of the step confirming the authenticator",
+			attempts: []attempt{{step: -3, wantErr: types.ErrMFACodeInvalid}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, fake := newTestAuth(t)
+
+			u := createUser(t, a, "ada@example.com")
+			current := currentTOTPStep()
+			secret := enrollTOTP(t, a, u.ID, current-3, nil)
+
+			for i, at := range tt.attempts {
+				challenge := fmt.Sprintf("challenge-%d", i)
+
+				_, err := a.VerifyMFA(context.Background(), types.MFAVerifyRequest{
+					LoginChallenge: challenge,
+					UserID:         u.ID.String(),
+					Method:         types.MFAMethodTOTP,
+					Code:           totpCode(t, secret, current+at.step),
+				})
+				if !errors.Is(err, at.wantErr) {
+					t.Fatalf("attempt %d: VerifyMFA() error = %v, want %v", i, err, at.wantErr)
+				}
+
+				accept, accepted := fake.acceptedLogin(challenge)
+				if accepted != (at.wantErr == nil) {
+					t.Fatalf("attempt %d: login accepted = %t, want %t", i, accepted, at.wantErr == nil)
+				}
+				if accepted && accept.ACR != acrMultiFactor {
+					t.Errorf("attempt %d: accepted ACR = %q, want %q", i, accept.ACR, acrMultiFactor)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyMFARecoveryCode(t *testing.T) {
+	codes := []string{"abcd-efgh", "ijkl-mnop", "qrst-uvwx"}
+
+	type attempt struct {
+		code    string
+		wantErr error
+	}
+
+	tests := []struct {
+		name          string
+		attempts      []attempt
+		wantRemaining int
+	}{
+		{
+			name:          "recovery code",
+			attempts:      []attempt{{code: "abcd-efgh"}},
+			wantRemaining: 2,
+		},
+		{
+			name:          "recovery code typed differently",
+			attempts:      []attempt{{code: " ABCD EFGH"}},
+			wantRemaining: 2,
+		},
+		{
+			name:          "recovery code used twice",
+			attempts:      []attempt{{code: "abcd-efgh"}, {code: "ABCDEFGH", wantErr: types.ErrMFACodeInvalid}},
+			wantRemaining: 2,
+		},
+		{
+			name:          "different recovery codes",
+			attempts:      []attempt{{code: "abcd-efgh"}, {code: "ijkl-mnop"}},
+			wantRemaining: 1,
+		},
+		{
+			name:          "unknown recovery code",
+			attempts:      []attempt{{code: "aaaa-aaaa", wantErr: types.ErrMFACodeInvalid}},
+			wantRemaining: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, fake := newTestAuth(t)
+
+			hashes := make([]string, 0, len(codes))
+			for _, code := range codes {
+				hashes = append(hashes, hashRecoveryCode(code))
+			}
+
+			u := createUser(t, a, "ada@example.com")
+			enrollTOTP(t, a, u.ID, currentTOTPStep(), hashes)
+
+			for i, at := range tt.attempts {
+				challenge := fmt.Sprintf("challenge-%d", i)
+
+				_, err := a.VerifyMFA(context.Background(), types.MFAVerifyRequest{
+					LoginChallenge: challenge,
+					UserID:         u.ID.String(),
+					Method:         types.MFAMethodRecoveryCode,
+					Code:           at.code,
+				})
+				if !errors.Is(err, at.wantErr) {
+					t.Fatalf("attempt %d: VerifyMFA() error = %v, want %v", i, err, at.wantErr)
+				}
+
+				if _, accepted := fake.acceptedLogin(challenge); accepted != (at.wantErr == nil) {
+					t.Fatalf("attempt %d: login accepted = %t, want %t", i, accepted, at.wantErr == nil)
+				}
+			}
+
+			remaining, err := a.mfaService.CountUnusedRecoveryCodes(a.db, u.ID)
+			if err != nil {
+				t.Fatalf("failed to count recovery codes: %v", err)
+			}
+			if remaining != tt.wantRemaining {
+				t.Errorf("recovery codes remaining = %d, want %d", remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"conformitea/domain/mfa"
+	"conformitea/domain/user"
+	"conformitea/server/types"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+// Name of passkeys the user did not name.
+const defaultPasskeyName = "Passkey"
+
+// passkeyUser is a user and their passkeys as seen by the WebAuthn ceremonies.
+// The user handle stored in passkeys is the user ID.
+type passkeyUser struct {
+	user     user.User
+	passkeys []mfa.Passkey
+}
+
+func (u passkeyUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+func (u passkeyUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u passkeyUser) WebAuthnDisplayName() string {
+	if name := strings.TrimSpace(u.user.FirstName + " " + u.user.LastName); name != "" {
+		return name
+	}
+
+	return u.user.Email
+}
+
+func (u passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.passkeys))
+	for _, p := range u.passkeys {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(p.Transports))
+		for _, t := range p.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+
+		credentials = append(credentials, webauthn.Credential{
+			ID:              p.CredentialID,
+			PublicKey:       p.PublicKey,
+			AttestationType: p.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: p.BackupEligible,
+				BackupState:    p.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    p.AAGUID,
+				SignCount: p.SignCount,
+			},
+		})
+	}
+
+	return credentials
+}
+
+// Returns the passkey the credential was created from.
+func (u passkeyUser) passkey(credential *webauthn.Credential) (mfa.Passkey, bool) {
+	i := slices.IndexFunc(u.passkeys, func(p mfa.Passkey) bool {
+		return bytes.Equal(p.CredentialID, credential.ID)
+	})
+	if i < 0 {
+		return mfa.Passkey{}, false
+	}
+
+	return u.passkeys[i], true
+}
+
+// Returns the passkeys of the user.
+func (a *Auth) ListPasskeys(ctx context.Context, userID string) ([]types.Passkey, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	passkeys, err := a.mfaService.GetPasskeys(a.db, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkeys: %w", err)
+	}
+
+	result := make([]types.Passkey, 0, len(passkeys))
+	for _, p := range passkeys {
+		result = append(result, toPasskey(p))
+	}
+
+	return result, nil
+}
+
+// Starts the registration of a new passkey for the user. Passkeys are created as
+// discoverable credentials so they can be used to sign in without an identity
+// provider.
+func (a *Auth) BeginPasskeyRegistration(ctx context.Context, userID string) (types.PasskeyCeremony, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return types.PasskeyCeremony{}, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	u, err := a.passkeyUser(id)
+	if err != nil {
+		return types.PasskeyCeremony{}, err
+	}
+
+	creation, session, err := a.webAuthn.BeginRegistration(u,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+		webauthn.WithExclusions(webauthn.Credentials(u.WebAuthnCredentials()).CredentialDescriptors()),
+	)
+	if err != nil {
+		return types.PasskeyCeremony{}, fmt.Errorf("failed to begin passkey registration: %w", err)
+	}
+
+	return passkeyCeremony(creation, session)
+}
+
+// Verifies the credential the browser created and stores it as a passkey of the
+// user. Recovery codes are issued when it is the user's first second factor.
+func (a *Auth) FinishPasskeyRegistration(ctx context.Context, req types.PasskeyRegistrationRequest) (types.PasskeyRegistrationResult, error) {
+	id, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return types.PasskeyRegistrationResult{}, fmt.Errorf("invalid user ID %q: %w", req.UserID, err)
+	}
+
+	session, err := ceremonySession(req.State)
+	if err != nil {
+		return types.PasskeyRegistrationResult{}, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Response)
+	if err != nil {
+		return types.PasskeyRegistrationResult{}, fmt.Errorf("%w: %w", types.ErrPasskeyInvalid, err)
+	}
+
+	u, err := a.passkeyUser(id)
+	if err != nil {
+		return types.PasskeyRegistrationResult{}, err
+	}
+
+	credential, err := a.webAuthn.CreateCredential(u, session, parsed)
+	if err != nil {
+		return types.PasskeyRegistrationResult{}, fmt.Errorf("%w: %w", types.ErrPasskeyInvalid, err)
+	}
+
+	totpEnabled, passkeys, err := a.mfaFactors(id)
+	if err != nil {
+		return types.PasskeyRegistrationResult{}, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = defaultPasskeyName
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, t := range credential.Transport {
+		transports = append(transports, string(t))
+	}
+
+	passkey, err := a.mfaService.AddPasskey(a.db, mfa.Passkey{
+		UserID:          id,
+		Name:            name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		Transports:      transports,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	})
+	if err != nil {
+		return types.PasskeyRegistrationResult{}, fmt.Errorf("failed to store passkey: %w", err)
+	}
+
+	result := types.PasskeyRegistrationResult{
+		Passkey: toPasskey(passkey),
+	}
+
+	if !totpEnabled && passkeys == 0 {
+		codes, hashes, err := generateRecoveryCodes()
+		if err != nil {
+			return types.PasskeyRegistrationResult{}, err
+		}
+
+		if err := a.mfaService.ReplaceRecoveryCodes(a.db, id, hashes); err != nil {
+			return types.PasskeyRegistrationResult{}, fmt.Errorf("failed to store recovery codes: %w", err)
+		}
+
+		result.RecoveryCodes = codes
+	}
+
+	return result, nil
+}
+
+// Removes a passkey of the user. Users of an organization requiring MFA cannot
+// remove their last second factor.
+func (a *Auth) DeletePasskey(ctx context.Context, userID, passkeyID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	pid, err := uuid.Parse(passkeyID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", types.ErrPasskeyNotFound, err)
+	}
+
+	required, err := a.organizationService.RequiresMFA(a.db, id)
+	if err != nil {
+		return fmt.Errorf("failed to check organization MFA policy: %w", err)
+	}
+
+	if required {
+		totpEnabled, passkeys, err := a.mfaFactors(id)
+		if err != nil {
+			return err
+		}
+
+		if !totpEnabled && passkeys <= 1 {
+			return types.ErrMFARequired
+		}
+	}
+
+	deleted, err := a.mfaService.RemovePasskey(a.db, id, pid)
+	if err != nil {
+		return fmt.Errorf("failed to delete passkey: %w", err)
+	}
+
+	if !deleted {
+		return types.ErrPasskeyNotFound
+	}
+
+	return nil
+}
+
+// Starts a passkey assertion for a pending login. With a user ID it checks the
+// second factor of that user, without one the user signs in with a passkey alone
+// and has to verify themselves to the authenticator.
+func (a *Auth) BeginPasskeyLogin(ctx context.Context, userID string) (types.PasskeyCeremony, error) {
+	if userID == "" {
+		assertion, session, err := a.webAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+		if err != nil {
+			return types.PasskeyCeremony{}, fmt.Errorf("failed to begin passkey login: %w", err)
+		}
+
+		return passkeyCeremony(assertion, session)
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return types.PasskeyCeremony{}, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+
+	u, err := a.passkeyUser(id)
+	if err != nil {
+		return types.PasskeyCeremony{}, err
+	}
+
+	if len(u.passkeys) == 0 {
+		return types.PasskeyCeremony{}, types.ErrMFANotEnrolled
+	}
+
+	assertion, session, err := a.webAuthn.BeginLogin(u)
+	if err != nil {
+		return types.PasskeyCeremony{}, fmt.Errorf("failed to begin passkey login: %w", err)
+	}
+
+	return passkeyCeremony(assertion, session)
+}
+
+// Verifies the assertion the browser returned and accepts the pending login.
+func (a *Auth) FinishPasskeyLogin(ctx context.Context, req types.PasskeyLoginRequest) (types.PasskeyLoginResult, error) {
+	session, err := ceremonySession(req.State)
+	if err != nil {
+		return types.PasskeyLoginResult{}, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Response)
+	if err != nil {
+		return types.PasskeyLoginResult{}, fmt.Errorf("%w: %w", types.ErrPasskeyInvalid, err)
+	}
+
+	var (
+		u          passkeyUser
+		credential *webauthn.Credential
+		amr        []string
+	)
+
+	if req.UserID != "" {
+		id, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return types.PasskeyLoginResult{}, fmt.Errorf("invalid user ID %q: %w", req.UserID, err)
+		}
+
+		if u, err = a.passkeyUser(id); err != nil {
+			return types.PasskeyLoginResult{}, err
+		}
+
+		if credential, err = a.webAuthn.ValidateLogin(u, session, parsed); err != nil {
+			return types.PasskeyLoginResult{}, fmt.Errorf("%w: %w", types.ErrPasskeyInvalid, err)
+		}
+
+		amr = []string{amrFederated, amrHardwareKey, amrMultiFactor}
+	} else {
+		// The authenticator verified the user, so the passkey is both factors
+		handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+			id, err := uuid.FromBytes(userHandle)
+			if err != nil {
+				return nil, fmt.Errorf("invalid user handle: %w", err)
+			}
+
+			return a.passkeyUser(id)
+		}
+
+		found, c, err := a.webAuthn.ValidatePasskeyLogin(handler, session, parsed)
+		if err != nil {
+			return types.PasskeyLoginResult{}, fmt.Errorf("%w: %w", types.ErrPasskeyInvalid, err)
+		}
+
+		u, credential = found.(passkeyUser), c
+		amr = []string{amrHardwareKey, amrMultiFactor}
+	}
+
+	if !u.user.Active() {
+		return types.PasskeyLoginResult{}, fmt.Errorf("%w: %s", types.ErrUserDeactivated, u.user.ID)
+	}
+
+	// Passkeys used as a second factor follow an identity provider sign in, which
+	// applied the domain policy already. Passkeys do not verify the email, users
+	// join domain organizations when an identity provider signs them in.
+	if req.UserID == "" {
+		if err := a.checkDomainSSO(u.user.Email, types.PasskeyProvider); err != nil {
+			return types.PasskeyLoginResult{}, err
+		}
+	}
+
+	if credential.Authenticator.CloneWarning {
+		return types.PasskeyLoginResult{}, fmt.Errorf("%w: signature counter went backwards, the authenticator may be cloned", types.ErrPasskeyInvalid)
+	}
+
+	passkey, ok := u.passkey(credential)
+	if !ok {
+		return types.PasskeyLoginResult{}, types.ErrPasskeyInvalid
+	}
+
+	if err := a.mfaService.RecordPasskeyUse(a.db, passkey.ID, credential.Authenticator.SignCount, credential.Flags.BackupState); err != nil {
+		return types.PasskeyLoginResult{}, fmt.Errorf("failed to record passkey use: %w", err)
+	}
+
+	subject := u.user.ID.String()
+
+	redirectTo, err := a.acceptLogin(req.LoginChallenge, subject, amr)
+	if err != nil {
+		return types.PasskeyLoginResult{}, err
+	}
+
+	return types.PasskeyLoginResult{
+		RedirectTo: redirectTo,
+		Subject:    subject,
+	}, nil
+}
+
+func (a *Auth) passkeyUser(userID uuid.UUID) (passkeyUser, error) {
+	u, err := a.userService.GetUserByID(a.db, userID)
+	if err != nil {
+		return passkeyUser{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	passkeys, err := a.mfaService.GetPasskeys(a.db, userID)
+	if err != nil {
+		return passkeyUser{}, fmt.Errorf("failed to get passkeys: %w", err)
+	}
+
+	return passkeyUser{
+		user:     u,
+		passkeys: passkeys,
+	}, nil
+}
+
+// Encodes the browser options and the state of a ceremony.
+func passkeyCeremony(options any, session *webauthn.SessionData) (types.PasskeyCeremony, error) {
+	encodedOptions, err := json.Marshal(options)
+	if err != nil {
+		return types.PasskeyCeremony{}, fmt.Errorf("failed to encode passkey options: %w", err)
+	}
+
+	state, err := json.Marshal(session)
+	if err != nil {
+		return types.PasskeyCeremony{}, fmt.Errorf("failed to encode passkey ceremony: %w", err)
+	}
+
+	return types.PasskeyCeremony{
+		Options: encodedOptions,
+		State:   string(state),
+	}, nil
+}
+
+// Decodes the state of a ceremony kept in the session.
+func ceremonySession(state string) (webauthn.SessionData, error) {
+	var session webauthn.SessionData
+
+	if state == "" {
+		return session, fmt.Errorf("%w: no passkey ceremony in progress", types.ErrPasskeyInvalid)
+	}
+
+	if err := json.Unmarshal([]byte(state), &session); err != nil {
+		return session, fmt.Errorf("%w: %w", types.ErrPasskeyInvalid, err)
+	}
+
+	return session, nil
+}
+
+func toPasskey(p mfa.Passkey) types.Passkey {
+	return types.Passkey{
+		ID:             p.ID.String(),
+		Name:           p.Name,
+		BackupEligible: p.BackupEligible,
+		LastUsedAt:     p.LastUsedAt,
+		CreatedAt:      p.CreatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/infrastructure/gateway/idp"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+)
+
+// Parameter of the authorization request naming the identity provider to sign in with.
+const providerHintParam = "provider"
+
+// Picks the identity provider for a Hydra login request. An explicit hint, given to
+// the login endpoint or as a "provider" parameter of the original authorization
+// request, wins over the provider pinned in the client metadata. When neither is
+// present and exactly one provider is configured, that provider is used.
+func (a *Auth) resolveProvider(loginSession *hydra.HydraLoginSession, hint string) (idp.IdentityProvider, error) {
+	if hint == "" {
+		hint = providerHintFromRequestURL(loginSession.RequestURL)
+	}
+
+	pinned, _ := loginSession.Client.Metadata[hydra.ClientMetadataIdentityProvider].(string)
+
+	var name string
+	switch {
+	case hint != "" && pinned != "" && hint != pinned:
+		return nil, fmt.Errorf("%w: client %s does not allow provider %s", types.ErrProviderNotSupported, loginSession.Client.ClientId, hint)
+	case hint != "":
+		name = hint
+	case pinned != "":
+		name = pinned
+	default:
+		names := a.providers.Names()
+		if len(names) != 1 {
+			return nil, fmt.Errorf("%w: no provider selected for client %s", types.ErrProviderNotSupported, loginSession.Client.ClientId)
+		}
+		name = names[0]
+	}
+
+	provider, err := a.providers.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", types.ErrProviderNotSupported, err)
+	}
+
+	return provider, nil
+}
+
+// Reports whether the login asks to sign in with a passkey. Clients pinned to an
+// identity provider cannot be signed in to with a passkey.
+func passkeyLoginRequested(loginSession *hydra.HydraLoginSession, hint string) (bool, error) {
+	if hint == "" {
+		hint = providerHintFromRequestURL(loginSession.RequestURL)
+	}
+
+	if hint != types.PasskeyProvider {
+		return false, nil
+	}
+
+	if pinned, _ := loginSession.Client.Metadata[hydra.ClientMetadataIdentityProvider].(string); pinned != "" {
+		return false, fmt.Errorf("%w: client %s does not allow passkey sign in", types.ErrProviderNotSupported, loginSession.Client.ClientId)
+	}
+
+	return true, nil
+}
+
+// Returns the organization whose SAML identity provider the login asks to sign in
+// with, through a "saml:<organization ID>" hint or client metadata, or uuid.Nil
+// when the login uses another provider.
+func samlLoginRequested(loginSession *hydra.HydraLoginSession, hint string) (uuid.UUID, error) {
+	if hint == "" {
+		hint = providerHintFromRequestURL(loginSession.RequestURL)
+	}
+
+	pinned, _ := loginSession.Client.Metadata[hydra.ClientMetadataIdentityProvider].(string)
+
+	// resolveProvider reports hints the client does not allow
+	if hint != "" && pinned != "" && hint != pinned {
+		return uuid.Nil, nil
+	}
+
+	name := hint
+	if name == "" {
+		name = pinned
+	}
+
+	id, ok := strings.CutPrefix(name, types.SAMLProviderPrefix)
+	if !ok {
+		return uuid.Nil, nil
+	}
+
+	orgID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid organization %q", types.ErrProviderNotSupported, id)
+	}
+
+	return orgID, nil
+}
+
+// Extracts the "provider" parameter from the authorization request Hydra received.
+func providerHintFromRequestURL(requestURL string) string {
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Query().Get(providerHintParam)
+}
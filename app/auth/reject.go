@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/server/types"
+)
+
+// Rejects a Hydra login request so the OAuth2 client receives the error.
+func (a *Auth) RejectLogin(ctx context.Context, req types.RejectRequest) (types.RejectResult, error) {
+	result, err := a.hydraClient.RejectLoginSession(req.Challenge, hydra.RejectRequest{
+		Error:            req.Error,
+		ErrorDescription: req.ErrorDescription,
+	})
+	if err != nil {
+		return types.RejectResult{}, fmt.Errorf("failed to reject login session: %w", err)
+	}
+
+	return types.RejectResult{
+		RedirectTo: result.RedirectTo,
+	}, nil
+}
+
+// Rejects a Hydra consent request so the OAuth2 client receives the error.
+func (a *Auth) RejectConsent(ctx context.Context, req types.RejectRequest) (types.RejectResult, error) {
+	result, err := a.hydraClient.RejectConsentSession(req.Challenge, hydra.RejectRequest{
+		Error:            req.Error,
+		ErrorDescription: req.ErrorDescription,
+	})
+	if err != nil {
+		return types.RejectResult{}, fmt.Errorf("failed to reject consent session: %w", err)
+	}
+
+	return types.RejectResult{
+		RedirectTo: result.RedirectTo,
+	}, nil
+}
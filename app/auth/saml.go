@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/saml"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attributes user fields are read from when the connection maps no attribute to
+// them, as sent by commonly used identity providers.
+var (
+	samlEmailAttributes = []string{
+		"email",
+		"mail",
+		"emailAddress",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+		"urn:oid:0.9.2342.19200300.100.1.3",
+	}
+	samlFirstNameAttributes = []string{
+		"firstName",
+		"givenName",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname",
+		"urn:oid:2.5.4.42",
+	}
+	samlLastNameAttributes = []string{
+		"lastName",
+		"sn",
+		"surname",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname",
+		"urn:oid:2.5.4.4",
+	}
+)
+
+// Verifies the response the organization's SAML identity provider posted and
+// completes the Hydra flow like ProcessCallback. Users are added to the
+// organization on their first sign in. The identity provider cannot sign in
+// users of other organizations, even when it asserts their email.
+func (a *Auth) ProcessSAMLResponse(ctx context.Context, req types.SAMLResponseRequest) (types.CallbackResult, error) {
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		return types.CallbackResult{}, fmt.Errorf("%w: invalid organization %q", types.ErrSAMLNotConfigured, req.OrganizationID)
+	}
+
+	connection, err := a.samlConnection(orgID)
+	if err != nil {
+		return types.CallbackResult{}, err
+	}
+
+	assertion, err := a.samlServiceProvider.ParseResponse(orgID.String(), []byte(connection.MetadataXML), req.SAMLResponse, req.RequestID)
+	if err != nil {
+		return types.CallbackResult{}, fmt.Errorf("%w: %w", types.ErrSAMLResponseInvalid, err)
+	}
+
+	return a.signInSAMLUser(req.HydraLoginChallenge, orgID, samlProfile(connection, assertion))
+}
+
+// Returns the metadata of the organization's SAML service provider. It is served
+// before an identity provider is registered, so it can be registered with it.
+func (a *Auth) GetSAMLMetadata(ctx context.Context, organizationID string) ([]byte, error) {
+	orgID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid organization %q", types.ErrSAMLNotConfigured, organizationID)
+	}
+
+	if _, err := a.organizationService.GetOrganizationByID(a.db, orgID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: unknown organization %s", types.ErrSAMLNotConfigured, orgID)
+		}
+
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	metadata, err := a.samlServiceProvider.Metadata(orgID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create saml metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// Sends the user to the organization's SAML identity provider.
+func (a *Auth) initiateSAMLLogin(loginChallenge string, orgID uuid.UUID) (types.LoginResult, error) {
+	connection, err := a.samlConnection(orgID)
+	if err != nil {
+		return types.LoginResult{}, err
+	}
+
+	authnRequest, err := a.samlServiceProvider.AuthnRequest(orgID.String(), []byte(connection.MetadataXML))
+	if err != nil {
+		return types.LoginResult{}, fmt.Errorf("failed to create saml authentication request: %w", err)
+	}
+
+	return types.LoginResult{
+		AuthURL:             authnRequest.URL,
+		HydraLoginChallenge: loginChallenge,
+		IDPProvider:         types.SAMLProviderPrefix + orgID.String(),
+		SAMLRequestID:       authnRequest.ID,
+	}, nil
+}
+
+func (a *Auth) samlConnection(orgID uuid.UUID) (organization.SAMLConnection, error) {
+	connection, err := a.organizationService.GetSAMLConnection(a.db, orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return organization.SAMLConnection{}, fmt.Errorf("%w: %s", types.ErrSAMLNotConfigured, orgID)
+	}
+	if err != nil {
+		return organization.SAMLConnection{}, fmt.Errorf("failed to get saml connection: %w", err)
+	}
+
+	return connection, nil
+}
+
+// Signs in the user the organization's identity provider asserted and adds them
+// to the organization. Members at the organization's domains are signed in even
+// when they used other providers before, so the organization can require its
+// identity provider of members it already has.
+func (a *Auth) signInSAMLUser(loginChallenge string, orgID uuid.UUID, profile user.ExternalProfile) (types.CallbackResult, error) {
+	if profile.Email == "" {
+		return types.CallbackResult{}, types.ErrEmailMissing
+	}
+
+	if err := a.checkSAMLUser(orgID, profile.Email); err != nil {
+		return types.CallbackResult{}, err
+	}
+
+	// The identity provider only vouches for emails at the organization's domains
+	verified, err := a.domainVerifiedBy(profile.Email, orgID)
+	if err != nil {
+		return types.CallbackResult{}, err
+	}
+	profile.EmailVerified = verified
+	profile.OrganizationDomain = verified
+
+	member, err := a.userService.ProvisionOrganizationMember(a.db, orgID, profile)
+	if err != nil {
+		return types.CallbackResult{}, translateProvisionError(err, profile)
+	}
+
+	return a.completeLogin(loginChallenge, member.User, profile)
+}
+
+// Checks that the account with the asserted email, if there is one, belongs to
+// the organization, so the identity provider cannot take over other accounts.
+// Emails at domains verified by other organizations are never accepted.
+func (a *Auth) checkSAMLUser(orgID uuid.UUID, email string) error {
+	domain, _, found, err := a.verifiedDomain(email)
+	if err != nil {
+		return err
+	}
+
+	if found && domain.OrganizationID != orgID {
+		return fmt.Errorf("%w: %s was verified by organization %s", types.ErrSAMLUserConflict, domain.Name, domain.OrganizationID)
+	}
+
+	existing, err := a.userService.GetUserByEmail(a.db, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	_, err = a.userService.GetOrganizationMember(a.db, orgID, existing.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %s", types.ErrSAMLUserConflict, existing.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get member: %w", err)
+	}
+
+	return nil
+}
+
+// Maps the asserted attributes onto the user's profile. The NameID identifies
+// the user at the identity provider and is used as email when it is one and no
+// email attribute was asserted.
+func samlProfile(connection organization.SAMLConnection, assertion saml.Assertion) user.ExternalProfile {
+	email := samlAttribute(assertion, connection.EmailAttribute, samlEmailAttributes)
+	if email == "" && strings.Contains(assertion.NameID, "@") {
+		email = assertion.NameID
+	}
+
+	return user.ExternalProfile{
+		Provider:  types.SAMLProviderPrefix + connection.OrganizationID.String(),
+		Subject:   assertion.NameID,
+		Email:     email,
+		FirstName: samlAttribute(assertion, connection.FirstNameAttribute, samlFirstNameAttributes),
+		LastName:  samlAttribute(assertion, connection.LastNameAttribute, samlLastNameAttributes),
+	}
+}
+
+// Returns the value of the mapped attribute, or of the first default attribute
+// present when none is mapped.
+func samlAttribute(assertion saml.Assertion, mapped string, defaults []string) string {
+	if mapped != "" {
+		return assertion.Attribute(mapped)
+	}
+
+	for _, name := range defaults {
+		if value := assertion.Attribute(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
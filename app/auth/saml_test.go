@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	domainOrganization "conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/infrastructure/persistence/organization"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+)
+
+// Creates an organization requiring users at example.com, which it verified, to
+// sign in with its identity provider. Returns its ID.
+func createSSOOrganization(t *testing.T, a *Auth) uuid.UUID {
+	t.Helper()
+
+	org := organization.Organization{Name: "Example", RequireSSO: true}
+	if err := a.db.Create(&org).Error; err != nil {
+		t.Fatalf("failed to create organization: %v", err)
+	}
+
+	verifiedAt := time.Now()
+	if err := a.db.Create(&organization.OrganizationDomain{
+		OrganizationID:    org.ID,
+		Domain:            "example.com",
+		VerificationToken: "token",
+		VerifiedAt:        &verifiedAt,
+	}).Error; err != nil {
+		t.Fatalf("failed to create domain: %v", err)
+	}
+
+	return org.ID
+}
+
+func TestSignInSAMLUserRequiredSSO(t *testing.T) {
+	tests := []struct {
+		name string
+		// Email of a user who signed in with another provider before
+		existing string
+		member   bool
+		// The organization requires the existing user to sign in with its
+		// identity provider
+		enforced bool
+		email    string
+		wantErr  error
+	}{
+		{
+			name:     "member who signed in with another provider",
+			existing: "ada@example.com",
+			member:   true,
+			enforced: true,
+			email:    "ada@example.com",
+		},
+		{
+			name:     "member who signed in with another provider asserted in another case",
+			existing: "ada@example.com",
+			member:   true,
+			enforced: true,
+			email:    "Ada@Example.com",
+		},
+		{
+			name:     "member at a domain the organization did not verify",
+			existing: "ada@gmail.com",
+			member:   true,
+			email:    "ada@gmail.com",
+			wantErr:  types.ErrEmailTaken,
+		},
+		{
+			name:     "user at the organization's domain who is not a member",
+			existing: "ada@example.com",
+			enforced: true,
+			email:    "ada@example.com",
+			wantErr:  types.ErrSAMLUserConflict,
+		},
+		{
+			name:  "new user",
+			email: "ada@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, fake := newTestAuth(t)
+			orgID := createSSOOrganization(t, a)
+			provider := types.SAMLProviderPrefix + orgID.String()
+
+			var existing user.User
+			if tt.existing != "" {
+				existing = createUser(t, a, tt.existing)
+			}
+
+			if tt.enforced {
+				if err := a.checkDomainSSO(tt.existing, testProvider); !errors.Is(err, types.ErrSSORequired) {
+					t.Fatalf("checkDomainSSO() error = %v, want %v", err, types.ErrSSORequired)
+				}
+			}
+
+			if tt.member {
+				if _, err := a.userService.AddOrganizationMember(a.db, existing, domainOrganization.Membership{
+					OrganizationID: orgID,
+					Role:           domainOrganization.RoleMember,
+				}); err != nil {
+					t.Fatalf("failed to add member: %v", err)
+				}
+			}
+
+			result, err := a.signInSAMLUser("challenge", orgID, user.ExternalProfile{
+				Provider: provider,
+				Subject:  "name-id",
+				Email:    tt.email,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("signInSAMLUser() error = %v, want %v", err, tt.wantErr)
+			}
+
+			identity, identityErr := a.userService.GetIdentity(a.db, provider, "name-id")
+
+			if tt.wantErr != nil {
+				if identityErr == nil {
+					t.Errorf("identity linked to %s, want it refused", identity.UserID)
+				}
+				return
+			}
+
+			if identityErr != nil {
+				t.Fatalf("failed to get identity: %v", identityErr)
+			}
+
+			if tt.existing != "" && (result.Subject != existing.ID.String() || identity.UserID != existing.ID) {
+				t.Errorf("signed in as %s with an identity of %s, want the member %s", result.Subject, identity.UserID, existing.ID)
+			}
+
+			if _, err := a.userService.GetOrganizationMember(a.db, orgID, identity.UserID); err != nil {
+				t.Errorf("signed in user is not a member: %v", err)
+			}
+
+			if accept, accepted := fake.acceptedLogin("challenge"); !accepted || accept.Subject != identity.UserID.String() {
+				t.Errorf("accepted login = %+v, want one of %s", accept, identity.UserID)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+)
+
+// Starts signing a user in to the frontend with the server as Hydra's first-party client.
+func (a *Auth) StartSession(ctx context.Context, req types.SessionLoginRequest) (types.SessionLoginResult, error) {
+	state, err := a.generateNonce()
+	if err != nil {
+		return types.SessionLoginResult{}, fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	nonce, err := a.generateNonce()
+	if err != nil {
+		return types.SessionLoginResult{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	codeVerifier := hydra.NewCodeVerifier()
+
+	params := map[string]string{}
+	if req.ProviderHint != "" {
+		params[providerHintParam] = req.ProviderHint
+	}
+
+	return types.SessionLoginResult{
+		AuthURL:      a.hydraClient.AuthCodeURL(state, nonce, codeVerifier, params),
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	}, nil
+}
+
+// Exchanges the authorization code Hydra returned to the first-party client and
+// loads the signed in user.
+func (a *Auth) CompleteSession(ctx context.Context, req types.SessionCallbackRequest) (types.SessionResult, error) {
+	if req.ExpectedState == "" || subtle.ConstantTimeCompare([]byte(req.State), []byte(req.ExpectedState)) != 1 {
+		return types.SessionResult{}, types.ErrStateMismatch
+	}
+
+	token, err := a.hydraClient.ExchangeCode(ctx, req.Code, req.CodeVerifier)
+	if err != nil {
+		return types.SessionResult{}, err
+	}
+
+	claims, err := a.hydraClient.VerifyIDToken(ctx, token, req.Nonce)
+	if err != nil {
+		return types.SessionResult{}, translateIDTokenError(err)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return types.SessionResult{}, fmt.Errorf("%w: subject %q is not a user ID", types.ErrIDTokenClaims, claims.Subject)
+	}
+
+	u, err := a.userService.GetUserByID(a.db, userID)
+	if err != nil {
+		return types.SessionResult{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	identities, err := a.userService.GetUserIdentities(a.db, userID)
+	if err != nil {
+		return types.SessionResult{}, fmt.Errorf("failed to get user identities: %w", err)
+	}
+
+	var provider string
+	if len(identities) > 0 {
+		provider = identities[0].Provider
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+
+	return types.SessionResult{
+		Tokens: types.SessionTokens{
+			AccessToken:  token.AccessToken,
+			RefreshToken: token.RefreshToken,
+			IDToken:      rawIDToken,
+			ExpiresAt:    token.Expiry,
+		},
+		UserID:         u.ID.String(),
+		Email:          u.Email,
+		Name:           strings.TrimSpace(u.FirstName + " " + u.LastName),
+		Provider:       provider,
+		HydraSessionID: claims.SessionID,
+	}, nil
+}
+
+// Exchanges the session's refresh token for new tokens. A refresh token Hydra
+// refuses yields ErrRefreshTokenInvalid and the session must end.
+func (a *Auth) RefreshSession(ctx context.Context, refreshToken string) (types.SessionTokens, error) {
+	token, err := a.hydraClient.RefreshToken(ctx, refreshToken)
+	if errors.Is(err, hydra.ErrRefreshTokenInvalid) {
+		return types.SessionTokens{}, fmt.Errorf("%w: %w", types.ErrRefreshTokenInvalid, err)
+	}
+	if err != nil {
+		return types.SessionTokens{}, err
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+
+	return types.SessionTokens{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		IDToken:      rawIDToken,
+		ExpiresAt:    token.Expiry,
+	}, nil
+}
+
+// Revokes the Hydra artifacts of a session that is signed out remotely: its
+// refresh token, which takes its access tokens along, and its Hydra login session,
+// so Hydra does not sign the browser in again without asking.
+func (a *Auth) RevokeSession(ctx context.Context, req types.RevokeSessionRequest) error {
+	if req.RefreshToken != "" {
+		if err := a.hydraClient.RevokeToken(ctx, req.RefreshToken); err != nil {
+			return err
+		}
+	}
+
+	if req.HydraSessionID != "" {
+		if err := a.hydraClient.RevokeLoginSession(req.HydraSessionID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,354 @@
+package clients
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Token endpoint authentication method of public clients, which have no secret.
+const authMethodNone = "none"
+
+// Registers an OAuth2 client at Hydra. Hydra generates the secret of confidential
+// clients, which is returned once.
+func (c *Clients) CreateClient(ctx context.Context, req types.CreateOAuthClientRequest) (types.IssuedOAuthClient, error) {
+	if err := c.authorize(req.Actor); err != nil {
+		return types.IssuedOAuthClient{}, err
+	}
+
+	if strings.TrimSpace(req.Client.Name) == "" {
+		return types.IssuedOAuthClient{}, fmt.Errorf("%w: name is required", types.ErrClientInvalid)
+	}
+
+	if err := c.validateMetadata(req.Client.IdentityProvider, req.Client.OrganizationID); err != nil {
+		return types.IssuedOAuthClient{}, err
+	}
+
+	created, err := c.hydraClient.CreateClient(hydra.OAuth2Client{
+		ClientID:                req.Client.ID,
+		ClientName:              req.Client.Name,
+		RedirectURIs:            req.Client.RedirectURIs,
+		PostLogoutRedirectURIs:  req.Client.PostLogoutRedirectURIs,
+		GrantTypes:              req.Client.GrantTypes,
+		ResponseTypes:           req.Client.ResponseTypes,
+		Scope:                   strings.Join(req.Client.Scopes, " "),
+		Audience:                req.Client.Audience,
+		TokenEndpointAuthMethod: req.Client.TokenEndpointAuthMethod,
+		Metadata:                setMetadata(nil, req.Client.IdentityProvider, req.Client.OrganizationID, req.Client.FirstParty),
+	})
+	if err != nil {
+		return types.IssuedOAuthClient{}, clientError(err, "create")
+	}
+
+	return types.IssuedOAuthClient{
+		Client: toOAuthClient(*created),
+		Secret: created.ClientSecret,
+	}, nil
+}
+
+func (c *Clients) ListClients(ctx context.Context, actor types.Actor) ([]types.OAuthClient, error) {
+	if err := c.authorize(actor); err != nil {
+		return nil, err
+	}
+
+	clients, err := c.hydraClient.ListClients()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	result := make([]types.OAuthClient, 0, len(clients))
+	for _, client := range clients {
+		result = append(result, toOAuthClient(client))
+	}
+
+	return result, nil
+}
+
+func (c *Clients) GetClient(ctx context.Context, actor types.Actor, clientID string) (types.OAuthClient, error) {
+	if err := c.authorize(actor); err != nil {
+		return types.OAuthClient{}, err
+	}
+
+	client, err := c.hydraClient.GetClient(clientID)
+	if err != nil {
+		return types.OAuthClient{}, clientError(err, "get")
+	}
+
+	return toOAuthClient(*client), nil
+}
+
+// Changes the fields of a client set in the request. The metadata keys the
+// request does not change are kept.
+func (c *Clients) UpdateClient(ctx context.Context, req types.UpdateOAuthClientRequest) (types.OAuthClient, error) {
+	if err := c.authorize(req.Actor); err != nil {
+		return types.OAuthClient{}, err
+	}
+
+	client, err := c.hydraClient.GetClient(req.ClientID)
+	if err != nil {
+		return types.OAuthClient{}, clientError(err, "get")
+	}
+
+	current := toOAuthClient(*client)
+
+	operations := []hydra.PatchOperation{}
+	add := func(path string, value any) {
+		operations = append(operations, hydra.PatchOperation{Op: "add", Path: path, Value: value})
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return types.OAuthClient{}, fmt.Errorf("%w: name is required", types.ErrClientInvalid)
+		}
+		add("/client_name", *req.Name)
+	}
+	if req.RedirectURIs != nil {
+		add("/redirect_uris", *req.RedirectURIs)
+	}
+	if req.PostLogoutRedirectURIs != nil {
+		add("/post_logout_redirect_uris", *req.PostLogoutRedirectURIs)
+	}
+	if req.GrantTypes != nil {
+		add("/grant_types", *req.GrantTypes)
+	}
+	if req.ResponseTypes != nil {
+		add("/response_types", *req.ResponseTypes)
+	}
+	if req.Scopes != nil {
+		add("/scope", strings.Join(*req.Scopes, " "))
+	}
+	if req.Audience != nil {
+		add("/audience", *req.Audience)
+	}
+
+	if req.IdentityProvider != nil || req.OrganizationID != nil || req.FirstParty != nil {
+		provider, organizationID, firstParty := current.IdentityProvider, current.OrganizationID, current.FirstParty
+		if req.IdentityProvider != nil {
+			provider = *req.IdentityProvider
+		}
+		if req.OrganizationID != nil {
+			organizationID = *req.OrganizationID
+		}
+		if req.FirstParty != nil {
+			firstParty = *req.FirstParty
+		}
+
+		if err := c.validateMetadata(provider, organizationID); err != nil {
+			return types.OAuthClient{}, err
+		}
+
+		add("/metadata", setMetadata(client.Metadata, provider, organizationID, firstParty))
+	}
+
+	if len(operations) == 0 {
+		return current, nil
+	}
+
+	patched, err := c.hydraClient.PatchClient(req.ClientID, operations)
+	if err != nil {
+		return types.OAuthClient{}, clientError(err, "update")
+	}
+
+	return toOAuthClient(*patched), nil
+}
+
+// Deletes a client. Hydra revokes the tokens issued to it.
+func (c *Clients) DeleteClient(ctx context.Context, actor types.Actor, clientID string) error {
+	if err := c.authorize(actor); err != nil {
+		return err
+	}
+
+	if err := c.hydraClient.DeleteClient(clientID); err != nil {
+		return clientError(err, "delete")
+	}
+
+	return nil
+}
+
+// Replaces the secret of a confidential client. The new secret is returned once,
+// and the old one stops working immediately.
+func (c *Clients) RotateClientSecret(ctx context.Context, actor types.Actor, clientID string) (types.IssuedOAuthClient, error) {
+	if err := c.authorize(actor); err != nil {
+		return types.IssuedOAuthClient{}, err
+	}
+
+	client, err := c.hydraClient.GetClient(clientID)
+	if err != nil {
+		return types.IssuedOAuthClient{}, clientError(err, "get")
+	}
+
+	if client.TokenEndpointAuthMethod == authMethodNone {
+		return types.IssuedOAuthClient{}, fmt.Errorf("%w: public client %s has no secret", types.ErrClientInvalid, clientID)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return types.IssuedOAuthClient{}, fmt.Errorf("failed to generate client secret: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	patched, err := c.hydraClient.PatchClient(clientID, []hydra.PatchOperation{
+		{Op: "replace", Path: "/client_secret", Value: secret},
+	})
+	if err != nil {
+		return types.IssuedOAuthClient{}, clientError(err, "update")
+	}
+
+	return types.IssuedOAuthClient{
+		Client: toOAuthClient(*patched),
+		Secret: secret,
+	}, nil
+}
+
+// Checks that the actor administers the installation: operators, and users with
+// staff rights.
+func (c *Clients) authorize(actor types.Actor) error {
+	switch actor.Kind {
+	case types.PrincipalOperator:
+		return nil
+	case types.PrincipalUser:
+	default:
+		return fmt.Errorf("%w: %s cannot manage oauth2 clients", types.ErrPermissionDenied, actor.Kind)
+	}
+
+	userID, err := uuid.Parse(actor.ID)
+	if err != nil {
+		return fmt.Errorf("invalid actor ID %q: %w", actor.ID, err)
+	}
+
+	u, err := c.userService.GetUserByID(c.db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.ErrPermissionDenied
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !u.IsStaff {
+		return fmt.Errorf("%w: staff rights required", types.ErrPermissionDenied)
+	}
+
+	return nil
+}
+
+// Checks that the identity provider a client is pinned to is configured, and
+// that the organization it belongs to exists. Empty values are valid.
+func (c *Clients) validateMetadata(provider, organizationID string) error {
+	if organizationID != "" {
+		if err := c.checkOrganization(organizationID); err != nil {
+			return err
+		}
+	}
+
+	if provider == "" {
+		return nil
+	}
+
+	if id, ok := strings.CutPrefix(provider, types.SAMLProviderPrefix); ok {
+		if err := c.checkOrganization(id); err != nil {
+			return err
+		}
+
+		orgID := uuid.MustParse(id)
+		if _, err := c.organizationService.GetSAMLConnection(c.db, orgID); errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: organization %s has no saml identity provider", types.ErrClientInvalid, id)
+		} else if err != nil {
+			return fmt.Errorf("failed to get saml connection: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, err := c.providers.Get(provider); err != nil {
+		return fmt.Errorf("%w: %w", types.ErrClientInvalid, err)
+	}
+
+	return nil
+}
+
+func (c *Clients) checkOrganization(organizationID string) error {
+	orgID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid organization ID %q", types.ErrClientInvalid, organizationID)
+	}
+
+	if _, err := c.organizationService.GetOrganizationByID(c.db, orgID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: organization %s not found", types.ErrClientInvalid, organizationID)
+	} else if err != nil {
+		return fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return nil
+}
+
+// Returns a copy of the client metadata with the identity provider, organization
+// and first-party flag set, removing the keys whose value is empty or false.
+func setMetadata(metadata map[string]any, provider, organizationID string, firstParty bool) map[string]any {
+	result := make(map[string]any, len(metadata)+3)
+	for k, v := range metadata {
+		result[k] = v
+	}
+
+	for key, value := range map[string]string{
+		hydra.ClientMetadataIdentityProvider: provider,
+		hydra.ClientMetadataOrganizationID:   organizationID,
+	} {
+		if value == "" {
+			delete(result, key)
+		} else {
+			result[key] = value
+		}
+	}
+
+	if firstParty {
+		result[hydra.ClientMetadataFirstParty] = true
+	} else {
+		delete(result, hydra.ClientMetadataFirstParty)
+	}
+
+	return result
+}
+
+// Translates errors of the Hydra clients API.
+func clientError(err error, action string) error {
+	switch {
+	case errors.Is(err, hydra.ErrClientNotFound):
+		return fmt.Errorf("%w: %w", types.ErrClientNotFound, err)
+	case errors.Is(err, hydra.ErrClientInvalid):
+		return fmt.Errorf("%w: %w", types.ErrClientInvalid, err)
+	default:
+		return fmt.Errorf("failed to %s client: %w", action, err)
+	}
+}
+
+func toOAuthClient(client hydra.OAuth2Client) types.OAuthClient {
+	provider, _ := client.Metadata[hydra.ClientMetadataIdentityProvider].(string)
+	organizationID, _ := client.Metadata[hydra.ClientMetadataOrganizationID].(string)
+	firstParty, _ := client.Metadata[hydra.ClientMetadataFirstParty].(bool)
+
+	return types.OAuthClient{
+		ID:                      client.ClientID,
+		Name:                    client.ClientName,
+		RedirectURIs:            client.RedirectURIs,
+		PostLogoutRedirectURIs:  client.PostLogoutRedirectURIs,
+		GrantTypes:              client.GrantTypes,
+		ResponseTypes:           client.ResponseTypes,
+		Scopes:                  strings.Fields(client.Scope),
+		Audience:                client.Audience,
+		TokenEndpointAuthMethod: client.TokenEndpointAuthMethod,
+		IdentityProvider:        provider,
+		OrganizationID:          organizationID,
+		FirstParty:              firstParty,
+		CreatedAt:               client.CreatedAt,
+		UpdatedAt:               client.UpdatedAt,
+	}
+}
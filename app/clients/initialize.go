@@ -0,0 +1,28 @@
+package clients
+
+import (
+	"conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/infrastructure/gateway/idp"
+
+	"gorm.io/gorm"
+)
+
+type Clients struct {
+	db                  *gorm.DB
+	userService         *user.UserService
+	organizationService *organization.OrganizationService
+	providers           *idp.Registry
+	hydraClient         *hydra.HydraClient
+}
+
+func Initialize(db *gorm.DB, us *user.UserService, os *organization.OrganizationService, pr *idp.Registry, hc *hydra.HydraClient) (*Clients, error) {
+	return &Clients{
+		db:                  db,
+		userService:         us,
+		organizationService: os,
+		providers:           pr,
+		hydraClient:         hc,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+type AuthConfig struct {
+	// Seconds Hydra remembers an accepted login before asking the user to sign in again.
+	RememberLoginFor int `mapstructure:"remember_login_for"`
+	// Seconds Hydra remembers granted consent before asking for it again.
+	RememberConsentFor int `mapstructure:"remember_consent_for"`
+
+	WebAuthn WebAuthnConfig `mapstructure:"webauthn"`
+}
+
+func (a *AuthConfig) Validate() error {
+	var errs []error
+	if a.RememberLoginFor <= 0 {
+		errs = append(errs, fmt.Errorf("auth.remember_login_for must be positive"))
+	}
+
+	if a.RememberConsentFor <= 0 {
+		errs = append(errs, fmt.Errorf("auth.remember_consent_for must be positive"))
+	}
+
+	if err := a.WebAuthn.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
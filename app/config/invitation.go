@@ -0,0 +1,39 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// InvitationConfig configures the emails inviting people to join an organization.
+type InvitationConfig struct {
+	// Key invitation links are signed with, at least 32 characters
+	Secret string `mapstructure:"secret"`
+	// Seconds an invitation can be accepted for after it was last sent
+	TTL int `mapstructure:"ttl"`
+	// Frontend page invitees accept invitations on. The link in the email adds the
+	// token as the "token" query parameter.
+	AcceptURL string `mapstructure:"accept_url"`
+}
+
+func (i *InvitationConfig) Validate() error {
+	var errs []error
+	if len(i.Secret) < 32 {
+		errs = append(errs, fmt.Errorf("invitations.secret must be at least 32 characters"))
+	}
+
+	if i.TTL <= 0 {
+		errs = append(errs, fmt.Errorf("invitations.ttl must be positive"))
+	}
+
+	if u, err := url.Parse(i.AcceptURL); err != nil || !u.IsAbs() {
+		errs = append(errs, fmt.Errorf("invitations.accept_url must be an absolute URL"))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+type WebAuthnConfig struct {
+	// Relying party ID passkeys are bound to, the registrable domain of the frontend.
+	RPID string `mapstructure:"rp_id"`
+	// Name shown to users when they create a passkey.
+	RPDisplayName string `mapstructure:"rp_display_name"`
+	// Origins WebAuthn ceremonies may be performed from.
+	RPOrigins []string `mapstructure:"rp_origins"`
+}
+
+func (w *WebAuthnConfig) Validate() error {
+	var errs []error
+	if w.RPID == "" {
+		errs = append(errs, fmt.Errorf("auth.webauthn.rp_id is required"))
+	}
+
+	if w.RPDisplayName == "" {
+		errs = append(errs, fmt.Errorf("auth.webauthn.rp_display_name is required"))
+	}
+
+	if len(w.RPOrigins) == 0 {
+		errs = append(errs, fmt.Errorf("auth.webauthn.rp_origins is required"))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
@@ -1,3 +1,10 @@
 module conformitea/app
 
 go 1.24.4
+
+require (
+	github.com/go-webauthn/webauthn v0.15.0
+	github.com/pquerna/otp v1.5.0
+)
+
+require github.com/boombuler/barcode v1.0.1-0.20190219062509-6c824513bacc // indirect
@@ -0,0 +1,264 @@
+package impersonation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"conformitea/domain/audit"
+	"conformitea/domain/user"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Impersonations last DefaultDuration unless the staff user asks for another
+// duration, up to MaxDuration.
+const (
+	DefaultDuration = 30 * time.Minute
+	MaxDuration     = 2 * time.Hour
+)
+
+// Longest reason accepted, enough for a sentence and a ticket reference.
+const maxReasonLength = 500
+
+// Starts an impersonation of the subject by the actor, a staff user. Staff users
+// cannot impersonate themselves, other staff users or deactivated users.
+func (i *Impersonation) StartImpersonation(ctx context.Context, req types.StartImpersonationRequest) (types.Impersonation, error) {
+	if req.Actor.Kind != types.PrincipalUser {
+		return types.Impersonation{}, fmt.Errorf("%w: only staff users can impersonate", types.ErrPermissionDenied)
+	}
+
+	actor, err := i.staff(req.Actor)
+	if err != nil {
+		return types.Impersonation{}, err
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return types.Impersonation{}, fmt.Errorf("%w: a reason is required", types.ErrInvalidRequest)
+	}
+	if len(reason) > maxReasonLength {
+		return types.Impersonation{}, fmt.Errorf("%w: reason is longer than %d characters", types.ErrInvalidRequest, maxReasonLength)
+	}
+
+	duration := req.Duration
+	if duration == 0 {
+		duration = DefaultDuration
+	}
+	if duration < 0 || duration > MaxDuration {
+		return types.Impersonation{}, fmt.Errorf("%w: duration must be positive and at most %s", types.ErrInvalidRequest, MaxDuration)
+	}
+
+	subject, err := i.subject(req.Subject)
+	if err != nil {
+		return types.Impersonation{}, err
+	}
+
+	switch {
+	case subject.ID == actor.ID:
+		return types.Impersonation{}, fmt.Errorf("%w: staff users cannot impersonate themselves", types.ErrInvalidRequest)
+	case subject.IsStaff:
+		return types.Impersonation{}, fmt.Errorf("%w: staff users cannot be impersonated", types.ErrPermissionDenied)
+	case !subject.Active():
+		return types.Impersonation{}, fmt.Errorf("%w: %s is deactivated", types.ErrInvalidRequest, subject.Email)
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return types.Impersonation{}, fmt.Errorf("failed to generate impersonation ID: %w", err)
+	}
+
+	now := time.Now()
+	impersonation := types.Impersonation{
+		ID:                id.String(),
+		ImpersonatorID:    actor.ID.String(),
+		ImpersonatorEmail: actor.Email,
+		SubjectID:         subject.ID.String(),
+		SubjectEmail:      subject.Email,
+		SubjectName:       strings.TrimSpace(subject.FirstName + " " + subject.LastName),
+		Reason:            reason,
+		StartedAt:         now,
+		ExpiresAt:         now.Add(duration),
+	}
+
+	if err := i.record(audit.ActionImpersonationStarted, impersonation, req.Request, 0); err != nil {
+		return types.Impersonation{}, err
+	}
+
+	return impersonation, nil
+}
+
+// Records the end of an impersonation, by the staff user or because it ran out
+// of time.
+func (i *Impersonation) EndImpersonation(ctx context.Context, req types.EndImpersonationRequest) error {
+	action := audit.ActionImpersonationEnded
+	if req.Expired {
+		action = audit.ActionImpersonationExpired
+	}
+
+	return i.record(action, req.Impersonation, req.Request, 0)
+}
+
+// Records a request made while impersonating.
+func (i *Impersonation) RecordImpersonatedRequest(ctx context.Context, req types.ImpersonatedRequest) error {
+	return i.record(audit.ActionImpersonatedRequest, req.Impersonation, req.Request, req.Status)
+}
+
+// Returns the most recent audit events matching the query. Only operators and
+// staff users can read the audit trail.
+func (i *Impersonation) ListAuditEvents(ctx context.Context, query types.AuditEventQuery) ([]types.AuditEvent, error) {
+	if query.Actor.Kind != types.PrincipalOperator {
+		if query.Actor.Kind != types.PrincipalUser {
+			return nil, fmt.Errorf("%w: %s cannot read the audit trail", types.ErrPermissionDenied, query.Actor.Kind)
+		}
+
+		if _, err := i.staff(query.Actor); err != nil {
+			return nil, err
+		}
+	}
+
+	var q audit.EventQuery
+	var err error
+
+	if q.ActorID, err = parseFilter("actor_id", query.ActorID); err != nil {
+		return nil, err
+	}
+	if q.SubjectID, err = parseFilter("subject_id", query.SubjectID); err != nil {
+		return nil, err
+	}
+	if q.ImpersonationID, err = parseFilter("impersonation_id", query.ImpersonationID); err != nil {
+		return nil, err
+	}
+	q.Limit = query.Limit
+
+	events, err := i.auditService.GetEvents(i.db, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit events: %w", err)
+	}
+
+	result := make([]types.AuditEvent, 0, len(events))
+	for _, e := range events {
+		result = append(result, toAuditEvent(e))
+	}
+
+	return result, nil
+}
+
+// Returns the user of the actor after checking that they have staff rights.
+func (i *Impersonation) staff(actor types.Actor) (user.User, error) {
+	userID, err := uuid.Parse(actor.ID)
+	if err != nil {
+		return user.User{}, fmt.Errorf("invalid actor ID %q: %w", actor.ID, err)
+	}
+
+	u, err := i.userService.GetUserByID(i.db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user.User{}, types.ErrPermissionDenied
+	}
+	if err != nil {
+		return user.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !u.IsStaff {
+		return user.User{}, fmt.Errorf("%w: staff rights required", types.ErrPermissionDenied)
+	}
+
+	return u, nil
+}
+
+// Returns the user with the ID or email.
+func (i *Impersonation) subject(idOrEmail string) (user.User, error) {
+	idOrEmail = strings.TrimSpace(idOrEmail)
+
+	var u user.User
+	var err error
+
+	if id, parseErr := uuid.Parse(idOrEmail); parseErr == nil {
+		u, err = i.userService.GetUserByID(i.db, id)
+	} else {
+		u, err = i.userService.GetUserByEmail(i.db, idOrEmail)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user.User{}, fmt.Errorf("%w: %s", types.ErrUserNotFound, idOrEmail)
+	}
+	if err != nil {
+		return user.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return u, nil
+}
+
+func (i *Impersonation) record(action string, impersonation types.Impersonation, req types.AuditRequest, status int) error {
+	actorID, err := uuid.Parse(impersonation.ImpersonatorID)
+	if err != nil {
+		return fmt.Errorf("invalid impersonator ID %q: %w", impersonation.ImpersonatorID, err)
+	}
+
+	subjectID, err := uuid.Parse(impersonation.SubjectID)
+	if err != nil {
+		return fmt.Errorf("invalid subject ID %q: %w", impersonation.SubjectID, err)
+	}
+
+	impersonationID, err := uuid.Parse(impersonation.ID)
+	if err != nil {
+		return fmt.Errorf("invalid impersonation ID %q: %w", impersonation.ID, err)
+	}
+
+	if _, err := i.auditService.RecordEvent(i.db, audit.Event{
+		Action:          action,
+		ActorID:         actorID,
+		SubjectID:       subjectID,
+		ImpersonationID: &impersonationID,
+		Reason:          impersonation.Reason,
+		Method:          req.Method,
+		Path:            req.Path,
+		Status:          status,
+		RequestID:       req.RequestID,
+		IPAddress:       req.IPAddress,
+	}); err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// Parses an ID the audit trail is filtered by, the nil UUID when empty.
+func parseFilter(name, value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid %s %q", types.ErrInvalidRequest, name, value)
+	}
+
+	return id, nil
+}
+
+func toAuditEvent(e audit.Event) types.AuditEvent {
+	event := types.AuditEvent{
+		ID:        e.ID.String(),
+		Action:    e.Action,
+		ActorID:   e.ActorID.String(),
+		SubjectID: e.SubjectID.String(),
+		Reason:    e.Reason,
+		Method:    e.Method,
+		Path:      e.Path,
+		Status:    e.Status,
+		RequestID: e.RequestID,
+		IPAddress: e.IPAddress,
+		CreatedAt: e.CreatedAt,
+	}
+
+	if e.ImpersonationID != nil {
+		event.ImpersonationID = e.ImpersonationID.String()
+	}
+
+	return event
+}
@@ -0,0 +1,22 @@
+package impersonation
+
+import (
+	"conformitea/domain/audit"
+	"conformitea/domain/user"
+
+	"gorm.io/gorm"
+)
+
+type Impersonation struct {
+	db           *gorm.DB
+	userService  *user.UserService
+	auditService *audit.AuditService
+}
+
+func Initialize(db *gorm.DB, us *user.UserService, aus *audit.AuditService) (*Impersonation, error) {
+	return &Impersonation{
+		db:           db,
+		userService:  us,
+		auditService: aus,
+	}, nil
+}
@@ -0,0 +1,199 @@
+// Package invitations holds the invitation links and emails shared by the
+// invitations members send and those the directory of an organization sends.
+package invitations
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"conformitea/app/config"
+	"conformitea/domain/apitoken"
+	"conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/mail"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sender emails invitation links and checks the links it sent.
+type Sender struct {
+	config              config.InvitationConfig
+	db                  *gorm.DB
+	organizationService *organization.OrganizationService
+	userService         *user.UserService
+	apiTokenService     *apitoken.APITokenService
+	mailer              mail.Mailer
+}
+
+func NewSender(cfg config.InvitationConfig, db *gorm.DB, os *organization.OrganizationService, us *user.UserService, as *apitoken.APITokenService, m mail.Mailer) *Sender {
+	return &Sender{
+		config:              cfg,
+		db:                  db,
+		organizationService: os,
+		userService:         us,
+		apiTokenService:     as,
+		mailer:              m,
+	}
+}
+
+// Returns when an invitation sent now can no longer be accepted.
+func (s *Sender) ExpiresAt(now time.Time) time.Time {
+	return now.Add(time.Duration(s.config.TTL) * time.Second)
+}
+
+// Returns the invitation of email to the organization that can still be
+// accepted, if any.
+func (s *Sender) PendingInvitation(organizationID uuid.UUID, email string) (organization.Invitation, bool, error) {
+	invitations, err := s.organizationService.GetInvitations(s.db, organizationID)
+	if err != nil {
+		return organization.Invitation{}, false, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	now := time.Now()
+	for _, i := range invitations {
+		if i.Email == email && i.Pending(now) {
+			return i, true, nil
+		}
+	}
+
+	return organization.Invitation{}, false, nil
+}
+
+// Emails the invitation's link to the invitee.
+func (s *Sender) Send(ctx context.Context, invitation organization.Invitation) error {
+	org, err := s.organizationService.GetOrganizationByID(s.db, invitation.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	inviter, err := s.InviterName(invitation)
+	if err != nil {
+		return err
+	}
+
+	link, err := url.Parse(s.config.AcceptURL)
+	if err != nil {
+		return fmt.Errorf("invalid accept URL: %w", err)
+	}
+
+	query := link.Query()
+	query.Set("token", s.token(invitation))
+	link.RawQuery = query.Encode()
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s invited you to join %s on ConformiTea as %s.\n\n", inviter, org.Name, invitation.Role)
+	fmt.Fprintf(&body, "Accept the invitation by following this link:\n\n%s\n\n", link.String())
+	fmt.Fprintf(&body, "The link can be used once and expires on %s.\n", invitation.ExpiresAt.UTC().Format("January 2, 2006 at 15:04 MST"))
+	body.WriteString("If you did not expect this invitation, you can ignore this email.\n")
+
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You are invited to join %s", org.Name),
+		Body:    body.String(),
+	}); err != nil {
+		return fmt.Errorf("failed to send invitation: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the name of who sent the invitation: the member who invited someone,
+// or the service account of the organization's directory.
+func (s *Sender) InviterName(invitation organization.Invitation) (string, error) {
+	inviter, err := s.userService.GetUserByID(s.db, invitation.InvitedBy)
+	if err == nil {
+		return displayName(inviter), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to get inviter: %w", err)
+	}
+
+	account, err := s.apiTokenService.GetServiceAccount(s.db, invitation.InvitedBy)
+	if err != nil {
+		return "", fmt.Errorf("failed to get inviter: %w", err)
+	}
+
+	return account.Name, nil
+}
+
+// Returns the ID and nonce of the invitation of a link that was signed by the
+// sender and did not expire. Reports false for any other link.
+func (s *Sender) ParseToken(token string) (uuid.UUID, string, bool) {
+	encodedPayload, encodedMAC, found := strings.Cut(token, ".")
+	if !found {
+		return uuid.Nil, "", false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, s.sign(string(payload))) {
+		return uuid.Nil, "", false
+	}
+
+	fields := strings.Split(string(payload), ":")
+	if len(fields) != 3 {
+		return uuid.Nil, "", false
+	}
+
+	id, err := uuid.Parse(fields[0])
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+
+	expiresAt, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return uuid.Nil, "", false
+	}
+
+	return id, fields[1], true
+}
+
+// Invitation links carry the invitation ID, its nonce and expiry, signed with
+// HMAC-SHA256 so they cannot be forged or extended.
+func (s *Sender) token(invitation organization.Invitation) string {
+	payload := fmt.Sprintf("%s:%s:%d", invitation.ID, invitation.TokenNonce, invitation.ExpiresAt.Unix())
+
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+func (s *Sender) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(s.config.Secret))
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
+
+// Returns a random nonce binding an invitation link to the invitation.
+func Nonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate invitation nonce: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Returns the full name of the user, or their email when they have none.
+func displayName(u user.User) string {
+	if name := strings.TrimSpace(u.FirstName + " " + u.LastName); name != "" {
+		return name
+	}
+
+	return u.Email
+}
@@ -0,0 +1,57 @@
+// Package tokens holds the API token logic shared by personal access tokens and
+// service account tokens.
+package tokens
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"conformitea/domain/apitoken"
+	"conformitea/server/types"
+)
+
+// Checks the name, scopes and lifetime of a new API token against the scopes
+// tokens of its kind can be granted, and returns its name and expiry.
+func ValidateRequest(req types.CreateAPITokenRequest, allowedScopes []string) (string, time.Time, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", time.Time{}, fmt.Errorf("%w: name is required", types.ErrAPITokenInvalid)
+	}
+
+	if len(req.Scopes) == 0 {
+		return "", time.Time{}, fmt.Errorf("%w: at least one scope is required", types.ErrAPITokenInvalid)
+	}
+
+	for _, scope := range req.Scopes {
+		if !slices.Contains(allowedScopes, scope) {
+			return "", time.Time{}, fmt.Errorf("%w: scope %q cannot be granted", types.ErrAPITokenInvalid, scope)
+		}
+	}
+
+	lifetime := apitoken.DefaultLifetime
+	if req.ExpiresInDays != 0 {
+		lifetime = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	if lifetime <= 0 || lifetime > apitoken.MaxLifetime {
+		return "", time.Time{}, fmt.Errorf("%w: tokens expire after 1 to %d days", types.ErrAPITokenInvalid, int(apitoken.MaxLifetime.Hours()/24))
+	}
+
+	return name, time.Now().Add(lifetime), nil
+}
+
+// Converts a token to the app contract.
+func ToAPIToken(t apitoken.Token) types.APIToken {
+	return types.APIToken{
+		ID:            t.ID.String(),
+		Name:          t.Name,
+		DisplayPrefix: t.DisplayPrefix,
+		Scopes:        t.Scopes,
+		ExpiresAt:     t.ExpiresAt,
+		LastUsedAt:    t.LastUsedAt,
+		RevokedAt:     t.RevokedAt,
+		CreatedAt:     t.CreatedAt,
+	}
+}
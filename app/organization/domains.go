@@ -0,0 +1,319 @@
+package organization
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"conformitea/domain/organization"
+	"conformitea/infrastructure/gateway/dns"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// The TXT record proving ownership of a domain is published at
+// <domainRecordPrefix>.<domain> with the value <domainRecordValuePrefix><token>.
+const (
+	domainRecordPrefix      = "_conformitea-challenge"
+	domainRecordValuePrefix = "conformitea-domain-verification="
+)
+
+// Lowercase domain names with at least two labels.
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z][a-z0-9-]{0,61}[a-z0-9]$`)
+
+// Claims an email domain for the organization. The domain is used once the
+// organization publishes the returned TXT record and verifies it.
+func (o *Organization) AddDomain(ctx context.Context, req types.AddDomainRequest) (types.OrganizationDomain, error) {
+	orgID, err := o.authorizeOrganization(req.Actor, req.OrganizationID, organization.PermissionOrganizationManage)
+	if err != nil {
+		return types.OrganizationDomain{}, err
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(req.Domain), "."))
+	if len(name) > 253 || !domainPattern.MatchString(name) {
+		return types.OrganizationDomain{}, fmt.Errorf("%w: %q is not a domain name", types.ErrInvalidRequest, req.Domain)
+	}
+
+	domains, err := o.organizationService.GetDomains(o.db, orgID)
+	if err != nil {
+		return types.OrganizationDomain{}, fmt.Errorf("failed to get domains: %w", err)
+	}
+
+	if slices.ContainsFunc(domains, func(d organization.Domain) bool { return d.Name == name }) {
+		return types.OrganizationDomain{}, fmt.Errorf("%w: %s was already added", types.ErrDomainConflict, name)
+	}
+
+	if err := o.checkDomainUnverified(orgID, name); err != nil {
+		return types.OrganizationDomain{}, err
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return types.OrganizationDomain{}, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	domain, err := o.organizationService.CreateDomain(o.db, organization.Domain{
+		OrganizationID:    orgID,
+		Name:              name,
+		VerificationToken: hex.EncodeToString(token),
+		AutoJoin:          req.AutoJoin,
+	})
+	if err != nil {
+		return types.OrganizationDomain{}, fmt.Errorf("failed to create domain: %w", err)
+	}
+
+	return toOrganizationDomain(domain), nil
+}
+
+// Returns the domains claimed by the organization.
+func (o *Organization) ListDomains(ctx context.Context, actor types.Actor, organizationID string) ([]types.OrganizationDomain, error) {
+	orgID, err := o.authorizeOrganization(actor, organizationID, organization.PermissionOrganizationRead)
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := o.organizationService.GetDomains(o.db, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domains: %w", err)
+	}
+
+	result := make([]types.OrganizationDomain, 0, len(domains))
+	for _, d := range domains {
+		result = append(result, toOrganizationDomain(d))
+	}
+
+	return result, nil
+}
+
+// Looks up the domain's TXT record and marks the domain verified when it holds
+// the verification token. Verified domains are not checked again.
+func (o *Organization) VerifyDomain(ctx context.Context, req types.DomainRequest) (types.OrganizationDomain, error) {
+	domain, err := o.domain(req.Actor, req.OrganizationID, req.DomainID)
+	if err != nil {
+		return types.OrganizationDomain{}, err
+	}
+
+	if domain.Verified() {
+		return toOrganizationDomain(domain), nil
+	}
+
+	if err := o.checkDomainUnverified(domain.OrganizationID, domain.Name); err != nil {
+		return types.OrganizationDomain{}, err
+	}
+
+	if err := checkVerificationRecord(ctx, o.resolver, domain); err != nil {
+		return types.OrganizationDomain{}, err
+	}
+
+	now := time.Now()
+	domain.VerifiedAt = &now
+
+	domain, err = o.organizationService.UpdateDomain(o.db, domain)
+	if err != nil {
+		return types.OrganizationDomain{}, fmt.Errorf("failed to update domain: %w", err)
+	}
+
+	return toOrganizationDomain(domain), nil
+}
+
+// Changes whether users at the domain are added to the organization.
+func (o *Organization) UpdateDomain(ctx context.Context, req types.UpdateDomainRequest) (types.OrganizationDomain, error) {
+	domain, err := o.domain(req.Actor, req.OrganizationID, req.DomainID)
+	if err != nil {
+		return types.OrganizationDomain{}, err
+	}
+
+	if req.AutoJoin != nil {
+		domain.AutoJoin = *req.AutoJoin
+	}
+
+	domain, err = o.organizationService.UpdateDomain(o.db, domain)
+	if err != nil {
+		return types.OrganizationDomain{}, fmt.Errorf("failed to update domain: %w", err)
+	}
+
+	return toOrganizationDomain(domain), nil
+}
+
+// Releases the organization's claim of the domain. Members at the domain stay
+// members.
+func (o *Organization) DeleteDomain(ctx context.Context, req types.DomainRequest) error {
+	domain, err := o.domain(req.Actor, req.OrganizationID, req.DomainID)
+	if err != nil {
+		return err
+	}
+
+	if err := o.organizationService.DeleteDomain(o.db, domain.OrganizationID, domain.ID); err != nil {
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the organization that verified the domain of the user's email, unless
+// the user already belongs to it.
+func (o *Organization) ListJoinableOrganizations(ctx context.Context, actor types.Actor) ([]types.JoinableOrganization, error) {
+	org, domain, found, err := o.joinableOrganization(actor)
+	if err != nil || !found {
+		return []types.JoinableOrganization{}, err
+	}
+
+	return []types.JoinableOrganization{{
+		ID:     org.ID.String(),
+		Name:   org.Name,
+		Domain: domain.Name,
+	}}, nil
+}
+
+// Adds the user to the organization that verified the domain of their email.
+// Users cannot join other organizations this way.
+func (o *Organization) JoinOrganization(ctx context.Context, actor types.Actor, organizationID string) error {
+	org, _, found, err := o.joinableOrganization(actor)
+	if err != nil {
+		return err
+	}
+
+	if !found || org.ID.String() != organizationID {
+		return fmt.Errorf("%w: %s cannot be joined", types.ErrOrganizationNotFound, organizationID)
+	}
+
+	u, err := o.userService.GetUserByID(o.db, uuid.MustParse(actor.ID))
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if _, err := o.userService.AddOrganizationMember(o.db, u, organization.Membership{
+		OrganizationID: org.ID,
+		Role:           organization.RoleMember,
+	}); err != nil {
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the organization that verified the domain of the actor's email, if the
+// actor is a user who does not belong to it yet.
+func (o *Organization) joinableOrganization(actor types.Actor) (organization.Organization, organization.Domain, bool, error) {
+	if actor.Kind != types.PrincipalUser {
+		return organization.Organization{}, organization.Domain{}, false, nil
+	}
+
+	userID, err := uuid.Parse(actor.ID)
+	if err != nil {
+		return organization.Organization{}, organization.Domain{}, false, fmt.Errorf("invalid actor ID %q: %w", actor.ID, err)
+	}
+
+	u, err := o.userService.GetUserByID(o.db, userID)
+	if err != nil {
+		return organization.Organization{}, organization.Domain{}, false, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	domain, err := o.organizationService.GetVerifiedDomain(o.db, organization.EmailDomain(u.Email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return organization.Organization{}, organization.Domain{}, false, nil
+	}
+	if err != nil {
+		return organization.Organization{}, organization.Domain{}, false, fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	_, err = o.userService.GetOrganizationMember(o.db, domain.OrganizationID, userID)
+	if err == nil {
+		return organization.Organization{}, organization.Domain{}, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return organization.Organization{}, organization.Domain{}, false, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	org, err := o.organizationService.GetOrganizationByID(o.db, domain.OrganizationID)
+	if err != nil {
+		return organization.Organization{}, organization.Domain{}, false, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return org, domain, true, nil
+}
+
+// Returns the organization's domain after checking that the actor may manage the
+// organization.
+func (o *Organization) domain(actor types.Actor, organizationID, domainID string) (organization.Domain, error) {
+	orgID, err := o.authorizeOrganization(actor, organizationID, organization.PermissionOrganizationManage)
+	if err != nil {
+		return organization.Domain{}, err
+	}
+
+	id, err := uuid.Parse(domainID)
+	if err != nil {
+		return organization.Domain{}, fmt.Errorf("%w: invalid ID %q", types.ErrDomainNotFound, domainID)
+	}
+
+	domain, err := o.organizationService.GetDomain(o.db, orgID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return organization.Domain{}, types.ErrDomainNotFound
+	}
+	if err != nil {
+		return organization.Domain{}, fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	return domain, nil
+}
+
+// Checks that no other organization verified the domain.
+func (o *Organization) checkDomainUnverified(orgID uuid.UUID, name string) error {
+	verified, err := o.organizationService.GetVerifiedDomain(o.db, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	if verified.OrganizationID != orgID {
+		return fmt.Errorf("%w: %s was verified by another organization", types.ErrDomainConflict, name)
+	}
+
+	return nil
+}
+
+// Checks that the domain's TXT record holds its verification token.
+func checkVerificationRecord(ctx context.Context, resolver dns.Resolver, domain organization.Domain) error {
+	recordName, recordValue := verificationRecord(domain)
+
+	values, err := resolver.LookupTXT(ctx, recordName)
+	if err != nil {
+		return fmt.Errorf("%w: %w", types.ErrDomainNotVerified, err)
+	}
+
+	if !slices.Contains(values, recordValue) {
+		return fmt.Errorf("%w: no TXT record %s with the verification token", types.ErrDomainNotVerified, recordName)
+	}
+
+	return nil
+}
+
+// Returns the name and value of the TXT record proving ownership of the domain.
+func verificationRecord(domain organization.Domain) (string, string) {
+	return domainRecordPrefix + "." + domain.Name, domainRecordValuePrefix + domain.VerificationToken
+}
+
+func toOrganizationDomain(domain organization.Domain) types.OrganizationDomain {
+	recordName, recordValue := verificationRecord(domain)
+
+	return types.OrganizationDomain{
+		ID:             domain.ID.String(),
+		OrganizationID: domain.OrganizationID.String(),
+		Domain:         domain.Name,
+		RecordName:     recordName,
+		RecordValue:    recordValue,
+		VerifiedAt:     domain.VerifiedAt,
+		AutoJoin:       domain.AutoJoin,
+		CreatedAt:      domain.CreatedAt,
+	}
+}
@@ -0,0 +1,95 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"conformitea/domain/organization"
+	"conformitea/infrastructure/config"
+	"conformitea/infrastructure/gateway/dns"
+	"conformitea/server/types"
+)
+
+// Resolver failing every lookup, like an unreachable nameserver.
+type failingResolver struct{}
+
+func (failingResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return nil, errors.New("nameserver unreachable")
+}
+
+func TestCheckVerificationRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		resolver dns.Resolver
+		wantErr  error
+	}{
+		{
+			name: "record with the token",
+			resolver: dns.NewStaticResolver([]config.DNSRecord{{
+				Name:   "_conformitea-challenge.example.com",
+				Values: []string{"v=spf1 -all", "conformitea-domain-verification=0123456789abcdef"},
+			}}),
+		},
+		{
+			name: "record named in another case with a trailing dot",
+			resolver: dns.NewStaticResolver([]config.DNSRecord{{
+				Name:   "_conformitea-challenge.Example.COM.",
+				Values: []string{"conformitea-domain-verification=0123456789abcdef"},
+			}}),
+		},
+		{
+			name: "record with another token",
+			resolver: dns.NewStaticResolver([]config.DNSRecord{{
+				Name:   "_conformitea-challenge.example.com",
+				Values: []string{"conformitea-domain-verification=fedcba9876543210"},
+			}}),
+			wantErr: types.ErrDomainNotVerified,
+		},
+		{
+			name: "token published at the domain itself",
+			resolver: dns.NewStaticResolver([]config.DNSRecord{{
+				Name:   "example.com",
+				Values: []string{"conformitea-domain-verification=0123456789abcdef"},
+			}}),
+			wantErr: types.ErrDomainNotVerified,
+		},
+		{
+			name:   "token of a parent domain",
+			domain: "sub.example.com",
+			resolver: dns.NewStaticResolver([]config.DNSRecord{{
+				Name:   "_conformitea-challenge.example.com",
+				Values: []string{"conformitea-domain-verification=0123456789abcdef"},
+			}}),
+			wantErr: types.ErrDomainNotVerified,
+		},
+		{
+			name:     "no records",
+			resolver: dns.NewStaticResolver(nil),
+			wantErr:  types.ErrDomainNotVerified,
+		},
+		{
+			name:     "lookup failure",
+			resolver: failingResolver{},
+			wantErr:  types.ErrDomainNotVerified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := organization.Domain{
+				Name:              "example.com",
+				VerificationToken: "0123456789abcdef",
+			}
+			if tt.domain != "" {
+				domain.Name = tt.domain
+			}
+
+			err := checkVerificationRecord(context.Background(), tt.resolver, domain)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkVerificationRecord() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
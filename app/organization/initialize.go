@@ -0,0 +1,45 @@
+package organization
+
+import (
+	"fmt"
+
+	"conformitea/app/config"
+	"conformitea/app/internal/invitations"
+	"conformitea/domain/apitoken"
+	"conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/dns"
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/infrastructure/gateway/mail"
+	"conformitea/infrastructure/gateway/saml"
+
+	"gorm.io/gorm"
+)
+
+type Organization struct {
+	db                  *gorm.DB
+	organizationService *organization.OrganizationService
+	userService         *user.UserService
+	apiTokenService     *apitoken.APITokenService
+	samlServiceProvider *saml.ServiceProvider
+	resolver            dns.Resolver
+	hydraClient         *hydra.HydraClient
+	invitations         *invitations.Sender
+}
+
+func Initialize(cfg config.InvitationConfig, db *gorm.DB, os *organization.OrganizationService, us *user.UserService, as *apitoken.APITokenService, sp *saml.ServiceProvider, r dns.Resolver, hc *hydra.HydraClient, m mail.Mailer) (*Organization, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid invitations configuration: %w", err)
+	}
+
+	return &Organization{
+		db:                  db,
+		organizationService: os,
+		userService:         us,
+		apiTokenService:     as,
+		samlServiceProvider: sp,
+		resolver:            r,
+		hydraClient:         hc,
+		invitations:         invitations.NewSender(cfg, db, os, us, as, m),
+	}, nil
+}
@@ -0,0 +1,353 @@
+package organization
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"conformitea/app/internal/invitations"
+	"conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invites someone by email to join the organization with a role, which the actor's
+// own role must grant every permission of. The invitation is created even when
+// the email cannot be sent, so it can be resent.
+func (o *Organization) CreateInvitation(ctx context.Context, req types.CreateInvitationRequest) (types.Invitation, error) {
+	orgID, err := o.authorizeOrganization(req.Actor, req.OrganizationID, organization.PermissionMembersManage)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	if req.Actor.Kind != types.PrincipalUser {
+		return types.Invitation{}, fmt.Errorf("%w: invitations are sent by members", types.ErrPermissionDenied)
+	}
+
+	address, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if err != nil || address.Name != "" {
+		return types.Invitation{}, fmt.Errorf("%w: %q is not an email address", types.ErrInvalidRequest, req.Email)
+	}
+	email := strings.ToLower(address.Address)
+
+	role := req.Role
+	if role == "" {
+		role = organization.RoleMember
+	}
+
+	if err := o.checkAssignableRole(req.Actor, orgID, role); err != nil {
+		return types.Invitation{}, err
+	}
+
+	if err := o.checkInvitee(orgID, email); err != nil {
+		return types.Invitation{}, err
+	}
+
+	nonce, err := invitations.Nonce()
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	now := time.Now()
+
+	invitation, err := o.organizationService.CreateInvitation(o.db, organization.Invitation{
+		OrganizationID:     orgID,
+		Email:              email,
+		Role:               role,
+		AllowEmailMismatch: req.AllowEmailMismatch,
+		TokenNonce:         nonce,
+		InvitedBy:          uuid.MustParse(req.Actor.ID),
+		ExpiresAt:          o.invitations.ExpiresAt(now),
+		SentAt:             now,
+	})
+	if err != nil {
+		return types.Invitation{}, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	if err := o.invitations.Send(ctx, invitation); err != nil {
+		return types.Invitation{}, err
+	}
+
+	return toInvitation(invitation, now), nil
+}
+
+// Returns the invitations of the organization, the most recent first.
+func (o *Organization) ListInvitations(ctx context.Context, actor types.Actor, organizationID string) ([]types.Invitation, error) {
+	orgID, err := o.authorizeOrganization(actor, organizationID, organization.PermissionMembersRead)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := o.organizationService.GetInvitations(o.db, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	now := time.Now()
+
+	result := make([]types.Invitation, 0, len(invitations))
+	for _, i := range invitations {
+		result = append(result, toInvitation(i, now))
+	}
+
+	return result, nil
+}
+
+// Sends an invitation again with a new link, which can be accepted for another
+// TTL. Links sent before stop working. Expired invitations can be resent.
+func (o *Organization) ResendInvitation(ctx context.Context, req types.InvitationRequest) (types.Invitation, error) {
+	invitation, err := o.invitation(req)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return types.Invitation{}, fmt.Errorf("%w: the invitation was accepted or revoked", types.ErrInvalidRequest)
+	}
+
+	if invitation.TokenNonce, err = invitations.Nonce(); err != nil {
+		return types.Invitation{}, err
+	}
+
+	now := time.Now()
+	invitation.SentAt = now
+	invitation.ExpiresAt = o.invitations.ExpiresAt(now)
+
+	invitation, err = o.organizationService.UpdateInvitation(o.db, invitation)
+	if err != nil {
+		return types.Invitation{}, fmt.Errorf("failed to update invitation: %w", err)
+	}
+
+	if err := o.invitations.Send(ctx, invitation); err != nil {
+		return types.Invitation{}, err
+	}
+
+	return toInvitation(invitation, now), nil
+}
+
+// Revokes an invitation that was not accepted, so its link stops working.
+func (o *Organization) RevokeInvitation(ctx context.Context, req types.InvitationRequest) error {
+	invitation, err := o.invitation(req)
+	if err != nil {
+		return err
+	}
+
+	if invitation.AcceptedAt != nil {
+		return fmt.Errorf("%w: the invitation was accepted", types.ErrInvalidRequest)
+	}
+
+	if invitation.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	invitation.RevokedAt = &now
+
+	if _, err := o.organizationService.UpdateInvitation(o.db, invitation); err != nil {
+		return fmt.Errorf("failed to update invitation: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the invitation of a link, so the invitee can see what they are invited
+// to before signing in.
+func (o *Organization) PreviewInvitation(ctx context.Context, token string) (types.InvitationPreview, error) {
+	invitation, err := o.invitationByToken(token)
+	if err != nil {
+		return types.InvitationPreview{}, err
+	}
+
+	return o.toInvitationPreview(invitation)
+}
+
+// Adds the signed in user to the organization of an invitation link with the
+// invited role. Users whose email differs from the invited one can only accept
+// when the invitation allows it. The link cannot be used again.
+func (o *Organization) AcceptInvitation(ctx context.Context, actor types.Actor, token string) (types.InvitationPreview, error) {
+	if actor.Kind != types.PrincipalUser {
+		return types.InvitationPreview{}, fmt.Errorf("%w: invitations are accepted by users", types.ErrPermissionDenied)
+	}
+
+	userID, err := uuid.Parse(actor.ID)
+	if err != nil {
+		return types.InvitationPreview{}, fmt.Errorf("invalid actor ID %q: %w", actor.ID, err)
+	}
+
+	invitation, err := o.invitationByToken(token)
+	if err != nil {
+		return types.InvitationPreview{}, err
+	}
+
+	u, err := o.userService.GetUserByID(o.db, userID)
+	if err != nil {
+		return types.InvitationPreview{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !strings.EqualFold(u.Email, invitation.Email) && !invitation.AllowEmailMismatch {
+		return types.InvitationPreview{}, fmt.Errorf("%w: sign in as %s to accept it", types.ErrInvitationEmail, invitation.Email)
+	}
+
+	_, err = o.userService.GetOrganizationMember(o.db, invitation.OrganizationID, userID)
+	if err == nil {
+		return types.InvitationPreview{}, fmt.Errorf("%w: you are a member of the organization", types.ErrInvitationConflict)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.InvitationPreview{}, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	// Marking the invitation accepted first keeps a link from adding two users
+	err = o.db.Transaction(func(tx *gorm.DB) error {
+		accepted, err := o.organizationService.AcceptInvitation(tx, invitation.ID, userID, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to accept invitation: %w", err)
+		}
+
+		if !accepted {
+			return types.ErrInvitationInvalid
+		}
+
+		if _, err := o.userService.AddOrganizationMember(tx, u, organization.Membership{
+			OrganizationID: invitation.OrganizationID,
+			Role:           invitation.Role,
+		}); err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return types.InvitationPreview{}, err
+	}
+
+	return o.toInvitationPreview(invitation)
+}
+
+// Checks that nobody with the email is a member of the organization or has a
+// pending invitation to it.
+func (o *Organization) checkInvitee(orgID uuid.UUID, email string) error {
+	_, total, err := o.userService.GetOrganizationMembers(o.db, user.MemberQuery{
+		OrganizationID: orgID,
+		Email:          email,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get members: %w", err)
+	}
+
+	if total > 0 {
+		return fmt.Errorf("%w: %s is a member", types.ErrInvitationConflict, email)
+	}
+
+	_, pending, err := o.invitations.PendingInvitation(orgID, email)
+	if err != nil {
+		return err
+	}
+
+	if pending {
+		return fmt.Errorf("%w: %s has a pending invitation", types.ErrInvitationConflict, email)
+	}
+
+	return nil
+}
+
+// Returns the invitation of the request after checking that the actor may manage
+// the organization's members.
+func (o *Organization) invitation(req types.InvitationRequest) (organization.Invitation, error) {
+	orgID, err := o.authorizeOrganization(req.Actor, req.OrganizationID, organization.PermissionMembersManage)
+	if err != nil {
+		return organization.Invitation{}, err
+	}
+
+	id, err := uuid.Parse(req.InvitationID)
+	if err != nil {
+		return organization.Invitation{}, fmt.Errorf("%w: invalid ID %q", types.ErrInvitationNotFound, req.InvitationID)
+	}
+
+	invitation, err := o.organizationService.GetInvitation(o.db, orgID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return organization.Invitation{}, types.ErrInvitationNotFound
+	}
+	if err != nil {
+		return organization.Invitation{}, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// Returns the pending invitation of a link. Links that were tampered with, that
+// expired or whose invitation was accepted, revoked or resent are invalid.
+func (o *Organization) invitationByToken(token string) (organization.Invitation, error) {
+	id, nonce, ok := o.invitations.ParseToken(token)
+	if !ok {
+		return organization.Invitation{}, types.ErrInvitationInvalid
+	}
+
+	invitation, err := o.organizationService.GetInvitationByID(o.db, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return organization.Invitation{}, types.ErrInvitationInvalid
+	}
+	if err != nil {
+		return organization.Invitation{}, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(nonce), []byte(invitation.TokenNonce)) != 1 || !invitation.Pending(time.Now()) {
+		return organization.Invitation{}, types.ErrInvitationInvalid
+	}
+
+	return invitation, nil
+}
+
+func (o *Organization) toInvitationPreview(invitation organization.Invitation) (types.InvitationPreview, error) {
+	org, err := o.organizationService.GetOrganizationByID(o.db, invitation.OrganizationID)
+	if err != nil {
+		return types.InvitationPreview{}, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	inviter, err := o.invitations.InviterName(invitation)
+	if err != nil {
+		return types.InvitationPreview{}, err
+	}
+
+	return types.InvitationPreview{
+		OrganizationID:     org.ID.String(),
+		OrganizationName:   org.Name,
+		Email:              invitation.Email,
+		Role:               invitation.Role,
+		AllowEmailMismatch: invitation.AllowEmailMismatch,
+		InviterName:        inviter,
+		ExpiresAt:          invitation.ExpiresAt,
+	}, nil
+}
+
+func toInvitation(invitation organization.Invitation, now time.Time) types.Invitation {
+	status := types.InvitationPending
+	switch {
+	case invitation.AcceptedAt != nil:
+		status = types.InvitationAccepted
+	case invitation.RevokedAt != nil:
+		status = types.InvitationRevoked
+	case !now.Before(invitation.ExpiresAt):
+		status = types.InvitationExpired
+	}
+
+	return types.Invitation{
+		ID:                 invitation.ID.String(),
+		OrganizationID:     invitation.OrganizationID.String(),
+		Email:              invitation.Email,
+		Role:               invitation.Role,
+		AllowEmailMismatch: invitation.AllowEmailMismatch,
+		InvitedBy:          invitation.InvitedBy.String(),
+		Status:             status,
+		ExpiresAt:          invitation.ExpiresAt,
+		SentAt:             invitation.SentAt,
+		AcceptedAt:         invitation.AcceptedAt,
+		CreatedAt:          invitation.CreatedAt,
+	}
+}
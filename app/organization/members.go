@@ -0,0 +1,135 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Signs a member out of the organization's clients by revoking the consents they
+// granted them, which invalidates the tokens the clients were issued for them.
+// Their sessions with other clients and with ConformiTea itself are their own and
+// are left alone. Only owners can sign out owners.
+func (o *Organization) SignOutMember(ctx context.Context, req types.MemberRequest) error {
+	orgID, err := o.authorizeOrganization(req.Actor, req.OrganizationID, organization.PermissionMembersManage)
+	if err != nil {
+		return err
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid ID %q", types.ErrMemberNotFound, req.UserID)
+	}
+
+	member, err := o.userService.GetOrganizationMember(o.db, orgID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.ErrMemberNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get member: %w", err)
+	}
+
+	if member.Membership.Role == organization.RoleOwner {
+		// The actor ID was parsed when authorizing the actor
+		role, err := o.actorRole(req.Actor.Kind, uuid.MustParse(req.Actor.ID), orgID)
+		if err != nil {
+			return err
+		}
+
+		if role != organization.RoleOwner {
+			return fmt.Errorf("%w: only owners can sign out owners", types.ErrPermissionDenied)
+		}
+	}
+
+	consents, err := o.hydraClient.ListConsentSessions(userID.String())
+	if err != nil {
+		return fmt.Errorf("failed to list consents: %w", err)
+	}
+
+	revoked := map[string]bool{}
+	for _, consent := range consents {
+		client := consent.ConsentRequest.Client
+		organizationID, _ := client.Metadata[hydra.ClientMetadataOrganizationID].(string)
+		if organizationID != orgID.String() || revoked[client.ClientId] {
+			continue
+		}
+
+		if err := o.hydraClient.RevokeClientConsent(userID.String(), client.ClientId); err != nil {
+			return fmt.Errorf("failed to revoke consent: %w", err)
+		}
+		revoked[client.ClientId] = true
+	}
+
+	return nil
+}
+
+// Gives a member of the organization another role. Actors can only give and take
+// away roles granting permissions their own role grants, and the last owner of
+// the organization keeps their role.
+func (o *Organization) UpdateMemberRole(ctx context.Context, req types.UpdateMemberRoleRequest) error {
+	orgID, err := o.authorizeOrganization(req.Actor, req.OrganizationID, organization.PermissionMembersManage)
+	if err != nil {
+		return err
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid ID %q", types.ErrMemberNotFound, req.UserID)
+	}
+
+	member, err := o.userService.GetOrganizationMember(o.db, orgID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.ErrMemberNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get member: %w", err)
+	}
+
+	current := member.Membership.Role
+	if current == req.Role {
+		return nil
+	}
+
+	if err := o.checkAssignableRole(req.Actor, orgID, req.Role); err != nil {
+		return err
+	}
+
+	// Taking a role away is checked like giving it
+	currentPermissions, err := o.organizationService.RolePermissions(o.db, orgID, current)
+	if err != nil {
+		return fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	if err := o.checkGrantable(req.Actor, orgID, current, currentPermissions); err != nil {
+		return err
+	}
+
+	if current == organization.RoleOwner {
+		_, owners, err := o.userService.GetOrganizationMembers(o.db, user.MemberQuery{
+			OrganizationID: orgID,
+			Role:           organization.RoleOwner,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get owners: %w", err)
+		}
+
+		if owners <= 1 {
+			return fmt.Errorf("%w: the last owner of the organization cannot be given another role", types.ErrInvalidRequest)
+		}
+	}
+
+	member.Membership.Role = req.Role
+	if _, err := o.userService.UpdateMembership(o.db, member.Membership); err != nil {
+		return fmt.Errorf("failed to update membership: %w", err)
+	}
+
+	return nil
+}
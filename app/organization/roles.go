@@ -0,0 +1,305 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Names of custom roles are slugs, so they read well in token claims.
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+
+// Descriptions of the built-in roles.
+var builtInRoleDescriptions = map[string]string{
+	organization.RoleOwner:    "Full control of the organization",
+	organization.RoleAdmin:    "Manages members, roles and service accounts",
+	organization.RoleMember:   "Views the organization and its members",
+	organization.RoleAuditor:  "Views the organization, its members and its service accounts",
+	organization.RoleReadOnly: "Views the organization",
+}
+
+// Checks that the actor's role in the organization grants permission.
+func (o *Organization) Authorize(ctx context.Context, actor types.Actor, organizationID, permission string) error {
+	_, err := o.authorizeOrganization(actor, organizationID, permission)
+	return err
+}
+
+// Returns the permission catalog roles are composed of.
+func (o *Organization) ListPermissions(ctx context.Context) []types.Permission {
+	catalog := organization.PermissionCatalog()
+
+	result := make([]types.Permission, 0, len(catalog))
+	for _, p := range catalog {
+		result = append(result, types.Permission{
+			Name:        p.Name,
+			Description: p.Description,
+		})
+	}
+
+	return result
+}
+
+// Returns the roles members of the organization can hold, the built-in roles
+// first.
+func (o *Organization) ListRoles(ctx context.Context, actor types.Actor, organizationID string) ([]types.OrganizationRole, error) {
+	orgID, err := o.authorizeOrganization(actor, organizationID, organization.PermissionMembersRead)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := o.organizationService.GetRoles(o.db, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	result := make([]types.OrganizationRole, 0, len(roles)+len(builtInRoleDescriptions))
+	for _, name := range organization.BuiltInRoles() {
+		result = append(result, types.OrganizationRole{
+			Name:        name,
+			Description: builtInRoleDescriptions[name],
+			Permissions: organization.PermissionsForRole(name),
+			BuiltIn:     true,
+		})
+	}
+
+	for _, r := range roles {
+		result = append(result, toOrganizationRole(r))
+	}
+
+	return result, nil
+}
+
+// Creates a custom role in the organization. Actors can only compose roles from
+// permissions their own role grants.
+func (o *Organization) CreateRole(ctx context.Context, req types.CreateRoleRequest) (types.OrganizationRole, error) {
+	orgID, err := o.authorizeOrganization(req.Actor, req.OrganizationID, organization.PermissionRolesManage)
+	if err != nil {
+		return types.OrganizationRole{}, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if !roleNamePattern.MatchString(name) {
+		return types.OrganizationRole{}, fmt.Errorf("%w: role name %q must be lowercase letters, digits, dashes and underscores", types.ErrInvalidRequest, name)
+	}
+
+	if organization.IsBuiltInRole(name) {
+		return types.OrganizationRole{}, fmt.Errorf("%w: %s is a built-in role", types.ErrRoleConflict, name)
+	}
+
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return types.OrganizationRole{}, err
+	}
+
+	if err := o.checkGrantable(req.Actor, orgID, name, permissions); err != nil {
+		return types.OrganizationRole{}, err
+	}
+
+	_, err = o.organizationService.GetRoleByName(o.db, orgID, name)
+	if err == nil {
+		return types.OrganizationRole{}, fmt.Errorf("%w: %s", types.ErrRoleConflict, name)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.OrganizationRole{}, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	role, err := o.organizationService.CreateRole(o.db, organization.Role{
+		OrganizationID: orgID,
+		Name:           name,
+		Description:    strings.TrimSpace(req.Description),
+		Permissions:    permissions,
+	})
+	if err != nil {
+		return types.OrganizationRole{}, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	return toOrganizationRole(role), nil
+}
+
+// Changes the description or permissions of a custom role of the organization.
+// Members and service accounts holding the role are granted its new permissions
+// on their next request.
+func (o *Organization) UpdateRole(ctx context.Context, req types.UpdateRoleRequest) (types.OrganizationRole, error) {
+	role, err := o.role(types.RoleRequest{
+		Actor:          req.Actor,
+		OrganizationID: req.OrganizationID,
+		RoleID:         req.RoleID,
+	})
+	if err != nil {
+		return types.OrganizationRole{}, err
+	}
+
+	if req.Description != nil {
+		role.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if req.Permissions != nil {
+		if role.Permissions, err = normalizePermissions(req.Permissions); err != nil {
+			return types.OrganizationRole{}, err
+		}
+
+		if err := o.checkGrantable(req.Actor, role.OrganizationID, role.Name, role.Permissions); err != nil {
+			return types.OrganizationRole{}, err
+		}
+	}
+
+	role, err = o.organizationService.UpdateRole(o.db, role)
+	if err != nil {
+		return types.OrganizationRole{}, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	return toOrganizationRole(role), nil
+}
+
+// Deletes a custom role of the organization. Roles held by members or service
+// accounts cannot be deleted.
+func (o *Organization) DeleteRole(ctx context.Context, req types.RoleRequest) error {
+	role, err := o.role(req)
+	if err != nil {
+		return err
+	}
+
+	_, holders, err := o.userService.GetOrganizationMembers(o.db, user.MemberQuery{
+		OrganizationID: role.OrganizationID,
+		Role:           role.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get members: %w", err)
+	}
+
+	if holders > 0 {
+		return fmt.Errorf("%w: %s is held by %d members", types.ErrRoleConflict, role.Name, holders)
+	}
+
+	accounts, err := o.apiTokenService.GetOrganizationServiceAccounts(o.db, role.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to get service accounts: %w", err)
+	}
+
+	for _, a := range accounts {
+		if a.Role == role.Name {
+			return fmt.Errorf("%w: %s is held by service account %s", types.ErrRoleConflict, role.Name, a.Name)
+		}
+	}
+
+	if err := o.organizationService.DeleteRole(o.db, role.OrganizationID, role.ID); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the custom role of the request after checking that the actor may
+// manage it.
+func (o *Organization) role(req types.RoleRequest) (organization.Role, error) {
+	orgID, err := o.authorizeOrganization(req.Actor, req.OrganizationID, organization.PermissionRolesManage)
+	if err != nil {
+		return organization.Role{}, err
+	}
+
+	id, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		return organization.Role{}, fmt.Errorf("%w: invalid ID %q", types.ErrRoleNotFound, req.RoleID)
+	}
+
+	role, err := o.organizationService.GetRole(o.db, orgID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return organization.Role{}, types.ErrRoleNotFound
+	}
+	if err != nil {
+		return organization.Role{}, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	// Roles granting more than the actor's own role are out of their reach
+	if err := o.checkGrantable(req.Actor, orgID, role.Name, role.Permissions); err != nil {
+		return organization.Role{}, err
+	}
+
+	return role, nil
+}
+
+// Checks that the actor can give role to a member or service account of the
+// organization: the role exists and grants no permission the actor's role does
+// not.
+func (o *Organization) checkAssignableRole(actor types.Actor, orgID uuid.UUID, role string) error {
+	if !organization.IsBuiltInRole(role) {
+		if _, err := o.organizationService.GetRoleByName(o.db, orgID, role); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: unknown role %q", types.ErrInvalidRequest, role)
+			}
+
+			return fmt.Errorf("failed to get role: %w", err)
+		}
+	}
+
+	permissions, err := o.organizationService.RolePermissions(o.db, orgID, role)
+	if err != nil {
+		return fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	return o.checkGrantable(actor, orgID, role, permissions)
+}
+
+// Checks that the actor's role grants every one of the permissions of role, so
+// actors cannot grant themselves or others more than they hold. Only owners can
+// grant the owner role.
+func (o *Organization) checkGrantable(actor types.Actor, orgID uuid.UUID, role string, permissions []string) error {
+	actorRole, actorPermissions, err := o.actorPermissions(actor, orgID)
+	if err != nil {
+		return err
+	}
+
+	if role == organization.RoleOwner && actorRole != organization.RoleOwner {
+		return fmt.Errorf("%w: only owners can grant the owner role", types.ErrPermissionDenied)
+	}
+
+	for _, p := range permissions {
+		if !slices.Contains(actorPermissions, p) {
+			return fmt.Errorf("%w: %s grants %s, which %s does not", types.ErrPermissionDenied, role, p, actorRole)
+		}
+	}
+
+	return nil
+}
+
+// Checks that every permission is in the catalog and returns them without
+// duplicates, in catalog order.
+func normalizePermissions(permissions []string) ([]string, error) {
+	if len(permissions) == 0 {
+		return nil, fmt.Errorf("%w: a role grants at least one permission", types.ErrInvalidRequest)
+	}
+
+	for _, p := range permissions {
+		if !organization.IsPermission(p) {
+			return nil, fmt.Errorf("%w: unknown permission %q", types.ErrInvalidRequest, p)
+		}
+	}
+
+	result := make([]string, 0, len(permissions))
+	for _, p := range organization.PermissionCatalog() {
+		if slices.Contains(permissions, p.Name) {
+			result = append(result, p.Name)
+		}
+	}
+
+	return result, nil
+}
+
+func toOrganizationRole(role organization.Role) types.OrganizationRole {
+	return types.OrganizationRole{
+		ID:          role.ID.String(),
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+	}
+}
@@ -0,0 +1,120 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"conformitea/domain/organization"
+	"conformitea/server/types"
+
+	"gorm.io/gorm"
+)
+
+// Registers the SAML identity provider members of the organization sign in with,
+// replacing the one registered before. Metadata given by URL is fetched once,
+// when the identity provider is registered.
+func (o *Organization) ConfigureSAML(ctx context.Context, req types.ConfigureSAMLRequest) (types.SAMLConnection, error) {
+	orgID, err := o.authorizeOrganization(req.Actor, req.OrganizationID, organization.PermissionOrganizationManage)
+	if err != nil {
+		return types.SAMLConnection{}, err
+	}
+
+	metadataXML := strings.TrimSpace(req.MetadataXML)
+	metadataURL := strings.TrimSpace(req.MetadataURL)
+
+	if (metadataXML == "") == (metadataURL == "") {
+		return types.SAMLConnection{}, fmt.Errorf("%w: exactly one of metadata_xml and metadata_url is required", types.ErrInvalidRequest)
+	}
+
+	if metadataURL != "" {
+		data, err := o.samlServiceProvider.FetchMetadata(ctx, metadataURL)
+		if err != nil {
+			return types.SAMLConnection{}, fmt.Errorf("%w: %w", types.ErrSAMLMetadataInvalid, err)
+		}
+
+		metadataXML = string(data)
+	}
+
+	idp, err := o.samlServiceProvider.ParseMetadata([]byte(metadataXML))
+	if err != nil {
+		return types.SAMLConnection{}, fmt.Errorf("%w: %w", types.ErrSAMLMetadataInvalid, err)
+	}
+
+	connection, err := o.organizationService.SaveSAMLConnection(o.db, organization.SAMLConnection{
+		OrganizationID:     orgID,
+		IdPEntityID:        idp.EntityID,
+		MetadataURL:        metadataURL,
+		MetadataXML:        metadataXML,
+		EmailAttribute:     strings.TrimSpace(req.EmailAttribute),
+		FirstNameAttribute: strings.TrimSpace(req.FirstNameAttribute),
+		LastNameAttribute:  strings.TrimSpace(req.LastNameAttribute),
+	})
+	if err != nil {
+		return types.SAMLConnection{}, fmt.Errorf("failed to save saml connection: %w", err)
+	}
+
+	return o.toSAMLConnection(connection), nil
+}
+
+// Returns the SAML identity provider of the organization.
+func (o *Organization) GetSAMLConnection(ctx context.Context, actor types.Actor, organizationID string) (types.SAMLConnection, error) {
+	connection, err := o.samlConnection(actor, organizationID, organization.PermissionOrganizationRead)
+	if err != nil {
+		return types.SAMLConnection{}, err
+	}
+
+	return o.toSAMLConnection(connection), nil
+}
+
+// Removes the SAML identity provider of the organization. Members it signed in
+// keep their accounts and sign in with another provider.
+func (o *Organization) DeleteSAMLConnection(ctx context.Context, actor types.Actor, organizationID string) error {
+	connection, err := o.samlConnection(actor, organizationID, organization.PermissionOrganizationManage)
+	if err != nil {
+		return err
+	}
+
+	if err := o.organizationService.DeleteSAMLConnection(o.db, connection.OrganizationID); err != nil {
+		return fmt.Errorf("failed to delete saml connection: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the organization's SAML connection after checking that the actor's role
+// grants permission.
+func (o *Organization) samlConnection(actor types.Actor, organizationID, permission string) (organization.SAMLConnection, error) {
+	orgID, err := o.authorizeOrganization(actor, organizationID, permission)
+	if err != nil {
+		return organization.SAMLConnection{}, err
+	}
+
+	connection, err := o.organizationService.GetSAMLConnection(o.db, orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return organization.SAMLConnection{}, types.ErrSAMLNotConfigured
+	}
+	if err != nil {
+		return organization.SAMLConnection{}, fmt.Errorf("failed to get saml connection: %w", err)
+	}
+
+	return connection, nil
+}
+
+func (o *Organization) toSAMLConnection(connection organization.SAMLConnection) types.SAMLConnection {
+	orgID := connection.OrganizationID.String()
+
+	return types.SAMLConnection{
+		OrganizationID:     orgID,
+		IdPEntityID:        connection.IdPEntityID,
+		MetadataURL:        connection.MetadataURL,
+		EmailAttribute:     connection.EmailAttribute,
+		FirstNameAttribute: connection.FirstNameAttribute,
+		LastNameAttribute:  connection.LastNameAttribute,
+		SPEntityID:         o.samlServiceProvider.EntityID(orgID),
+		ACSURL:             o.samlServiceProvider.ACSURL(orgID),
+		CreatedAt:          connection.CreatedAt,
+		UpdatedAt:          connection.UpdatedAt,
+	}
+}
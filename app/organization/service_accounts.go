@@ -0,0 +1,225 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"conformitea/app/internal/tokens"
+	"conformitea/domain/apitoken"
+	"conformitea/domain/organization"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scopes a service account token can be granted.
+var serviceAccountTokenScopes = []string{types.OrganizationsScope, types.SCIMScope}
+
+// Creates a service account in the organization.
+func (o *Organization) CreateServiceAccount(ctx context.Context, req types.CreateServiceAccountRequest) (types.ServiceAccount, error) {
+	orgID, err := o.authorizeServiceAccounts(req.Actor, req.OrganizationID, organization.PermissionServiceAccountsManage)
+	if err != nil {
+		return types.ServiceAccount{}, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return types.ServiceAccount{}, fmt.Errorf("%w: name is required", types.ErrInvalidRequest)
+	}
+
+	role := req.Role
+	if role == "" {
+		role = organization.RoleMember
+	}
+
+	// Service accounts never own an organization
+	if role == organization.RoleOwner {
+		return types.ServiceAccount{}, fmt.Errorf("%w: service accounts cannot be given role %q", types.ErrInvalidRequest, role)
+	}
+
+	if err := o.checkAssignableRole(req.Actor, orgID, role); err != nil {
+		return types.ServiceAccount{}, err
+	}
+
+	account, err := o.apiTokenService.CreateServiceAccount(o.db, apitoken.ServiceAccount{
+		OrganizationID: orgID,
+		Name:           name,
+		Description:    strings.TrimSpace(req.Description),
+		Role:           role,
+		CreatedBy:      uuid.MustParse(req.Actor.ID),
+	})
+	if err != nil {
+		return types.ServiceAccount{}, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	return toServiceAccount(account), nil
+}
+
+// Returns the service accounts of the organization.
+func (o *Organization) ListServiceAccounts(ctx context.Context, actor types.Actor, organizationID string) ([]types.ServiceAccount, error) {
+	orgID, err := o.authorizeServiceAccounts(actor, organizationID, organization.PermissionServiceAccountsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := o.apiTokenService.GetOrganizationServiceAccounts(o.db, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service accounts: %w", err)
+	}
+
+	result := make([]types.ServiceAccount, 0, len(accounts))
+	for _, a := range accounts {
+		result = append(result, toServiceAccount(a))
+	}
+
+	return result, nil
+}
+
+// Deletes a service account of the organization and its tokens.
+func (o *Organization) DeleteServiceAccount(ctx context.Context, req types.ServiceAccountRequest) error {
+	account, err := o.serviceAccount(req, organization.PermissionServiceAccountsManage)
+	if err != nil {
+		return err
+	}
+
+	if err := o.apiTokenService.DeleteServiceAccount(o.db, account.ID); err != nil {
+		return fmt.Errorf("failed to delete service account: %w", err)
+	}
+
+	return nil
+}
+
+// Issues a token to a service account of the organization.
+func (o *Organization) CreateServiceAccountToken(ctx context.Context, req types.CreateAPITokenRequest) (types.IssuedAPIToken, error) {
+	account, err := o.serviceAccount(types.ServiceAccountRequest{
+		Actor:            req.Actor,
+		OrganizationID:   req.OrganizationID,
+		ServiceAccountID: req.ServiceAccountID,
+	}, organization.PermissionServiceAccountsManage)
+	if err != nil {
+		return types.IssuedAPIToken{}, err
+	}
+
+	name, expiresAt, err := tokens.ValidateRequest(req, serviceAccountTokenScopes)
+	if err != nil {
+		return types.IssuedAPIToken{}, err
+	}
+
+	token, secret, err := o.apiTokenService.IssueToken(o.db, apitoken.Token{
+		ServiceAccountID: &account.ID,
+		Name:             name,
+		Scopes:           req.Scopes,
+		ExpiresAt:        expiresAt,
+	}, types.ServiceAccountTokenPrefix)
+	if err != nil {
+		return types.IssuedAPIToken{}, fmt.Errorf("failed to issue api token: %w", err)
+	}
+
+	return types.IssuedAPIToken{
+		Token:  tokens.ToAPIToken(token),
+		Secret: secret,
+	}, nil
+}
+
+// Returns the tokens of a service account of the organization, including
+// expired and revoked ones.
+func (o *Organization) ListServiceAccountTokens(ctx context.Context, req types.ServiceAccountRequest) ([]types.APIToken, error) {
+	account, err := o.serviceAccount(req, organization.PermissionServiceAccountsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	accountTokens, err := o.apiTokenService.GetServiceAccountTokens(o.db, account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api tokens: %w", err)
+	}
+
+	result := make([]types.APIToken, 0, len(accountTokens))
+	for _, t := range accountTokens {
+		result = append(result, tokens.ToAPIToken(t))
+	}
+
+	return result, nil
+}
+
+// Revokes a token of a service account of the organization.
+func (o *Organization) RevokeServiceAccountToken(ctx context.Context, req types.ServiceAccountRequest, tokenID string) error {
+	account, err := o.serviceAccount(req, organization.PermissionServiceAccountsManage)
+	if err != nil {
+		return err
+	}
+
+	tid, err := uuid.Parse(tokenID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", types.ErrAPITokenNotFound, err)
+	}
+
+	revoked, err := o.apiTokenService.RevokeServiceAccountToken(o.db, account.ID, tid)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api token: %w", err)
+	}
+
+	if !revoked {
+		return types.ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+// Checks that the actor's role grants permission over the organization's service
+// accounts and returns the organization ID. Service accounts cannot manage
+// service accounts.
+func (o *Organization) authorizeServiceAccounts(actor types.Actor, organizationID, permission string) (uuid.UUID, error) {
+	if actor.Kind == types.PrincipalServiceAccount {
+		return uuid.Nil, fmt.Errorf("%w: service accounts cannot manage service accounts", types.ErrPermissionDenied)
+	}
+
+	orgID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid ID %q", types.ErrOrganizationNotFound, organizationID)
+	}
+
+	if err := o.authorize(actor, orgID, permission); err != nil {
+		return uuid.Nil, err
+	}
+
+	return orgID, nil
+}
+
+// Returns the service account of the request after checking that the actor's role
+// grants permission over it.
+func (o *Organization) serviceAccount(req types.ServiceAccountRequest, permission string) (apitoken.ServiceAccount, error) {
+	orgID, err := o.authorizeServiceAccounts(req.Actor, req.OrganizationID, permission)
+	if err != nil {
+		return apitoken.ServiceAccount{}, err
+	}
+
+	id, err := uuid.Parse(req.ServiceAccountID)
+	if err != nil {
+		return apitoken.ServiceAccount{}, fmt.Errorf("%w: invalid ID %q", types.ErrServiceAccountNotFound, req.ServiceAccountID)
+	}
+
+	account, err := o.apiTokenService.GetServiceAccount(o.db, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && account.OrganizationID != orgID) {
+		return apitoken.ServiceAccount{}, types.ErrServiceAccountNotFound
+	}
+	if err != nil {
+		return apitoken.ServiceAccount{}, fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	return account, nil
+}
+
+func toServiceAccount(account apitoken.ServiceAccount) types.ServiceAccount {
+	return types.ServiceAccount{
+		ID:             account.ID.String(),
+		OrganizationID: account.OrganizationID.String(),
+		Name:           account.Name,
+		Description:    account.Description,
+		Role:           account.Role,
+		CreatedAt:      account.CreatedAt,
+	}
+}
@@ -0,0 +1,98 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Links an Entra tenant to an organization, making the tenant's users members of
+// it when they sign in. Only staff and operators link tenants, once an
+// administrator of the tenant confirmed that the organization uses it, as tenant
+// users of any organization could claim the tenant otherwise.
+func (o *Organization) LinkTenant(ctx context.Context, req types.LinkTenantRequest) (types.OrganizationResult, error) {
+	if err := o.authorizeStaff(req.Actor); err != nil {
+		return types.OrganizationResult{}, err
+	}
+
+	orgID, err := uuid.Parse(req.OrganizationID)
+	if err != nil {
+		return types.OrganizationResult{}, fmt.Errorf("%w: invalid ID %q", types.ErrOrganizationNotFound, req.OrganizationID)
+	}
+
+	org, err := o.organizationService.GetOrganizationByID(o.db, orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.OrganizationResult{}, types.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return types.OrganizationResult{}, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	tenantID := strings.ToLower(strings.TrimSpace(req.TenantID))
+	if err := o.checkTenantLink(orgID, tenantID); err != nil {
+		return types.OrganizationResult{}, err
+	}
+
+	org.EntraTenantID = tenantID
+
+	org, err = o.organizationService.UpdateOrganization(o.db, org)
+	if err != nil {
+		return types.OrganizationResult{}, fmt.Errorf("failed to update organization: %w", err)
+	}
+
+	return toOrganizationResult(org), nil
+}
+
+// Checks that the tenant can be linked to the organization: it is a tenant ID not
+// linked to another organization.
+func (o *Organization) checkTenantLink(orgID uuid.UUID, tenantID string) error {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return fmt.Errorf("%w: %q is not a tenant ID", types.ErrInvalidRequest, tenantID)
+	}
+
+	linked, err := o.organizationService.GetOrganizationByTenantID(o.db, tenantID)
+	if err == nil && linked.ID != orgID {
+		return fmt.Errorf("%w: %s", types.ErrTenantConflict, tenantID)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return nil
+}
+
+// Checks that the actor is the operator or a staff user.
+func (o *Organization) authorizeStaff(actor types.Actor) error {
+	switch actor.Kind {
+	case types.PrincipalOperator:
+		return nil
+	case types.PrincipalUser:
+	default:
+		return fmt.Errorf("%w: %s cannot link tenants", types.ErrPermissionDenied, actor.Kind)
+	}
+
+	userID, err := uuid.Parse(actor.ID)
+	if err != nil {
+		return fmt.Errorf("invalid actor ID %q: %w", actor.ID, err)
+	}
+
+	u, err := o.userService.GetUserByID(o.db, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.ErrPermissionDenied
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !u.IsStaff {
+		return fmt.Errorf("%w: staff rights required", types.ErrPermissionDenied)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"conformitea/domain/organization"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Updates the organization's settings on behalf of a member or service account
+// allowed to manage it.
+func (o *Organization) UpdateOrganization(ctx context.Context, req types.UpdateOrganizationRequest) (types.OrganizationResult, error) {
+	orgID, err := o.authorizeOrganization(req.Actor, req.OrganizationID, organization.PermissionOrganizationManage)
+	if err != nil {
+		return types.OrganizationResult{}, err
+	}
+
+	org, err := o.organizationService.GetOrganizationByID(o.db, orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.OrganizationResult{}, types.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return types.OrganizationResult{}, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	if req.Name != nil {
+		org.Name = *req.Name
+	}
+
+	if req.RequireMFA != nil {
+		org.RequireMFA = *req.RequireMFA
+	}
+
+	if req.RequireSSO != nil {
+		org.RequireSSO = *req.RequireSSO
+	}
+
+	if req.EntraTenantID != nil {
+		tenantID := strings.ToLower(strings.TrimSpace(*req.EntraTenantID))
+		if tenantID != "" && tenantID != org.EntraTenantID {
+			return types.OrganizationResult{}, fmt.Errorf("%w: tenants are linked by staff", types.ErrPermissionDenied)
+		}
+
+		org.EntraTenantID = tenantID
+	}
+
+	// Users at the organization's domains could not sign in at all without its
+	// identity provider
+	if org.RequireSSO {
+		if _, err := o.organizationService.GetSAMLConnection(o.db, orgID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return types.OrganizationResult{}, fmt.Errorf("%w: require_sso needs a saml identity provider", types.ErrInvalidRequest)
+			}
+
+			return types.OrganizationResult{}, fmt.Errorf("failed to get saml connection: %w", err)
+		}
+	}
+
+	org, err = o.organizationService.UpdateOrganization(o.db, org)
+	if err != nil {
+		return types.OrganizationResult{}, fmt.Errorf("failed to update organization: %w", err)
+	}
+
+	return toOrganizationResult(org), nil
+}
+
+// Parses the organization ID and checks that the actor's role in the organization
+// grants permission.
+func (o *Organization) authorizeOrganization(actor types.Actor, organizationID, permission string) (uuid.UUID, error) {
+	orgID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid ID %q", types.ErrOrganizationNotFound, organizationID)
+	}
+
+	if err := o.authorize(actor, orgID, permission); err != nil {
+		return uuid.Nil, err
+	}
+
+	return orgID, nil
+}
+
+// Checks that the actor's role in the organization grants permission. Users who
+// are not members, and service accounts of other organizations, are told the
+// organization does not exist.
+func (o *Organization) authorize(actor types.Actor, orgID uuid.UUID, permission string) error {
+	role, permissions, err := o.actorPermissions(actor, orgID)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(permissions, permission) {
+		return fmt.Errorf("%w: %s requires %s", types.ErrPermissionDenied, role, permission)
+	}
+
+	return nil
+}
+
+// Returns the role the actor holds in the organization and the permissions it
+// grants, which for a custom role are those of the organization's role.
+func (o *Organization) actorPermissions(actor types.Actor, orgID uuid.UUID) (string, []string, error) {
+	// Clients acting on their own behalf are members of no organization
+	if actor.Kind == types.PrincipalClient {
+		return "", nil, types.ErrOrganizationNotFound
+	}
+
+	actorID, err := uuid.Parse(actor.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid actor ID %q: %w", actor.ID, err)
+	}
+
+	role, err := o.actorRole(actor.Kind, actorID, orgID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	permissions, err := o.organizationService.RolePermissions(o.db, orgID, role)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	return role, permissions, nil
+}
+
+// Returns the role the actor holds in the organization.
+func (o *Organization) actorRole(kind string, actorID, orgID uuid.UUID) (string, error) {
+	if kind == types.PrincipalServiceAccount {
+		account, err := o.apiTokenService.GetServiceAccount(o.db, actorID)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && account.OrganizationID != orgID) {
+			return "", types.ErrOrganizationNotFound
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get service account: %w", err)
+		}
+
+		return account.Role, nil
+	}
+
+	memberships, err := o.userService.GetUserMemberships(o.db, actorID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get memberships: %w", err)
+	}
+
+	for _, m := range memberships {
+		if m.OrganizationID == orgID && m.Active() {
+			return m.Role, nil
+		}
+	}
+
+	return "", types.ErrOrganizationNotFound
+}
+
+func toOrganizationResult(org organization.Organization) types.OrganizationResult {
+	return types.OrganizationResult{
+		ID:            org.ID.String(),
+		Name:          org.Name,
+		RequireMFA:    org.RequireMFA,
+		RequireSSO:    org.RequireSSO,
+		EntraTenantID: org.EntraTenantID,
+	}
+}
@@ -0,0 +1,93 @@
+package scim
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"conformitea/server/types"
+)
+
+// Parses a SCIM filter (RFC 7644 section 3.4.2.2) into the compared values by
+// lowercased attribute name. Only eq comparisons of the given attributes joined
+// by and are supported, which is what identity providers send to find the
+// resource they are about to provision.
+func parseFilter(filter string, attributes ...string) (map[string]string, error) {
+	values := map[string]string{}
+
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(tokens); i += 4 {
+		if i > 0 && !strings.EqualFold(tokens[i-1], "and") {
+			return nil, fmt.Errorf("%w: only and is supported between comparisons", types.ErrSCIMInvalidFilter)
+		}
+
+		if len(tokens) < i+3 {
+			return nil, fmt.Errorf("%w: incomplete comparison", types.ErrSCIMInvalidFilter)
+		}
+
+		attribute, operator, value := strings.ToLower(tokens[i]), tokens[i+1], tokens[i+2]
+
+		if !slices.Contains(attributes, attribute) {
+			return nil, fmt.Errorf("%w: filtering on %q is not supported", types.ErrSCIMInvalidFilter, tokens[i])
+		}
+
+		if !strings.EqualFold(operator, "eq") {
+			return nil, fmt.Errorf("%w: operator %q is not supported", types.ErrSCIMInvalidFilter, operator)
+		}
+
+		if !strings.HasPrefix(value, `"`) {
+			return nil, fmt.Errorf("%w: %s must be compared with a string", types.ErrSCIMInvalidFilter, tokens[i])
+		}
+
+		if values[attribute], err = strconv.Unquote(value); err != nil {
+			return nil, fmt.Errorf("%w: invalid string %s", types.ErrSCIMInvalidFilter, value)
+		}
+	}
+
+	if len(tokens)%4 != 3 && len(tokens) > 0 {
+		return nil, fmt.Errorf("%w: trailing %q", types.ErrSCIMInvalidFilter, tokens[len(tokens)-1])
+	}
+
+	return values, nil
+}
+
+// Splits a filter into words and quoted strings, quotes included.
+func tokenizeFilter(filter string) ([]string, error) {
+	var tokens []string
+
+	for i := 0; i < len(filter); {
+		switch {
+		case filter[i] == ' ':
+			i++
+		case filter[i] == '"':
+			end := i + 1
+			for end < len(filter) && filter[end] != '"' {
+				if filter[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(filter) {
+				return nil, fmt.Errorf("%w: unterminated string", types.ErrSCIMInvalidFilter)
+			}
+
+			tokens = append(tokens, filter[i:end+1])
+			i = end + 1
+		default:
+			end := i
+			for end < len(filter) && filter[end] != ' ' && filter[end] != '"' {
+				end++
+			}
+
+			tokens = append(tokens, filter[i:end])
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
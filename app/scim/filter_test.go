@@ -0,0 +1,92 @@
+package scim
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"conformitea/server/types"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name:   "empty",
+			filter: "",
+			want:   map[string]string{},
+		},
+		{
+			name:   "one comparison",
+			filter: `userName eq "ada@example.com"`,
+			want:   map[string]string{"username": "ada@example.com"},
+		},
+		{
+			name:   "attribute and operator in another case",
+			filter: `USERNAME EQ "ada@example.com"`,
+			want:   map[string]string{"username": "ada@example.com"},
+		},
+		{
+			name:   "comparisons joined by and",
+			filter: `userName eq "ada@example.com" and externalId eq "00u1"`,
+			want:   map[string]string{"username": "ada@example.com", "externalid": "00u1"},
+		},
+		{
+			name:   "string with escaped quotes and spaces",
+			filter: `externalId eq "a \"quoted\" id"`,
+			want:   map[string]string{"externalid": `a "quoted" id`},
+		},
+		{
+			name:    "comparisons joined by or",
+			filter:  `userName eq "ada@example.com" or externalId eq "00u1"`,
+			wantErr: types.ErrSCIMInvalidFilter,
+		},
+		{
+			name:    "unsupported attribute",
+			filter:  `displayName eq "Ada"`,
+			wantErr: types.ErrSCIMInvalidFilter,
+		},
+		{
+			name:    "unsupported operator",
+			filter:  `userName co "example.com"`,
+			wantErr: types.ErrSCIMInvalidFilter,
+		},
+		{
+			name:    "value that is not a string",
+			filter:  `userName eq true`,
+			wantErr: types.ErrSCIMInvalidFilter,
+		},
+		{
+			name:    "unterminated string",
+			filter:  `userName eq "ada@example.com`,
+			wantErr: types.ErrSCIMInvalidFilter,
+		},
+		{
+			name:    "incomplete comparison",
+			filter:  `userName eq`,
+			wantErr: types.ErrSCIMInvalidFilter,
+		},
+		{
+			name:    "trailing and",
+			filter:  `userName eq "ada@example.com" and`,
+			wantErr: types.ErrSCIMInvalidFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFilter(tt.filter, "username", "externalid")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseFilter() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && !maps.Equal(got, tt.want) {
+				t.Errorf("parseFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"conformitea/domain/team"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attributes groups can be filtered on.
+var groupFilterAttributes = []string{"id", "displayname", "externalid"}
+
+// Prefix of group attribute paths qualified with the schema URN, lowercased.
+const groupSchemaPrefix = "urn:ietf:params:scim:schemas:core:2.0:group:"
+
+// Path selecting one member of a group, as sent to remove it.
+var memberPathPattern = regexp.MustCompile(`(?i)^members\[value eq "([^"]*)"\]$`)
+
+// A member reference in a group's members attribute.
+type memberValue struct {
+	Value string `json:"value"`
+}
+
+// Returns the organization's teams matching the filter.
+func (s *SCIM) ListGroups(ctx context.Context, req types.SCIMListRequest) (types.SCIMGroupList, error) {
+	orgID, err := s.authorize(req.Tenant)
+	if err != nil {
+		return types.SCIMGroupList{}, err
+	}
+
+	result := types.SCIMGroupList{
+		StartIndex: max(req.StartIndex, 1),
+		Groups:     []types.SCIMGroup{},
+	}
+
+	values, err := parseFilter(req.Filter, groupFilterAttributes...)
+	if err != nil {
+		return types.SCIMGroupList{}, err
+	}
+
+	// Nothing has an empty display name or ID
+	if slices.Contains(slices.Collect(maps.Values(values)), "") {
+		return result, nil
+	}
+
+	query := team.TeamQuery{
+		OrganizationID: orgID,
+		Name:           values["displayname"],
+		ExternalID:     values["externalid"],
+	}
+
+	if id, ok := values["id"]; ok {
+		if query.TeamID, err = uuid.Parse(id); err != nil {
+			return result, nil
+		}
+	}
+
+	query.Offset, query.Limit = page(req)
+
+	teams, total, err := s.teamService.GetTeams(s.db, query)
+	if err != nil {
+		return types.SCIMGroupList{}, fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	result.TotalResults = int(total)
+	for _, t := range teams {
+		var members []uuid.UUID
+		if !req.ExcludeMembers {
+			if members, err = s.teamService.GetTeamMembers(s.db, t.ID); err != nil {
+				return types.SCIMGroupList{}, fmt.Errorf("failed to get team members: %w", err)
+			}
+		}
+
+		result.Groups = append(result.Groups, toSCIMGroup(t, members))
+	}
+
+	return result, nil
+}
+
+// Returns a team of the organization with its members.
+func (s *SCIM) GetGroup(ctx context.Context, tenant types.SCIMTenant, id string) (types.SCIMGroup, error) {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	t, err := s.team(orgID, id)
+	if err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	members, err := s.teamService.GetTeamMembers(s.db, t.ID)
+	if err != nil {
+		return types.SCIMGroup{}, fmt.Errorf("failed to get team members: %w", err)
+	}
+
+	return toSCIMGroup(t, members), nil
+}
+
+// Creates a team in the organization. Members must belong to the organization.
+func (s *SCIM) CreateGroup(ctx context.Context, tenant types.SCIMTenant, g types.SCIMGroup) (types.SCIMGroup, error) {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	if err := validateGroup(&g); err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	if err := s.checkGroupExternalIDFree(orgID, uuid.Nil, g.ExternalID); err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	members, err := s.memberIDs(orgID, g.Members)
+	if err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	t, err := s.teamService.CreateTeam(s.db, team.Team{
+		OrganizationID: orgID,
+		Name:           g.DisplayName,
+		ExternalID:     g.ExternalID,
+	}, members)
+	if err != nil {
+		return types.SCIMGroup{}, fmt.Errorf("failed to create team: %w", err)
+	}
+
+	return toSCIMGroup(t, members), nil
+}
+
+// Replaces the name and members of a team of the organization.
+func (s *SCIM) ReplaceGroup(ctx context.Context, tenant types.SCIMTenant, g types.SCIMGroup) (types.SCIMGroup, error) {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	t, err := s.team(orgID, g.ID)
+	if err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	if err := validateGroup(&g); err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	if g.ExternalID != t.ExternalID {
+		if err := s.checkGroupExternalIDFree(orgID, t.ID, g.ExternalID); err != nil {
+			return types.SCIMGroup{}, err
+		}
+	}
+
+	members, err := s.memberIDs(orgID, g.Members)
+	if err != nil {
+		return types.SCIMGroup{}, err
+	}
+
+	t.Name = g.DisplayName
+	t.ExternalID = g.ExternalID
+
+	if t, err = s.teamService.UpdateTeam(s.db, t, team.MemberChange{Replace: true, Added: members}); err != nil {
+		return types.SCIMGroup{}, fmt.Errorf("failed to replace team: %w", err)
+	}
+
+	return toSCIMGroup(t, members), nil
+}
+
+// Applies PATCH operations to a team of the organization. Members are added and
+// removed one by one instead of replacing the member list, which may be large.
+func (s *SCIM) PatchGroup(ctx context.Context, tenant types.SCIMTenant, id string, ops []types.SCIMPatchOperation) error {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.team(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	g := toSCIMGroup(t, nil)
+	change := team.MemberChange{}
+
+	for _, op := range ops {
+		expanded, err := expandPatchOp(op)
+		if err != nil {
+			return err
+		}
+
+		for _, op := range expanded {
+			if err := s.patchGroup(orgID, &g, &change, op); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := validateGroup(&g); err != nil {
+		return err
+	}
+
+	if g.ExternalID != t.ExternalID {
+		if err := s.checkGroupExternalIDFree(orgID, t.ID, g.ExternalID); err != nil {
+			return err
+		}
+	}
+
+	t.Name = g.DisplayName
+	t.ExternalID = g.ExternalID
+
+	if _, err := s.teamService.UpdateTeam(s.db, t, change); err != nil {
+		return fmt.Errorf("failed to update team: %w", err)
+	}
+
+	return nil
+}
+
+// Deletes a team of the organization. Its members stay in the organization.
+func (s *SCIM) DeleteGroup(ctx context.Context, tenant types.SCIMTenant, id string) error {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.team(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.teamService.DeleteTeam(s.db, t.ID); err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	return nil
+}
+
+// Applies one PATCH operation with a path to g, collecting member changes in change.
+func (s *SCIM) patchGroup(orgID uuid.UUID, g *types.SCIMGroup, change *team.MemberChange, op types.SCIMPatchOperation) error {
+	name, err := patchOp(op)
+	if err != nil {
+		return err
+	}
+
+	path := strings.TrimPrefix(strings.ToLower(op.Path), groupSchemaPrefix)
+
+	if match := memberPathPattern.FindStringSubmatch(path); match != nil {
+		if name != opRemove {
+			return fmt.Errorf("%w: members can only be selected to be removed", types.ErrSCIMInvalidPath)
+		}
+
+		if userID, err := uuid.Parse(match[1]); err == nil {
+			change.Remove(userID)
+		}
+
+		return nil
+	}
+
+	switch path {
+	case "displayname":
+		if name == opRemove {
+			return fmt.Errorf("%w: displayName cannot be removed", types.ErrSCIMInvalidValue)
+		}
+
+		g.DisplayName, err = stringValue(op.Path, op.Value)
+
+		return err
+	case "externalid":
+		if name == opRemove {
+			g.ExternalID = ""
+			return nil
+		}
+
+		g.ExternalID, err = stringValue(op.Path, op.Value)
+
+		return err
+	case "members":
+		return s.patchMembers(orgID, change, name, op.Value)
+	default:
+		return fmt.Errorf("%w: %s", types.ErrSCIMInvalidPath, op.Path)
+	}
+}
+
+// Collects the members added, removed or replaced by an operation on members.
+func (s *SCIM) patchMembers(orgID uuid.UUID, change *team.MemberChange, op string, value json.RawMessage) error {
+	// Removing members without a value removes all of them
+	if op == opRemove && len(value) == 0 {
+		change.Reset()
+		return nil
+	}
+
+	var refs []memberValue
+	if err := json.Unmarshal(value, &refs); err != nil {
+		return fmt.Errorf("%w: members must be a list of references", types.ErrSCIMInvalidValue)
+	}
+
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.Value)
+	}
+
+	if op == opRemove {
+		for _, id := range ids {
+			if userID, err := uuid.Parse(id); err == nil {
+				change.Remove(userID)
+			}
+		}
+
+		return nil
+	}
+
+	userIDs, err := s.memberIDs(orgID, ids)
+	if err != nil {
+		return err
+	}
+
+	if op == opReplace {
+		change.Reset()
+	}
+
+	for _, userID := range userIDs {
+		change.Add(userID)
+	}
+
+	return nil
+}
+
+// Returns the organization's team with the given ID.
+func (s *SCIM) team(orgID uuid.UUID, id string) (team.Team, error) {
+	teamID, err := uuid.Parse(id)
+	if err != nil {
+		return team.Team{}, fmt.Errorf("%w: invalid ID %q", types.ErrSCIMNotFound, id)
+	}
+
+	t, err := s.teamService.GetTeamByID(s.db, teamID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && t.OrganizationID != orgID) {
+		return team.Team{}, fmt.Errorf("%w: group %s", types.ErrSCIMNotFound, id)
+	}
+	if err != nil {
+		return team.Team{}, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	return t, nil
+}
+
+// Parses group member references, checking that each is a member of the organization.
+func (s *SCIM) memberIDs(orgID uuid.UUID, ids []string) ([]uuid.UUID, error) {
+	userIDs := make([]uuid.UUID, 0, len(ids))
+
+	for _, id := range ids {
+		member, err := s.member(orgID, id)
+		if errors.Is(err, types.ErrSCIMNotFound) {
+			return nil, fmt.Errorf("%w: %s is not a member of the organization", types.ErrSCIMInvalidValue, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(userIDs, member.User.ID) {
+			userIDs = append(userIDs, member.User.ID)
+		}
+	}
+
+	return userIDs, nil
+}
+
+// Checks that no team of the organization other than teamID has the external ID.
+func (s *SCIM) checkGroupExternalIDFree(orgID, teamID uuid.UUID, externalID string) error {
+	if externalID == "" {
+		return nil
+	}
+
+	teams, _, err := s.teamService.GetTeams(s.db, team.TeamQuery{
+		OrganizationID: orgID,
+		ExternalID:     externalID,
+		Limit:          1,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	if len(teams) > 0 && teams[0].ID != teamID {
+		return fmt.Errorf("%w: external ID %s belongs to another group", types.ErrSCIMUniqueness, externalID)
+	}
+
+	return nil
+}
+
+func validateGroup(g *types.SCIMGroup) error {
+	g.DisplayName = strings.TrimSpace(g.DisplayName)
+	if g.DisplayName == "" {
+		return fmt.Errorf("%w: displayName is required", types.ErrSCIMInvalidValue)
+	}
+
+	return nil
+}
+
+func toSCIMGroup(t team.Team, members []uuid.UUID) types.SCIMGroup {
+	g := types.SCIMGroup{
+		ID:           t.ID.String(),
+		ExternalID:   t.ExternalID,
+		DisplayName:  t.Name,
+		Members:      make([]string, 0, len(members)),
+		Created:      t.CreatedAt,
+		LastModified: t.UpdatedAt,
+	}
+
+	for _, m := range members {
+		g.Members = append(g.Members, m.String())
+	}
+
+	return g
+}
@@ -0,0 +1,36 @@
+package scim
+
+import (
+	"conformitea/app/config"
+	"conformitea/app/internal/invitations"
+	"conformitea/domain/apitoken"
+	"conformitea/domain/organization"
+	"conformitea/domain/team"
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/infrastructure/gateway/mail"
+
+	"gorm.io/gorm"
+)
+
+type SCIM struct {
+	db                  *gorm.DB
+	userService         *user.UserService
+	teamService         *team.TeamService
+	apiTokenService     *apitoken.APITokenService
+	organizationService *organization.OrganizationService
+	hydraClient         *hydra.HydraClient
+	invitations         *invitations.Sender
+}
+
+func Initialize(cfg config.InvitationConfig, db *gorm.DB, us *user.UserService, ts *team.TeamService, as *apitoken.APITokenService, os *organization.OrganizationService, hc *hydra.HydraClient, m mail.Mailer) (*SCIM, error) {
+	return &SCIM{
+		db:                  db,
+		userService:         us,
+		teamService:         ts,
+		apiTokenService:     as,
+		organizationService: os,
+		hydraClient:         hc,
+		invitations:         invitations.NewSender(cfg, db, os, us, as, m),
+	}, nil
+}
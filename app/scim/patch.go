@@ -0,0 +1,77 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"conformitea/server/types"
+)
+
+// Operations of a SCIM PATCH request.
+const (
+	opAdd     = "add"
+	opRemove  = "remove"
+	opReplace = "replace"
+)
+
+// Returns the lowercased operation of a PATCH operation.
+func patchOp(op types.SCIMPatchOperation) (string, error) {
+	name := strings.ToLower(op.Op)
+
+	switch name {
+	case opAdd, opRemove, opReplace:
+		return name, nil
+	default:
+		return "", fmt.Errorf("%w: unknown patch operation %q", types.ErrSCIMInvalidSyntax, op.Op)
+	}
+}
+
+// Splits an operation without a path into one operation per attribute of its
+// value object, so they can be applied like operations with a path.
+func expandPatchOp(op types.SCIMPatchOperation) ([]types.SCIMPatchOperation, error) {
+	if op.Path != "" {
+		return []types.SCIMPatchOperation{op}, nil
+	}
+
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(op.Value, &attributes); err != nil {
+		return nil, fmt.Errorf("%w: an operation without a path needs an object value", types.ErrSCIMInvalidValue)
+	}
+
+	ops := make([]types.SCIMPatchOperation, 0, len(attributes))
+	for path, value := range attributes {
+		ops = append(ops, types.SCIMPatchOperation{Op: op.Op, Path: path, Value: value})
+	}
+
+	return ops, nil
+}
+
+// Decodes a string attribute value.
+func stringValue(path string, value json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return "", fmt.Errorf("%w: %s must be a string", types.ErrSCIMInvalidValue, path)
+	}
+
+	return s, nil
+}
+
+// Decodes a boolean attribute value. Some identity providers send booleans as
+// strings, such as "False".
+func boolValue(path string, value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+
+	return false, fmt.Errorf("%w: %s must be a boolean", types.ErrSCIMInvalidValue, path)
+}
@@ -0,0 +1,106 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"conformitea/server/types"
+)
+
+func TestPatchUser(t *testing.T) {
+	original := types.SCIMUser{
+		ExternalID: "00u1",
+		UserName:   "ada@example.com",
+		GivenName:  "Ada",
+		FamilyName: "Lovelace",
+		Active:     true,
+	}
+
+	tests := []struct {
+		name    string
+		ops     string
+		want    types.SCIMUser
+		wantErr error
+	}{
+		{
+			name: "replace active with a path",
+			ops:  `[{"op": "replace", "path": "active", "value": false}]`,
+			want: types.SCIMUser{ExternalID: "00u1", UserName: "ada@example.com", GivenName: "Ada", FamilyName: "Lovelace"},
+		},
+		{
+			name: "replace active sent as a string",
+			ops:  `[{"op": "Replace", "path": "active", "value": "False"}]`,
+			want: types.SCIMUser{ExternalID: "00u1", UserName: "ada@example.com", GivenName: "Ada", FamilyName: "Lovelace"},
+		},
+		{
+			name: "replace without a path",
+			ops:  `[{"op": "replace", "value": {"active": false, "userName": "ada.lovelace@example.com"}}]`,
+			want: types.SCIMUser{ExternalID: "00u1", UserName: "ada.lovelace@example.com", GivenName: "Ada", FamilyName: "Lovelace"},
+		},
+		{
+			name: "replace a sub-attribute qualified with the schema",
+			ops:  `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:name.givenName", "value": "Augusta"}]`,
+			want: types.SCIMUser{ExternalID: "00u1", UserName: "ada@example.com", GivenName: "Augusta", FamilyName: "Lovelace", Active: true},
+		},
+		{
+			name: "replace part of name",
+			ops:  `[{"op": "replace", "path": "name", "value": {"familyName": "King"}}]`,
+			want: types.SCIMUser{ExternalID: "00u1", UserName: "ada@example.com", GivenName: "Ada", FamilyName: "King", Active: true},
+		},
+		{
+			name: "remove externalId",
+			ops:  `[{"op": "remove", "path": "externalId"}]`,
+			want: types.SCIMUser{UserName: "ada@example.com", GivenName: "Ada", FamilyName: "Lovelace", Active: true},
+		},
+		{
+			name: "unknown attributes are ignored",
+			ops:  `[{"op": "add", "path": "title", "value": "Countess"}]`,
+			want: original,
+		},
+		{
+			name:    "remove userName",
+			ops:     `[{"op": "remove", "path": "userName"}]`,
+			wantErr: types.ErrSCIMInvalidValue,
+		},
+		{
+			name:    "unknown operation",
+			ops:     `[{"op": "move", "path": "userName", "value": "ada@example.com"}]`,
+			wantErr: types.ErrSCIMInvalidSyntax,
+		},
+		{
+			name:    "userName that is not a string",
+			ops:     `[{"op": "replace", "path": "userName", "value": 42}]`,
+			wantErr: types.ErrSCIMInvalidValue,
+		},
+		{
+			name:    "active that is not a boolean",
+			ops:     `[{"op": "replace", "path": "active", "value": "maybe"}]`,
+			wantErr: types.ErrSCIMInvalidValue,
+		},
+		{
+			name:    "no path and a value that is not an object",
+			ops:     `[{"op": "replace", "value": false}]`,
+			wantErr: types.ErrSCIMInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []types.SCIMPatchOperation
+			if err := json.Unmarshal([]byte(tt.ops), &ops); err != nil {
+				t.Fatalf("failed to decode operations: %v", err)
+			}
+
+			u := original
+			err := patchUserOps(&u, ops)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("patch error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && u != tt.want {
+				t.Errorf("patched user = %+v, want %+v", u, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"conformitea/domain/organization"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Checks that the tenant's actor is a service account of the organization allowed
+// to manage its members, and returns the organization ID.
+func (s *SCIM) authorize(tenant types.SCIMTenant) (uuid.UUID, error) {
+	if tenant.Actor.Kind != types.PrincipalServiceAccount {
+		return uuid.Nil, fmt.Errorf("%w: only service accounts can provision organizations", types.ErrPermissionDenied)
+	}
+
+	orgID, err := uuid.Parse(tenant.OrganizationID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid organization ID %q", types.ErrPermissionDenied, tenant.OrganizationID)
+	}
+
+	accountID, err := uuid.Parse(tenant.Actor.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid service account ID %q: %w", tenant.Actor.ID, err)
+	}
+
+	account, err := s.apiTokenService.GetServiceAccount(s.db, accountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && account.OrganizationID != orgID) {
+		return uuid.Nil, fmt.Errorf("%w: service account does not belong to the organization", types.ErrPermissionDenied)
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	permissions, err := s.organizationService.RolePermissions(s.db, orgID, account.Role)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	// Provisioning the organization's members and teams is managing its members
+	if !slices.Contains(permissions, organization.PermissionMembersManage) {
+		return uuid.Nil, fmt.Errorf("%w: %s requires %s", types.ErrPermissionDenied, account.Role, organization.PermissionMembersManage)
+	}
+
+	return orgID, nil
+}
+
+// Returns the offset and limit of a list request's page.
+func page(req types.SCIMListRequest) (int, int) {
+	offset := max(req.StartIndex, 1) - 1
+	limit := min(max(req.Count, 0), types.SCIMMaxResults)
+
+	return offset, limit
+}
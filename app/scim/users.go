@@ -0,0 +1,537 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"conformitea/app/internal/invitations"
+	"conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attributes users can be filtered on.
+var userFilterAttributes = []string{"id", "username", "externalid"}
+
+// Prefix of user attribute paths qualified with the schema URN, lowercased.
+const userSchemaPrefix = "urn:ietf:params:scim:schemas:core:2.0:user:"
+
+// Returns the organization's members matching the filter.
+func (s *SCIM) ListUsers(ctx context.Context, req types.SCIMListRequest) (types.SCIMUserList, error) {
+	orgID, err := s.authorize(req.Tenant)
+	if err != nil {
+		return types.SCIMUserList{}, err
+	}
+
+	result := types.SCIMUserList{
+		StartIndex: max(req.StartIndex, 1),
+		Users:      []types.SCIMUser{},
+	}
+
+	values, err := parseFilter(req.Filter, userFilterAttributes...)
+	if err != nil {
+		return types.SCIMUserList{}, err
+	}
+
+	// Nothing has an empty user name or ID
+	if slices.Contains(slices.Collect(maps.Values(values)), "") {
+		return result, nil
+	}
+
+	query := user.MemberQuery{
+		OrganizationID: orgID,
+		Email:          values["username"],
+		ExternalID:     values["externalid"],
+	}
+
+	if id, ok := values["id"]; ok {
+		if query.UserID, err = uuid.Parse(id); err != nil {
+			return result, nil
+		}
+	}
+
+	query.Offset, query.Limit = page(req)
+
+	members, total, err := s.userService.GetOrganizationMembers(s.db, query)
+	if err != nil {
+		return types.SCIMUserList{}, fmt.Errorf("failed to get members: %w", err)
+	}
+
+	result.TotalResults = int(total)
+	for _, m := range members {
+		result.Users = append(result.Users, toSCIMUser(m))
+	}
+
+	return result, nil
+}
+
+// Returns a member of the organization.
+func (s *SCIM) GetUser(ctx context.Context, tenant types.SCIMTenant, id string) (types.SCIMUser, error) {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	member, err := s.member(orgID, id)
+	if err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	return toSCIMUser(member), nil
+}
+
+// Adds a user to the organization. A user who already has an account, because
+// they signed in before or belong to another organization, is linked by email and
+// keeps their profile unless the organization owns the account. Accounts are only
+// created at domains the organization verified; anyone else is invited to join
+// with an account of their own, and the directory finds them once they did.
+func (s *SCIM) CreateUser(ctx context.Context, tenant types.SCIMTenant, u types.SCIMUser) (types.SCIMUser, error) {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	if err := validateUser(&u); err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	if err := s.checkExternalIDFree(orgID, uuid.Nil, u.ExternalID); err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	// Accounts the organization creates are its own
+	owned := true
+
+	existing, err := s.userService.GetUserByEmail(s.db, u.UserName)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		verified, err := s.verifiedDomain(orgID, u.UserName)
+		if err != nil {
+			return types.SCIMUser{}, err
+		}
+
+		if !verified {
+			return types.SCIMUser{}, s.invite(ctx, orgID, tenant.Actor, u.UserName)
+		}
+
+		existing = user.User{}
+	case err != nil:
+		return types.SCIMUser{}, fmt.Errorf("failed to get user: %w", err)
+	default:
+		_, err := s.userService.GetOrganizationMember(s.db, orgID, existing.ID)
+		if err == nil {
+			return types.SCIMUser{}, fmt.Errorf("%w: %s is already a member", types.ErrSCIMUniqueness, u.UserName)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.SCIMUser{}, fmt.Errorf("failed to get member: %w", err)
+		}
+
+		if owned, err = s.ownsUser(orgID, existing, u.UserName); err != nil {
+			return types.SCIMUser{}, err
+		}
+
+		if owned && existing.Active() && !u.Active {
+			if err := s.revokeSessions(existing.ID); err != nil {
+				return types.SCIMUser{}, err
+			}
+		}
+	}
+
+	if owned {
+		applyUser(&existing, u)
+	}
+
+	member, err := s.userService.AddOrganizationMember(s.db, existing, organization.Membership{
+		OrganizationID: orgID,
+		Role:           organization.RoleMember,
+		ExternalID:     u.ExternalID,
+		DeactivatedAt:  deactivatedAt(nil, u.Active),
+	})
+	if err != nil {
+		return types.SCIMUser{}, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	return toSCIMUser(member), nil
+}
+
+// Replaces the profile of a member of the organization.
+func (s *SCIM) ReplaceUser(ctx context.Context, tenant types.SCIMTenant, u types.SCIMUser) (types.SCIMUser, error) {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	member, err := s.member(orgID, u.ID)
+	if err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	return s.saveUser(member, u)
+}
+
+// Applies PATCH operations to a member of the organization. Attributes that
+// are not stored, such as phone numbers or titles, are ignored.
+func (s *SCIM) PatchUser(ctx context.Context, tenant types.SCIMTenant, id string, ops []types.SCIMPatchOperation) (types.SCIMUser, error) {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	member, err := s.member(orgID, id)
+	if err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	u := toSCIMUser(member)
+
+	if err := patchUserOps(&u, ops); err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	return s.saveUser(member, u)
+}
+
+// Deactivates a member of the organization, and the account with its sessions
+// when the organization owns it. Users are never deleted, to keep the history of
+// what they did.
+func (s *SCIM) DeactivateUser(ctx context.Context, tenant types.SCIMTenant, id string) error {
+	orgID, err := s.authorize(tenant)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.member(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if !member.Membership.Active() {
+		return nil
+	}
+
+	u := toSCIMUser(member)
+	u.Active = false
+
+	_, err = s.saveUser(member, u)
+
+	return err
+}
+
+// Stores the membership of a member, and their profile when the organization owns
+// their account. Deactivating an owned account ends its sessions first, so a
+// failure to do so can be retried. The profile of other accounts is left alone.
+func (s *SCIM) saveUser(member user.Member, u types.SCIMUser) (types.SCIMUser, error) {
+	if err := validateUser(&u); err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	owned, err := s.ownsUser(member.Membership.OrganizationID, member.User, u.UserName)
+	if err != nil {
+		return types.SCIMUser{}, err
+	}
+
+	if owned && !strings.EqualFold(u.UserName, member.User.Email) {
+		other, err := s.userService.GetUserByEmail(s.db, u.UserName)
+		if err == nil && other.ID != member.User.ID {
+			return types.SCIMUser{}, fmt.Errorf("%w: %s belongs to another user", types.ErrSCIMUniqueness, u.UserName)
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.SCIMUser{}, fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+
+	if u.ExternalID != member.Membership.ExternalID {
+		if err := s.checkExternalIDFree(member.Membership.OrganizationID, member.User.ID, u.ExternalID); err != nil {
+			return types.SCIMUser{}, err
+		}
+	}
+
+	member.Membership.ExternalID = u.ExternalID
+	member.Membership.DeactivatedAt = deactivatedAt(member.Membership.DeactivatedAt, u.Active)
+
+	if !owned {
+		if member.Membership, err = s.userService.UpdateMembership(s.db, member.Membership); err != nil {
+			return types.SCIMUser{}, fmt.Errorf("failed to update membership: %w", err)
+		}
+
+		return toSCIMUser(member), nil
+	}
+
+	if member.User.Active() && !u.Active {
+		if err := s.revokeSessions(member.User.ID); err != nil {
+			return types.SCIMUser{}, err
+		}
+	}
+
+	applyUser(&member.User, u)
+
+	if member, err = s.userService.UpdateOrganizationMember(s.db, member); err != nil {
+		return types.SCIMUser{}, fmt.Errorf("failed to update member: %w", err)
+	}
+
+	return toSCIMUser(member), nil
+}
+
+// Reports whether the organization owns the account: it has no other members,
+// and both its email and the one the directory gives it are at domains the
+// organization verified. Only the directory of an owned account changes its
+// profile or deactivates it.
+func (s *SCIM) ownsUser(orgID uuid.UUID, account user.User, email string) (bool, error) {
+	for _, e := range []string{account.Email, email} {
+		verified, err := s.verifiedDomain(orgID, e)
+		if err != nil || !verified {
+			return false, err
+		}
+	}
+
+	memberships, err := s.userService.GetUserMemberships(s.db, account.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get memberships: %w", err)
+	}
+
+	for _, m := range memberships {
+		if m.OrganizationID != orgID {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Reports whether the email is at a domain the organization verified.
+func (s *SCIM) verifiedDomain(orgID uuid.UUID, email string) (bool, error) {
+	domain, err := s.organizationService.GetVerifiedDomain(s.db, organization.EmailDomain(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	return domain.OrganizationID == orgID, nil
+}
+
+// Invites email to join the organization as a member on behalf of the directory's
+// service account, unless an invitation is pending already. Returns
+// ErrSCIMUserInvited once the invitation was sent, as the user does not exist
+// until they accept it.
+func (s *SCIM) invite(ctx context.Context, orgID uuid.UUID, actor types.Actor, email string) error {
+	email = strings.ToLower(email)
+	invited := fmt.Errorf("%w: %s is not at a domain the organization verified and was invited to join it", types.ErrSCIMUserInvited, email)
+
+	_, pending, err := s.invitations.PendingInvitation(orgID, email)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return invited
+	}
+
+	accountID, err := uuid.Parse(actor.ID)
+	if err != nil {
+		return fmt.Errorf("invalid service account ID %q: %w", actor.ID, err)
+	}
+
+	nonce, err := invitations.Nonce()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	invitation, err := s.organizationService.CreateInvitation(s.db, organization.Invitation{
+		OrganizationID: orgID,
+		Email:          email,
+		Role:           organization.RoleMember,
+		TokenNonce:     nonce,
+		InvitedBy:      accountID,
+		ExpiresAt:      s.invitations.ExpiresAt(now),
+		SentAt:         now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	if err := s.invitations.Send(ctx, invitation); err != nil {
+		return err
+	}
+
+	return invited
+}
+
+// Returns the organization's member with the given ID.
+func (s *SCIM) member(orgID uuid.UUID, id string) (user.Member, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return user.Member{}, fmt.Errorf("%w: invalid ID %q", types.ErrSCIMNotFound, id)
+	}
+
+	member, err := s.userService.GetOrganizationMember(s.db, orgID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user.Member{}, fmt.Errorf("%w: user %s", types.ErrSCIMNotFound, id)
+	}
+	if err != nil {
+		return user.Member{}, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	return member, nil
+}
+
+// Checks that no member of the organization other than userID has the external ID.
+func (s *SCIM) checkExternalIDFree(orgID, userID uuid.UUID, externalID string) error {
+	if externalID == "" {
+		return nil
+	}
+
+	members, _, err := s.userService.GetOrganizationMembers(s.db, user.MemberQuery{
+		OrganizationID: orgID,
+		ExternalID:     externalID,
+		Limit:          1,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get members: %w", err)
+	}
+
+	if len(members) > 0 && members[0].User.ID != userID {
+		return fmt.Errorf("%w: external ID %s belongs to another user", types.ErrSCIMUniqueness, externalID)
+	}
+
+	return nil
+}
+
+// Ends the user's Hydra sessions and revokes the tokens issued to them.
+func (s *SCIM) revokeSessions(userID uuid.UUID) error {
+	if err := s.hydraClient.RevokeSubjectSessions(userID.String()); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// Applies the operations of a PATCH request to u.
+func patchUserOps(u *types.SCIMUser, ops []types.SCIMPatchOperation) error {
+	for _, op := range ops {
+		expanded, err := expandPatchOp(op)
+		if err != nil {
+			return err
+		}
+
+		for _, op := range expanded {
+			if err := patchUser(u, op); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Applies one PATCH operation with a path to u.
+func patchUser(u *types.SCIMUser, op types.SCIMPatchOperation) error {
+	name, err := patchOp(op)
+	if err != nil {
+		return err
+	}
+
+	path := strings.TrimPrefix(strings.ToLower(op.Path), userSchemaPrefix)
+
+	if name == opRemove {
+		switch path {
+		case "username", "active":
+			return fmt.Errorf("%w: %s cannot be removed", types.ErrSCIMInvalidValue, op.Path)
+		case "externalid":
+			u.ExternalID = ""
+		case "name":
+			u.GivenName, u.FamilyName = "", ""
+		case "name.givenname":
+			u.GivenName = ""
+		case "name.familyname":
+			u.FamilyName = ""
+		}
+
+		return nil
+	}
+
+	switch path {
+	case "username":
+		u.UserName, err = stringValue(op.Path, op.Value)
+	case "externalid":
+		u.ExternalID, err = stringValue(op.Path, op.Value)
+	case "name.givenname":
+		u.GivenName, err = stringValue(op.Path, op.Value)
+	case "name.familyname":
+		u.FamilyName, err = stringValue(op.Path, op.Value)
+	case "active":
+		u.Active, err = boolValue(op.Path, op.Value)
+	case "name":
+		// Sub-attributes left out of the value are unchanged
+		var name struct {
+			GivenName  *string `json:"givenName"`
+			FamilyName *string `json:"familyName"`
+		}
+		if err := json.Unmarshal(op.Value, &name); err != nil {
+			return fmt.Errorf("%w: name must be an object", types.ErrSCIMInvalidValue)
+		}
+		if name.GivenName != nil {
+			u.GivenName = *name.GivenName
+		}
+		if name.FamilyName != nil {
+			u.FamilyName = *name.FamilyName
+		}
+	}
+
+	return err
+}
+
+func validateUser(u *types.SCIMUser) error {
+	u.UserName = strings.TrimSpace(u.UserName)
+	if u.UserName == "" {
+		return fmt.Errorf("%w: userName is required", types.ErrSCIMInvalidValue)
+	}
+
+	return nil
+}
+
+// Copies the directory's view of a user onto an account the organization owns.
+func applyUser(account *user.User, u types.SCIMUser) {
+	account.Email = u.UserName
+	account.FirstName = u.GivenName
+	account.LastName = u.FamilyName
+	account.DeactivatedAt = deactivatedAt(account.DeactivatedAt, u.Active)
+}
+
+// Returns when the user or membership was deactivated, keeping the time of an
+// earlier deactivation.
+func deactivatedAt(current *time.Time, active bool) *time.Time {
+	switch {
+	case active:
+		return nil
+	case current == nil:
+		now := time.Now()
+		return &now
+	default:
+		return current
+	}
+}
+
+func toSCIMUser(m user.Member) types.SCIMUser {
+	return types.SCIMUser{
+		ID:           m.User.ID.String(),
+		ExternalID:   m.Membership.ExternalID,
+		UserName:     m.User.Email,
+		GivenName:    m.User.FirstName,
+		FamilyName:   m.User.LastName,
+		Active:       m.Membership.Active(),
+		Created:      m.Membership.CreatedAt,
+		LastModified: m.User.UpdatedAt,
+	}
+}
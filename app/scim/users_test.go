@@ -0,0 +1,209 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"conformitea/app/config"
+	"conformitea/domain"
+	domainAPIToken "conformitea/domain/apitoken"
+	domainOrganization "conformitea/domain/organization"
+	"conformitea/domain/user"
+	"conformitea/infrastructure/gateway/mail"
+	"conformitea/infrastructure/persistence/apitoken"
+	"conformitea/infrastructure/persistence/audit"
+	"conformitea/infrastructure/persistence/mfa"
+	"conformitea/infrastructure/persistence/organization"
+	"conformitea/infrastructure/persistence/persistencetest"
+	"conformitea/infrastructure/persistence/team"
+	persistenceUser "conformitea/infrastructure/persistence/user"
+	"conformitea/server/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Records the emails sent.
+type recordingMailer struct {
+	sent []mail.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, message mail.Message) error {
+	m.sent = append(m.sent, message)
+
+	return nil
+}
+
+// Returns a SCIM app backed by an empty database with an organization that
+// verified example.com, and the tenant of its directory.
+func newTestSCIM(t *testing.T) (*SCIM, types.SCIMTenant, *recordingMailer) {
+	t.Helper()
+
+	db := persistencetest.Open(t)
+
+	dc, err := domain.Initialize(&persistenceUser.UserRepository{}, &team.TeamRepository{}, &organization.OrganizationRepository{}, &mfa.MFARepository{}, &apitoken.APITokenRepository{}, &audit.AuditRepository{})
+	if err != nil {
+		t.Fatalf("failed to initialize domain: %v", err)
+	}
+
+	org := organization.Organization{Name: "Example"}
+	if err := db.Create(&org).Error; err != nil {
+		t.Fatalf("failed to create organization: %v", err)
+	}
+
+	verifiedAt := time.Now()
+	if err := db.Create(&organization.OrganizationDomain{
+		OrganizationID:    org.ID,
+		Domain:            "example.com",
+		VerificationToken: "token",
+		VerifiedAt:        &verifiedAt,
+	}).Error; err != nil {
+		t.Fatalf("failed to create domain: %v", err)
+	}
+
+	account, err := dc.GetAPITokenService().CreateServiceAccount(db, domainAPIToken.ServiceAccount{
+		OrganizationID: org.ID,
+		Name:           "Directory",
+		Role:           domainOrganization.RoleAdmin,
+		CreatedBy:      uuid.New(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create service account: %v", err)
+	}
+
+	mailer := &recordingMailer{}
+
+	s, err := Initialize(config.InvitationConfig{
+		Secret:    "0123456789abcdef0123456789abcdef",
+		TTL:       3600,
+		AcceptURL: "https://app.example.com/invitations/accept",
+	}, db, dc.GetUserService(), dc.GetTeamService(), dc.GetAPITokenService(), dc.GetOrganizationService(), nil, mailer)
+	if err != nil {
+		t.Fatalf("failed to initialize SCIM: %v", err)
+	}
+
+	return s, types.SCIMTenant{
+		Actor:          types.Actor{ID: account.ID.String(), Kind: types.PrincipalServiceAccount},
+		OrganizationID: org.ID.String(),
+	}, mailer
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name string
+		// Users signed in before the directory provisions them
+		existing []string
+		// Creates the user a second time
+		again          bool
+		userName       string
+		wantErr        error
+		wantUser       bool
+		wantOwned      bool
+		wantInvitation bool
+	}{
+		{
+			name:      "user at a verified domain",
+			userName:  "ada@example.com",
+			wantUser:  true,
+			wantOwned: true,
+		},
+		{
+			name:           "user at another domain",
+			userName:       "ada@gmail.com",
+			wantErr:        types.ErrSCIMUserInvited,
+			wantInvitation: true,
+		},
+		{
+			name:           "user at another domain created again",
+			userName:       "ada@gmail.com",
+			again:          true,
+			wantErr:        types.ErrSCIMUserInvited,
+			wantInvitation: true,
+		},
+		{
+			name:     "user at another domain who signed in before",
+			existing: []string{"ada@gmail.com"},
+			userName: "ada@gmail.com",
+			wantUser: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, tenant, mailer := newTestSCIM(t)
+			orgID := uuid.MustParse(tenant.OrganizationID)
+
+			for _, email := range tt.existing {
+				if _, err := s.userService.ProvisionUser(s.db, user.ExternalProfile{
+					Provider:      "oidc",
+					Subject:       email,
+					Email:         email,
+					EmailVerified: true,
+					FirstName:     "Signed in",
+				}); err != nil {
+					t.Fatalf("failed to provision user: %v", err)
+				}
+			}
+
+			u := types.SCIMUser{UserName: tt.userName, GivenName: "Directory", Active: true}
+
+			if tt.again {
+				if _, err := s.CreateUser(context.Background(), tenant, u); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateUser() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+
+			created, err := s.CreateUser(context.Background(), tenant, u)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateUser() error = %v, want %v", err, tt.wantErr)
+			}
+
+			account, err := s.userService.GetUserByEmail(s.db, tt.userName)
+			if exists := err == nil; exists != tt.wantUser {
+				t.Fatalf("user exists = %t, want %t", exists, tt.wantUser)
+			}
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Fatalf("failed to get user: %v", err)
+			}
+
+			if tt.wantUser {
+				if created.ID != account.ID.String() {
+					t.Errorf("CreateUser() ID = %q, want %s", created.ID, account.ID)
+				}
+
+				if owned := account.FirstName == "Directory"; owned != tt.wantOwned {
+					t.Errorf("profile set by the directory = %t, want %t", owned, tt.wantOwned)
+				}
+			}
+
+			invitations, err := s.organizationService.GetInvitations(s.db, orgID)
+			if err != nil {
+				t.Fatalf("failed to get invitations: %v", err)
+			}
+
+			if !tt.wantInvitation {
+				if len(invitations) != 0 || len(mailer.sent) != 0 {
+					t.Errorf("invitations = %d, emails = %d, want none", len(invitations), len(mailer.sent))
+				}
+				return
+			}
+
+			// Creating the user again does not invite them again
+			if len(invitations) != 1 || len(mailer.sent) != 1 {
+				t.Fatalf("invitations = %d, emails = %d, want one", len(invitations), len(mailer.sent))
+			}
+
+			invitation := invitations[0]
+			if invitation.Email != tt.userName || invitation.Role != domainOrganization.RoleMember || invitation.AllowEmailMismatch || !invitation.Pending(time.Now()) {
+				t.Errorf("invitation = %+v, want a pending member invitation of %s", invitation, tt.userName)
+			}
+
+			if sent := mailer.sent[0]; sent.To != tt.userName || !strings.HasPrefix(sent.Body, "Directory invited you to join Example") {
+				t.Errorf("invitation sent to %q:\n%s\nwant it sent to %q by the directory", sent.To, sent.Body, tt.userName)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+	"slices"
+)
+
+// CLIConfig configures the commands that call the API as a signed in user.
+type CLIConfig struct {
+	// Hydra public URL the CLI signs in at. Defaults to hydra.public_url.
+	Issuer string `mapstructure:"issuer"`
+	// Public OAuth2 client of Hydra allowed to use the device authorization grant
+	ClientID string   `mapstructure:"client_id"`
+	Scopes   []string `mapstructure:"scopes"`
+}
+
+func (c *CLIConfig) Validate() error {
+	var errs []error
+	if c.Issuer == "" {
+		errs = append(errs, errors.New("cli.issuer is required"))
+	}
+
+	if c.ClientID == "" {
+		errs = append(errs, errors.New("cli.client_id is required"))
+	}
+
+	// Credentials must outlive the access token
+	if !slices.Contains(c.Scopes, "offline_access") {
+		errs = append(errs, errors.New("cli.scopes must include offline_access"))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	app "conformitea/app/config"
 	infrastructure "conformitea/infrastructure/config"
 	server "conformitea/server/config"
 )
@@ -10,8 +11,16 @@ type Config struct {
 	HTTPServerConfig server.HTTPServerConfig `mapstructure:"server"`
 	RedisConfig      server.RedisConfig      `mapstructure:"redis"`
 
+	AuthConfig       app.AuthConfig       `mapstructure:"auth"`
+	InvitationConfig app.InvitationConfig `mapstructure:"invitations"`
+
 	LoggerConfig   infrastructure.LoggerConfig   `mapstructure:"logger"`
 	DatabaseConfig infrastructure.DatabaseConfig `mapstructure:"database"`
 	HydraConfig    infrastructure.HydraConfig    `mapstructure:"hydra"`
 	OAuthConfig    infrastructure.OAuthConfig    `mapstructure:"oauth"`
+	SAMLConfig     infrastructure.SAMLConfig     `mapstructure:"saml"`
+	DNSConfig      infrastructure.DNSConfig      `mapstructure:"dns"`
+	MailConfig     infrastructure.MailConfig     `mapstructure:"mail"`
+
+	CLIConfig CLIConfig `mapstructure:"cli"`
 }
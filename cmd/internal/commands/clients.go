@@ -0,0 +1,291 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"conformitea/app/clients"
+	cmd "conformitea/cmd/config"
+	"conformitea/server/types"
+
+	"github.com/spf13/cobra"
+)
+
+// Commands act as the operator, who administers the installation with the server
+// configuration.
+var operator = types.Actor{Kind: types.PrincipalOperator}
+
+func ClientsCmd(config cmd.Config) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "clients",
+		Short: "Manage the OAuth2 clients registered at Hydra",
+	}
+
+	command.AddCommand(
+		clientsListCmd(config),
+		clientsGetCmd(config),
+		clientsCreateCmd(config),
+		clientsUpdateCmd(config),
+		clientsDeleteCmd(config),
+		clientsRotateSecretCmd(config),
+	)
+
+	return command
+}
+
+func clientsListCmd(config cmd.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the OAuth2 clients",
+		Args:  cobra.NoArgs,
+		RunE: func(command *cobra.Command, args []string) error {
+			app, err := initializeClientsCommand(config)
+			if err != nil {
+				return err
+			}
+
+			list, err := app.ListClients(command.Context(), operator)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(command.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tGRANT TYPES\tIDENTITY PROVIDER\tORGANIZATION")
+			for _, c := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.ID, c.Name, strings.Join(c.GrantTypes, ","), c.IdentityProvider, c.OrganizationID)
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+func clientsGetCmd(config cmd.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <client-id>",
+		Short: "Show an OAuth2 client",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(command *cobra.Command, args []string) error {
+			app, err := initializeClientsCommand(config)
+			if err != nil {
+				return err
+			}
+
+			client, err := app.GetClient(command.Context(), operator, args[0])
+			if err != nil {
+				return err
+			}
+
+			return printClient(command.OutOrStdout(), client, "")
+		},
+	}
+}
+
+func clientsCreateCmd(config cmd.Config) *cobra.Command {
+	var client types.OAuthClient
+
+	command := &cobra.Command{
+		Use:   "create",
+		Short: "Register an OAuth2 client",
+		Long:  "Register an OAuth2 client. The secret of confidential clients is only shown once.",
+		Args:  cobra.NoArgs,
+		RunE: func(command *cobra.Command, args []string) error {
+			app, err := initializeClientsCommand(config)
+			if err != nil {
+				return err
+			}
+
+			issued, err := app.CreateClient(command.Context(), types.CreateOAuthClientRequest{
+				Actor:  operator,
+				Client: client,
+			})
+			if err != nil {
+				return err
+			}
+
+			return printClient(command.OutOrStdout(), issued.Client, issued.Secret)
+		},
+	}
+
+	command.Flags().StringVar(&client.ID, "id", "", "Client ID, generated when empty")
+	command.Flags().StringVar(&client.TokenEndpointAuthMethod, "token-endpoint-auth-method", "", `"none" for public clients, defaults to client_secret_basic`)
+	clientFlags(command, &client)
+
+	_ = command.MarkFlagRequired("name")
+
+	return command
+}
+
+func clientsUpdateCmd(config cmd.Config) *cobra.Command {
+	var client types.OAuthClient
+
+	command := &cobra.Command{
+		Use:   "update <client-id>",
+		Short: "Change an OAuth2 client",
+		Long:  "Change the fields of an OAuth2 client given as flags. An empty value removes the identity provider or organization.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(command *cobra.Command, args []string) error {
+			app, err := initializeClientsCommand(config)
+			if err != nil {
+				return err
+			}
+
+			flags := command.Flags()
+			req := types.UpdateOAuthClientRequest{
+				Actor:    operator,
+				ClientID: args[0],
+			}
+
+			if flags.Changed("name") {
+				req.Name = &client.Name
+			}
+			if flags.Changed("redirect-uri") {
+				req.RedirectURIs = &client.RedirectURIs
+			}
+			if flags.Changed("post-logout-redirect-uri") {
+				req.PostLogoutRedirectURIs = &client.PostLogoutRedirectURIs
+			}
+			if flags.Changed("grant-type") {
+				req.GrantTypes = &client.GrantTypes
+			}
+			if flags.Changed("response-type") {
+				req.ResponseTypes = &client.ResponseTypes
+			}
+			if flags.Changed("scope") {
+				req.Scopes = &client.Scopes
+			}
+			if flags.Changed("audience") {
+				req.Audience = &client.Audience
+			}
+			if flags.Changed("identity-provider") {
+				req.IdentityProvider = &client.IdentityProvider
+			}
+			if flags.Changed("organization") {
+				req.OrganizationID = &client.OrganizationID
+			}
+			if flags.Changed("first-party") {
+				req.FirstParty = &client.FirstParty
+			}
+
+			updated, err := app.UpdateClient(command.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			return printClient(command.OutOrStdout(), updated, "")
+		},
+	}
+
+	clientFlags(command, &client)
+
+	return command
+}
+
+func clientsDeleteCmd(config cmd.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <client-id>",
+		Short: "Delete an OAuth2 client and the tokens issued to it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(command *cobra.Command, args []string) error {
+			app, err := initializeClientsCommand(config)
+			if err != nil {
+				return err
+			}
+
+			if err := app.DeleteClient(command.Context(), operator, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(command.OutOrStdout(), "Deleted client %s\n", args[0])
+
+			return nil
+		},
+	}
+}
+
+func clientsRotateSecretCmd(config cmd.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate-secret <client-id>",
+		Short: "Replace the secret of a confidential OAuth2 client",
+		Long:  "Replace the secret of a confidential OAuth2 client. The old secret stops working immediately and the new one is only shown once.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(command *cobra.Command, args []string) error {
+			app, err := initializeClientsCommand(config)
+			if err != nil {
+				return err
+			}
+
+			issued, err := app.RotateClientSecret(command.Context(), operator, args[0])
+			if err != nil {
+				return err
+			}
+
+			return printClient(command.OutOrStdout(), issued.Client, issued.Secret)
+		},
+	}
+}
+
+// Flags of the client fields that can be set on creation and changed later.
+func clientFlags(command *cobra.Command, client *types.OAuthClient) {
+	flags := command.Flags()
+	flags.StringVar(&client.Name, "name", "", "Client name")
+	flags.StringSliceVar(&client.RedirectURIs, "redirect-uri", nil, "Allowed redirect URIs")
+	flags.StringSliceVar(&client.PostLogoutRedirectURIs, "post-logout-redirect-uri", nil, "Allowed post logout redirect URIs")
+	flags.StringSliceVar(&client.GrantTypes, "grant-type", nil, "Allowed grant types, e.g. authorization_code,refresh_token")
+	flags.StringSliceVar(&client.ResponseTypes, "response-type", nil, "Allowed response types, e.g. code")
+	flags.StringSliceVar(&client.Scopes, "scope", nil, "Scopes the client may request")
+	flags.StringSliceVar(&client.Audience, "audience", nil, "Audiences the client may request")
+	flags.StringVar(&client.IdentityProvider, "identity-provider", "", `Identity provider users sign in with, e.g. "microsoft" or "saml:<organization ID>"`)
+	flags.StringVar(&client.OrganizationID, "organization", "", "ID of the organization the client belongs to")
+	flags.BoolVar(&client.FirstParty, "first-party", false, "Whether ConformiTea operates the client, so users are not asked to consent")
+}
+
+func initializeClientsCommand(c cmd.Config) (*clients.Clients, error) {
+	ic, err := initializeInfrastructure(c)
+	if err != nil {
+		return nil, err
+	}
+
+	dc, err := initializeDomain(ic.GetPersistence())
+	if err != nil {
+		return nil, err
+	}
+
+	return initializeClients(dc, ic)
+}
+
+// Prints the fields of a client, and its secret when it was just issued.
+func printClient(out io.Writer, client types.OAuthClient, secret string) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "ID:\t%s\n", client.ID)
+	fmt.Fprintf(w, "Name:\t%s\n", client.Name)
+	fmt.Fprintf(w, "Redirect URIs:\t%s\n", strings.Join(client.RedirectURIs, " "))
+	fmt.Fprintf(w, "Post logout redirect URIs:\t%s\n", strings.Join(client.PostLogoutRedirectURIs, " "))
+	fmt.Fprintf(w, "Grant types:\t%s\n", strings.Join(client.GrantTypes, " "))
+	fmt.Fprintf(w, "Response types:\t%s\n", strings.Join(client.ResponseTypes, " "))
+	fmt.Fprintf(w, "Scopes:\t%s\n", strings.Join(client.Scopes, " "))
+	fmt.Fprintf(w, "Audience:\t%s\n", strings.Join(client.Audience, " "))
+	fmt.Fprintf(w, "Token endpoint auth method:\t%s\n", client.TokenEndpointAuthMethod)
+	fmt.Fprintf(w, "Identity provider:\t%s\n", client.IdentityProvider)
+	fmt.Fprintf(w, "Organization:\t%s\n", client.OrganizationID)
+	fmt.Fprintf(w, "First party:\t%t\n", client.FirstParty)
+	fmt.Fprintf(w, "Created at:\t%s\n", client.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "Updated at:\t%s\n", client.UpdatedAt.Format(time.RFC3339))
+	if secret != "" {
+		fmt.Fprintf(w, "Secret:\t%s\n", secret)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if secret != "" {
+		fmt.Fprintln(out, "Store the secret now, it is not shown again.")
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+
+	cmd "conformitea/cmd/config"
+	"conformitea/cmd/internal/credentials"
+
+	"github.com/spf13/cobra"
+)
+
+func LoginCmd(config cmd.Config) *cobra.Command {
+	cli := config.CLIConfig
+	if cli.Issuer == "" {
+		cli.Issuer = config.HydraConfig.PublicURL
+	}
+	if len(cli.Scopes) == 0 {
+		cli.Scopes = []string{"openid", "offline_access"}
+	}
+
+	command := &cobra.Command{
+		Use:   "login",
+		Short: "Sign in to ConformiTea from this device",
+		Long: "Sign in with the OAuth2 device authorization grant: open the shown URL in a browser,\n" +
+			"enter the code and sign in. The credentials are stored in the user's config directory.",
+		RunE: func(command *cobra.Command, args []string) error {
+			if err := cli.Validate(); err != nil {
+				return fmt.Errorf("invalid cli configuration: %w", err)
+			}
+
+			ctx := command.Context()
+			oauthConfig := credentials.OAuthConfig(cli.Issuer, cli.ClientID, cli.Scopes)
+
+			device, err := oauthConfig.DeviceAuth(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to start device authorization: %w", err)
+			}
+
+			out := command.OutOrStdout()
+			fmt.Fprintf(out, "Open %s in a browser and enter the code %s\n", device.VerificationURI, device.UserCode)
+			if device.VerificationURIComplete != "" {
+				fmt.Fprintf(out, "or open %s\n", device.VerificationURIComplete)
+			}
+			fmt.Fprintln(out, "Waiting for the sign in to complete...")
+
+			token, err := oauthConfig.DeviceAccessToken(ctx, device)
+			if err != nil {
+				return fmt.Errorf("failed to sign in: %w", err)
+			}
+
+			if err := credentials.Save(credentials.Credentials{
+				Issuer:   cli.Issuer,
+				ClientID: cli.ClientID,
+				Token:    token,
+			}); err != nil {
+				return err
+			}
+
+			path, _ := credentials.Path()
+			fmt.Fprintf(out, "Signed in, credentials stored in %s\n", path)
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&cli.Issuer, "issuer", cli.Issuer, "Hydra public URL to sign in at")
+	command.Flags().StringVar(&cli.ClientID, "client-id", cli.ClientID, "OAuth2 client of the CLI")
+	command.Flags().StringSliceVar(&cli.Scopes, "scopes", cli.Scopes, "Scopes to request")
+
+	return command
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+
+	"conformitea/app/organization"
+	cmd "conformitea/cmd/config"
+	"conformitea/server/types"
+
+	"github.com/spf13/cobra"
+)
+
+func OrganizationsCmd(config cmd.Config) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "organizations",
+		Short: "Manage organizations",
+	}
+
+	command.AddCommand(organizationsLinkTenantCmd(config))
+
+	return command
+}
+
+func organizationsLinkTenantCmd(config cmd.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "link-tenant <organization-id> <tenant-id>",
+		Short: "Link an Entra tenant to an organization",
+		Long: "Link an Entra tenant to an organization, so the tenant's users become members of it when they sign in " +
+			"with Microsoft. Only link a tenant once an administrator of the tenant confirmed the organization uses it.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(command *cobra.Command, args []string) error {
+			ic, err := initializeInfrastructure(config)
+			if err != nil {
+				return err
+			}
+
+			dc, err := initializeDomain(ic.GetPersistence())
+			if err != nil {
+				return err
+			}
+
+			app, err := organization.Initialize(config.InvitationConfig, ic.GetDatabase(), dc.GetOrganizationService(), dc.GetUserService(), dc.GetAPITokenService(), ic.GetSAMLServiceProvider(), ic.GetDNSResolver(), ic.GetHydraClient(), ic.GetMailer())
+			if err != nil {
+				return err
+			}
+
+			result, err := app.LinkTenant(command.Context(), types.LinkTenantRequest{
+				Actor:          operator,
+				OrganizationID: args[0],
+				TenantID:       args[1],
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(command.OutOrStdout(), "Tenant %s is linked to %s (%s)\n", result.EntraTenantID, result.Name, result.ID)
+
+			return nil
+		},
+	}
+}
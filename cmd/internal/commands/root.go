@@ -17,6 +17,12 @@ func Execute(config config.Config) {
 
 	rootCmd.SilenceErrors = true
 	rootCmd.AddCommand(ServeCmd(config))
+	rootCmd.AddCommand(LoginCmd(config))
+	rootCmd.AddCommand(WhoamiCmd())
+	rootCmd.AddCommand(ClientsCmd(config))
+	rootCmd.AddCommand(StaffCmd(config))
+	rootCmd.AddCommand(UsersCmd(config))
+	rootCmd.AddCommand(OrganizationsCmd(config))
 
 	rootCmd.ErrOrStderr()
 
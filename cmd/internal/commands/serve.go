@@ -1,7 +1,12 @@
 package commands
 
 import (
+	"conformitea/app/accounts"
 	"conformitea/app/auth"
+	"conformitea/app/clients"
+	"conformitea/app/impersonation"
+	"conformitea/app/organization"
+	"conformitea/app/scim"
 	cmd "conformitea/cmd/config"
 	"conformitea/domain"
 	"conformitea/infrastructure"
@@ -43,7 +48,35 @@ func initializeServer(c cmd.Config) (types.Server, error) {
 		return nil, err
 	}
 
-	auth := initializeApp(dc, ic)
+	auth, err := initializeApp(c, dc, ic)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := organization.Initialize(c.InvitationConfig, ic.GetDatabase(), dc.GetOrganizationService(), dc.GetUserService(), dc.GetAPITokenService(), ic.GetSAMLServiceProvider(), ic.GetDNSResolver(), ic.GetHydraClient(), ic.GetMailer())
+	if err != nil {
+		return nil, err
+	}
+
+	provisioning, err := scim.Initialize(c.InvitationConfig, ic.GetDatabase(), dc.GetUserService(), dc.GetTeamService(), dc.GetAPITokenService(), dc.GetOrganizationService(), ic.GetHydraClient(), ic.GetMailer())
+	if err != nil {
+		return nil, err
+	}
+
+	oauthClients, err := initializeClients(dc, ic)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonations, err := impersonation.Initialize(ic.GetDatabase(), dc.GetUserService(), dc.GetAuditService())
+	if err != nil {
+		return nil, err
+	}
+
+	userAccounts, err := accounts.Initialize(ic.GetDatabase(), dc.GetUserService(), ic.GetHydraClient())
+	if err != nil {
+		return nil, err
+	}
 
 	sc := serverConfig.Config{
 		General:    c.GeneralConfig,
@@ -51,22 +84,34 @@ func initializeServer(c cmd.Config) (types.Server, error) {
 		Redis:      c.RedisConfig,
 	}
 
-	return server.Initialize(sc, ic.GetLogger(), auth)
+	return server.Initialize(sc, ic.GetLogger(), auth, org, provisioning, oauthClients, impersonations, userAccounts)
 }
 
-func initializeApp(dc *domain.Container, ic *infrastructure.Container) *auth.Auth {
-	auth := auth.Initialize(
+func initializeApp(c cmd.Config, dc *domain.Container, ic *infrastructure.Container) (*auth.Auth, error) {
+	auth, err := auth.Initialize(
+		c.AuthConfig,
 		ic.GetDatabase(),
 		dc.GetUserService(),
-		ic.GetMicrosoftClient(),
+		dc.GetOrganizationService(),
+		dc.GetMFAService(),
+		dc.GetAPITokenService(),
+		ic.GetIdentityProviders(),
 		ic.GetHydraClient(),
+		ic.GetSAMLServiceProvider(),
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
 
-	return auth
+func initializeClients(dc *domain.Container, ic *infrastructure.Container) (*clients.Clients, error) {
+	return clients.Initialize(ic.GetDatabase(), dc.GetUserService(), dc.GetOrganizationService(), ic.GetIdentityProviders(), ic.GetHydraClient())
 }
 
 func initializeDomain(p infrastructure.Persistence) (*domain.Container, error) {
-	container, err := domain.Initialize(p.GetUserRepository(), p.GetTeamRepository(), p.GetOrganizationRepository())
+	container, err := domain.Initialize(p.GetUserRepository(), p.GetTeamRepository(), p.GetOrganizationRepository(), p.GetMFARepository(), p.GetAPITokenRepository(), p.GetAuditRepository())
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +120,7 @@ func initializeDomain(p infrastructure.Persistence) (*domain.Container, error) {
 }
 
 func initializeInfrastructure(c cmd.Config) (*infrastructure.Container, error) {
-	container, err := infrastructure.Initialize(c.LoggerConfig, c.DatabaseConfig, c.HydraConfig, c.OAuthConfig)
+	container, err := infrastructure.Initialize(c.LoggerConfig, c.DatabaseConfig, c.HydraConfig, c.OAuthConfig, c.SAMLConfig, c.DNSConfig, c.MailConfig)
 	if err != nil {
 		return nil, err
 	}
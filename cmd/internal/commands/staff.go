@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+
+	cmd "conformitea/cmd/config"
+
+	"github.com/spf13/cobra"
+)
+
+func StaffCmd(config cmd.Config) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "staff",
+		Short: "Manage the users who administer the installation",
+		Long:  "Staff users can use the admin API, e.g. to manage OAuth2 clients, with a token granted the admin scope.",
+	}
+
+	command.AddCommand(
+		staffSetCmd(config, "grant", "Grant staff rights to a user", true),
+		staffSetCmd(config, "revoke", "Revoke the staff rights of a user", false),
+	)
+
+	return command
+}
+
+func staffSetCmd(config cmd.Config, use, short string, staff bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " <email>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(command *cobra.Command, args []string) error {
+			ic, err := initializeInfrastructure(config)
+			if err != nil {
+				return err
+			}
+
+			dc, err := initializeDomain(ic.GetPersistence())
+			if err != nil {
+				return err
+			}
+
+			userService := dc.GetUserService()
+
+			u, err := userService.GetUserByEmail(ic.GetDatabase(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get user %s: %w", args[0], err)
+			}
+
+			if _, err := userService.SetStaff(ic.GetDatabase(), u.ID, staff); err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+
+			if staff {
+				fmt.Fprintf(command.OutOrStdout(), "%s is staff\n", u.Email)
+			} else {
+				fmt.Fprintf(command.OutOrStdout(), "%s is no longer staff\n", u.Email)
+			}
+
+			return nil
+		},
+	}
+}
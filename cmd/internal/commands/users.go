@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"conformitea/app/accounts"
+	cmd "conformitea/cmd/config"
+	"conformitea/server/types"
+
+	"github.com/spf13/cobra"
+)
+
+func UsersCmd(config cmd.Config) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "users",
+		Short: "Manage user accounts",
+	}
+
+	command.AddCommand(usersMergeCmd(config))
+
+	return command
+}
+
+func usersMergeCmd(config cmd.Config) *cobra.Command {
+	var reason string
+	var dryRun bool
+
+	command := &cobra.Command{
+		Use:   "merge <source> <target>",
+		Short: "Merge a duplicate user into another user",
+		Long: "Merge a duplicate user into the user the same person keeps signing in as. Users are given by ID or email. " +
+			"The source's identities, organization memberships and team memberships move to the target, " +
+			"and the source is deactivated and signed out but kept, so their history stays intact.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(command *cobra.Command, args []string) error {
+			ic, err := initializeInfrastructure(config)
+			if err != nil {
+				return err
+			}
+
+			dc, err := initializeDomain(ic.GetPersistence())
+			if err != nil {
+				return err
+			}
+
+			app, err := accounts.Initialize(ic.GetDatabase(), dc.GetUserService(), ic.GetHydraClient())
+			if err != nil {
+				return err
+			}
+
+			merge, err := app.MergeUsers(command.Context(), types.MergeUsersRequest{
+				Actor:  operator,
+				Source: args[0],
+				Target: args[1],
+				Reason: reason,
+				DryRun: dryRun,
+			})
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(command.OutOrStdout(), 0, 0, 2, ' ', 0)
+			if merge.DryRun {
+				fmt.Fprintln(w, "Dry run, nothing was merged")
+			} else {
+				fmt.Fprintf(w, "Merge:\t%s\n", merge.ID)
+			}
+			fmt.Fprintf(w, "Source:\t%s (%s)\n", merge.SourceEmail, merge.SourceUserID)
+			fmt.Fprintf(w, "Target:\t%s (%s)\n", merge.TargetEmail, merge.TargetUserID)
+			fmt.Fprintf(w, "Identities:\t%d\n", merge.Identities)
+			fmt.Fprintf(w, "Organization memberships:\t%d\n", merge.Memberships)
+			fmt.Fprintf(w, "Team memberships:\t%d\n", merge.Teams)
+
+			return w.Flush()
+		},
+	}
+
+	command.Flags().StringVar(&reason, "reason", "", "Why the users are merged, kept with the merge")
+	command.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would move without merging")
+
+	_ = command.MarkFlagRequired("reason")
+
+	return command
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"conformitea/cmd/internal/credentials"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+)
+
+// Claims of the signed in user returned by the OpenID Connect userinfo endpoint.
+type userInfo struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+}
+
+func WhoamiCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the user signed in with conformitea login",
+		Long: "Show the user the stored credentials were issued to. The access token is refreshed\n" +
+			"when it expired, so this also checks the credentials are still valid.",
+		Args: cobra.NoArgs,
+		RunE: func(command *cobra.Command, args []string) error {
+			c, err := credentials.Load()
+			if err != nil {
+				return err
+			}
+
+			ctx := command.Context()
+			client := oauth2.NewClient(ctx, c.TokenSource(ctx))
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.Issuer, "/")+"/userinfo", nil)
+			if err != nil {
+				return fmt.Errorf("failed to create userinfo request: %w", err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to get userinfo: %w", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				return fmt.Errorf("userinfo returned status %d: %s", resp.StatusCode, string(body))
+			}
+
+			var info userInfo
+			if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+				return fmt.Errorf("failed to decode userinfo: %w", err)
+			}
+
+			out := command.OutOrStdout()
+			fmt.Fprintf(out, "Signed in as %s\n", info.Subject)
+			if info.Name != "" {
+				fmt.Fprintf(out, "Name:  %s\n", info.Name)
+			}
+			if info.Email != "" {
+				fmt.Fprintf(out, "Email: %s\n", info.Email)
+			}
+
+			return nil
+		},
+	}
+}
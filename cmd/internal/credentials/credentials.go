@@ -0,0 +1,150 @@
+// Package credentials stores the tokens the CLI signed in with in the user's
+// config directory.
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrNotSignedIn is returned when no credentials are stored.
+var ErrNotSignedIn = errors.New("not signed in, run conformitea login")
+
+// Credentials are the tokens Hydra issued to the CLI and what they are refreshed with.
+type Credentials struct {
+	Issuer   string        `json:"issuer"`
+	ClientID string        `json:"client_id"`
+	Token    *oauth2.Token `json:"token"`
+}
+
+// Returns the path credentials are stored at.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "conformitea", "credentials.json"), nil
+}
+
+// Returns the stored credentials.
+func Load() (Credentials, error) {
+	path, err := Path()
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Credentials{}, ErrNotSignedIn
+	}
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	var c Credentials
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Credentials{}, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+
+	if c.Token == nil {
+		return Credentials{}, ErrNotSignedIn
+	}
+
+	return c, nil
+}
+
+// Stores the credentials, readable only by the user.
+func Save(c Credentials) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	// Written next to the credentials and renamed, so a failed write keeps the old ones
+	tmp, err := os.CreateTemp(filepath.Dir(path), "credentials-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the OAuth2 configuration of the CLI's client at Hydra.
+func OAuthConfig(issuer, clientID string, scopes []string) oauth2.Config {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	return oauth2.Config{
+		ClientID: clientID,
+		Scopes:   scopes,
+		Endpoint: oauth2.Endpoint{
+			DeviceAuthURL: issuer + "/oauth2/device/auth",
+			TokenURL:      issuer + "/oauth2/token",
+			// The CLI is a public client without a secret
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// Returns a token source for API calls that refreshes the access token when it
+// expires. Hydra rotates refresh tokens, so refreshed tokens are stored.
+func (c Credentials) TokenSource(ctx context.Context) oauth2.TokenSource {
+	config := OAuthConfig(c.Issuer, c.ClientID, nil)
+
+	return oauth2.ReuseTokenSource(c.Token, &storingTokenSource{
+		source:      config.TokenSource(ctx, c.Token),
+		credentials: c,
+	})
+}
+
+// Stores every token the wrapped source returns.
+type storingTokenSource struct {
+	source      oauth2.TokenSource
+	credentials Credentials
+}
+
+func (s *storingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if token.AccessToken != s.credentials.Token.AccessToken {
+		s.credentials.Token = token
+		if err := Save(s.credentials); err != nil {
+			return nil, err
+		}
+	}
+
+	return token, nil
+}
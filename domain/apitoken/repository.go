@@ -0,0 +1,23 @@
+package apitoken
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APITokenRepository interface {
+	CreateToken(DB *gorm.DB, token Token) (Token, error)
+	GetTokenByHash(DB *gorm.DB, secretHash string) (Token, error)
+	GetUserTokens(DB *gorm.DB, userID uuid.UUID) ([]Token, error)
+	GetServiceAccountTokens(DB *gorm.DB, serviceAccountID uuid.UUID) ([]Token, error)
+	RevokeUserToken(DB *gorm.DB, userID, id uuid.UUID) (bool, error)
+	RevokeServiceAccountToken(DB *gorm.DB, serviceAccountID, id uuid.UUID) (bool, error)
+	TouchToken(DB *gorm.DB, id uuid.UUID, usedBefore time.Time) error
+
+	CreateServiceAccount(DB *gorm.DB, account ServiceAccount) (ServiceAccount, error)
+	GetServiceAccount(DB *gorm.DB, id uuid.UUID) (ServiceAccount, error)
+	GetOrganizationServiceAccounts(DB *gorm.DB, organizationID uuid.UUID) ([]ServiceAccount, error)
+	DeleteServiceAccount(DB *gorm.DB, id uuid.UUID) error
+}
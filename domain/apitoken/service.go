@@ -0,0 +1,87 @@
+package apitoken
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Last use of a token is recorded at most this often.
+const lastUsedGranularity = time.Minute
+
+type APITokenService struct {
+	repository APITokenRepository
+}
+
+func Initialize(r APITokenRepository) *APITokenService {
+	return &APITokenService{
+		repository: r,
+	}
+}
+
+// Issues a token with a new secret starting with prefix. The secret is returned
+// once and only its hash is stored.
+func (s *APITokenService) IssueToken(DB *gorm.DB, token Token, prefix string) (Token, string, error) {
+	secret, hash, displayPrefix, err := GenerateSecret(prefix)
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	token.SecretHash = hash
+	token.DisplayPrefix = displayPrefix
+
+	token, err = s.repository.CreateToken(DB, token)
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	return token, secret, nil
+}
+
+func (s *APITokenService) GetTokenByHash(DB *gorm.DB, secretHash string) (Token, error) {
+	return s.repository.GetTokenByHash(DB, secretHash)
+}
+
+func (s *APITokenService) GetUserTokens(DB *gorm.DB, userID uuid.UUID) ([]Token, error) {
+	return s.repository.GetUserTokens(DB, userID)
+}
+
+func (s *APITokenService) GetServiceAccountTokens(DB *gorm.DB, serviceAccountID uuid.UUID) ([]Token, error) {
+	return s.repository.GetServiceAccountTokens(DB, serviceAccountID)
+}
+
+// Revokes a personal access token of the user. Reports false when the user has
+// no such active token.
+func (s *APITokenService) RevokeUserToken(DB *gorm.DB, userID, id uuid.UUID) (bool, error) {
+	return s.repository.RevokeUserToken(DB, userID, id)
+}
+
+// Revokes a token of the service account. Reports false when it has no such
+// active token.
+func (s *APITokenService) RevokeServiceAccountToken(DB *gorm.DB, serviceAccountID, id uuid.UUID) (bool, error) {
+	return s.repository.RevokeServiceAccountToken(DB, serviceAccountID, id)
+}
+
+// Records that the token was used now. Uses within a minute of the last recorded
+// one are not written.
+func (s *APITokenService) RecordTokenUse(DB *gorm.DB, id uuid.UUID) error {
+	return s.repository.TouchToken(DB, id, time.Now().Add(-lastUsedGranularity))
+}
+
+func (s *APITokenService) CreateServiceAccount(DB *gorm.DB, account ServiceAccount) (ServiceAccount, error) {
+	return s.repository.CreateServiceAccount(DB, account)
+}
+
+func (s *APITokenService) GetServiceAccount(DB *gorm.DB, id uuid.UUID) (ServiceAccount, error) {
+	return s.repository.GetServiceAccount(DB, id)
+}
+
+func (s *APITokenService) GetOrganizationServiceAccounts(DB *gorm.DB, organizationID uuid.UUID) ([]ServiceAccount, error) {
+	return s.repository.GetOrganizationServiceAccounts(DB, organizationID)
+}
+
+// Removes the service account and its tokens.
+func (s *APITokenService) DeleteServiceAccount(DB *gorm.DB, id uuid.UUID) error {
+	return s.repository.DeleteServiceAccount(DB, id)
+}
@@ -0,0 +1,65 @@
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lifetime of tokens created without one, and the longest lifetime allowed.
+const (
+	DefaultLifetime = 90 * 24 * time.Hour
+	MaxLifetime     = 365 * 24 * time.Hour
+)
+
+// Characters of the secret kept after the prefix to tell tokens apart.
+const displayLength = 8
+
+// Token is an API token of a user, a personal access token, or of a service
+// account. Only the hash of its secret is stored.
+type Token struct {
+	ID uuid.UUID `json:"id"`
+	// Exactly one of UserID and ServiceAccountID is set
+	UserID           *uuid.UUID `json:"user_id,omitempty"`
+	ServiceAccountID *uuid.UUID `json:"service_account_id,omitempty"`
+	Name             string     `json:"name"`
+	// Start of the secret, shown so users can recognize the token
+	DisplayPrefix string     `json:"display_prefix"`
+	SecretHash    string     `json:"-"`
+	Scopes        []string   `json:"scopes"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Reports whether the token can be used to authenticate at now.
+func (t Token) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// Generates a token secret with the given prefix and returns it with its hash and
+// display prefix.
+func GenerateSecret(prefix string) (secret, hash, displayPrefix string, err error) {
+	b := make([]byte, 30)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate token secret: %w", err)
+	}
+
+	secret = prefix + strings.ToLower(base32.StdEncoding.EncodeToString(b))
+
+	return secret, HashSecret(secret), secret[:len(prefix)+displayLength], nil
+}
+
+// Hashes a token secret. Secrets are random enough for a plain SHA-256.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions audit events record.
+const (
+	ActionImpersonationStarted = "impersonation.started"
+	ActionImpersonationEnded   = "impersonation.ended"
+	ActionImpersonationExpired = "impersonation.expired"
+	// A request made while impersonating, including the ones that were blocked
+	ActionImpersonatedRequest = "impersonation.request"
+)
+
+// Event is an entry of the audit trail of what staff did on behalf of users.
+type Event struct {
+	ID     uuid.UUID `json:"id"`
+	Action string    `json:"action"`
+	// Staff user who acted
+	ActorID uuid.UUID `json:"actor_id"`
+	// User the actor acted as
+	SubjectID uuid.UUID `json:"subject_id"`
+	// Impersonation the event belongs to
+	ImpersonationID *uuid.UUID `json:"impersonation_id,omitempty"`
+	Reason          string     `json:"reason"`
+	// Request the event was recorded for, empty for events of no request
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	RequestID string    `json:"request_id"`
+	IPAddress string    `json:"ip_address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EventQuery filters the audit trail. Zero fields do not filter.
+type EventQuery struct {
+	ActorID         uuid.UUID
+	SubjectID       uuid.UUID
+	ImpersonationID uuid.UUID
+	// Most recent events returned
+	Limit int
+}
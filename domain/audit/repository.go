@@ -0,0 +1,10 @@
+package audit
+
+import (
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	CreateEvent(DB *gorm.DB, event Event) (Event, error)
+	GetEvents(DB *gorm.DB, query EventQuery) ([]Event, error)
+}
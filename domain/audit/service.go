@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"gorm.io/gorm"
+)
+
+// Events returned by a query without a limit, and the most a query can return.
+const (
+	DefaultEventLimit = 100
+	MaxEventLimit     = 1000
+)
+
+type AuditService struct {
+	repository AuditRepository
+}
+
+func Initialize(r AuditRepository) *AuditService {
+	return &AuditService{
+		repository: r,
+	}
+}
+
+func (s *AuditService) RecordEvent(DB *gorm.DB, event Event) (Event, error) {
+	return s.repository.CreateEvent(DB, event)
+}
+
+// Returns the most recent events matching the query, most recent first.
+func (s *AuditService) GetEvents(DB *gorm.DB, query EventQuery) ([]Event, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultEventLimit
+	}
+	query.Limit = min(query.Limit, MaxEventLimit)
+
+	return s.repository.GetEvents(DB, query)
+}
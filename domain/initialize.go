@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"conformitea/domain/apitoken"
+	"conformitea/domain/audit"
+	"conformitea/domain/mfa"
 	"conformitea/domain/organization"
 	"conformitea/domain/team"
 	"conformitea/domain/user"
@@ -10,17 +13,26 @@ type Container struct {
 	user         *user.UserService
 	team         *team.TeamService
 	organization *organization.OrganizationService
+	mfa          *mfa.MFAService
+	apiToken     *apitoken.APITokenService
+	audit        *audit.AuditService
 }
 
-func Initialize(ur user.UserRepository, tr team.TeamRepository, or organization.OrganizationRepository) (*Container, error) {
+func Initialize(ur user.UserRepository, tr team.TeamRepository, or organization.OrganizationRepository, mr mfa.MFARepository, ar apitoken.APITokenRepository, aur audit.AuditRepository) (*Container, error) {
 	us := user.Initialize(ur)
 	ts := team.Initialize(tr)
 	os := organization.Initialize(or)
+	ms := mfa.Initialize(mr)
+	as := apitoken.Initialize(ar)
+	aus := audit.Initialize(aur)
 
 	return &Container{
 		user:         us,
 		team:         ts,
 		organization: os,
+		mfa:          ms,
+		apiToken:     as,
+		audit:        aus,
 	}, nil
 }
 
@@ -35,3 +47,15 @@ func (c *Container) GetTeamService() *team.TeamService {
 func (c *Container) GetOrganizationService() *organization.OrganizationService {
 	return c.organization
 }
+
+func (c *Container) GetMFAService() *mfa.MFAService {
+	return c.mfa
+}
+
+func (c *Container) GetAPITokenService() *apitoken.APITokenService {
+	return c.apiToken
+}
+
+func (c *Container) GetAuditService() *audit.AuditService {
+	return c.audit
+}
@@ -0,0 +1,57 @@
+package mfa
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TOTPFactor is a user's time-based one-time password authenticator.
+type TOTPFactor struct {
+	UserID uuid.UUID `json:"user_id"`
+	Secret string    `json:"-"`
+	// Set once the user proved the authenticator works. Unconfirmed factors are
+	// not used to sign in.
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	// Last time step a code was accepted for, so codes cannot be replayed
+	LastUsedStep int64     `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Reports whether the factor can be used to sign in.
+func (f TOTPFactor) Confirmed() bool {
+	return f.ConfirmedAt != nil
+}
+
+// RecoveryCode is a single-use code that replaces the second factor when the
+// authenticator is lost. Only its hash is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Passkey is a WebAuthn credential of a user. It is used as a second factor or
+// to sign in without an identity provider.
+type Passkey struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	// Name the user gave the passkey to tell their passkeys apart
+	Name            string   `json:"name"`
+	CredentialID    []byte   `json:"-"`
+	PublicKey       []byte   `json:"-"`
+	AttestationType string   `json:"-"`
+	AAGUID          []byte   `json:"-"`
+	Transports      []string `json:"transports"`
+	// Signature counter reported by the authenticator, used to detect cloned
+	// authenticators
+	SignCount uint32 `json:"-"`
+	// Whether the passkey may be synced to other devices, and whether it is
+	BackupEligible bool       `json:"backup_eligible"`
+	BackupState    bool       `json:"backup_state"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package mfa
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MFARepository interface {
+	GetTOTPFactor(DB *gorm.DB, userID uuid.UUID) (TOTPFactor, error)
+	SaveTOTPFactor(DB *gorm.DB, factor TOTPFactor) (TOTPFactor, error)
+	DeleteTOTPFactor(DB *gorm.DB, userID uuid.UUID) error
+	UseTOTPStep(DB *gorm.DB, userID uuid.UUID, step int64) (bool, error)
+
+	ReplaceRecoveryCodes(DB *gorm.DB, userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(DB *gorm.DB, userID uuid.UUID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(DB *gorm.DB, userID uuid.UUID) (int, error)
+
+	GetPasskeys(DB *gorm.DB, userID uuid.UUID) ([]Passkey, error)
+	CreatePasskey(DB *gorm.DB, passkey Passkey) (Passkey, error)
+	UpdatePasskeyUse(DB *gorm.DB, id uuid.UUID, signCount uint32, backupState bool) error
+	DeletePasskey(DB *gorm.DB, userID, id uuid.UUID) (bool, error)
+	CountPasskeys(DB *gorm.DB, userID uuid.UUID) (int, error)
+}
@@ -0,0 +1,144 @@
+package mfa
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MFAService struct {
+	repository MFARepository
+}
+
+func Initialize(r MFARepository) *MFAService {
+	return &MFAService{
+		repository: r,
+	}
+}
+
+func (s *MFAService) GetTOTPFactor(DB *gorm.DB, userID uuid.UUID) (TOTPFactor, error) {
+	return s.repository.GetTOTPFactor(DB, userID)
+}
+
+// Stores a new, unconfirmed authenticator for the user, replacing an earlier
+// unconfirmed one.
+func (s *MFAService) EnrollTOTPFactor(DB *gorm.DB, userID uuid.UUID, secret string) (TOTPFactor, error) {
+	return s.repository.SaveTOTPFactor(DB, TOTPFactor{
+		UserID: userID,
+		Secret: secret,
+	})
+}
+
+// Confirms the user's authenticator with the time step of the code that proved it
+// works and replaces their recovery codes.
+func (s *MFAService) ConfirmTOTPFactor(DB *gorm.DB, factor TOTPFactor, step int64, recoveryCodeHashes []string) (TOTPFactor, error) {
+	now := time.Now()
+	factor.ConfirmedAt = &now
+	factor.LastUsedStep = step
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if factor, err = s.repository.SaveTOTPFactor(tx, factor); err != nil {
+			return err
+		}
+
+		return s.repository.ReplaceRecoveryCodes(tx, factor.UserID, recoveryCodeHashes)
+	})
+	if err != nil {
+		return TOTPFactor{}, err
+	}
+
+	return factor, nil
+}
+
+// Removes the user's authenticator. Their recovery codes are removed as well
+// unless they still have a passkey.
+func (s *MFAService) DisableTOTPFactor(DB *gorm.DB, userID uuid.UUID) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.repository.DeleteTOTPFactor(tx, userID); err != nil {
+			return err
+		}
+
+		return s.dropUnusableRecoveryCodes(tx, userID)
+	})
+}
+
+// Records that a code for step was accepted. Reports false when a code for the
+// same or a later step was accepted before, i.e. the code is replayed.
+func (s *MFAService) UseTOTPStep(DB *gorm.DB, userID uuid.UUID, step int64) (bool, error) {
+	return s.repository.UseTOTPStep(DB, userID, step)
+}
+
+func (s *MFAService) ReplaceRecoveryCodes(DB *gorm.DB, userID uuid.UUID, codeHashes []string) error {
+	return s.repository.ReplaceRecoveryCodes(DB, userID, codeHashes)
+}
+
+// Marks the unused recovery code with the given hash as used. Reports false when
+// the user has no such unused code.
+func (s *MFAService) UseRecoveryCode(DB *gorm.DB, userID uuid.UUID, codeHash string) (bool, error) {
+	return s.repository.UseRecoveryCode(DB, userID, codeHash)
+}
+
+func (s *MFAService) CountUnusedRecoveryCodes(DB *gorm.DB, userID uuid.UUID) (int, error) {
+	return s.repository.CountUnusedRecoveryCodes(DB, userID)
+}
+
+func (s *MFAService) GetPasskeys(DB *gorm.DB, userID uuid.UUID) ([]Passkey, error) {
+	return s.repository.GetPasskeys(DB, userID)
+}
+
+func (s *MFAService) AddPasskey(DB *gorm.DB, passkey Passkey) (Passkey, error) {
+	return s.repository.CreatePasskey(DB, passkey)
+}
+
+// Records that the passkey was used to sign in with the signature counter and
+// backup state its authenticator reported.
+func (s *MFAService) RecordPasskeyUse(DB *gorm.DB, id uuid.UUID, signCount uint32, backupState bool) error {
+	return s.repository.UpdatePasskeyUse(DB, id, signCount, backupState)
+}
+
+// Removes a passkey of the user. When it was their last second factor, their
+// recovery codes are removed as well. Reports false when the user has no such
+// passkey.
+func (s *MFAService) RemovePasskey(DB *gorm.DB, userID, id uuid.UUID) (bool, error) {
+	var deleted bool
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if deleted, err = s.repository.DeletePasskey(tx, userID, id); err != nil || !deleted {
+			return err
+		}
+
+		factor, err := s.repository.GetTOTPFactor(tx, userID)
+		switch {
+		case err == nil && factor.Confirmed():
+			return nil
+		case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		return s.dropUnusableRecoveryCodes(tx, userID)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return deleted, nil
+}
+
+func (s *MFAService) CountPasskeys(DB *gorm.DB, userID uuid.UUID) (int, error) {
+	return s.repository.CountPasskeys(DB, userID)
+}
+
+// Removes the user's recovery codes when they have no passkey left. Callers
+// check that the user has no authenticator either.
+func (s *MFAService) dropUnusableRecoveryCodes(DB *gorm.DB, userID uuid.UUID) error {
+	count, err := s.repository.CountPasskeys(DB, userID)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	return s.repository.ReplaceRecoveryCodes(DB, userID, nil)
+}
@@ -0,0 +1,41 @@
+package organization
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Domain is an email domain claimed by an organization. Once the organization
+// proves it owns the domain, users with an address at it are offered to join the
+// organization or added to it when they sign in.
+type Domain struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// Lowercase domain name, e.g. example.com
+	Name string `json:"name"`
+	// Value the organization publishes in a TXT record to prove ownership
+	VerificationToken string     `json:"verification_token"`
+	VerifiedAt        *time.Time `json:"verified_at"`
+	// Users are added as members instead of being offered to join
+	AutoJoin  bool      `json:"auto_join"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// A domain can be verified by a single organization.
+func (d Domain) Verified() bool {
+	return d.VerifiedAt != nil
+}
+
+// Returns the lowercase domain of an email address, or an empty string when it
+// has none.
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(email[at+1:]), "."))
+}
@@ -0,0 +1,33 @@
+package organization
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation invites someone by email to join an organization with a role.
+type Invitation struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Email          string    `json:"email"`
+	Role           string    `json:"role"`
+	// The invitee may accept with an account whose email differs from Email
+	AllowEmailMismatch bool `json:"allow_email_mismatch"`
+	// Random value the invitation link is bound to. Resending the invitation
+	// replaces it, so earlier links stop working.
+	TokenNonce string    `json:"-"`
+	InvitedBy  uuid.UUID `json:"invited_by"`
+	// The invitation cannot be accepted after this time
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentAt     time.Time  `json:"sent_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	AcceptedBy *uuid.UUID `json:"accepted_by,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Reports whether the invitation can still be accepted.
+func (i Invitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
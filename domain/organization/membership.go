@@ -0,0 +1,25 @@
+package organization
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Membership is a user's membership of an organization.
+type Membership struct {
+	UserID         uuid.UUID `json:"user_id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Role           string    `json:"role"`
+	// ID of the member in the organization's directory, set by SCIM provisioning
+	ExternalID string `json:"external_id,omitempty"`
+	// Set when the organization's directory deactivated the member, who keeps
+	// the membership but no longer has access to the organization
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Reports whether the membership grants access to the organization.
+func (m Membership) Active() bool {
+	return m.DeactivatedAt == nil
+}
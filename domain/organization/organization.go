@@ -7,8 +7,16 @@ import (
 )
 
 type Organization struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Members must sign in with a second factor
+	RequireMFA bool `json:"require_mfa"`
+	// Users at the organization's verified domains must sign in with its SAML
+	// identity provider
+	RequireSSO bool `json:"require_sso"`
+	// Entra tenant whose users are members of the organization, empty when none
+	// is linked
+	EntraTenantID string    `json:"entra_tenant_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
@@ -1,10 +1,40 @@
 package organization
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type OrganizationRepository interface {
 	GetOrganizationByID(DB *gorm.DB, id uuid.UUID) (Organization, error)
+	GetOrganizationByTenantID(DB *gorm.DB, tenantID string) (Organization, error)
+	UpdateOrganization(DB *gorm.DB, organization Organization) (Organization, error)
+	RequiresMFA(DB *gorm.DB, userID uuid.UUID) (bool, error)
+
+	GetSAMLConnection(DB *gorm.DB, organizationID uuid.UUID) (SAMLConnection, error)
+	SaveSAMLConnection(DB *gorm.DB, connection SAMLConnection) (SAMLConnection, error)
+	DeleteSAMLConnection(DB *gorm.DB, organizationID uuid.UUID) error
+
+	GetDomains(DB *gorm.DB, organizationID uuid.UUID) ([]Domain, error)
+	GetDomain(DB *gorm.DB, organizationID, id uuid.UUID) (Domain, error)
+	GetVerifiedDomain(DB *gorm.DB, name string) (Domain, error)
+	CreateDomain(DB *gorm.DB, domain Domain) (Domain, error)
+	UpdateDomain(DB *gorm.DB, domain Domain) (Domain, error)
+	DeleteDomain(DB *gorm.DB, organizationID, id uuid.UUID) error
+
+	GetRoles(DB *gorm.DB, organizationID uuid.UUID) ([]Role, error)
+	GetRole(DB *gorm.DB, organizationID, id uuid.UUID) (Role, error)
+	GetRoleByName(DB *gorm.DB, organizationID uuid.UUID, name string) (Role, error)
+	CreateRole(DB *gorm.DB, role Role) (Role, error)
+	UpdateRole(DB *gorm.DB, role Role) (Role, error)
+	DeleteRole(DB *gorm.DB, organizationID, id uuid.UUID) error
+
+	GetInvitations(DB *gorm.DB, organizationID uuid.UUID) ([]Invitation, error)
+	GetInvitation(DB *gorm.DB, organizationID, id uuid.UUID) (Invitation, error)
+	GetInvitationByID(DB *gorm.DB, id uuid.UUID) (Invitation, error)
+	CreateInvitation(DB *gorm.DB, invitation Invitation) (Invitation, error)
+	UpdateInvitation(DB *gorm.DB, invitation Invitation) (Invitation, error)
+	AcceptInvitation(DB *gorm.DB, id, userID uuid.UUID, at time.Time) (bool, error)
 }
@@ -0,0 +1,126 @@
+package organization
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Built-in roles a member can hold in every organization.
+const (
+	RoleOwner    = "owner"
+	RoleAdmin    = "admin"
+	RoleMember   = "member"
+	RoleAuditor  = "auditor"
+	RoleReadOnly = "read_only"
+)
+
+// Permissions roles are composed of.
+const (
+	PermissionOrganizationRead      = "organization:read"
+	PermissionOrganizationManage    = "organization:manage"
+	PermissionMembersRead           = "members:read"
+	PermissionMembersManage         = "members:manage"
+	PermissionServiceAccountsRead   = "service_accounts:read"
+	PermissionServiceAccountsManage = "service_accounts:manage"
+	PermissionRolesManage           = "roles:manage"
+)
+
+// Permission is an entry of the permission catalog.
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Every permission a role can grant, in the order they are listed.
+var permissionCatalog = []Permission{
+	{PermissionOrganizationRead, "View the organization, its SSO connection and its domains"},
+	{PermissionOrganizationManage, "Change the organization's settings, SSO connection and domains"},
+	{PermissionMembersRead, "View the organization's members and roles"},
+	{PermissionMembersManage, "Change the roles of members and sign them out"},
+	{PermissionServiceAccountsRead, "View the organization's service accounts and their tokens"},
+	{PermissionServiceAccountsManage, "Create and delete service accounts and issue their tokens"},
+	{PermissionRolesManage, "Create, change and delete the organization's custom roles"},
+}
+
+// Permissions granted by each built-in role.
+var rolePermissions = map[string][]string{
+	RoleOwner: {
+		PermissionOrganizationRead,
+		PermissionOrganizationManage,
+		PermissionMembersRead,
+		PermissionMembersManage,
+		PermissionServiceAccountsRead,
+		PermissionServiceAccountsManage,
+		PermissionRolesManage,
+	},
+	RoleAdmin: {
+		PermissionOrganizationRead,
+		PermissionMembersRead,
+		PermissionMembersManage,
+		PermissionServiceAccountsRead,
+		PermissionServiceAccountsManage,
+		PermissionRolesManage,
+	},
+	RoleMember: {
+		PermissionOrganizationRead,
+		PermissionMembersRead,
+	},
+	RoleAuditor: {
+		PermissionOrganizationRead,
+		PermissionMembersRead,
+		PermissionServiceAccountsRead,
+	},
+	RoleReadOnly: {
+		PermissionOrganizationRead,
+	},
+}
+
+// Built-in roles in the order they are listed, most privileged first.
+var builtInRoles = []string{RoleOwner, RoleAdmin, RoleMember, RoleAuditor, RoleReadOnly}
+
+// Role is a custom role of an organization, composed from the permission catalog.
+type Role struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// Members and service accounts are given the role by its name, which cannot
+	// be one of the built-in roles
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Returns the permissions granted by a built-in role, or none for any other role.
+func PermissionsForRole(role string) []string {
+	return rolePermissions[role]
+}
+
+// Returns the permission catalog.
+func PermissionCatalog() []Permission {
+	return slices.Clone(permissionCatalog)
+}
+
+// Reports whether permission is in the catalog.
+func IsPermission(permission string) bool {
+	return slices.ContainsFunc(permissionCatalog, func(p Permission) bool { return p.Name == permission })
+}
+
+// Returns the built-in roles, most privileged first.
+func BuiltInRoles() []string {
+	return slices.Clone(builtInRoles)
+}
+
+func IsBuiltInRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Reports whether role is a built-in role more privileged than the built-in role
+// other. Custom roles are not ranked.
+func OutranksRole(role, other string) bool {
+	i, j := slices.Index(builtInRoles, role), slices.Index(builtInRoles, other)
+	return i >= 0 && j >= 0 && i < j
+}
@@ -0,0 +1,26 @@
+package organization
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SAMLConnection is the SAML identity provider members of an organization can
+// sign in with.
+type SAMLConnection struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	// Entity ID of the identity provider, read from its metadata
+	IdPEntityID string `json:"idp_entity_id"`
+	// URL the metadata was fetched from, empty when it was uploaded
+	MetadataURL string `json:"metadata_url,omitempty"`
+	MetadataXML string `json:"metadata_xml"`
+	// Assertion attributes mapped to user fields, empty when commonly used
+	// attributes are read
+	EmailAttribute     string    `json:"email_attribute"`
+	FirstNameAttribute string    `json:"first_name_attribute"`
+	LastNameAttribute  string    `json:"last_name_attribute"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
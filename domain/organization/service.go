@@ -1,6 +1,9 @@
 package organization
 
 import (
+	"errors"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -18,3 +21,144 @@ func Initialize(r OrganizationRepository) *OrganizationService {
 func (s *OrganizationService) GetOrganizationByID(DB *gorm.DB, id uuid.UUID) (Organization, error) {
 	return s.repository.GetOrganizationByID(DB, id)
 }
+
+// Returns the organization linked to the Entra tenant.
+func (s *OrganizationService) GetOrganizationByTenantID(DB *gorm.DB, tenantID string) (Organization, error) {
+	return s.repository.GetOrganizationByTenantID(DB, tenantID)
+}
+
+func (s *OrganizationService) UpdateOrganization(DB *gorm.DB, organization Organization) (Organization, error) {
+	return s.repository.UpdateOrganization(DB, organization)
+}
+
+// Reports whether any organization the user belongs to requires MFA.
+func (s *OrganizationService) RequiresMFA(DB *gorm.DB, userID uuid.UUID) (bool, error) {
+	return s.repository.RequiresMFA(DB, userID)
+}
+
+func (s *OrganizationService) GetSAMLConnection(DB *gorm.DB, organizationID uuid.UUID) (SAMLConnection, error) {
+	return s.repository.GetSAMLConnection(DB, organizationID)
+}
+
+// Creates the organization's SAML connection or replaces the existing one.
+func (s *OrganizationService) SaveSAMLConnection(DB *gorm.DB, connection SAMLConnection) (SAMLConnection, error) {
+	return s.repository.SaveSAMLConnection(DB, connection)
+}
+
+// Deletes the organization's SAML connection. Users at its verified domains are
+// no longer required to sign in with it.
+func (s *OrganizationService) DeleteSAMLConnection(DB *gorm.DB, organizationID uuid.UUID) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.repository.DeleteSAMLConnection(tx, organizationID); err != nil {
+			return err
+		}
+
+		organization, err := s.repository.GetOrganizationByID(tx, organizationID)
+		if err != nil || !organization.RequireSSO {
+			return err
+		}
+
+		organization.RequireSSO = false
+		_, err = s.repository.UpdateOrganization(tx, organization)
+
+		return err
+	})
+}
+
+// Returns the domains claimed by the organization, verified or not.
+func (s *OrganizationService) GetDomains(DB *gorm.DB, organizationID uuid.UUID) ([]Domain, error) {
+	return s.repository.GetDomains(DB, organizationID)
+}
+
+func (s *OrganizationService) GetDomain(DB *gorm.DB, organizationID, id uuid.UUID) (Domain, error) {
+	return s.repository.GetDomain(DB, organizationID, id)
+}
+
+// Returns the claim of the organization that verified the domain.
+func (s *OrganizationService) GetVerifiedDomain(DB *gorm.DB, name string) (Domain, error) {
+	return s.repository.GetVerifiedDomain(DB, name)
+}
+
+func (s *OrganizationService) CreateDomain(DB *gorm.DB, domain Domain) (Domain, error) {
+	return s.repository.CreateDomain(DB, domain)
+}
+
+func (s *OrganizationService) UpdateDomain(DB *gorm.DB, domain Domain) (Domain, error) {
+	return s.repository.UpdateDomain(DB, domain)
+}
+
+func (s *OrganizationService) DeleteDomain(DB *gorm.DB, organizationID, id uuid.UUID) error {
+	return s.repository.DeleteDomain(DB, organizationID, id)
+}
+
+// Returns the custom roles of the organization, by name.
+func (s *OrganizationService) GetRoles(DB *gorm.DB, organizationID uuid.UUID) ([]Role, error) {
+	return s.repository.GetRoles(DB, organizationID)
+}
+
+func (s *OrganizationService) GetRole(DB *gorm.DB, organizationID, id uuid.UUID) (Role, error) {
+	return s.repository.GetRole(DB, organizationID, id)
+}
+
+func (s *OrganizationService) GetRoleByName(DB *gorm.DB, organizationID uuid.UUID, name string) (Role, error) {
+	return s.repository.GetRoleByName(DB, organizationID, name)
+}
+
+func (s *OrganizationService) CreateRole(DB *gorm.DB, role Role) (Role, error) {
+	return s.repository.CreateRole(DB, role)
+}
+
+func (s *OrganizationService) UpdateRole(DB *gorm.DB, role Role) (Role, error) {
+	return s.repository.UpdateRole(DB, role)
+}
+
+func (s *OrganizationService) DeleteRole(DB *gorm.DB, organizationID, id uuid.UUID) error {
+	return s.repository.DeleteRole(DB, organizationID, id)
+}
+
+// Returns the permissions role grants in the organization, whether it is a
+// built-in or a custom role. Unknown roles grant none.
+func (s *OrganizationService) RolePermissions(DB *gorm.DB, organizationID uuid.UUID, role string) ([]string, error) {
+	if IsBuiltInRole(role) {
+		return PermissionsForRole(role), nil
+	}
+
+	custom, err := s.repository.GetRoleByName(DB, organizationID, role)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return custom.Permissions, nil
+}
+
+// Returns the invitations of the organization, the most recent first.
+func (s *OrganizationService) GetInvitations(DB *gorm.DB, organizationID uuid.UUID) ([]Invitation, error) {
+	return s.repository.GetInvitations(DB, organizationID)
+}
+
+func (s *OrganizationService) GetInvitation(DB *gorm.DB, organizationID, id uuid.UUID) (Invitation, error) {
+	return s.repository.GetInvitation(DB, organizationID, id)
+}
+
+// Returns an invitation of any organization, for invitees following its link.
+func (s *OrganizationService) GetInvitationByID(DB *gorm.DB, id uuid.UUID) (Invitation, error) {
+	return s.repository.GetInvitationByID(DB, id)
+}
+
+func (s *OrganizationService) CreateInvitation(DB *gorm.DB, invitation Invitation) (Invitation, error) {
+	return s.repository.CreateInvitation(DB, invitation)
+}
+
+// Updates the link, expiry and revocation of an invitation.
+func (s *OrganizationService) UpdateInvitation(DB *gorm.DB, invitation Invitation) (Invitation, error) {
+	return s.repository.UpdateInvitation(DB, invitation)
+}
+
+// Marks a pending invitation accepted by the user. Reports false when it was
+// accepted or revoked in the meantime, so an invitation is only accepted once.
+func (s *OrganizationService) AcceptInvitation(DB *gorm.DB, id, userID uuid.UUID, at time.Time) (bool, error) {
+	return s.repository.AcceptInvitation(DB, id, userID, at)
+}
@@ -0,0 +1,40 @@
+package team
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// MemberChange collects changes to a team's members, to be applied at once.
+type MemberChange struct {
+	// Every current member is removed before the added ones are added
+	Replace bool
+	Added   []uuid.UUID
+	Removed []uuid.UUID
+}
+
+// Adds the user, undoing an earlier removal.
+func (c *MemberChange) Add(userID uuid.UUID) {
+	c.Removed = slices.DeleteFunc(c.Removed, func(id uuid.UUID) bool { return id == userID })
+
+	if !slices.Contains(c.Added, userID) {
+		c.Added = append(c.Added, userID)
+	}
+}
+
+// Removes the user, undoing an earlier addition.
+func (c *MemberChange) Remove(userID uuid.UUID) {
+	c.Added = slices.DeleteFunc(c.Added, func(id uuid.UUID) bool { return id == userID })
+
+	if !c.Replace && !slices.Contains(c.Removed, userID) {
+		c.Removed = append(c.Removed, userID)
+	}
+}
+
+// Removes every member, including the ones added so far.
+func (c *MemberChange) Reset() {
+	c.Replace = true
+	c.Added = nil
+	c.Removed = nil
+}
@@ -7,4 +7,13 @@ import (
 
 type TeamRepository interface {
 	GetTeamByID(DB *gorm.DB, id uuid.UUID) (Team, error)
+	GetTeams(DB *gorm.DB, query TeamQuery) ([]Team, int64, error)
+	CreateTeam(DB *gorm.DB, team Team) (Team, error)
+	UpdateTeam(DB *gorm.DB, team Team) (Team, error)
+	DeleteTeam(DB *gorm.DB, id uuid.UUID) error
+
+	GetTeamMembers(DB *gorm.DB, teamID uuid.UUID) ([]uuid.UUID, error)
+	AddTeamMembers(DB *gorm.DB, teamID uuid.UUID, userIDs []uuid.UUID) error
+	RemoveTeamMembers(DB *gorm.DB, teamID uuid.UUID, userIDs []uuid.UUID) error
+	RemoveAllTeamMembers(DB *gorm.DB, teamID uuid.UUID) error
 }
@@ -18,3 +18,64 @@ func Initialize(r TeamRepository) *TeamService {
 func (s *TeamService) GetTeamByID(DB *gorm.DB, id uuid.UUID) (Team, error) {
 	return s.repository.GetTeamByID(DB, id)
 }
+
+// Returns the teams matching the query, oldest first, and how many match in total.
+func (s *TeamService) GetTeams(DB *gorm.DB, query TeamQuery) ([]Team, int64, error) {
+	return s.repository.GetTeams(DB, query)
+}
+
+// Creates a team with the given members.
+func (s *TeamService) CreateTeam(DB *gorm.DB, team Team, memberIDs []uuid.UUID) (Team, error) {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		if team, err = s.repository.CreateTeam(tx, team); err != nil {
+			return err
+		}
+
+		return s.repository.AddTeamMembers(tx, team.ID, memberIDs)
+	})
+	if err != nil {
+		return Team{}, err
+	}
+
+	return team, nil
+}
+
+// Updates the team and applies the member changes.
+func (s *TeamService) UpdateTeam(DB *gorm.DB, team Team, change MemberChange) (Team, error) {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		if team, err = s.repository.UpdateTeam(tx, team); err != nil {
+			return err
+		}
+
+		if change.Replace {
+			if err := s.repository.RemoveAllTeamMembers(tx, team.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repository.RemoveTeamMembers(tx, team.ID, change.Removed); err != nil {
+			return err
+		}
+
+		return s.repository.AddTeamMembers(tx, team.ID, change.Added)
+	})
+	if err != nil {
+		return Team{}, err
+	}
+
+	return team, nil
+}
+
+// Deletes the team. Its memberships are deleted with it.
+func (s *TeamService) DeleteTeam(DB *gorm.DB, id uuid.UUID) error {
+	return s.repository.DeleteTeam(DB, id)
+}
+
+// Returns the IDs of the team's members.
+func (s *TeamService) GetTeamMembers(DB *gorm.DB, teamID uuid.UUID) ([]uuid.UUID, error) {
+	return s.repository.GetTeamMembers(DB, teamID)
+}
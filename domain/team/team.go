@@ -1,8 +1,27 @@
 package team
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Team struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Name           string    `json:"name"`
+	// ID of the group in the organization's directory, set by SCIM provisioning
+	ExternalID string    `json:"external_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TeamQuery selects teams of an organization. Empty criteria match every team.
+type TeamQuery struct {
+	OrganizationID uuid.UUID
+	TeamID         uuid.UUID
+	Name           string
+	ExternalID     string
+	Offset         int
+	Limit          int
 }
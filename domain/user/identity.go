@@ -0,0 +1,35 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links an account at an external identity provider to a user.
+type Identity struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	Email    string    `json:"email"`
+	// Directory tenant of the account, for multi-tenant providers
+	TenantID  string    `json:"tenant_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ExternalProfile is the profile of a user authenticated by an external identity provider.
+type ExternalProfile struct {
+	Provider  string
+	Subject   string
+	Email     string
+	FirstName string
+	LastName  string
+	TenantID  string
+	// The provider vouches that the user owns Email
+	EmailVerified bool
+	// Email is at a domain the organization provisioning the user verified, so
+	// its identity provider may sign in the organization's members with it
+	OrganizationDomain bool
+}
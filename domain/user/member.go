@@ -0,0 +1,25 @@
+package user
+
+import (
+	"conformitea/domain/organization"
+
+	"github.com/google/uuid"
+)
+
+// Member is a user together with their membership of an organization.
+type Member struct {
+	User       User
+	Membership organization.Membership
+}
+
+// MemberQuery selects members of an organization. Empty criteria match every member.
+type MemberQuery struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+	// Matched case-insensitively
+	Email      string
+	ExternalID string
+	Role       string
+	Offset     int
+	Limit      int
+}
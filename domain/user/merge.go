@@ -0,0 +1,23 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Merge records that a duplicate user, the source, was merged into the target.
+// The source is kept, deactivated, so the history of what it did stays intact.
+type Merge struct {
+	ID           uuid.UUID `json:"id"`
+	SourceUserID uuid.UUID `json:"source_user_id"`
+	TargetUserID uuid.UUID `json:"target_user_id"`
+	// Staff user who merged the users, nil for operators
+	MergedBy *uuid.UUID `json:"merged_by,omitempty"`
+	Reason   string     `json:"reason"`
+	// Identities, organization memberships and team memberships moved to the target
+	Identities  int       `json:"identities"`
+	Memberships int       `json:"memberships"`
+	Teams       int       `json:"teams"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"conformitea/domain/organization"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -8,4 +10,23 @@ import (
 type UserRepository interface {
 	GetUserByID(DB *gorm.DB, id uuid.UUID) (User, error)
 	GetUserByEmail(DB *gorm.DB, email string) (User, error)
+	CreateUser(DB *gorm.DB, user User) (User, error)
+	UpdateUser(DB *gorm.DB, user User) (User, error)
+	GetMemberships(DB *gorm.DB, userID uuid.UUID) ([]organization.Membership, error)
+	CreateMembership(DB *gorm.DB, membership organization.Membership) (organization.Membership, error)
+	UpdateMembership(DB *gorm.DB, membership organization.Membership) (organization.Membership, error)
+	GetMember(DB *gorm.DB, organizationID, userID uuid.UUID) (Member, error)
+	GetMembers(DB *gorm.DB, query MemberQuery) ([]Member, int64, error)
+
+	GetIdentity(DB *gorm.DB, provider, subject string) (Identity, error)
+	GetIdentities(DB *gorm.DB, userID uuid.UUID) ([]Identity, error)
+	CreateIdentity(DB *gorm.DB, identity Identity) (Identity, error)
+	UpdateIdentity(DB *gorm.DB, identity Identity) (Identity, error)
+	DeleteIdentity(DB *gorm.DB, userID, id uuid.UUID) (bool, error)
+
+	MoveIdentities(DB *gorm.DB, fromUserID, toUserID uuid.UUID) (int64, error)
+	MoveMembership(DB *gorm.DB, organizationID, fromUserID, toUserID uuid.UUID) error
+	DeleteMembership(DB *gorm.DB, organizationID, userID uuid.UUID) error
+	MoveTeamMemberships(DB *gorm.DB, fromUserID, toUserID uuid.UUID) (int64, error)
+	CreateMerge(DB *gorm.DB, merge Merge) (Merge, error)
 }
@@ -1,6 +1,11 @@
 package user
 
 import (
+	"errors"
+	"time"
+
+	"conformitea/domain/organization"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -20,5 +25,384 @@ func (s *UserService) GetUserByID(DB *gorm.DB, id uuid.UUID) (User, error) {
 }
 
 func (s *UserService) GetUserByEmail(DB *gorm.DB, email string) (User, error) {
-	return s.repository.GetUserByEmail(DB, email)
+	return s.repository.GetUserByEmail(DB, NormalizeEmail(email))
+}
+
+// Grants or revokes staff rights of the user.
+func (s *UserService) SetStaff(DB *gorm.DB, id uuid.UUID, staff bool) (User, error) {
+	user, err := s.repository.GetUserByID(DB, id)
+	if err != nil {
+		return User{}, err
+	}
+
+	user.IsStaff = staff
+
+	return s.repository.UpdateUser(DB, user)
+}
+
+func (s *UserService) GetUserMemberships(DB *gorm.DB, userID uuid.UUID) ([]organization.Membership, error) {
+	return s.repository.GetMemberships(DB, userID)
+}
+
+// Returns the user's membership of the organization.
+func (s *UserService) GetOrganizationMember(DB *gorm.DB, organizationID, userID uuid.UUID) (Member, error) {
+	return s.repository.GetMember(DB, organizationID, userID)
+}
+
+// Returns the members of an organization matching the query, oldest first, and
+// how many match in total.
+func (s *UserService) GetOrganizationMembers(DB *gorm.DB, query MemberQuery) ([]Member, int64, error) {
+	return s.repository.GetMembers(DB, query)
+}
+
+// Adds the user to an organization. Users without an ID are created, the profile
+// of existing users is updated.
+func (s *UserService) AddOrganizationMember(DB *gorm.DB, user User, membership organization.Membership) (Member, error) {
+	var member Member
+
+	user.Email = NormalizeEmail(user.Email)
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		if user.ID == uuid.Nil {
+			user, err = s.repository.CreateUser(tx, user)
+		} else {
+			user, err = s.repository.UpdateUser(tx, user)
+		}
+		if err != nil {
+			return err
+		}
+
+		membership.UserID = user.ID
+		if membership, err = s.repository.CreateMembership(tx, membership); err != nil {
+			return err
+		}
+
+		member = Member{User: user, Membership: membership}
+
+		return nil
+	})
+	if err != nil {
+		return Member{}, err
+	}
+
+	return member, nil
+}
+
+// Changes the role or external ID of a membership.
+func (s *UserService) UpdateMembership(DB *gorm.DB, membership organization.Membership) (organization.Membership, error) {
+	return s.repository.UpdateMembership(DB, membership)
+}
+
+// Updates the profile of an organization member and their membership.
+func (s *UserService) UpdateOrganizationMember(DB *gorm.DB, member Member) (Member, error) {
+	member.User.Email = NormalizeEmail(member.User.Email)
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		if member.User, err = s.repository.UpdateUser(tx, member.User); err != nil {
+			return err
+		}
+
+		member.Membership, err = s.repository.UpdateMembership(tx, member.Membership)
+
+		return err
+	})
+	if err != nil {
+		return Member{}, err
+	}
+
+	return member, nil
+}
+
+// Returns the identities linked to the user, the most recently used first.
+func (s *UserService) GetUserIdentities(DB *gorm.DB, userID uuid.UUID) ([]Identity, error) {
+	return s.repository.GetIdentities(DB, userID)
+}
+
+// Returns the identity of a provider's account, whoever it is linked to.
+func (s *UserService) GetIdentity(DB *gorm.DB, provider, subject string) (Identity, error) {
+	return s.repository.GetIdentity(DB, provider, subject)
+}
+
+// Links another identity provider account to an existing user, so they can sign
+// in with either.
+func (s *UserService) LinkIdentity(DB *gorm.DB, identity Identity) (Identity, error) {
+	return s.repository.CreateIdentity(DB, identity)
+}
+
+// Unlinks an identity from the user. Reports whether the user had the identity.
+func (s *UserService) UnlinkIdentity(DB *gorm.DB, userID, id uuid.UUID) (bool, error) {
+	return s.repository.DeleteIdentity(DB, userID, id)
+}
+
+// Returned when an identity signs in with the email of another user it is not
+// linked to. The user has to sign in and link the identity to their account.
+var ErrEmailTaken = errors.New("email belongs to another user")
+
+// Returned to roll back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// Merges the source user of merge into its target: their identities, organization
+// memberships and team memberships move to the target, and the source is
+// deactivated and marked as merged rather than deleted, so their history stays.
+// When both belong to an organization, the target keeps the more privileged of
+// their built-in roles. A dry run returns what would move without changing
+// anything.
+func (s *UserService) MergeUsers(DB *gorm.DB, merge Merge, dryRun bool) (Merge, error) {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		source, err := s.repository.GetUserByID(tx, merge.SourceUserID)
+		if err != nil {
+			return err
+		}
+
+		identities, err := s.repository.MoveIdentities(tx, merge.SourceUserID, merge.TargetUserID)
+		if err != nil {
+			return err
+		}
+		merge.Identities = int(identities)
+
+		memberships, err := s.repository.GetMemberships(tx, merge.SourceUserID)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range memberships {
+			if err := s.mergeMembership(tx, m, merge.TargetUserID); err != nil {
+				return err
+			}
+		}
+		merge.Memberships = len(memberships)
+
+		teams, err := s.repository.MoveTeamMemberships(tx, merge.SourceUserID, merge.TargetUserID)
+		if err != nil {
+			return err
+		}
+		merge.Teams = int(teams)
+
+		if source.DeactivatedAt == nil {
+			now := time.Now()
+			source.DeactivatedAt = &now
+		}
+		source.MergedIntoID = &merge.TargetUserID
+
+		if _, err := s.repository.UpdateUser(tx, source); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+
+		merge, err = s.repository.CreateMerge(tx, merge)
+
+		return err
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return Merge{}, err
+	}
+
+	return merge, nil
+}
+
+// Moves a membership of a merged user to the target of the merge, or folds it
+// into the target's membership when they belong to the organization too.
+func (s *UserService) mergeMembership(tx *gorm.DB, membership organization.Membership, targetUserID uuid.UUID) error {
+	target, err := s.repository.GetMember(tx, membership.OrganizationID, targetUserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.repository.MoveMembership(tx, membership.OrganizationID, membership.UserID, targetUserID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.DeleteMembership(tx, membership.OrganizationID, membership.UserID); err != nil {
+		return err
+	}
+
+	if organization.OutranksRole(membership.Role, target.Membership.Role) {
+		target.Membership.Role = membership.Role
+	}
+	if target.Membership.ExternalID == "" {
+		target.Membership.ExternalID = membership.ExternalID
+	}
+
+	_, err = s.repository.UpdateMembership(tx, target.Membership)
+
+	return err
+}
+
+// Creates or updates the user signing in with an external identity and links the
+// identity to them. Identities are matched by provider and subject first; an
+// unknown identity is linked to the user with the same email, or to a new user.
+func (s *UserService) ProvisionUser(DB *gorm.DB, profile ExternalProfile) (User, error) {
+	var user User
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = s.provisionUser(tx, uuid.Nil, profile)
+
+		return err
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// Provisions the user signing in with an external identity of an organization's
+// identity provider, like ProvisionUser, and adds them to the organization as a
+// member if they do not belong to it yet. An identity at a domain the organization
+// verified is linked to the member with its email, even when they signed in with
+// other providers before.
+func (s *UserService) ProvisionOrganizationMember(DB *gorm.DB, organizationID uuid.UUID, profile ExternalProfile) (Member, error) {
+	var member Member
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		user, err := s.provisionUser(tx, organizationID, profile)
+		if err != nil {
+			return err
+		}
+
+		member, err = s.repository.GetMember(tx, organizationID, user.ID)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		membership, err := s.repository.CreateMembership(tx, organization.Membership{
+			UserID:         user.ID,
+			OrganizationID: organizationID,
+			Role:           organization.RoleMember,
+		})
+		if err != nil {
+			return err
+		}
+
+		member = Member{User: user, Membership: membership}
+
+		return nil
+	})
+	if err != nil {
+		return Member{}, err
+	}
+
+	return member, nil
+}
+
+// Provisions the user of the profile. An identity of an organization's identity
+// provider passes the organization's ID, uuid.Nil otherwise.
+func (s *UserService) provisionUser(tx *gorm.DB, organizationID uuid.UUID, profile ExternalProfile) (User, error) {
+	identity, err := s.repository.GetIdentity(tx, profile.Provider, profile.Subject)
+	linked := err == nil
+
+	profile.Email = NormalizeEmail(profile.Email)
+
+	var user User
+	switch {
+	case linked:
+		user, err = s.repository.GetUserByID(tx, identity.UserID)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err = s.repository.GetUserByEmail(tx, profile.Email)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			user, err = s.repository.CreateUser(tx, User{Email: profile.Email})
+		} else if err == nil {
+			err = s.checkClaimable(tx, organizationID, user, profile)
+		}
+	}
+	if err != nil {
+		return User{}, err
+	}
+
+	// Users merged into another one sign in as the user they were merged into
+	for user.MergedIntoID != nil {
+		if user, err = s.repository.GetUserByID(tx, *user.MergedIntoID); err != nil {
+			return User{}, err
+		}
+	}
+
+	// The email of users with several linked identities is left as it is, so it
+	// does not change with each provider they sign in with
+	identities, err := s.repository.GetIdentities(tx, user.ID)
+	if err != nil {
+		return User{}, err
+	}
+	if len(identities) <= 1 && profile.Email != user.Email {
+		if user.Email, err = s.availableEmail(tx, user, profile.Email); err != nil {
+			return User{}, err
+		}
+	}
+	user.FirstName = profile.FirstName
+	user.LastName = profile.LastName
+
+	if user, err = s.repository.UpdateUser(tx, user); err != nil {
+		return User{}, err
+	}
+
+	if linked {
+		identity.Email = profile.Email
+		identity.TenantID = profile.TenantID
+		_, err = s.repository.UpdateIdentity(tx, identity)
+	} else {
+		_, err = s.repository.CreateIdentity(tx, Identity{
+			UserID:   user.ID,
+			Provider: profile.Provider,
+			Subject:  profile.Subject,
+			Email:    profile.Email,
+			TenantID: profile.TenantID,
+		})
+	}
+	if err != nil {
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// Checks that the identity may sign in as the user with its email. Identities are
+// only linked implicitly to users without any, such as users provisioned by an
+// organization, and only when the provider verified the email. Anyone can claim
+// an unverified email, and users with identities link others themselves. An
+// organization's identity provider also signs in the organization's members at
+// its verified domains, so it can be required once they signed in with others.
+// Merged users sign in as the user they were merged into, who has identities.
+func (s *UserService) checkClaimable(tx *gorm.DB, organizationID uuid.UUID, user User, profile ExternalProfile) error {
+	if !profile.EmailVerified || user.MergedIntoID != nil {
+		return ErrEmailTaken
+	}
+
+	if profile.OrganizationDomain && organizationID != uuid.Nil {
+		_, err := s.repository.GetMember(tx, organizationID, user.ID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	identities, err := s.repository.GetIdentities(tx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(identities) > 0 {
+		return ErrEmailTaken
+	}
+
+	return nil
+}
+
+// Returns email if no other user has it, the user's current email otherwise.
+func (s *UserService) availableEmail(tx *gorm.DB, user User, email string) (string, error) {
+	other, err := s.repository.GetUserByEmail(tx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && other.ID == user.ID) {
+		return email, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return user.Email, nil
 }
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"strings"
 	"time"
 
 	"conformitea/domain/organization"
@@ -9,11 +10,27 @@ import (
 )
 
 type User struct {
-	ID            uuid.UUID                   `json:"id"`
-	Email         string                      `json:"email"`
-	FirstName     string                      `json:"first_name"`
-	LastName      string                      `json:"last_name"`
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	// Set when the user was deprovisioned, deactivated users cannot sign in
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	// Staff administer the ConformiTea installation, such as its OAuth2 clients
+	IsStaff bool `json:"is_staff"`
+	// Set when the user was merged into another user, the one they sign in as
+	MergedIntoID  *uuid.UUID                  `json:"merged_into_id,omitempty"`
 	CreatedAt     time.Time                   `json:"created_at"`
 	UpdatedAt     time.Time                   `json:"updated_at"`
 	Organizations []organization.Organization `json:"organizations,omitempty"`
 }
+
+// Reports whether the user may sign in.
+func (u User) Active() bool {
+	return u.DeactivatedAt == nil
+}
+
+// Returns the email as it is stored. Emails are unique regardless of case.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,43 @@
+import { useState } from "react";
+
+import { api } from "@/lib/api";
+
+import { useUser } from "@/hooks/use-user";
+
+import { Button } from "@/components/ui/button";
+
+// Reminds staff users that they see the application as the user they impersonate,
+// and that only reads are allowed.
+export function ImpersonationBanner() {
+  const { user, mutate } = useUser();
+  const [pending, setPending] = useState(false);
+
+  if (!user?.impersonated || !user.impersonation) {
+    return null;
+  }
+
+  const onEnd = async () => {
+    setPending(true);
+
+    try {
+      await api.impersonation.end();
+      await mutate();
+    } catch (error) {
+      console.error("Ending impersonation failed:", error);
+    } finally {
+      setPending(false);
+    }
+  };
+
+  return (
+    <div className="sticky top-0 z-40 flex items-center justify-center gap-4 bg-destructive px-4 py-2 text-sm text-white">
+      <span>
+        {user.impersonation.impersonator_email} is viewing as {user.email} ({user.impersonation.reason}) until{" "}
+        {new Date(user.impersonation.expires_at).toLocaleTimeString()}. Changes are blocked.
+      </span>
+      <Button size="sm" variant="outline" disabled={pending} onClick={onEnd}>
+        End impersonation
+      </Button>
+    </div>
+  );
+}
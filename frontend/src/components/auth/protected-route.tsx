@@ -2,6 +2,8 @@ import { Navigate, useLocation } from "react-router";
 
 import { useUser } from "@/hooks/use-user";
 
+import { ImpersonationBanner } from "@/components/auth/impersonation-banner";
+
 import { useAuthStore } from "@/stores/auth-store";
 
 interface ProtectedRouteProps {
@@ -28,5 +30,10 @@ export function ProtectedRoute({ children, redirectTo = "/auth/signup" }: Protec
     return <Navigate to={redirectTo} state={{ from: location }} replace />;
   }
 
-  return <>{children}</>;
+  return (
+    <>
+      <ImpersonationBanner />
+      {children}
+    </>
+  );
 }
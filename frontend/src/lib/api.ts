@@ -1,4 +1,23 @@
-import type { User } from "@/types/auth";
+import type {
+  ConnectedApp,
+  ConsentDecisionResponse,
+  ConsentRequest,
+  DeviceVerificationResponse,
+  LinkedIdentity,
+  LinkIdentityResponse,
+  LogoutResponse,
+  MFAChallenge,
+  MFACompleteResponse,
+  MFAMethod,
+  Passkey,
+  PasskeyLoginResponse,
+  PasskeyRegistration,
+  ProvidersResponse,
+  TOTPEnrollment,
+  User,
+} from "@/types/auth";
+import type { InvitationPreview } from "@/types/organization";
+import type { CreationOptionsJSON, RequestOptionsJSON } from "@/lib/webauthn";
 
 const API_URL = import.meta.env.VITE_API_URL || "http://localhost:8080";
 
@@ -27,11 +46,47 @@ export const fetcher = async (url: string) => {
   return response.json();
 };
 
+// Sends a JSON body and returns the JSON response. Errors carry the ConformiTea error code.
+const post = async (url: string, body?: unknown) => {
+  const response = await fetch(`${API_URL}${url}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify(body ?? {}),
+  });
+
+  const data = await response.json().catch(() => ({}));
+
+  if (!response.ok) {
+    throw new ApiError(response.status, data.code ?? response.statusText);
+  }
+
+  return data;
+};
+
+// Deletes a resource. Errors carry the ConformiTea error code.
+const del = async (url: string) => {
+  const response = await fetch(`${API_URL}${url}`, {
+    method: "DELETE",
+    credentials: "include",
+  });
+
+  if (!response.ok) {
+    const data = await response.json().catch(() => ({}));
+    throw new ApiError(response.status, data.code ?? response.statusText);
+  }
+};
+
 // Type-safe API methods
 export const api = {
   auth: {
+    // The server signs the user in through Hydra and redirects back with a session cookie
+    loginURL: (provider: string) => `${API_URL}/auth/session/login?` + new URLSearchParams({ provider }),
     me: () => fetcher("/users/me") as Promise<User>,
-    logout: async () => {
+    providers: () => fetcher("/auth/providers") as Promise<ProvidersResponse>,
+    logout: async (): Promise<LogoutResponse> => {
       const response = await fetch(`${API_URL}/auth/logout`, {
         method: "POST",
         credentials: "include",
@@ -40,6 +95,71 @@ export const api = {
       if (!response.ok) {
         throw new ApiError(response.status, response.statusText);
       }
+
+      return response.json();
     },
   },
+  mfa: {
+    challenge: () => fetcher("/auth/mfa") as Promise<MFAChallenge>,
+    verify: (method: MFAMethod, code: string) =>
+      post("/auth/mfa/verify", { method, code }) as Promise<MFACompleteResponse>,
+    beginEnrollment: () => post("/auth/mfa/totp") as Promise<TOTPEnrollment>,
+    confirmEnrollment: (code: string) =>
+      post("/auth/mfa/totp/confirm", { code }) as Promise<MFACompleteResponse>,
+    beginPasskey: () => post("/auth/mfa/passkey/begin") as Promise<RequestOptionsJSON>,
+    finishPasskey: (credential: unknown) =>
+      post("/auth/mfa/passkey/finish", credential) as Promise<MFACompleteResponse>,
+  },
+  device: {
+    verify: (challenge: string, userCode: string) =>
+      post("/auth/device/verify", {
+        device_challenge: challenge,
+        user_code: userCode,
+      }) as Promise<DeviceVerificationResponse>,
+  },
+  consent: {
+    request: (challenge: string) =>
+      fetcher(
+        "/auth/consent/request?" + new URLSearchParams({ consent_challenge: challenge }),
+      ) as Promise<ConsentRequest>,
+    accept: (challenge: string, grantScope: string[], remember: boolean) =>
+      post("/auth/consent/accept", {
+        consent_challenge: challenge,
+        grant_scope: grantScope,
+        remember,
+      }) as Promise<ConsentDecisionResponse>,
+    reject: (challenge: string) =>
+      post("/auth/consent/reject", { consent_challenge: challenge }) as Promise<ConsentDecisionResponse>,
+  },
+  impersonation: {
+    // Staff users act as themselves again
+    end: () => del("/admin/impersonation"),
+  },
+  connectedApps: {
+    list: () => fetcher("/users/me/connected-apps") as Promise<ConnectedApp[]>,
+    revoke: (clientID: string) => del(`/users/me/connected-apps/${encodeURIComponent(clientID)}`),
+  },
+  identities: {
+    list: () => fetcher("/users/me/identities") as Promise<LinkedIdentity[]>,
+    // The user signs in with the provider, which calls back to link the account
+    link: (provider: string) =>
+      post("/users/me/identities/link", { provider }) as Promise<LinkIdentityResponse>,
+    unlink: (id: string) => del(`/users/me/identities/${encodeURIComponent(id)}`),
+  },
+  invitations: {
+    preview: (token: string) =>
+      fetcher("/invitations/preview?" + new URLSearchParams({ token })) as Promise<InvitationPreview>,
+    accept: (token: string) => post("/invitations/accept", { token }) as Promise<InvitationPreview>,
+    // Signs the invitee in and sends them back to the invitation
+    loginURL: (token: string) => `${API_URL}/auth/session/login?` + new URLSearchParams({ invitation: token }),
+  },
+  passkey: {
+    // Signing in with a passkey alone, while Hydra waits for the login
+    beginLogin: () => post("/auth/passkey/begin") as Promise<RequestOptionsJSON>,
+    finishLogin: (credential: unknown) => post("/auth/passkey/finish", credential) as Promise<PasskeyLoginResponse>,
+    list: () => fetcher("/users/me/passkeys") as Promise<Passkey[]>,
+    beginRegistration: () => post("/users/me/passkeys/begin") as Promise<CreationOptionsJSON>,
+    finishRegistration: (name: string, credential: unknown) =>
+      post("/users/me/passkeys/finish", { name, credential }) as Promise<PasskeyRegistration>,
+  },
 };
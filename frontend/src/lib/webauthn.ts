@@ -0,0 +1,107 @@
+// Runs WebAuthn ceremonies with the options the server returns. Binary values are
+// exchanged with the server as base64url strings.
+
+const toBytes = (value: string) => {
+  const base64 = value.replace(/-/g, "+").replace(/_/g, "/").padEnd(Math.ceil(value.length / 4) * 4, "=");
+  return Uint8Array.from(atob(base64), (c) => c.charCodeAt(0));
+};
+
+const toBase64URL = (buffer: ArrayBuffer) =>
+  btoa(String.fromCharCode(...new Uint8Array(buffer)))
+    .replace(/\+/g, "-")
+    .replace(/\//g, "_")
+    .replace(/=+$/, "");
+
+interface CredentialDescriptorJSON {
+  id: string;
+  type: PublicKeyCredentialType;
+  transports?: AuthenticatorTransport[];
+}
+
+// Options of navigator.credentials.create as encoded by the server
+export interface CreationOptionsJSON {
+  publicKey: Omit<PublicKeyCredentialCreationOptions, "challenge" | "user" | "excludeCredentials"> & {
+    challenge: string;
+    user: Omit<PublicKeyCredentialUserEntity, "id"> & { id: string };
+    excludeCredentials?: CredentialDescriptorJSON[];
+  };
+}
+
+// Options of navigator.credentials.get as encoded by the server
+export interface RequestOptionsJSON {
+  publicKey: Omit<PublicKeyCredentialRequestOptions, "challenge" | "allowCredentials"> & {
+    challenge: string;
+    allowCredentials?: CredentialDescriptorJSON[];
+  };
+}
+
+const toDescriptors = (descriptors?: CredentialDescriptorJSON[]) =>
+  descriptors?.map((descriptor) => ({ ...descriptor, id: toBytes(descriptor.id) }));
+
+export const isPasskeySupported = () => typeof window !== "undefined" && "PublicKeyCredential" in window;
+
+// Creates a passkey and returns the credential to send to the server.
+export async function createPasskey(options: CreationOptionsJSON) {
+  const { publicKey } = options;
+
+  const credential = (await navigator.credentials.create({
+    publicKey: {
+      ...publicKey,
+      challenge: toBytes(publicKey.challenge),
+      user: { ...publicKey.user, id: toBytes(publicKey.user.id) },
+      excludeCredentials: toDescriptors(publicKey.excludeCredentials),
+    },
+  })) as PublicKeyCredential | null;
+
+  if (!credential) {
+    throw new Error("No passkey was created");
+  }
+
+  const response = credential.response as AuthenticatorAttestationResponse;
+
+  return {
+    id: credential.id,
+    rawId: toBase64URL(credential.rawId),
+    type: credential.type,
+    authenticatorAttachment: credential.authenticatorAttachment,
+    clientExtensionResults: credential.getClientExtensionResults(),
+    response: {
+      clientDataJSON: toBase64URL(response.clientDataJSON),
+      attestationObject: toBase64URL(response.attestationObject),
+      transports: response.getTransports?.() ?? [],
+    },
+  };
+}
+
+// Signs the server's challenge with a passkey and returns the assertion to send to the server.
+export async function getPasskey(options: RequestOptionsJSON) {
+  const { publicKey } = options;
+
+  const credential = (await navigator.credentials.get({
+    publicKey: {
+      ...publicKey,
+      challenge: toBytes(publicKey.challenge),
+      allowCredentials: toDescriptors(publicKey.allowCredentials),
+    },
+  })) as PublicKeyCredential | null;
+
+  if (!credential) {
+    throw new Error("No passkey was selected");
+  }
+
+  const response = credential.response as AuthenticatorAssertionResponse;
+
+  return {
+    id: credential.id,
+    rawId: toBase64URL(credential.rawId),
+    type: credential.type,
+    authenticatorAttachment: credential.authenticatorAttachment,
+    clientExtensionResults: credential.getClientExtensionResults(),
+    response: {
+      clientDataJSON: toBase64URL(response.clientDataJSON),
+      authenticatorData: toBase64URL(response.authenticatorData),
+      signature: toBase64URL(response.signature),
+      userHandle: response.userHandle ? toBase64URL(response.userHandle) : undefined,
+    },
+  };
+}
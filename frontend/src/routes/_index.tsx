@@ -1,4 +1,4 @@
-import { Navigate } from "react-router";
+import { Navigate, useLocation } from "react-router";
 
 import { useUser } from "@/hooks/use-user";
 
@@ -7,6 +7,12 @@ import { useAuthStore } from "@/stores/auth-store";
 export default function Index() {
   const { isAuthenticated } = useAuthStore();
   const { isLoading } = useUser();
+  const location = useLocation();
+
+  // Hydra redirects back with an OAuth2 error when a sign in is rejected
+  if (new URLSearchParams(location.search).has("error")) {
+    return <Navigate to={`/auth/error${location.search}`} replace />;
+  }
 
   // Show loading state while checking authentication
   if (isLoading) {
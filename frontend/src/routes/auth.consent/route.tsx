@@ -0,0 +1,136 @@
+import { useState } from "react";
+import useSWR from "swr";
+import { Navigate, useSearchParams } from "react-router";
+
+import { api } from "@/lib/api";
+
+import type { ConsentRequest } from "@/types/auth";
+
+import { Button } from "@/components/ui/button";
+
+// Asks the user which of the scopes a third-party client requested to grant.
+export default function Consent() {
+  const [searchParams] = useSearchParams();
+  const challenge = searchParams.get("consent_challenge");
+
+  const { data: request, error, isLoading } = useSWR<ConsentRequest>(
+    challenge ? ["/auth/consent/request", challenge] : null,
+    () => api.consent.request(challenge!),
+    { revalidateOnFocus: false },
+  );
+
+  if (!challenge) {
+    return <Navigate to="/auth/error?code=CT_AUTH_001" replace />;
+  }
+
+  if (isLoading) {
+    return (
+      <div className="flex items-center justify-center min-h-screen">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-primary"></div>
+      </div>
+    );
+  }
+
+  if (error || !request) {
+    return <Navigate to="/auth/error?code=CT_AUTH_001" replace />;
+  }
+
+  return (
+    <div className="flex flex-1 flex-col items-center justify-center mx-auto h-screen gap-6 p-7 max-w-7xl">
+      <div className="flex flex-col justify-start gap-4 w-[380px]">
+        <img src="/images/conformitea.svg" alt="Conformitea Logo" className="w-10 h-10" />
+        <Decide challenge={challenge} request={request} />
+      </div>
+    </div>
+  );
+}
+
+function Decide({ challenge, request }: { challenge: string; request: ConsentRequest }) {
+  const [granted, setGranted] = useState<string[]>(request.scopes.map((scope) => scope.name));
+  const [remember, setRemember] = useState(true);
+  const [pending, setPending] = useState(false);
+  const [message, setMessage] = useState<string | null>(null);
+
+  const clientName = request.client_name || request.client_id;
+
+  const decide = async (decision: () => Promise<{ redirect_to: string }>) => {
+    setPending(true);
+    setMessage(null);
+
+    try {
+      const { redirect_to } = await decision();
+      window.location.href = redirect_to;
+    } catch {
+      setMessage("Something went wrong. Please try again.");
+      setPending(false);
+    }
+  };
+
+  const toggle = (name: string, checked: boolean) => {
+    setGranted((current) => (checked ? [...current, name] : current.filter((scope) => scope !== name)));
+  };
+
+  return (
+    <form
+      className="flex flex-col gap-4"
+      onSubmit={(event) => {
+        event.preventDefault();
+        decide(() => api.consent.accept(challenge, granted, remember));
+      }}
+    >
+      <div className="gap-2 flex flex-col items-start">
+        {request.logo_uri && <img src={request.logo_uri} alt="" className="w-10 h-10" />}
+        <span className="header-md">Allow {clientName} to access your account?</span>
+        <span className="text-sm text-muted-foreground">
+          {request.client_uri ? (
+            <a href={request.client_uri} target="_blank" rel="noreferrer" className="underline">
+              {clientName}
+            </a>
+          ) : (
+            clientName
+          )}{" "}
+          is not operated by ConformiTea. Choose what it may do on your behalf.
+        </span>
+      </div>
+      <ul className="flex flex-col gap-3">
+        {request.scopes.map((scope) => (
+          <li key={scope.name}>
+            <label className="flex items-start gap-3 text-sm">
+              <input
+                type="checkbox"
+                className="mt-1"
+                checked={scope.required || granted.includes(scope.name)}
+                disabled={scope.required || pending}
+                onChange={(e) => toggle(scope.name, e.target.checked)}
+              />
+              <span className="flex flex-col">
+                <span>{scope.description || scope.name}</span>
+                <span className="text-xs text-muted-foreground">
+                  {scope.name}
+                  {scope.required && " (required)"}
+                </span>
+              </span>
+            </label>
+          </li>
+        ))}
+      </ul>
+      <label className="flex items-center gap-3 text-sm">
+        <input type="checkbox" checked={remember} disabled={pending} onChange={(e) => setRemember(e.target.checked)} />
+        Remember my decision
+      </label>
+      {message && <span className="text-sm text-destructive">{message}</span>}
+      <Button type="submit" size="lg" disabled={pending}>
+        Allow
+      </Button>
+      <Button
+        type="button"
+        variant="outline"
+        size="lg"
+        disabled={pending}
+        onClick={() => decide(() => api.consent.reject(challenge))}
+      >
+        Deny
+      </Button>
+    </form>
+  );
+}
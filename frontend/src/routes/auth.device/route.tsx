@@ -0,0 +1,63 @@
+import { useState } from "react";
+import { Navigate, useSearchParams } from "react-router";
+
+import { api, ApiError } from "@/lib/api";
+
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+
+// Asks for the code a device shows to sign it in, e.g. the conformitea CLI.
+export default function Device() {
+  const [searchParams] = useSearchParams();
+  const challenge = searchParams.get("device_challenge");
+
+  const [userCode, setUserCode] = useState("");
+  const [pending, setPending] = useState(false);
+  const [message, setMessage] = useState<string | null>(null);
+
+  if (!challenge) {
+    return <Navigate to="/auth/error?code=CT_AUTH_001" replace />;
+  }
+
+  const onSubmit = async (event: React.FormEvent) => {
+    event.preventDefault();
+    setPending(true);
+    setMessage(null);
+
+    try {
+      const { redirect_to } = await api.device.verify(challenge, userCode.trim());
+      window.location.href = redirect_to;
+    } catch (error) {
+      setMessage(
+        error instanceof ApiError && error.message === "CT_AUTH_041"
+          ? "That code is invalid or expired. Check the code your device shows."
+          : "Something went wrong. Please try again.",
+      );
+      setPending(false);
+    }
+  };
+
+  return (
+    <div className="flex flex-1 flex-col items-center justify-center mx-auto h-screen gap-6 p-7 max-w-7xl">
+      <form className="flex flex-col justify-start gap-4 w-[380px]" onSubmit={onSubmit}>
+        <img src="/images/conformitea.svg" alt="Conformitea Logo" className="w-10 h-10" />
+        <div className="gap-2 flex flex-col items-start">
+          <span className="header-md">Sign in a device</span>
+          <span className="text-sm text-muted-foreground">Enter the code shown on your device.</span>
+        </div>
+        <Input
+          autoFocus
+          autoComplete="off"
+          autoCapitalize="characters"
+          value={userCode}
+          onChange={(e) => setUserCode(e.target.value)}
+          aria-invalid={message !== null}
+        />
+        {message && <span className="text-sm text-destructive">{message}</span>}
+        <Button type="submit" size="lg" disabled={pending || userCode.trim() === ""}>
+          Continue
+        </Button>
+      </form>
+    </div>
+  );
+}
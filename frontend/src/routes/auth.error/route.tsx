@@ -0,0 +1,51 @@
+import { Link, useSearchParams } from "react-router";
+
+import { Button } from "@/components/ui/button";
+
+// Messages for the OAuth2 errors Hydra returns to the client.
+const oauthErrorMessages: Record<string, string> = {
+  access_denied: "Your sign in was denied. Make sure you are using an account that has access to ConformiTea.",
+  login_required: "Your sign in could not be completed. Please sign in again.",
+  server_error: "Something went wrong on our side while signing you in. Please try again in a moment.",
+};
+
+// Messages for the ConformiTea errors the server redirects with when the OAuth2 flow cannot continue.
+const errorCodeMessages: Record<string, string> = {
+  CT_AUTH_001: "The sign in request was incomplete. Please start again.",
+  CT_AUTH_002: "Your sign in session has expired. Please start again.",
+  CT_AUTH_007: "We could not create your session. Please try again.",
+  CT_AUTH_011: "The sign in response did not match your sign in. Please start again.",
+  CT_AUTH_021: "We could not sign you out. Please try again.",
+  CT_AUTH_028: "Your sign in has expired. Please start again.",
+};
+
+const defaultMessage = "We could not sign you in. Please try again.";
+
+export default function AuthError() {
+  const [searchParams] = useSearchParams();
+
+  const error = searchParams.get("error");
+  const code = searchParams.get("code");
+
+  const message =
+    (error && oauthErrorMessages[error]) || (code && errorCodeMessages[code]) || defaultMessage;
+  const reference = code ?? error;
+
+  return (
+    <div className="flex flex-1 flex-col items-center justify-center mx-auto h-screen gap-6 p-7 max-w-7xl">
+      <div className="flex flex-col justify-start gap-4 w-[380px]">
+        <img src="/images/conformitea.svg" alt="Conformitea Logo" className="w-10 h-10" />
+        <div className="gap-2 flex flex-col items-start">
+          <span className="header-md">Sign in failed</span>
+          <span className="text-sm text-muted-foreground">{message}</span>
+          {reference && <span className="text-xs text-muted-foreground">Reference: {reference}</span>}
+        </div>
+      </div>
+      <div className="flex flex-col gap-4 w-[380px]">
+        <Button className="w-full" size="lg" asChild>
+          <Link to="/auth/signup">Back to sign in</Link>
+        </Button>
+      </div>
+    </div>
+  );
+}
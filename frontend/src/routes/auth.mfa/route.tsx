@@ -0,0 +1,213 @@
+import { useEffect, useState } from "react";
+import useSWR from "swr";
+import { Navigate } from "react-router";
+
+import { api, ApiError } from "@/lib/api";
+import { getPasskey } from "@/lib/webauthn";
+
+import type { MFAChallenge, MFAMethod, TOTPEnrollment } from "@/types/auth";
+
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+
+export default function MFA() {
+  const { data: challenge, error, isLoading } = useSWR<MFAChallenge>("/auth/mfa", api.mfa.challenge, {
+    revalidateOnFocus: false,
+  });
+
+  if (isLoading) {
+    return (
+      <div className="flex items-center justify-center min-h-screen">
+        <div className="animate-spin rounded-full h-8 w-8 border-b-2 border-primary"></div>
+      </div>
+    );
+  }
+
+  // No login is waiting for a second factor
+  if (error || !challenge) {
+    return <Navigate to="/auth/error?code=CT_AUTH_028" replace />;
+  }
+
+  return (
+    <div className="flex flex-1 flex-col items-center justify-center mx-auto h-screen gap-6 p-7 max-w-7xl">
+      <div className="flex flex-col justify-start gap-4 w-[380px]">
+        <img src="/images/conformitea.svg" alt="Conformitea Logo" className="w-10 h-10" />
+        {challenge.enrollment_required ? <Enroll /> : <Verify methods={challenge.methods} />}
+      </div>
+    </div>
+  );
+}
+
+const methodLabels: Record<MFAMethod, string> = {
+  totp: "Use your authenticator app",
+  passkey: "Use a passkey",
+  recovery_code: "Use a recovery code",
+};
+
+// Asks for a code of the user's authenticator, a passkey, or one of their recovery codes.
+function Verify({ methods }: { methods: MFAMethod[] }) {
+  const [method, setMethod] = useState<MFAMethod>(methods[0] ?? "recovery_code");
+  const [code, setCode] = useState("");
+  const [pending, setPending] = useState(false);
+  const [message, setMessage] = useState<string | null>(null);
+
+  const complete = async (verify: () => Promise<{ redirect_to: string }>) => {
+    setPending(true);
+
+    try {
+      const { redirect_to } = await verify();
+      window.location.href = redirect_to;
+    } catch (error) {
+      setCode("");
+      setMessage(errorMessage(error));
+      setPending(false);
+    }
+  };
+
+  const onSubmit = (event: React.FormEvent) => {
+    event.preventDefault();
+
+    if (method === "passkey") {
+      complete(async () => api.mfa.finishPasskey(await getPasskey(await api.mfa.beginPasskey())));
+    } else {
+      complete(() => api.mfa.verify(method, code));
+    }
+  };
+
+  return (
+    <form className="flex flex-col gap-4" onSubmit={onSubmit}>
+      <div className="gap-2 flex flex-col items-start">
+        <span className="header-md">Two-factor authentication</span>
+        <span className="text-sm text-muted-foreground">
+          {method === "totp" && "Enter the 6-digit code from your authenticator app."}
+          {method === "passkey" && "Confirm it's you with one of your passkeys."}
+          {method === "recovery_code" &&
+            "Enter one of the recovery codes you saved when setting up two-factor authentication."}
+        </span>
+      </div>
+      {method !== "passkey" && (
+        <Input
+          autoFocus
+          autoComplete="one-time-code"
+          inputMode={method === "totp" ? "numeric" : "text"}
+          value={code}
+          onChange={(e) => setCode(e.target.value)}
+          aria-invalid={message !== null}
+        />
+      )}
+      {message && <span className="text-sm text-destructive">{message}</span>}
+      <Button type="submit" size="lg" disabled={pending || (method !== "passkey" && code === "")}>
+        {method === "passkey" ? "Continue with passkey" : "Verify"}
+      </Button>
+      {methods
+        .filter((other) => other !== method)
+        .map((other) => (
+          <Button
+            key={other}
+            type="button"
+            variant="link"
+            onClick={() => {
+              setMethod(other);
+              setCode("");
+              setMessage(null);
+            }}
+          >
+            {methodLabels[other]}
+          </Button>
+        ))}
+    </form>
+  );
+}
+
+// Sets up an authenticator for users of an organization that requires one.
+function Enroll() {
+  const [enrollment, setEnrollment] = useState<TOTPEnrollment | null>(null);
+  const [code, setCode] = useState("");
+  const [message, setMessage] = useState<string | null>(null);
+  const [completed, setCompleted] = useState<{ redirectTo: string; recoveryCodes: string[] } | null>(null);
+
+  useEffect(() => {
+    api.mfa
+      .beginEnrollment()
+      .then(setEnrollment)
+      .catch((error) => setMessage(errorMessage(error)));
+  }, []);
+
+  const onSubmit = async (event: React.FormEvent) => {
+    event.preventDefault();
+
+    try {
+      const { redirect_to, recovery_codes } = await api.mfa.confirmEnrollment(code);
+      setCompleted({ redirectTo: redirect_to, recoveryCodes: recovery_codes ?? [] });
+    } catch (error) {
+      setCode("");
+      setMessage(errorMessage(error));
+    }
+  };
+
+  if (completed) {
+    return (
+      <div className="flex flex-col gap-4">
+        <div className="gap-2 flex flex-col items-start">
+          <span className="header-md">Save your recovery codes</span>
+          <span className="text-sm text-muted-foreground">
+            Each code signs you in once if you lose your authenticator. They will not be shown again.
+          </span>
+        </div>
+        <ul className="grid grid-cols-2 gap-2 font-mono text-sm">
+          {completed.recoveryCodes.map((recoveryCode) => (
+            <li key={recoveryCode}>{recoveryCode}</li>
+          ))}
+        </ul>
+        <Button size="lg" onClick={() => (window.location.href = completed.redirectTo)}>
+          Continue
+        </Button>
+      </div>
+    );
+  }
+
+  return (
+    <form className="flex flex-col gap-4" onSubmit={onSubmit}>
+      <div className="gap-2 flex flex-col items-start">
+        <span className="header-md">Set up two-factor authentication</span>
+        <span className="text-sm text-muted-foreground">
+          Your organization requires a second factor. Scan the QR code with your authenticator app and enter the
+          6-digit code it shows.
+        </span>
+      </div>
+      {enrollment && (
+        <div className="flex flex-col items-center gap-2">
+          <img src={enrollment.qr_code} alt="Authenticator QR code" className="w-48 h-48" />
+          <code className="text-xs break-all text-muted-foreground">{enrollment.secret}</code>
+        </div>
+      )}
+      <Input
+        autoComplete="one-time-code"
+        inputMode="numeric"
+        value={code}
+        onChange={(e) => setCode(e.target.value)}
+        aria-invalid={message !== null}
+      />
+      {message && <span className="text-sm text-destructive">{message}</span>}
+      <Button type="submit" size="lg" disabled={!enrollment || code === ""}>
+        Verify and continue
+      </Button>
+    </form>
+  );
+}
+
+function errorMessage(error: unknown) {
+  if (error instanceof ApiError && error.message === "CT_AUTH_025") {
+    return "That code is not valid. Please try again.";
+  }
+
+  if (error instanceof ApiError && error.message === "CT_AUTH_030") {
+    return "That passkey could not be verified. Please try again.";
+  }
+
+  if (error instanceof DOMException && error.name === "NotAllowedError") {
+    return "The passkey prompt was dismissed. Please try again.";
+  }
+
+  return "Something went wrong. Please try again.";
+}
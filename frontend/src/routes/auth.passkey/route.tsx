@@ -0,0 +1,67 @@
+import { useState } from "react";
+import { Link } from "react-router";
+import { FaKey } from "react-icons/fa";
+
+import { api, ApiError } from "@/lib/api";
+import { getPasskey } from "@/lib/webauthn";
+
+import { Button } from "@/components/ui/button";
+
+// Signs the user in with a passkey while Hydra waits for the login.
+export default function PasskeyLogin() {
+  const [pending, setPending] = useState(false);
+  const [message, setMessage] = useState<string | null>(null);
+
+  const onSignIn = async () => {
+    setPending(true);
+    setMessage(null);
+
+    try {
+      const options = await api.passkey.beginLogin();
+      const credential = await getPasskey(options);
+      const { redirect_to } = await api.passkey.finishLogin(credential);
+      window.location.href = redirect_to;
+    } catch (error) {
+      setMessage(errorMessage(error));
+      setPending(false);
+    }
+  };
+
+  return (
+    <div className="flex flex-1 flex-col items-center justify-center mx-auto h-screen gap-6 p-7 max-w-7xl">
+      <div className="flex flex-col justify-start gap-4 w-[380px]">
+        <img src="/images/conformitea.svg" alt="Conformitea Logo" className="w-10 h-10" />
+        <div className="gap-2 flex flex-col items-start">
+          <span className="header-md">Sign in with a passkey</span>
+          <span className="text-sm text-muted-foreground">
+            Use the passkey saved on this device, your phone or your security key.
+          </span>
+        </div>
+        {message && <span className="text-sm text-destructive">{message}</span>}
+        <Button size="lg" onClick={onSignIn} disabled={pending}>
+          <FaKey className="mr-1" />
+          Continue with passkey
+        </Button>
+        <Button variant="link" asChild>
+          <Link to="/auth/signup">Use another sign in method</Link>
+        </Button>
+      </div>
+    </div>
+  );
+}
+
+function errorMessage(error: unknown) {
+  if (error instanceof ApiError && error.message === "CT_AUTH_028") {
+    return "Your sign in expired. Please start again.";
+  }
+
+  if (error instanceof ApiError && error.message === "CT_AUTH_030") {
+    return "That passkey could not be verified. Please try again.";
+  }
+
+  if (error instanceof DOMException && error.name === "NotAllowedError") {
+    return "The passkey prompt was dismissed. Please try again.";
+  }
+
+  return "Something went wrong. Please try again.";
+}
@@ -1,20 +1,18 @@
 import { FaGoogle } from "react-icons/fa";
-import { FaMicrosoft } from "react-icons/fa";
+import { FaKey, FaMicrosoft } from "react-icons/fa";
 
 import { Button } from "@/components/ui/button";
 
+import { api } from "@/lib/api";
+import { isPasskeySupported } from "@/lib/webauthn";
+
 export default function Signup() {
   const onMicrosoftSignUp = () => {
-    // Initiate OAuth2 flow with Hydra
-    window.location.href =
-      `${import.meta.env.VITE_HYDRA_PUBLIC_URL}/oauth2/auth?` +
-      new URLSearchParams({
-        client_id: "microsoft",
-        response_type: "code",
-        redirect_uri: window.location.origin,
-        scope: "offline_access offline openid",
-        state: Math.random().toString(36).substring(2, 15),
-      });
+    window.location.href = api.auth.loginURL("microsoft");
+  };
+
+  const onPasskeySignIn = () => {
+    window.location.href = api.auth.loginURL("passkey");
   };
 
   return (
@@ -38,6 +36,12 @@ export default function Signup() {
             <FaMicrosoft className="mr-1" />
             Sign up with Microsoft
           </Button>
+          {isPasskeySupported() && (
+            <Button className="w-full" variant="ghost" size="lg" onClick={onPasskeySignIn}>
+              <FaKey className="mr-1" />
+              Sign in with a passkey
+            </Button>
+          )}
         </div>
         <div></div>
       </div>
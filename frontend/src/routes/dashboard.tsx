@@ -1,4 +1,4 @@
-import { useNavigate } from "react-router";
+import { Link, useNavigate } from "react-router";
 
 import { useAuthStore } from "@/stores/auth-store";
 
@@ -14,9 +14,16 @@ export default function Dashboard() {
 
   const handleLogout = async () => {
     try {
-      await api.auth.logout();
+      const { logout_url } = await api.auth.logout();
       // Clear the auth store and redirect
       useAuthStore.getState().clearUser();
+
+      // End the Hydra session too, so other applications sign out as well
+      if (logout_url) {
+        window.location.href = logout_url;
+        return;
+      }
+
       navigate("/auth/signup");
     } catch (error) {
       console.error("Logout failed:", error);
@@ -28,9 +35,17 @@ export default function Dashboard() {
       <div className="container mx-auto p-6 max-w-4xl">
         <div className="flex justify-between items-center mb-8">
           <h1 className="text-3xl font-bold">Dashboard</h1>
-          <Button onClick={handleLogout} variant="outline">
-            Logout
-          </Button>
+          <div className="flex gap-2">
+            <Button variant="ghost" asChild>
+              <Link to="/settings/identities">Sign-in methods</Link>
+            </Button>
+            <Button variant="ghost" asChild>
+              <Link to="/settings/connected-apps">Connected apps</Link>
+            </Button>
+            <Button onClick={handleLogout} variant="outline">
+              Logout
+            </Button>
+          </div>
         </div>
 
         <Card>
@@ -44,7 +59,7 @@ export default function Dashboard() {
                 <strong>Email:</strong> {user?.email}
               </p>
               <p>
-                <strong>ID:</strong> {user?.id}
+                <strong>ID:</strong> {user?.user_id}
               </p>
               <p>
                 <strong>Provider:</strong> {user?.provider}
@@ -0,0 +1,105 @@
+import { useState } from "react";
+import useSWR from "swr";
+import { Link, Navigate, useSearchParams } from "react-router";
+
+import { api, ApiError } from "@/lib/api";
+
+import type { InvitationPreview } from "@/types/organization";
+
+import { useUser } from "@/hooks/use-user";
+
+import { Button } from "@/components/ui/button";
+
+// Shows the invitation of an emailed link and lets the invitee accept it, signing
+// them in first.
+export default function AcceptInvitation() {
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get("token");
+
+  const { user, isLoading: userLoading } = useUser();
+  const { data: invitation, error } = useSWR<InvitationPreview>(
+    token ? ["/invitations/preview", token] : null,
+    () => api.invitations.preview(token!),
+    { shouldRetryOnError: false },
+  );
+
+  const [pending, setPending] = useState(false);
+  const [accepted, setAccepted] = useState(false);
+  const [message, setMessage] = useState<string | null>(null);
+
+  if (!token) {
+    return <Navigate to="/auth/error?code=CT_ORG_022" replace />;
+  }
+
+  const onAccept = async () => {
+    setPending(true);
+    setMessage(null);
+
+    try {
+      await api.invitations.accept(token);
+      setAccepted(true);
+    } catch (error) {
+      if (error instanceof ApiError && error.message === "CT_ORG_024") {
+        setMessage(`This invitation was sent to ${invitation?.email}. Sign in with that account to accept it.`);
+      } else if (error instanceof ApiError && error.message === "CT_ORG_023") {
+        setMessage("You are already a member of this organization.");
+      } else if (error instanceof ApiError && error.message === "CT_ORG_022") {
+        setMessage("This invitation expired or was already used. Ask for a new one.");
+      } else {
+        setMessage("Something went wrong. Please try again.");
+      }
+    } finally {
+      setPending(false);
+    }
+  };
+
+  return (
+    <div className="flex flex-1 flex-col items-center justify-center mx-auto h-screen gap-6 p-7 max-w-7xl">
+      <div className="flex flex-col justify-start gap-4 w-[380px]">
+        <img src="/images/conformitea.svg" alt="Conformitea Logo" className="w-10 h-10" />
+
+        {error && (
+          <span className="text-sm text-destructive">
+            This invitation link is invalid, expired or was already used. Ask for a new invitation.
+          </span>
+        )}
+
+        {!error && !invitation && <span className="text-sm text-muted-foreground">Loading...</span>}
+
+        {invitation && (
+          <>
+            <div className="gap-2 flex flex-col items-start">
+              <span className="header-md">Join {invitation.organization_name}</span>
+              <span className="text-sm text-muted-foreground">
+                {invitation.inviter_name} invited {invitation.email} to join as {invitation.role}. The
+                invitation expires on {new Date(invitation.expires_at).toLocaleDateString()}.
+              </span>
+            </div>
+
+            {message && <span className="text-sm text-destructive">{message}</span>}
+
+            {accepted ? (
+              <>
+                <span className="text-sm">You joined {invitation.organization_name}.</span>
+                <Button size="lg" asChild>
+                  <Link to="/dashboard">Continue</Link>
+                </Button>
+              </>
+            ) : user ? (
+              <>
+                <span className="text-sm text-muted-foreground">Signed in as {user.email}.</span>
+                <Button size="lg" disabled={pending} onClick={onAccept}>
+                  Accept invitation
+                </Button>
+              </>
+            ) : (
+              <Button size="lg" disabled={userLoading} asChild>
+                <a href={api.invitations.loginURL(token)}>Sign in to accept</a>
+              </Button>
+            )}
+          </>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,83 @@
+import { useState } from "react";
+import useSWR from "swr";
+import { Link } from "react-router";
+
+import { api } from "@/lib/api";
+
+import type { ConnectedApp } from "@/types/auth";
+
+import { ProtectedRoute } from "@/components/auth/protected-route";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+
+// Lists the third-party applications the user granted access to their account.
+export default function ConnectedApps() {
+  const { data: apps, error, isLoading, mutate } = useSWR<ConnectedApp[]>(
+    "/users/me/connected-apps",
+    api.connectedApps.list,
+  );
+  const [revoking, setRevoking] = useState<string | null>(null);
+  const [message, setMessage] = useState<string | null>(null);
+
+  const onRevoke = async (app: ConnectedApp) => {
+    setRevoking(app.client_id);
+    setMessage(null);
+
+    try {
+      await api.connectedApps.revoke(app.client_id);
+      await mutate();
+    } catch {
+      setMessage(`We could not remove ${app.client_name || app.client_id}. Please try again.`);
+    } finally {
+      setRevoking(null);
+    }
+  };
+
+  return (
+    <ProtectedRoute>
+      <div className="container mx-auto p-6 max-w-4xl">
+        <div className="flex justify-between items-center mb-8">
+          <h1 className="text-3xl font-bold">Connected apps</h1>
+          <Button variant="outline" asChild>
+            <Link to="/dashboard">Back</Link>
+          </Button>
+        </div>
+
+        <p className="text-sm text-muted-foreground mb-4">
+          Applications not operated by ConformiTea that you allowed to access your account. Removing an
+          application signs it out and it has to ask for access again.
+        </p>
+
+        {message && <p className="text-sm text-destructive mb-4">{message}</p>}
+        {error && <p className="text-sm text-destructive">We could not load your connected apps.</p>}
+        {isLoading && <p className="text-sm text-muted-foreground">Loading...</p>}
+        {apps && apps.length === 0 && (
+          <p className="text-sm text-muted-foreground">You have not connected any applications.</p>
+        )}
+
+        <div className="flex flex-col gap-4">
+          {apps?.map((app) => (
+            <Card key={app.client_id}>
+              <CardHeader>
+                <CardTitle>{app.client_name || app.client_id}</CardTitle>
+                <CardDescription>Access granted {new Date(app.granted_at).toLocaleDateString()}</CardDescription>
+              </CardHeader>
+              <CardContent className="flex justify-between items-end gap-4">
+                <div className="flex flex-wrap gap-2">
+                  {app.scopes.map((scope) => (
+                    <code key={scope} className="text-xs rounded bg-muted px-2 py-1">
+                      {scope}
+                    </code>
+                  ))}
+                </div>
+                <Button variant="destructive" disabled={revoking === app.client_id} onClick={() => onRevoke(app)}>
+                  Remove access
+                </Button>
+              </CardContent>
+            </Card>
+          ))}
+        </div>
+      </div>
+    </ProtectedRoute>
+  );
+}
@@ -0,0 +1,140 @@
+import { useState } from "react";
+import useSWR from "swr";
+import { Link, useSearchParams } from "react-router";
+
+import { api, ApiError } from "@/lib/api";
+
+import type { LinkedIdentity, ProvidersResponse } from "@/types/auth";
+
+import { ProtectedRoute } from "@/components/auth/protected-route";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+
+// Explanations of the errors linking or unlinking an account can fail with.
+const errorMessages: Record<string, string> = {
+  CT_AUTH_038: "Accounts at your organization's domain have to sign in with its identity provider.",
+  CT_AUTH_039: "Accounts of this directory are not allowed to sign in.",
+  CT_AUTH_045:
+    "This account already belongs to another ConformiTea user. Ask an administrator to merge the two users.",
+  CT_AUTH_046: "You need at least one account to sign in with.",
+  CT_IMP_005: "Accounts cannot be linked while impersonating a user.",
+};
+
+const errorMessage = (code: string | null, fallback: string) => (code && errorMessages[code]) || fallback;
+
+// Lists the identity provider accounts the user signs in with and links more.
+export default function Identities() {
+  const [searchParams] = useSearchParams();
+  const { data: identities, error, isLoading, mutate } = useSWR<LinkedIdentity[]>(
+    "/users/me/identities",
+    api.identities.list,
+  );
+  const { data: providers } = useSWR<ProvidersResponse>("/auth/providers", api.auth.providers);
+  const [pending, setPending] = useState<string | null>(null);
+  const [message, setMessage] = useState<string | null>(() => {
+    // The identity provider's callback sends the user back here
+    if (searchParams.get("error")) {
+      return errorMessage(searchParams.get("error"), "We could not link the account. Please try again.");
+    }
+    return null;
+  });
+  const linked = searchParams.get("linked");
+
+  const onLink = async (provider: string) => {
+    setPending(provider);
+    setMessage(null);
+
+    try {
+      const { auth_url } = await api.identities.link(provider);
+      window.location.href = auth_url;
+    } catch (err) {
+      setMessage(
+        errorMessage(err instanceof ApiError ? err.message : null, "We could not start linking the account."),
+      );
+      setPending(null);
+    }
+  };
+
+  const onUnlink = async (identity: LinkedIdentity) => {
+    setPending(identity.id);
+    setMessage(null);
+
+    try {
+      await api.identities.unlink(identity.id);
+      await mutate();
+    } catch (err) {
+      setMessage(
+        errorMessage(
+          err instanceof ApiError ? err.message : null,
+          `We could not remove ${identity.email}. Please try again.`,
+        ),
+      );
+    } finally {
+      setPending(null);
+    }
+  };
+
+  return (
+    <ProtectedRoute>
+      <div className="container mx-auto p-6 max-w-4xl">
+        <div className="flex justify-between items-center mb-8">
+          <h1 className="text-3xl font-bold">Sign-in methods</h1>
+          <Button variant="outline" asChild>
+            <Link to="/dashboard">Back</Link>
+          </Button>
+        </div>
+
+        <p className="text-sm text-muted-foreground mb-4">
+          Accounts at identity providers you sign in to ConformiTea with. Link another account to sign in with
+          either one.
+        </p>
+
+        {linked && !message && (
+          <p className="text-sm text-muted-foreground mb-4">Your {linked} account is linked.</p>
+        )}
+        {message && <p className="text-sm text-destructive mb-4">{message}</p>}
+        {error && <p className="text-sm text-destructive">We could not load your sign-in methods.</p>}
+        {isLoading && <p className="text-sm text-muted-foreground">Loading...</p>}
+
+        <div className="flex flex-col gap-4 mb-8">
+          {identities?.map((identity) => (
+            <Card key={identity.id}>
+              <CardHeader>
+                <CardTitle className="capitalize">{identity.provider}</CardTitle>
+                <CardDescription>
+                  Linked {new Date(identity.linked_at).toLocaleDateString()}, last used{" "}
+                  {new Date(identity.last_used_at).toLocaleDateString()}
+                </CardDescription>
+              </CardHeader>
+              <CardContent className="flex justify-between items-center gap-4">
+                <span className="text-sm">{identity.email}</span>
+                <Button
+                  variant="destructive"
+                  disabled={pending === identity.id || identities.length <= 1}
+                  onClick={() => onUnlink(identity)}
+                >
+                  Remove
+                </Button>
+              </CardContent>
+            </Card>
+          ))}
+        </div>
+
+        {providers && providers.providers.length > 0 && (
+          <div className="flex flex-wrap gap-2">
+            {providers.providers.map((provider) => (
+              <Button
+                key={provider}
+                variant="outline"
+                disabled={pending !== null}
+                onClick={() => onLink(provider)}
+              >
+                Link a <span className="capitalize">{provider}</span> account
+              </Button>
+            ))}
+          </div>
+        )}
+      </div>
+    </ProtectedRoute>
+  );
+}
@@ -1,9 +1,121 @@
 export interface User {
-  id: string;
+  user_id: string;
   email: string;
   name: string;
   picture?: string;
   provider: string;
+  // A staff user is impersonating the user
+  impersonated: boolean;
+  impersonation?: Impersonation;
+}
+
+// Who impersonates the user, and why
+export interface Impersonation {
+  id: string;
+  impersonator_id: string;
+  impersonator_email: string;
+  reason: string;
+  expires_at: string;
+}
+
+export interface LogoutResponse {
+  authenticated: boolean;
+  // Hydra endpoint ending the single sign-on session, when the user was signed in
+  logout_url?: string;
+}
+
+export type MFAMethod = "totp" | "recovery_code" | "passkey";
+
+// Second factor a login is waiting for
+export interface MFAChallenge {
+  enrollment_required: boolean;
+  methods: MFAMethod[];
+}
+
+export interface TOTPEnrollment {
+  secret: string;
+  otpauth_url: string;
+  // PNG data URL of the otpauth URL
+  qr_code: string;
+}
+
+export interface MFACompleteResponse {
+  redirect_to: string;
+  // Only returned when an authenticator was enrolled, shown once
+  recovery_codes?: string[];
+}
+
+export interface Passkey {
+  id: string;
+  name: string;
+  // The passkey may be synced to the user's other devices
+  backup_eligible: boolean;
+  last_used_at: string | null;
+  created_at: string;
+}
+
+export interface PasskeyRegistration {
+  passkey: Passkey;
+  // Only returned for the user's first second factor, shown once
+  recovery_codes?: string[];
+}
+
+export interface PasskeyLoginResponse {
+  redirect_to: string;
+}
+
+export interface DeviceVerificationResponse {
+  redirect_to: string;
+}
+
+export interface ConsentScope {
+  name: string;
+  description?: string;
+  // Granted whenever the request is accepted
+  required: boolean;
+}
+
+// Scopes a third-party client asks the user to grant
+export interface ConsentRequest {
+  client_id: string;
+  client_name: string;
+  client_uri?: string;
+  logo_uri?: string;
+  scopes: ConsentScope[];
+  audience: string[];
+}
+
+export interface ConsentDecisionResponse {
+  redirect_to: string;
+}
+
+// Third-party client the user granted access to
+export interface ConnectedApp {
+  client_id: string;
+  client_name: string;
+  client_uri?: string;
+  logo_uri?: string;
+  scopes: string[];
+  audience: string[];
+  granted_at: string;
+}
+
+// Identity provider account the user can sign in with
+export interface LinkedIdentity {
+  id: string;
+  provider: string;
+  email: string;
+  tenant_id?: string;
+  linked_at: string;
+  last_used_at: string;
+}
+
+export interface ProvidersResponse {
+  providers: string[];
+}
+
+export interface LinkIdentityResponse {
+  auth_url: string;
 }
 
 export interface AuthState {
@@ -0,0 +1,11 @@
+// Invitation to join an organization, as seen by the invitee following its link
+export interface InvitationPreview {
+  organization_id: string;
+  organization_name: string;
+  email: string;
+  role: string;
+  // The invitation can be accepted with an account whose email differs
+  allow_email_mismatch: boolean;
+  inviter_name: string;
+  expires_at: string;
+}
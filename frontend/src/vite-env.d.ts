@@ -2,7 +2,6 @@
 
 interface ImportMetaEnv {
   readonly VITE_API_URL: string;
-  readonly VITE_HYDRA_PUBLIC_URL: string;
 }
 
 interface ImportMeta {
@@ -11,6 +11,9 @@ type Config struct {
 	DatabaseConfig DatabaseConfig `mapstructure:"database"`
 	HydraConfig    HydraConfig    `mapstructure:"hydra"`
 	OAuthConfig    OAuthConfig    `mapstructure:"oauth"`
+	SAMLConfig     SAMLConfig     `mapstructure:"saml"`
+	DNSConfig      DNSConfig      `mapstructure:"dns"`
+	MailConfig     MailConfig     `mapstructure:"mail"`
 }
 
 func (c *Config) Validate() error {
@@ -32,6 +35,18 @@ func (c *Config) Validate() error {
 		errs = append(errs, err)
 	}
 
+	if err := c.SAMLConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := c.DNSConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := c.MailConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// DNSConfig configures how the TXT records organizations prove domain ownership
+// with are looked up. The system resolver is used by default.
+type DNSConfig struct {
+	// Address (host:port) of a nameserver to query instead of the system resolver
+	Nameserver string `mapstructure:"nameserver"`
+	// Records answered without querying DNS at all, for local development
+	Records []DNSRecord `mapstructure:"records"`
+}
+
+// DNSRecord is a static TXT record.
+type DNSRecord struct {
+	Name   string   `mapstructure:"name"`
+	Values []string `mapstructure:"values"`
+}
+
+func (d *DNSConfig) Validate() error {
+	var errs []error
+	if d.Nameserver != "" {
+		if _, _, err := net.SplitHostPort(d.Nameserver); err != nil {
+			errs = append(errs, fmt.Errorf("dns.nameserver must be host:port: %w", err))
+		}
+	}
+
+	if d.Nameserver != "" && len(d.Records) > 0 {
+		errs = append(errs, errors.New("dns.nameserver and dns.records are mutually exclusive"))
+	}
+
+	for i, r := range d.Records {
+		if r.Name == "" {
+			errs = append(errs, fmt.Errorf("dns.records[%d].name is required", i))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
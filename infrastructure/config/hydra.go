@@ -2,11 +2,34 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 )
 
 type HydraConfig struct {
 	AdminURL  string `mapstructure:"admin_url"`
 	PublicURL string `mapstructure:"public_url"`
+	// Issuer of the tokens signed by Hydra (urls.self.issuer). Defaults to public_url.
+	Issuer string `mapstructure:"issuer"`
+	// OAuth2 client the server signs users in to the frontend with
+	Client HydraClientConfig `mapstructure:"client"`
+}
+
+// HydraClientConfig is the first-party OAuth2 client of Hydra the server acts as.
+type HydraClientConfig struct {
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+// Returns the issuer of the tokens signed by Hydra.
+func (h *HydraConfig) IssuerURL() string {
+	if h.Issuer != "" {
+		return h.Issuer
+	}
+
+	return h.PublicURL
 }
 
 func (h *HydraConfig) Validate() error {
@@ -19,6 +42,39 @@ func (h *HydraConfig) Validate() error {
 		errs = append(errs, errors.New("hydra.public_url is required"))
 	}
 
+	if err := h.Client.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (c *HydraClientConfig) Validate() error {
+	var errs []error
+	if c.ClientID == "" {
+		errs = append(errs, errors.New("hydra.client.client_id is required"))
+	}
+
+	if c.ClientSecret == "" {
+		errs = append(errs, errors.New("hydra.client.client_secret is required"))
+	}
+
+	if c.RedirectURL == "" {
+		errs = append(errs, errors.New("hydra.client.redirect_url is required"))
+	}
+
+	// Sessions need an ID token to identify the user and a refresh token to outlive
+	// the access token
+	for _, scope := range []string{"openid", "offline_access"} {
+		if !slices.Contains(c.Scopes, scope) {
+			errs = append(errs, fmt.Errorf("hydra.client.scopes must include %s", scope))
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"errors"
+)
+
+// MailConfig configures the SMTP server emails, such as organization invitations,
+// are sent through. Without a host, emails are written to the log instead, for
+// local development.
+type MailConfig struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+	// Credentials of PLAIN authentication, which is skipped when Username is empty
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Address emails are sent from, e.g. "ConformiTea <no-reply@example.com>"
+	From string `mapstructure:"from"`
+}
+
+func (m *MailConfig) Validate() error {
+	if m.Host == "" {
+		return nil
+	}
+
+	var errs []error
+	if m.Port <= 0 {
+		errs = append(errs, errors.New("mail.port must be positive"))
+	}
+
+	if m.From == "" {
+		errs = append(errs, errors.New("mail.from is required"))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
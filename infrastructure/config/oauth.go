@@ -3,39 +3,130 @@ package config
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
 )
 
-type OAuthConfig struct {
-	Microsoft MicrosoftOAuthConfig `mapstructure:"microsoft"`
-}
+// Identity provider implementations that can be configured in [oauth.<name>] sections.
+var oauthProviderTypes = []string{"microsoft", "oidc"}
+
+// Entra tenants "microsoft" providers accept users of.
+const (
+	// Any work, school or personal Microsoft account
+	TenantModeCommon = "common"
+	// The single tenant in tenants
+	TenantModeSingle = "single"
+	// The tenants in tenants
+	TenantModeAllowlist = "allowlist"
+	// Tenants linked to an organization, and the tenants in tenants
+	TenantModeMulti = "multi"
+)
+
+var tenantModes = []string{TenantModeCommon, TenantModeSingle, TenantModeAllowlist, TenantModeMulti}
+
+// OAuthConfig holds the identity providers configured in [oauth.<name>] sections,
+// keyed by provider name.
+type OAuthConfig map[string]OAuthProviderConfig
+
+func (o OAuthConfig) Validate() error {
+	if len(o) == 0 {
+		return errors.New("at least one oauth.<name> identity provider is required")
+	}
+
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		p := o[name]
+		if err := p.Validate(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
 
-func (o *OAuthConfig) Validate() error {
-	return o.Microsoft.Validate()
+	return nil
 }
 
-type MicrosoftOAuthConfig struct {
+type OAuthProviderConfig struct {
+	// Implementation backing the provider. Defaults to the section name.
+	Type string `mapstructure:"type"`
+	// OpenID Connect issuer URL serving /.well-known/openid-configuration.
+	// Required for "oidc" providers.
+	Issuer       string   `mapstructure:"issuer"`
 	ClientID     string   `mapstructure:"client_id"`
 	ClientSecret string   `mapstructure:"client_secret"`
 	RedirectURL  string   `mapstructure:"redirect_url"`
 	Scopes       []string `mapstructure:"scopes"`
+	// Entra tenants users are accepted from, for "microsoft" providers. Defaults
+	// to common.
+	TenantMode string `mapstructure:"tenant_mode"`
+	// Entra tenant IDs for the single, allowlist and multi tenant modes
+	Tenants []string `mapstructure:"tenants"`
+}
+
+// Returns the tenant mode of a "microsoft" provider.
+func (p *OAuthProviderConfig) EntraTenantMode() string {
+	if p.TenantMode != "" {
+		return p.TenantMode
+	}
+
+	return TenantModeCommon
 }
 
-func (m *MicrosoftOAuthConfig) Validate() error {
+// Returns the implementation type of the provider configured under name.
+func (p *OAuthProviderConfig) ProviderType(name string) string {
+	if p.Type != "" {
+		return p.Type
+	}
+
+	return name
+}
+
+func (p *OAuthProviderConfig) Validate(name string) error {
 	var errs []error
-	if m.ClientID == "" {
-		errs = append(errs, fmt.Errorf("oauth.microsoft.client_id is required"))
+	if !slices.Contains(oauthProviderTypes, p.ProviderType(name)) {
+		errs = append(errs, fmt.Errorf("oauth.%s.type must be one of: %v", name, oauthProviderTypes))
+	}
+
+	if p.ClientID == "" {
+		errs = append(errs, fmt.Errorf("oauth.%s.client_id is required", name))
 	}
 
-	if m.ClientSecret == "" {
-		errs = append(errs, fmt.Errorf("oauth.microsoft.client_secret is required"))
+	if p.ClientSecret == "" {
+		errs = append(errs, fmt.Errorf("oauth.%s.client_secret is required", name))
 	}
 
-	if m.RedirectURL == "" {
-		errs = append(errs, fmt.Errorf("oauth.microsoft.redirect_url is required"))
+	if p.RedirectURL == "" {
+		errs = append(errs, fmt.Errorf("oauth.%s.redirect_url is required", name))
+	}
+
+	if len(p.Scopes) == 0 {
+		errs = append(errs, fmt.Errorf("oauth.%s.scopes is required and must not be empty", name))
+	}
+
+	if p.ProviderType(name) == "oidc" {
+		if p.Issuer == "" {
+			errs = append(errs, fmt.Errorf("oauth.%s.issuer is required", name))
+		}
+
+		if !slices.Contains(p.Scopes, "openid") {
+			errs = append(errs, fmt.Errorf("oauth.%s.scopes must include openid", name))
+		}
 	}
 
-	if len(m.Scopes) == 0 {
-		errs = append(errs, fmt.Errorf("oauth.microsoft.scopes is required and must not be empty"))
+	if p.ProviderType(name) == "microsoft" {
+		errs = append(errs, p.validateTenants(name)...)
+	} else if p.TenantMode != "" || len(p.Tenants) > 0 {
+		errs = append(errs, fmt.Errorf("oauth.%s.tenant_mode and tenants are only supported by microsoft providers", name))
 	}
 
 	if len(errs) > 0 {
@@ -44,3 +135,29 @@ func (m *MicrosoftOAuthConfig) Validate() error {
 
 	return nil
 }
+
+func (p *OAuthProviderConfig) validateTenants(name string) []error {
+	var errs []error
+
+	mode := p.EntraTenantMode()
+	if !slices.Contains(tenantModes, mode) {
+		errs = append(errs, fmt.Errorf("oauth.%s.tenant_mode must be one of: %v", name, tenantModes))
+	}
+
+	switch {
+	case mode == TenantModeCommon && len(p.Tenants) > 0:
+		errs = append(errs, fmt.Errorf("oauth.%s.tenants is not supported with tenant_mode common", name))
+	case mode == TenantModeSingle && len(p.Tenants) != 1:
+		errs = append(errs, fmt.Errorf("oauth.%s.tenants must hold exactly one tenant with tenant_mode single", name))
+	case mode == TenantModeAllowlist && len(p.Tenants) == 0:
+		errs = append(errs, fmt.Errorf("oauth.%s.tenants is required with tenant_mode allowlist", name))
+	}
+
+	for _, tenant := range p.Tenants {
+		if _, err := uuid.Parse(tenant); err != nil {
+			errs = append(errs, fmt.Errorf("oauth.%s.tenants: %q is not a tenant ID", name, tenant))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+)
+
+// SAMLConfig configures the SAML service provider organizations sign in to with
+// their own identity provider.
+type SAMLConfig struct {
+	// Public URL of the server. The metadata and assertion consumer service of
+	// each organization are served below <base_url>/auth/saml/<organization ID>.
+	BaseURL string `mapstructure:"base_url"`
+	// PEM files of the certificate and RSA private key authentication requests are
+	// signed with and encrypted assertions are decrypted with.
+	CertificateFile string `mapstructure:"certificate_file"`
+	KeyFile         string `mapstructure:"key_file"`
+}
+
+func (s *SAMLConfig) Validate() error {
+	var errs []error
+	if s.BaseURL == "" {
+		errs = append(errs, errors.New("saml.base_url is required"))
+	}
+
+	if s.CertificateFile == "" {
+		errs = append(errs, errors.New("saml.certificate_file is required"))
+	}
+
+	if s.KeyFile == "" {
+		errs = append(errs, errors.New("saml.key_file is required"))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
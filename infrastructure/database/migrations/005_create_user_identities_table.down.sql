@@ -0,0 +1,2 @@
+DROP INDEX idx_user_identities_user_id;
+DROP TABLE user_identities;
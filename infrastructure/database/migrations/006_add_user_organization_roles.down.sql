@@ -0,0 +1,2 @@
+ALTER TABLE user_organizations
+DROP COLUMN role;
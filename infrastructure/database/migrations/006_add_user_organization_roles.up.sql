@@ -0,0 +1,2 @@
+ALTER TABLE user_organizations
+ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
@@ -0,0 +1,6 @@
+DROP INDEX idx_user_recovery_codes_user_id;
+DROP TABLE user_recovery_codes;
+DROP TABLE user_totp_factors;
+
+ALTER TABLE organizations
+DROP COLUMN require_mfa;
@@ -0,0 +1,2 @@
+DROP INDEX idx_user_passkeys_user_id;
+DROP TABLE user_passkeys;
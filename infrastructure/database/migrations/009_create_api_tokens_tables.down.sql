@@ -0,0 +1,6 @@
+DROP INDEX idx_api_tokens_service_account_id;
+DROP INDEX idx_api_tokens_user_id;
+DROP TABLE api_tokens;
+
+DROP INDEX idx_service_accounts_org_id;
+DROP TABLE service_accounts;
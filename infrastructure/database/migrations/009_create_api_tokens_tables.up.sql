@@ -0,0 +1,34 @@
+CREATE TABLE service_accounts (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT 'member',
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_service_accounts_org_id ON service_accounts(organization_id);
+
+CREATE TABLE api_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID,
+    service_account_id UUID,
+    name TEXT NOT NULL,
+    display_prefix TEXT NOT NULL,
+    secret_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (service_account_id) REFERENCES service_accounts(id),
+    CHECK ((user_id IS NULL) <> (service_account_id IS NULL))
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+CREATE INDEX idx_api_tokens_service_account_id ON api_tokens(service_account_id);
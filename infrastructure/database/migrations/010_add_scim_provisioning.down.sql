@@ -0,0 +1,14 @@
+DROP INDEX idx_team_members_user_id;
+DROP TABLE team_members;
+
+DROP INDEX idx_teams_external_id;
+DROP INDEX idx_teams_org_id;
+DROP TABLE teams;
+
+DROP INDEX idx_user_organizations_external_id;
+
+ALTER TABLE user_organizations
+DROP COLUMN external_id;
+
+ALTER TABLE users
+DROP COLUMN deactivated_at;
@@ -0,0 +1,33 @@
+ALTER TABLE users
+ADD COLUMN deactivated_at TIMESTAMP;
+
+ALTER TABLE user_organizations
+ADD COLUMN external_id TEXT;
+
+CREATE UNIQUE INDEX idx_user_organizations_external_id ON user_organizations(organization_id, external_id)
+WHERE external_id IS NOT NULL;
+
+CREATE TABLE teams (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    external_id TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id)
+);
+
+CREATE INDEX idx_teams_org_id ON teams(organization_id);
+CREATE UNIQUE INDEX idx_teams_external_id ON teams(organization_id, external_id)
+WHERE external_id IS NOT NULL;
+
+CREATE TABLE team_members (
+    team_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id),
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_team_members_user_id ON team_members(user_id);
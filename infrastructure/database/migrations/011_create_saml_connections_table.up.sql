@@ -0,0 +1,13 @@
+CREATE TABLE saml_connections (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL UNIQUE,
+    idp_entity_id TEXT NOT NULL,
+    metadata_url TEXT NOT NULL DEFAULT '',
+    metadata_xml TEXT NOT NULL,
+    email_attribute TEXT NOT NULL DEFAULT '',
+    first_name_attribute TEXT NOT NULL DEFAULT '',
+    last_name_attribute TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+DROP INDEX idx_organization_domains_verified_domain;
+DROP TABLE organization_domains;
+
+ALTER TABLE organizations
+DROP COLUMN require_sso;
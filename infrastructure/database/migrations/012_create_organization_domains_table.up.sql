@@ -0,0 +1,17 @@
+ALTER TABLE organizations
+ADD COLUMN require_sso BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE organization_domains (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL,
+    domain TEXT NOT NULL,
+    verification_token TEXT NOT NULL,
+    verified_at TIMESTAMP,
+    auto_join BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (organization_id, domain),
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_organization_domains_verified_domain ON organization_domains(domain) WHERE verified_at IS NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE user_identities
+DROP COLUMN tenant_id;
+
+ALTER TABLE organizations
+DROP COLUMN entra_tenant_id;
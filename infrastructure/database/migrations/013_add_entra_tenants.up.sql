@@ -0,0 +1,5 @@
+ALTER TABLE organizations
+ADD COLUMN entra_tenant_id TEXT UNIQUE;
+
+ALTER TABLE user_identities
+ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE users
+ADD COLUMN is_staff BOOLEAN NOT NULL DEFAULT FALSE;
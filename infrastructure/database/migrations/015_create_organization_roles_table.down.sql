@@ -0,0 +1 @@
+DROP TABLE organization_roles;
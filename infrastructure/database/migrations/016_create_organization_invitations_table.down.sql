@@ -0,0 +1,2 @@
+DROP INDEX idx_organization_invitations_org_id;
+DROP TABLE organization_invitations;
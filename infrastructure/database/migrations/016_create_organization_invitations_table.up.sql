@@ -0,0 +1,20 @@
+CREATE TABLE organization_invitations (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL,
+    allow_email_mismatch BOOLEAN NOT NULL DEFAULT FALSE,
+    token_nonce TEXT NOT NULL,
+    invited_by UUID NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_by UUID,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users(id),
+    FOREIGN KEY (accepted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_organization_invitations_org_id ON organization_invitations(organization_id);
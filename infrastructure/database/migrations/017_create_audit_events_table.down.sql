@@ -0,0 +1,4 @@
+DROP INDEX idx_audit_events_impersonation_id;
+DROP INDEX idx_audit_events_subject_id;
+DROP INDEX idx_audit_events_actor_id;
+DROP TABLE audit_events;
@@ -0,0 +1,20 @@
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY,
+    action TEXT NOT NULL,
+    actor_id UUID NOT NULL,
+    subject_id UUID NOT NULL,
+    impersonation_id UUID,
+    reason TEXT NOT NULL,
+    method TEXT NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    status INTEGER NOT NULL DEFAULT 0,
+    request_id TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (actor_id) REFERENCES users(id),
+    FOREIGN KEY (subject_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id);
+CREATE INDEX idx_audit_events_subject_id ON audit_events(subject_id);
+CREATE INDEX idx_audit_events_impersonation_id ON audit_events(impersonation_id);
@@ -0,0 +1,6 @@
+DROP INDEX idx_user_merges_target_user_id;
+DROP INDEX idx_user_merges_source_user_id;
+DROP TABLE user_merges;
+
+ALTER TABLE users
+DROP COLUMN merged_into_id;
@@ -0,0 +1,20 @@
+ALTER TABLE users
+ADD COLUMN merged_into_id UUID REFERENCES users(id);
+
+CREATE TABLE user_merges (
+    id UUID PRIMARY KEY,
+    source_user_id UUID NOT NULL,
+    target_user_id UUID NOT NULL,
+    merged_by UUID,
+    reason TEXT NOT NULL,
+    identities INTEGER NOT NULL DEFAULT 0,
+    memberships INTEGER NOT NULL DEFAULT 0,
+    teams INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (source_user_id) REFERENCES users(id),
+    FOREIGN KEY (target_user_id) REFERENCES users(id),
+    FOREIGN KEY (merged_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_user_merges_source_user_id ON user_merges(source_user_id);
+CREATE INDEX idx_user_merges_target_user_id ON user_merges(target_user_id);
@@ -0,0 +1,62 @@
+// Package dns looks up the DNS records organizations prove ownership of their
+// email domains with.
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"conformitea/infrastructure/config"
+)
+
+// Resolver looks up TXT records. Names without records resolve to no values
+// rather than an error.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Returns the static resolver when records are configured, otherwise a resolver
+// querying the configured nameserver or the system resolver.
+func Initialize(dnsConfigValues config.DNSConfig) (Resolver, error) {
+	if err := dnsConfigValues.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid DNS configuration: %w", err)
+	}
+
+	if len(dnsConfigValues.Records) > 0 {
+		return NewStaticResolver(dnsConfigValues.Records), nil
+	}
+
+	resolver := &net.Resolver{}
+
+	if nameserver := dnsConfigValues.Nameserver; nameserver != "" {
+		resolver.PreferGo = true
+		resolver.Dial = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			dialer := net.Dialer{Timeout: 5 * time.Second}
+			return dialer.DialContext(ctx, network, nameserver)
+		}
+	}
+
+	return &netResolver{resolver: resolver}, nil
+}
+
+// netResolver queries DNS.
+type netResolver struct {
+	resolver *net.Resolver
+}
+
+func (r *netResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	values, err := r.resolver.LookupTXT(ctx, name)
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up TXT records of %s: %w", name, err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,36 @@
+package dns
+
+import (
+	"context"
+	"strings"
+
+	"conformitea/infrastructure/config"
+)
+
+// StaticResolver answers from a fixed set of records, so domains can be verified
+// without publishing records.
+type StaticResolver struct {
+	records map[string][]string
+}
+
+func NewStaticResolver(records []config.DNSRecord) *StaticResolver {
+	r := &StaticResolver{
+		records: map[string][]string{},
+	}
+
+	for _, record := range records {
+		name := normalizeName(record.Name)
+		r.records[name] = append(r.records[name], record.Values...)
+	}
+
+	return r
+}
+
+func (r *StaticResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return r.records[normalizeName(name)], nil
+}
+
+// Names are compared case-insensitively and without the trailing dot.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
@@ -3,15 +3,32 @@ package hydra
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strings"
 	"time"
 
 	"conformitea/infrastructure/config"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
 )
 
+// Event identifying a back-channel logout token.
+const backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// Logout tokens are refused once they were issued this long ago. Hydra sends them
+// right after the logout.
+const logoutTokenMaxAge = 5 * time.Minute
+
+// Allowed difference between Hydra's clock and ours.
+const clockSkew = time.Minute
+
 var client *HydraClient
 
 func Initialize(hydraConfigValues config.HydraConfig) (*HydraClient, error) {
@@ -19,10 +36,39 @@ func Initialize(hydraConfigValues config.HydraConfig) (*HydraClient, error) {
 		return nil, fmt.Errorf("invalid hydra configuration: %w", err)
 	}
 
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	publicURL := strings.TrimSuffix(hydraConfigValues.PublicURL, "/")
+
+	// The key set keeps using this context to refresh the cached JWKS.
+	keySet := gooidc.NewRemoteKeySet(gooidc.ClientContext(context.Background(), httpClient), publicURL+"/.well-known/jwks.json")
+
 	client = &HydraClient{
-		adminURL: hydraConfigValues.AdminURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+		adminURL:   hydraConfigValues.AdminURL,
+		publicURL:  publicURL,
+		httpClient: httpClient,
+		issuer:     hydraConfigValues.IssuerURL(),
+		verifier: gooidc.NewVerifier(hydraConfigValues.IssuerURL(), keySet, &gooidc.Config{
+			SkipClientIDCheck: true,
+			SkipExpiryCheck:   true,
+		}),
+		logoutVerifier: gooidc.NewVerifier(hydraConfigValues.IssuerURL(), keySet, &gooidc.Config{
+			ClientID: hydraConfigValues.Client.ClientID,
+			// Logout tokens are not required to carry an expiry, their age is
+			// checked instead
+			SkipExpiryCheck: true,
+		}),
+		oauthConfig: oauth2.Config{
+			ClientID:     hydraConfigValues.Client.ClientID,
+			ClientSecret: hydraConfigValues.Client.ClientSecret,
+			RedirectURL:  hydraConfigValues.Client.RedirectURL,
+			Scopes:       hydraConfigValues.Client.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  publicURL + "/oauth2/auth",
+				TokenURL: publicURL + "/oauth2/token",
+			},
 		},
 	}
 
@@ -51,14 +97,8 @@ func (c *HydraClient) GetLoginSession(loginChallenge string) (*HydraLoginSession
 	return &session, nil
 }
 
-// AcceptLoginSession accepts a Hydra login session with the provided user ID and returns OAuth2 tokens.
-func (c *HydraClient) AcceptLoginSession(loginChallenge, userID string) (*AcceptLoginResponse, error) {
-	acceptReq := AcceptLoginRequest{
-		Subject:     userID,
-		Remember:    true,
-		RememberFor: 3600,
-	}
-
+// AcceptLoginSession accepts a Hydra login session for the subject of the accept request.
+func (c *HydraClient) AcceptLoginSession(loginChallenge string, acceptReq AcceptLoginRequest) (*AcceptLoginResponse, error) {
 	jsonData, err := json.Marshal(acceptReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal accept request: %w", err)
@@ -91,47 +131,48 @@ func (c *HydraClient) AcceptLoginSession(loginChallenge, userID string) (*Accept
 	return &result, nil
 }
 
-// RejectLoginSession rejects a Hydra login session with the specified error code.
-func (c *HydraClient) RejectLoginSession(loginChallenge, errorCode string) error {
-	rejectReq := map[string]interface{}{
-		"error":             errorCode,
-		"error_description": "Authentication failed",
-	}
-
+// RejectLoginSession rejects a Hydra login session, sending the OAuth2 error to the client.
+func (c *HydraClient) RejectLoginSession(loginChallenge string, rejectReq RejectRequest) (*RejectResponse, error) {
 	jsonData, err := json.Marshal(rejectReq)
 	if err != nil {
-		return fmt.Errorf("failed to marshal reject request: %w", err)
+		return nil, fmt.Errorf("failed to marshal reject request: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/admin/oauth2/auth/requests/login/reject?login_challenge=%s", c.adminURL, loginChallenge)
 
 	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create reject request: %w", err)
+		return nil, fmt.Errorf("failed to create reject request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to reject login session: %w", err)
+		return nil, fmt.Errorf("failed to reject login session: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("hydra reject API error: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("hydra reject API error: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	return nil
+	var result RejectResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode reject login response: %w", err)
+	}
+
+	return &result, nil
 }
 
 // IntrospectToken validates an OAuth2 token with Hydra's introspection endpoint.
 func (c *HydraClient) IntrospectToken(token string) (*TokenInfo, error) {
-	data := fmt.Sprintf("token=%s", token)
+	data := neturl.Values{}
+	data.Set("token", token)
 
 	url := fmt.Sprintf("%s/admin/oauth2/introspect", c.adminURL)
 
-	resp, err := c.httpClient.Post(url, "application/x-www-form-urlencoded", bytes.NewBufferString(data))
+	resp, err := c.httpClient.Post(url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to introspect token: %w", err)
 	}
@@ -207,36 +248,235 @@ func (c *HydraClient) AcceptConsentSession(consentChallenge string, acceptReq Hy
 	return &result, nil
 }
 
-// RejectConsentSession rejects a Hydra consent session with the specified error.
-func (c *HydraClient) RejectConsentSession(consentChallenge string, errorCode string, errorDescription string) error {
-	rejectReq := map[string]any{
-		"error":             errorCode,
-		"error_description": errorDescription,
-	}
-
+// RejectConsentSession rejects a Hydra consent session, sending the OAuth2 error to the client.
+func (c *HydraClient) RejectConsentSession(consentChallenge string, rejectReq RejectRequest) (*RejectResponse, error) {
 	jsonData, err := json.Marshal(rejectReq)
 	if err != nil {
-		return fmt.Errorf("failed to marshal reject consent request: %w", err)
+		return nil, fmt.Errorf("failed to marshal reject consent request: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/admin/oauth2/auth/requests/consent/reject?consent_challenge=%s", c.adminURL, consentChallenge)
 
 	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create reject consent request: %w", err)
+		return nil, fmt.Errorf("failed to create reject consent request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to reject consent session: %w", err)
+		return nil, fmt.Errorf("failed to reject consent session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("hydra reject consent API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result RejectResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode reject consent response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetLogoutRequest retrieves logout request details from Hydra using the provided logout challenge.
+func (c *HydraClient) GetLogoutRequest(logoutChallenge string) (*HydraLogoutRequest, error) {
+	url := fmt.Sprintf("%s/admin/oauth2/auth/requests/logout?logout_challenge=%s", c.adminURL, logoutChallenge)
+
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logout request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("hydra API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var logoutRequest HydraLogoutRequest
+	if err := json.NewDecoder(resp.Body).Decode(&logoutRequest); err != nil {
+		return nil, fmt.Errorf("failed to decode logout request: %w", err)
+	}
+
+	return &logoutRequest, nil
+}
+
+// AcceptLogoutRequest accepts a Hydra logout request, ending the subject's Hydra session.
+func (c *HydraClient) AcceptLogoutRequest(logoutChallenge string) (*AcceptLogoutResponse, error) {
+	url := fmt.Sprintf("%s/admin/oauth2/auth/requests/logout/accept?logout_challenge=%s", c.adminURL, logoutChallenge)
+
+	req, err := http.NewRequest("PUT", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create accept logout request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept logout request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("hydra reject consent API error: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("hydra accept logout API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result AcceptLogoutResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode accept logout response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// RejectLogoutRequest rejects a Hydra logout request, keeping the subject signed in.
+func (c *HydraClient) RejectLogoutRequest(logoutChallenge string) error {
+	url := fmt.Sprintf("%s/admin/oauth2/auth/requests/logout/reject?logout_challenge=%s", c.adminURL, logoutChallenge)
+
+	req, err := http.NewRequest("PUT", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create reject logout request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reject logout request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("hydra reject logout API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// RevokeSubjectSessions ends every Hydra login session of the subject and revokes
+// their consents, invalidating the access and refresh tokens issued to them.
+func (c *HydraClient) RevokeSubjectSessions(subject string) error {
+	query := neturl.Values{"subject": {subject}}.Encode()
+
+	urls := []string{
+		fmt.Sprintf("%s/admin/oauth2/auth/sessions/login?%s", c.adminURL, query),
+		fmt.Sprintf("%s/admin/oauth2/auth/sessions/consent?%s&all=true", c.adminURL, query),
+	}
+
+	for _, url := range urls {
+		if err := c.deleteSessions(url); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokeLoginSession ends a single Hydra login session. Hydra notifies the
+// clients of the session with back-channel logouts naming it.
+func (c *HydraClient) RevokeLoginSession(sessionID string) error {
+	query := neturl.Values{"sid": {sessionID}}.Encode()
+
+	return c.deleteSessions(fmt.Sprintf("%s/admin/oauth2/auth/sessions/login?%s", c.adminURL, query))
+}
+
+func (c *HydraClient) deleteSessions(url string) error {
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create revoke sessions request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("hydra revoke sessions API error: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
 	return nil
 }
+
+// LogoutURL returns Hydra's endpoint for RP-initiated logout.
+func (c *HydraClient) LogoutURL() string {
+	return c.publicURL + "/oauth2/sessions/logout"
+}
+
+// VerifyLogoutToken verifies an OpenID Connect back-channel logout token signed by
+// Hydra for the first-party client. Tokens must have been issued recently and carry
+// an identifier the caller refuses to accept twice.
+func (c *HydraClient) VerifyLogoutToken(ctx context.Context, rawToken string) (*LogoutTokenClaims, error) {
+	token, err := c.logoutVerifier.Verify(gooidc.ClientContext(ctx, c.httpClient), rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify logout token: %w", err)
+	}
+
+	var claims struct {
+		SessionID string                     `json:"sid"`
+		TokenID   string                     `json:"jti"`
+		Events    map[string]json.RawMessage `json:"events"`
+		Nonce     *string                    `json:"nonce"`
+	}
+	if err := token.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode logout token claims: %w", err)
+	}
+
+	if _, ok := claims.Events[backChannelLogoutEvent]; !ok {
+		return nil, errors.New("logout token does not carry the back-channel logout event")
+	}
+
+	if claims.Nonce != nil {
+		return nil, errors.New("logout token must not contain a nonce")
+	}
+
+	if token.Subject == "" && claims.SessionID == "" {
+		return nil, errors.New("logout token must contain sub or sid")
+	}
+
+	if claims.TokenID == "" {
+		return nil, errors.New("logout token must contain jti")
+	}
+
+	validUntil, err := checkLogoutTokenAge(token.IssuedAt, token.Expiry, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &LogoutTokenClaims{
+		Subject:    token.Subject,
+		SessionID:  claims.SessionID,
+		Audience:   token.Audience,
+		TokenID:    claims.TokenID,
+		IssuedAt:   token.IssuedAt,
+		ValidUntil: validUntil,
+	}, nil
+}
+
+// Checks that a logout token issued at issuedAt, expiring at expiry if set, is
+// still accepted at now and returns until when it is.
+func checkLogoutTokenAge(issuedAt, expiry, now time.Time) (time.Time, error) {
+	if issuedAt.IsZero() {
+		return time.Time{}, errors.New("logout token must contain iat")
+	}
+
+	if issuedAt.After(now.Add(clockSkew)) {
+		return time.Time{}, fmt.Errorf("logout token issued in the future, at %s", issuedAt)
+	}
+
+	validUntil := issuedAt.Add(logoutTokenMaxAge + clockSkew)
+	if !expiry.IsZero() && expiry.Add(clockSkew).Before(validUntil) {
+		validUntil = expiry.Add(clockSkew)
+	}
+
+	if !now.Before(validUntil) {
+		return time.Time{}, fmt.Errorf("logout token issued at %s is no longer accepted", issuedAt)
+	}
+
+	return validUntil, nil
+}
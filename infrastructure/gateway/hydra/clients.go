@@ -0,0 +1,192 @@
+package hydra
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+)
+
+var (
+	// ErrClientNotFound is returned when Hydra does not know an OAuth2 client.
+	ErrClientNotFound = errors.New("oauth2 client not found")
+	// ErrClientInvalid is returned when Hydra rejects the metadata of an OAuth2 client.
+	ErrClientInvalid = errors.New("oauth2 client invalid")
+)
+
+// Metadata keys of OAuth2 clients.
+const (
+	// Identity provider the client signs users in with
+	ClientMetadataIdentityProvider = "identity_provider"
+	// Organization the client belongs to
+	ClientMetadataOrganizationID = "organization_id"
+	// Whether the client is operated by ConformiTea, so users are not asked to
+	// consent to the scopes it requests
+	ClientMetadataFirstParty = "first_party"
+)
+
+// Clients are listed in pages of this size.
+const clientPageSize = 250
+
+// URL of the next page in the Link header of a list response.
+var nextPagePattern = regexp.MustCompile(`<([^>]*)>;\s*rel="next"`)
+
+// CreateClient registers an OAuth2 client. Hydra generates the ID, and the secret
+// of confidential clients, when they are empty. The secret is only returned here.
+func (c *HydraClient) CreateClient(client OAuth2Client) (*OAuth2Client, error) {
+	jsonData, err := json.Marshal(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal client: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.adminURL+"/admin/clients", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.doClientRequest(req, http.StatusCreated)
+}
+
+// ListClients returns every OAuth2 client.
+func (c *HydraClient) ListClients() ([]OAuth2Client, error) {
+	clients := []OAuth2Client{}
+
+	url := fmt.Sprintf("%s/admin/clients?page_size=%d", c.adminURL, clientPageSize)
+	for url != "" {
+		resp, err := c.httpClient.Get(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list clients: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("hydra list clients API error: status %d, body: %s", resp.StatusCode, string(body))
+		}
+
+		var page []OAuth2Client
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode clients: %w", err)
+		}
+
+		clients = append(clients, page...)
+
+		url, err = c.nextPage("/admin/clients", resp.Header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return clients, nil
+}
+
+// GetClient returns an OAuth2 client.
+func (c *HydraClient) GetClient(clientID string) (*OAuth2Client, error) {
+	req, err := http.NewRequest("GET", c.clientURL(clientID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client request: %w", err)
+	}
+
+	return c.doClientRequest(req, http.StatusOK)
+}
+
+// PatchClient applies JSON Patch operations to an OAuth2 client, leaving the
+// fields they do not touch alone. A client_secret set by the operations is
+// returned once.
+func (c *HydraClient) PatchClient(clientID string, operations []PatchOperation) (*OAuth2Client, error) {
+	jsonData, err := json.Marshal(operations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal client patch: %w", err)
+	}
+
+	req, err := http.NewRequest("PATCH", c.clientURL(clientID), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.doClientRequest(req, http.StatusOK)
+}
+
+// DeleteClient deletes an OAuth2 client and the tokens issued to it.
+func (c *HydraClient) DeleteClient(clientID string) error {
+	req, err := http.NewRequest("DELETE", c.clientURL(clientID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create client request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete client: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrClientNotFound, clientID)
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("hydra delete client API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+func (c *HydraClient) clientURL(clientID string) string {
+	return c.adminURL + "/admin/clients/" + neturl.PathEscape(clientID)
+}
+
+// Sends a request answered with a client and decodes it.
+func (c *HydraClient) doClientRequest(req *http.Request, expectedStatus int) (*OAuth2Client, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call hydra clients API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrClientNotFound, req.URL.Path)
+	}
+
+	if resp.StatusCode == http.StatusBadRequest {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w: %s", ErrClientInvalid, string(body))
+	}
+
+	if resp.StatusCode != expectedStatus {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("hydra clients API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result OAuth2Client
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode client: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Returns the admin API URL of the next page of a list at path named by a Link
+// header, or an empty string on the last page.
+func (c *HydraClient) nextPage(path, link string) (string, error) {
+	match := nextPagePattern.FindStringSubmatch(link)
+	if match == nil {
+		return "", nil
+	}
+
+	next, err := neturl.Parse(match[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid page link %q: %w", match[1], err)
+	}
+
+	// Hydra links pages relative to its own view of the admin URL
+	return fmt.Sprintf("%s%s?%s", c.adminURL, path, next.RawQuery), nil
+}
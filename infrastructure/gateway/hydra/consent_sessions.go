@@ -0,0 +1,60 @@
+package hydra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+)
+
+// Consent sessions are listed in pages of this size.
+const consentSessionPageSize = 250
+
+// ListConsentSessions returns the consents the subject granted, across all clients.
+func (c *HydraClient) ListConsentSessions(subject string) ([]HydraPreviousConsentSession, error) {
+	sessions := []HydraPreviousConsentSession{}
+
+	query := neturl.Values{
+		"subject":   {subject},
+		"page_size": {fmt.Sprint(consentSessionPageSize)},
+	}.Encode()
+
+	url := fmt.Sprintf("%s/admin/oauth2/auth/sessions/consent?%s", c.adminURL, query)
+	for url != "" {
+		resp, err := c.httpClient.Get(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list consent sessions: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("hydra list consent sessions API error: status %d, body: %s", resp.StatusCode, string(body))
+		}
+
+		var page []HydraPreviousConsentSession
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode consent sessions: %w", err)
+		}
+
+		sessions = append(sessions, page...)
+
+		url, err = c.nextPage("/admin/oauth2/auth/sessions/consent", resp.Header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sessions, nil
+}
+
+// RevokeClientConsent revokes the consents the subject granted to a client,
+// invalidating the access and refresh tokens issued to it for the subject.
+func (c *HydraClient) RevokeClientConsent(subject, clientID string) error {
+	query := neturl.Values{"subject": {subject}, "client": {clientID}}.Encode()
+
+	return c.deleteSessions(fmt.Sprintf("%s/admin/oauth2/auth/sessions/consent?%s", c.adminURL, query))
+}
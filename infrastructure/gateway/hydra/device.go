@@ -0,0 +1,56 @@
+package hydra
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+)
+
+// ErrUserCodeInvalid is returned when Hydra refuses the user code of a device
+// authorization request because it is unknown, expired or already used.
+var ErrUserCodeInvalid = errors.New("user code is invalid")
+
+// AcceptDeviceRequest accepts the device authorization request of a device
+// challenge for the user code the user entered. The returned URL continues with
+// Hydra's login and consent flows.
+func (c *HydraClient) AcceptDeviceRequest(deviceChallenge, userCode string) (*AcceptDeviceResponse, error) {
+	jsonData, err := json.Marshal(AcceptDeviceRequest{UserCode: userCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal accept device request: %w", err)
+	}
+
+	query := neturl.Values{"device_challenge": {deviceChallenge}}.Encode()
+	url := fmt.Sprintf("%s/admin/oauth2/auth/requests/device/accept?%s", c.adminURL, query)
+
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create accept device request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept device request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return nil, fmt.Errorf("%w: status %d, body: %s", ErrUserCodeInvalid, resp.StatusCode, string(body))
+		}
+
+		return nil, fmt.Errorf("hydra accept device API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result AcceptDeviceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode accept device response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,140 @@
+package hydra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	"conformitea/infrastructure/gateway/idp"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// ErrRefreshTokenInvalid is returned when Hydra refuses a refresh token because it
+// expired or was revoked, as opposed to Hydra being unreachable.
+var ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+
+// Returns a new PKCE code verifier.
+func NewCodeVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// Returns the ID of the first-party client.
+func (c *HydraClient) FirstPartyClientID() string {
+	return c.oauthConfig.ClientID
+}
+
+// Returns the URL of Hydra's authorization endpoint for the first-party client.
+// params are added to the URL, so they show up in the request URL of the login request.
+func (c *HydraClient) AuthCodeURL(state, nonce, codeVerifier string, params map[string]string) string {
+	opts := []oauth2.AuthCodeOption{
+		gooidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(codeVerifier),
+	}
+
+	for key, value := range params {
+		opts = append(opts, oauth2.SetAuthURLParam(key, value))
+	}
+
+	return c.oauthConfig.AuthCodeURL(state, opts...)
+}
+
+// Exchanges an authorization code issued to the first-party client for tokens.
+func (c *HydraClient) ExchangeCode(ctx context.Context, code, codeVerifier string) (*oauth2.Token, error) {
+	token, err := c.oauthConfig.Exchange(c.clientContext(ctx), code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	return token, nil
+}
+
+// Exchanges a refresh token of the first-party client for new tokens. Hydra rotates
+// refresh tokens, so the returned token replaces the given one.
+func (c *HydraClient) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	token, err := c.oauthConfig.TokenSource(c.clientContext(ctx), &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			return nil, fmt.Errorf("%w: %w", ErrRefreshTokenInvalid, err)
+		}
+
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Revokes a refresh token of the first-party client. Hydra also revokes the access
+// tokens issued with it.
+func (c *HydraClient) RevokeToken(ctx context.Context, refreshToken string) error {
+	form := neturl.Values{
+		"token":           {refreshToken},
+		"token_type_hint": {"refresh_token"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.publicURL+"/oauth2/revoke", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create revoke token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(neturl.QueryEscape(c.oauthConfig.ClientID), neturl.QueryEscape(c.oauthConfig.ClientSecret))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("hydra revoke token API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// Verifies an ID token Hydra issued to the first-party client for the given nonce.
+func (c *HydraClient) VerifyIDToken(ctx context.Context, token *oauth2.Token, nonce string) (idp.IDTokenClaims, error) {
+	rawIDToken, err := idp.RawIDToken(token)
+	if err != nil {
+		return idp.IDTokenClaims{}, err
+	}
+
+	idToken, err := c.verifier.Verify(c.clientContext(ctx), rawIDToken)
+	if err != nil {
+		return idp.IDTokenClaims{}, fmt.Errorf("%w: %w", idp.ErrIDTokenSignature, err)
+	}
+
+	claims := idp.IDTokenClaims{
+		Issuer:    idToken.Issuer,
+		Subject:   idToken.Subject,
+		Audience:  idToken.Audience,
+		Nonce:     idToken.Nonce,
+		ExpiresAt: idToken.Expiry,
+	}
+
+	var extra struct {
+		SessionID string `json:"sid"`
+	}
+	if err := idToken.Claims(&extra); err != nil {
+		return idp.IDTokenClaims{}, fmt.Errorf("%w: %w", idp.ErrInvalidClaims, err)
+	}
+	claims.SessionID = extra.SessionID
+
+	if err := claims.Validate(c.issuer, c.oauthConfig.ClientID, nonce); err != nil {
+		return idp.IDTokenClaims{}, err
+	}
+
+	return claims, nil
+}
+
+// Makes the oauth2 and go-oidc packages use the client's HTTP client.
+func (c *HydraClient) clientContext(ctx context.Context) context.Context {
+	return gooidc.ClientContext(ctx, c.httpClient)
+}
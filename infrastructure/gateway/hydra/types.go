@@ -1,20 +1,41 @@
 package hydra
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
 
 // HydraClient represents a Hydra admin API client for managing OAuth2 sessions and tokens.
 type HydraClient struct {
 	adminURL   string
+	publicURL  string
 	httpClient *http.Client
+	issuer     string
+	// Verifies ID tokens Hydra issued to the first-party client, whose claims
+	// are validated separately.
+	verifier *gooidc.IDTokenVerifier
+	// Verifies back-channel logout tokens addressed to the first-party client.
+	logoutVerifier *gooidc.IDTokenVerifier
+	// First-party client the server signs users in to the frontend with
+	oauthConfig oauth2.Config
 }
 
 // HydraLoginSession represents a Hydra OAuth2 login session request.
 type HydraLoginSession struct {
 	Challenge string `json:"challenge"`
 	Client    struct {
-		ClientId string `json:"client_id"`
+		ClientId string         `json:"client_id"`
+		Metadata map[string]any `json:"metadata"`
 	} `json:"client"`
-	RequestURL     string   `json:"request_url"`
+	RequestURL string `json:"request_url"`
+	// OpenID Connect parameters of the authorization request
+	OIDCContext struct {
+		LoginHint string   `json:"login_hint"`
+		ACRValues []string `json:"acr_values"`
+	} `json:"oidc_context"`
 	Skip           bool     `json:"skip"`
 	Subject        string   `json:"subject"`
 	RequestedScope []string `json:"requested_scope"`
@@ -23,20 +44,40 @@ type HydraLoginSession struct {
 type AcceptLoginRequest struct {
 	Subject     string `json:"subject"`
 	Remember    bool   `json:"remember"`
-	RememberFor int    `json:"remember_for"`
+	RememberFor int    `json:"remember_for,omitempty"`
+	// Authentication context class reference and methods, reported to clients in
+	// the acr and amr claims of the ID token
+	ACR string   `json:"acr,omitempty"`
+	AMR []string `json:"amr,omitempty"`
 }
 
 type AcceptLoginResponse struct {
 	RedirectTo string `json:"redirect_to"`
 }
 
+// RejectRequest carries the OAuth2 error returned to the client when a login or
+// consent request is rejected.
+type RejectRequest struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type RejectResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
 // TokenInfo represents the response from Hydra's token introspection endpoint.
 type TokenInfo struct {
-	Active bool   `json:"active"`
-	Sub    string `json:"sub"`
-	Exp    int64  `json:"exp"`
-	Iat    int64  `json:"iat"`
-	Scope  string `json:"scope"`
+	Active   bool   `json:"active"`
+	Sub      string `json:"sub"`
+	Exp      int64  `json:"exp"`
+	Iat      int64  `json:"iat"`
+	Scope    string `json:"scope"`
+	ClientID string `json:"client_id"`
+	// Either access_token or refresh_token
+	TokenUse string `json:"token_use"`
+	// Claims added to the access token at consent
+	Ext map[string]any `json:"ext"`
 }
 
 type HydraGetConsentResponse struct {
@@ -44,14 +85,28 @@ type HydraGetConsentResponse struct {
 	Skip      bool   `json:"skip"`
 	Subject   string `json:"subject"`
 	Client    struct {
-		ClientId   string `json:"client_id"`
-		ClientName string `json:"client_name"`
+		ClientId   string         `json:"client_id"`
+		ClientName string         `json:"client_name"`
+		ClientURI  string         `json:"client_uri"`
+		LogoURI    string         `json:"logo_uri"`
+		Metadata   map[string]any `json:"metadata"`
 	} `json:"client"`
 	RequestURL                   string   `json:"request_url"`
 	RequestedScope               []string `json:"requested_scope"`
 	RequestedAccessTokenAudience []string `json:"requested_access_token_audience"`
 }
 
+// HydraPreviousConsentSession is a consent a subject granted to a client.
+type HydraPreviousConsentSession struct {
+	ConsentRequest           HydraGetConsentResponse `json:"consent_request"`
+	GrantScope               []string                `json:"grant_scope"`
+	GrantAccessTokenAudience []string                `json:"grant_access_token_audience"`
+	HandledAt                time.Time               `json:"handled_at"`
+	Remember                 bool                    `json:"remember"`
+	// Seconds the consent is remembered for, 0 for ever
+	RememberFor int `json:"remember_for"`
+}
+
 type HydraConsentSessionTokens struct {
 	AccessToken map[string]any `json:"access_token,omitempty"`
 	IDToken     map[string]any `json:"id_token,omitempty"`
@@ -61,10 +116,73 @@ type HydraPutAcceptConsentRequest struct {
 	GrantScope               []string                  `json:"grant_scope"`
 	GrantAccessTokenAudience []string                  `json:"grant_access_token_audience,omitempty"`
 	Remember                 bool                      `json:"remember"`
-	RememberFor              int                       `json:"remember_for"`
+	RememberFor              int                       `json:"remember_for,omitempty"`
 	Session                  HydraConsentSessionTokens `json:"session"`
 }
 
 type HydraPutAcceptConsentResponse struct {
 	RedirectTo string `json:"redirect_to"`
 }
+
+// HydraLogoutRequest represents a Hydra logout request.
+type HydraLogoutRequest struct {
+	Challenge   string `json:"challenge"`
+	Subject     string `json:"subject"`
+	SessionID   string `json:"sid"`
+	RequestURL  string `json:"request_url"`
+	RPInitiated bool   `json:"rp_initiated"`
+	Client      *struct {
+		ClientId string `json:"client_id"`
+	} `json:"client"`
+}
+
+type AcceptLogoutResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// LogoutTokenClaims are the verified claims of an OpenID Connect back-channel logout token.
+type LogoutTokenClaims struct {
+	Subject   string
+	SessionID string
+	Audience  []string
+	// Unique identifier of the token, so a replayed token can be refused
+	TokenID  string
+	IssuedAt time.Time
+	// The token is refused after this time, so its identifier only has to be
+	// remembered until then
+	ValidUntil time.Time
+}
+
+// AcceptDeviceRequest carries the user code entered for a device authorization request.
+type AcceptDeviceRequest struct {
+	UserCode string `json:"user_code"`
+}
+
+type AcceptDeviceResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// OAuth2Client is an OAuth2 client registered at Hydra.
+type OAuth2Client struct {
+	ClientID               string   `json:"client_id,omitempty"`
+	ClientName             string   `json:"client_name"`
+	ClientSecret           string   `json:"client_secret,omitempty"`
+	RedirectURIs           []string `json:"redirect_uris"`
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris"`
+	GrantTypes             []string `json:"grant_types"`
+	ResponseTypes          []string `json:"response_types"`
+	// Space separated scopes the client may request
+	Scope                   string         `json:"scope"`
+	Audience                []string       `json:"audience"`
+	TokenEndpointAuthMethod string         `json:"token_endpoint_auth_method,omitempty"`
+	Metadata                map[string]any `json:"metadata"`
+	CreatedAt               time.Time      `json:"created_at,omitzero"`
+	UpdatedAt               time.Time      `json:"updated_at,omitzero"`
+}
+
+// PatchOperation is a JSON Patch (RFC 6902) operation.
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
@@ -0,0 +1,69 @@
+package idp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Errors reported when an ID token fails verification.
+var (
+	ErrIDTokenMissing   = errors.New("id_token is missing from the token response")
+	ErrIDTokenSignature = errors.New("id_token signature is invalid")
+	ErrIDTokenExpired   = errors.New("id_token has expired")
+	ErrIssuerMismatch   = errors.New("id_token issuer does not match")
+	ErrAudienceMismatch = errors.New("id_token audience does not match")
+	ErrNonceMismatch    = errors.New("id_token nonce does not match")
+	ErrInvalidClaims    = errors.New("id_token claims are invalid")
+	ErrTenantNotAllowed = errors.New("id_token tenant is not allowed")
+)
+
+// IDTokenClaims are the verified claims of an ID token.
+type IDTokenClaims struct {
+	Issuer string
+	// Stable identifier of the user at the provider, matching UserProfile.ID.
+	Subject   string
+	Audience  []string
+	Nonce     string
+	ExpiresAt time.Time
+	// Directory tenant the user belongs to, for multi-tenant providers.
+	TenantID string
+	// The tenant is only accepted when it is linked to an organization.
+	TenantLinkRequired bool
+	// Login session at the provider the token was issued in.
+	SessionID string
+}
+
+// Checks the issuer, audience, expiry and nonce of the claims.
+func (c IDTokenClaims) Validate(issuer, clientID, nonce string) error {
+	if c.Issuer != issuer {
+		return fmt.Errorf("%w: expected %q, got %q", ErrIssuerMismatch, issuer, c.Issuer)
+	}
+
+	if !slices.Contains(c.Audience, clientID) {
+		return fmt.Errorf("%w: %q not in %v", ErrAudienceMismatch, clientID, c.Audience)
+	}
+
+	if !c.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: expired at %s", ErrIDTokenExpired, c.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if nonce == "" || c.Nonce != nonce {
+		return ErrNonceMismatch
+	}
+
+	return nil
+}
+
+// Returns the raw ID token of an OAuth2 token response.
+func RawIDToken(token *oauth2.Token) (string, error) {
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return "", ErrIDTokenMissing
+	}
+
+	return rawIDToken, nil
+}
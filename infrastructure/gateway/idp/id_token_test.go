@@ -0,0 +1,40 @@
+package idp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIDTokenClaimsValidateNonce(t *testing.T) {
+	claims := IDTokenClaims{
+		Issuer:    "https://issuer.example.com",
+		Subject:   "user-1",
+		Audience:  []string{"conformitea"},
+		Nonce:     "nonce-value",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name       string
+		tokenNonce string
+		nonce      string
+		wantErr    error
+	}{
+		{name: "same nonce", tokenNonce: "nonce-value", nonce: "nonce-value"},
+		{name: "other nonce", tokenNonce: "nonce-value", nonce: "another-nonce", wantErr: ErrNonceMismatch},
+		{name: "no nonce in the token", tokenNonce: "", nonce: "nonce-value", wantErr: ErrNonceMismatch},
+		{name: "no nonce expected", tokenNonce: "", nonce: "", wantErr: ErrNonceMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := claims
+			c.Nonce = tt.tokenNonce
+
+			if err := c.Validate(claims.Issuer, "conformitea", tt.nonce); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Package idp defines the contract shared by all upstream identity providers.
+package idp
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+)
+
+// IdentityProvider is an upstream OAuth2 identity provider users can sign in with.
+type IdentityProvider interface {
+	// Name under which the provider is registered, e.g. "microsoft".
+	Name() string
+	// Creates the authorization URL the user is redirected to.
+	GenerateAuthURL(state, nonce string) (string, error)
+	// Exchanges an authorization code for an OAuth2 token.
+	ExchangeCodeForToken(ctx context.Context, code string) (*oauth2.Token, error)
+	// Verifies the signature and claims of the ID token returned with the token,
+	// including that it was issued for the given nonce.
+	VerifyIDToken(ctx context.Context, token *oauth2.Token, nonce string) (IDTokenClaims, error)
+	// Retrieves the authenticated user's profile.
+	GetUserProfile(ctx context.Context, token *oauth2.Token) (UserProfile, error)
+}
+
+// UserProfile is the provider-independent profile of an authenticated user.
+type UserProfile struct {
+	// Identifier of the user in the provider's profile API. Identities are keyed
+	// on the subject of the ID token, which it does not always equal.
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	GivenName   string `json:"given_name"`
+	Surname     string `json:"surname"`
+	Email       string `json:"email"`
+	// The provider asserted that the user owns Email. Only verified emails are
+	// matched against existing users.
+	EmailVerified bool `json:"email_verified"`
+}
@@ -0,0 +1,52 @@
+package idp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrProviderNotFound = errors.New("identity provider not found")
+
+// Registry holds the configured identity providers by name.
+type Registry struct {
+	providers map[string]IdentityProvider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]IdentityProvider),
+	}
+}
+
+// Adds a provider to the registry. Provider names must be unique.
+func (r *Registry) Register(p IdentityProvider) error {
+	if _, exists := r.providers[p.Name()]; exists {
+		return fmt.Errorf("identity provider %q is already registered", p.Name())
+	}
+
+	r.providers[p.Name()] = p
+
+	return nil
+}
+
+// Returns the provider registered under name.
+func (r *Registry) Get(name string) (IdentityProvider, error) {
+	p, exists := r.providers[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, name)
+	}
+
+	return p, nil
+}
+
+// Returns the sorted names of all registered providers.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
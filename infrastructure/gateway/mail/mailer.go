@@ -0,0 +1,121 @@
+// Package mail sends the emails of ConformiTea, such as organization invitations.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"conformitea/infrastructure/config"
+
+	"go.uber.org/zap"
+)
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// Returns a mailer sending through the configured SMTP server, or writing emails
+// to the log when no server is configured.
+func Initialize(mailConfigValues config.MailConfig, l *zap.Logger) (Mailer, error) {
+	if err := mailConfigValues.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid mail configuration: %w", err)
+	}
+
+	if mailConfigValues.Host == "" {
+		return &logMailer{logger: l}, nil
+	}
+
+	from, err := mail.ParseAddress(mailConfigValues.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail.from address: %w", err)
+	}
+
+	m := &smtpMailer{
+		address: net.JoinHostPort(mailConfigValues.Host, strconv.Itoa(mailConfigValues.Port)),
+		from:    from,
+	}
+
+	if mailConfigValues.Username != "" {
+		m.auth = smtp.PlainAuth("", mailConfigValues.Username, mailConfigValues.Password, mailConfigValues.Host)
+	}
+
+	return m, nil
+}
+
+// smtpMailer sends emails through an SMTP server, upgrading the connection with
+// STARTTLS when the server offers it.
+type smtpMailer struct {
+	address string
+	from    *mail.Address
+	auth    smtp.Auth
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message Message) error {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", message.To, err)
+	}
+
+	data, err := compose(m.from, to, message)
+	if err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(m.address, m.auth, m.from.Address, []string{to.Address}, data); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// logMailer writes emails to the log instead of sending them.
+type logMailer struct {
+	logger *zap.Logger
+}
+
+func (m *logMailer) Send(ctx context.Context, message Message) error {
+	m.logger.Info("email not sent, no SMTP server is configured",
+		zap.String("to", message.To),
+		zap.String("subject", message.Subject),
+		zap.String("body", message.Body))
+
+	return nil
+}
+
+// Returns the RFC 5322 message of a plain text email.
+func compose(from, to *mail.Address, message Message) ([]byte, error) {
+	// Line breaks would let the subject add headers of its own
+	if strings.ContainsAny(message.Subject, "\r\n") {
+		return nil, errors.New("email subject contains a line break")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+
+	return b.Bytes(), nil
+}
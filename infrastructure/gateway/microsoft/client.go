@@ -5,29 +5,77 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"conformitea/infrastructure/config"
+	"conformitea/infrastructure/gateway/idp"
 
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/google/uuid"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/microsoft"
 )
 
-func Initialize(msConfigValues config.MicrosoftOAuthConfig) (*OAuthClient, error) {
-	if err := msConfigValues.Validate(); err != nil {
+// Signing keys of the Microsoft identity platform, shared by all tenants.
+const jwksURL = "https://login.microsoftonline.com/common/discovery/v2.0/keys"
+
+var _ idp.IdentityProvider = (*OAuthClient)(nil)
+
+func Initialize(name string, msConfigValues config.OAuthProviderConfig) (*OAuthClient, error) {
+	if err := msConfigValues.Validate(name); err != nil {
 		return nil, fmt.Errorf("invalid Microsoft OAuth configuration: %w", err)
 	}
 
-	client := &OAuthClient{
+	return newClient(name, msConfigValues, gooidc.NewRemoteKeySet(context.Background(), jwksURL)), nil
+}
+
+// Creates a client checking ID token signatures against keySet, so the client can
+// be tested with local keys.
+func newClient(name string, msConfigValues config.OAuthProviderConfig, keySet gooidc.KeySet) *OAuthClient {
+	tenants := make([]string, 0, len(msConfigValues.Tenants))
+	for _, tenant := range msConfigValues.Tenants {
+		tenants = append(tenants, strings.ToLower(tenant))
+	}
+
+	return &OAuthClient{
+		name: name,
 		config: oauth2.Config{
 			ClientID:     msConfigValues.ClientID,
 			ClientSecret: msConfigValues.ClientSecret,
 			RedirectURL:  msConfigValues.RedirectURL,
 			Scopes:       msConfigValues.Scopes,
-			Endpoint:     microsoft.AzureADEndpoint("common"),
+			Endpoint:     microsoft.AzureADEndpoint(authority(msConfigValues.EntraTenantMode(), tenants)),
 		},
+		// The issuer differs per tenant, so only the signature is checked by the
+		// verifier and the remaining claims are validated against the token's tenant.
+		verifier: gooidc.NewVerifier("", keySet, &gooidc.Config{
+			SkipClientIDCheck: true,
+			SkipIssuerCheck:   true,
+			SkipExpiryCheck:   true,
+		}),
+		tenantMode: msConfigValues.EntraTenantMode(),
+		tenants:    tenants,
+	}
+}
+
+// Returns the authority users sign in at. Restricted modes use the work and
+// school account endpoint, which personal accounts cannot sign in at, or the
+// tenant's own endpoint.
+func authority(tenantMode string, tenants []string) string {
+	switch tenantMode {
+	case config.TenantModeSingle:
+		return tenants[0]
+	case config.TenantModeAllowlist, config.TenantModeMulti:
+		return "organizations"
+	default:
+		return "common"
 	}
+}
 
-	return client, nil
+// Returns the name the provider is registered under.
+func (c *OAuthClient) Name() string {
+	return c.name
 }
 
 // Creates a Microsoft OAuth2 authorization URL with state and nonce parameters.
@@ -56,25 +104,103 @@ func (c *OAuthClient) ExchangeCodeForToken(ctx context.Context, code string) (*o
 	return token, nil
 }
 
+// Verifies the ID token against Microsoft's signing keys and checks that it was
+// issued by the user's tenant, for this application and for the given nonce. The
+// returned subject is the user's object ID. For personal accounts it differs from
+// their Graph ID, so the profile is never matched against it.
+func (c *OAuthClient) VerifyIDToken(ctx context.Context, token *oauth2.Token, nonce string) (idp.IDTokenClaims, error) {
+	rawIDToken, err := idp.RawIDToken(token)
+	if err != nil {
+		return idp.IDTokenClaims{}, err
+	}
+
+	idToken, err := c.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return idp.IDTokenClaims{}, fmt.Errorf("%w: %w", idp.ErrIDTokenSignature, err)
+	}
+
+	var msClaims MicrosoftIDTokenClaims
+	if err := idToken.Claims(&msClaims); err != nil {
+		return idp.IDTokenClaims{}, fmt.Errorf("%w: %w", idp.ErrInvalidClaims, err)
+	}
+
+	if _, err := uuid.Parse(msClaims.TenantID); err != nil {
+		return idp.IDTokenClaims{}, fmt.Errorf("%w: tid %q is not a tenant ID", idp.ErrInvalidClaims, msClaims.TenantID)
+	}
+
+	if _, err := uuid.Parse(msClaims.ObjectID); err != nil {
+		return idp.IDTokenClaims{}, fmt.Errorf("%w: oid %q is not an object ID", idp.ErrInvalidClaims, msClaims.ObjectID)
+	}
+
+	claims := idp.IDTokenClaims{
+		Issuer:    idToken.Issuer,
+		Subject:   strings.ToLower(msClaims.ObjectID),
+		Audience:  idToken.Audience,
+		Nonce:     idToken.Nonce,
+		ExpiresAt: idToken.Expiry,
+		TenantID:  strings.ToLower(msClaims.TenantID),
+	}
+
+	issuer := fmt.Sprintf("https://login.microsoftonline.com/%s/v2.0", msClaims.TenantID)
+	if err := claims.Validate(issuer, c.config.ClientID, nonce); err != nil {
+		return idp.IDTokenClaims{}, err
+	}
+
+	if err := c.checkTenant(&claims); err != nil {
+		return idp.IDTokenClaims{}, err
+	}
+
+	return claims, nil
+}
+
+// Checks the tenant of the claims against the tenant mode. In multi tenant mode,
+// tenants other than the configured ones are left to be checked against the
+// tenants linked to organizations.
+func (c *OAuthClient) checkTenant(claims *idp.IDTokenClaims) error {
+	switch c.tenantMode {
+	case config.TenantModeSingle, config.TenantModeAllowlist:
+		if !slices.Contains(c.tenants, claims.TenantID) {
+			return fmt.Errorf("%w: %s", idp.ErrTenantNotAllowed, claims.TenantID)
+		}
+	case config.TenantModeMulti:
+		claims.TenantLinkRequired = !slices.Contains(c.tenants, claims.TenantID)
+	}
+
+	return nil
+}
+
 // Retrieves the user's profile information from Microsoft Graph API.
-func (c *OAuthClient) GetUserProfile(ctx context.Context, token *oauth2.Token) (MicrosoftUserProfile, error) {
+func (c *OAuthClient) GetUserProfile(ctx context.Context, token *oauth2.Token) (idp.UserProfile, error) {
 	client := c.config.Client(ctx, token)
 
 	resp, err := client.Get("https://graph.microsoft.com/v1.0/me")
 	if err != nil {
-		return MicrosoftUserProfile{}, fmt.Errorf("failed to get user profile: %w", err)
+		return idp.UserProfile{}, fmt.Errorf("failed to get user profile: %w", err)
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return MicrosoftUserProfile{}, fmt.Errorf("microsoft Graph API error: status %d", resp.StatusCode)
+		return idp.UserProfile{}, fmt.Errorf("microsoft Graph API error: status %d", resp.StatusCode)
 	}
 
 	var profile MicrosoftUserProfile
 	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
-		return MicrosoftUserProfile{}, fmt.Errorf("failed to decode user profile: %w", err)
+		return idp.UserProfile{}, fmt.Errorf("failed to decode user profile: %w", err)
+	}
+
+	// Tenant administrators set mail and the user principal name to any address,
+	// so neither is a verified email
+	email := profile.Mail
+	if email == "" {
+		email = profile.UserPrincipalName
 	}
 
-	return profile, nil
+	return idp.UserProfile{
+		ID:          profile.ID,
+		DisplayName: profile.DisplayName,
+		GivenName:   profile.GivenName,
+		Surname:     profile.Surname,
+		Email:       email,
+	}, nil
 }
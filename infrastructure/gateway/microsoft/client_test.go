@@ -0,0 +1,159 @@
+package microsoft
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"conformitea/infrastructure/config"
+	"conformitea/infrastructure/gateway/idp"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+const (
+	testClientID = "00000000-0000-0000-0000-00000000c11e"
+	testNonce    = "nonce-value"
+	// Tenant of personal Microsoft accounts
+	consumersTenant = "9188040d-6c67-4c5b-b112-36a304b66dad"
+	workTenant      = "72f988bf-86f1-41af-91ab-2d7cd011db47"
+)
+
+// Returns a client of the given tenant mode trusting the public key of key.
+func testClient(t *testing.T, key *rsa.PrivateKey, tenantMode string, tenants ...string) *OAuthClient {
+	t.Helper()
+
+	return newClient("microsoft", config.OAuthProviderConfig{
+		Type:       "microsoft",
+		ClientID:   testClientID,
+		TenantMode: tenantMode,
+		Tenants:    tenants,
+	}, &gooidc.StaticKeySet{PublicKeys: []crypto.PublicKey{&key.PublicKey}})
+}
+
+// Returns valid claims of an ID token the tenant issued to the test client.
+func tenantClaims(tenant, objectID string) map[string]any {
+	return map[string]any{
+		"iss":   "https://login.microsoftonline.com/" + tenant + "/v2.0",
+		"sub":   "AAAAAAAAAAAAAAAAAAAAAIkzqFVrSaSaFHy782bbtaQ",
+		"aud":   testClientID,
+		"nonce": testNonce,
+		"tid":   tenant,
+		"oid":   objectID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// Returns a token response holding an ID token with claims signed by key.
+func signedToken(t *testing.T, key *rsa.PrivateKey, claims map[string]any) *oauth2.Token {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("failed to encode claims: %v", err)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	signingInput := encode(header) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	token := &oauth2.Token{AccessToken: "access-token", TokenType: "Bearer"}
+
+	return token.WithExtra(map[string]any{"id_token": signingInput + "." + encode(signature)})
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		client      *OAuthClient
+		claims      map[string]any
+		change      func(claims map[string]any)
+		wantErr     error
+		wantSubject string
+		wantTenant  string
+	}{
+		{
+			name:        "work account",
+			client:      testClient(t, key, config.TenantModeCommon),
+			claims:      tenantClaims(workTenant, "A1B2C3D4-0000-4000-8000-000000000001"),
+			wantSubject: "a1b2c3d4-0000-4000-8000-000000000001",
+			wantTenant:  workTenant,
+		},
+		{
+			// The Graph ID of personal accounts is not their object ID, the subject
+			// must be taken from the token alone
+			name:        "personal account",
+			client:      testClient(t, key, config.TenantModeCommon),
+			claims:      tenantClaims(consumersTenant, "00000000-0000-0000-66F3-3332ECA7EA81"),
+			wantSubject: "00000000-0000-0000-66f3-3332eca7ea81",
+			wantTenant:  consumersTenant,
+		},
+		{
+			name:    "personal account in single tenant mode",
+			client:  testClient(t, key, config.TenantModeSingle, workTenant),
+			claims:  tenantClaims(consumersTenant, "00000000-0000-0000-66f3-3332eca7ea81"),
+			wantErr: idp.ErrTenantNotAllowed,
+		},
+		{
+			name:   "issuer of another tenant",
+			client: testClient(t, key, config.TenantModeCommon),
+			claims: tenantClaims(consumersTenant, "00000000-0000-0000-66f3-3332eca7ea81"),
+			change: func(claims map[string]any) {
+				claims["iss"] = "https://login.microsoftonline.com/" + workTenant + "/v2.0"
+			},
+			wantErr: idp.ErrIssuerMismatch,
+		},
+		{
+			name:    "object ID that is not a UUID",
+			client:  testClient(t, key, config.TenantModeCommon),
+			claims:  tenantClaims(consumersTenant, "66f33332eca7ea81"),
+			wantErr: idp.ErrInvalidClaims,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.change != nil {
+				tt.change(tt.claims)
+			}
+
+			claims, err := tt.client.VerifyIDToken(context.Background(), signedToken(t, key, tt.claims), testNonce)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyIDToken() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if claims.Subject != tt.wantSubject || claims.TenantID != tt.wantTenant {
+				t.Errorf("VerifyIDToken() subject %q tenant %q, want %q tenant %q",
+					claims.Subject, claims.TenantID, tt.wantSubject, tt.wantTenant)
+			}
+		})
+	}
+}
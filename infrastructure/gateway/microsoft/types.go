@@ -1,6 +1,9 @@
 package microsoft
 
-import "golang.org/x/oauth2"
+import (
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
 
 // OAuth2 configuration.
 type OAuthConfig struct {
@@ -12,7 +15,12 @@ type OAuthConfig struct {
 
 // OAuth2 client for authentication flows.
 type OAuthClient struct {
-	config oauth2.Config
+	name     string
+	config   oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+	// Tenant mode and the tenants it accepts, lowercase
+	tenantMode string
+	tenants    []string
 }
 
 // Microsoft user profile from Graph API.
@@ -24,3 +32,9 @@ type MicrosoftUserProfile struct {
 	UserPrincipalName string `json:"userPrincipalName"`
 	Mail              string `json:"mail"`
 }
+
+// Microsoft-specific claims of an ID token.
+type MicrosoftIDTokenClaims struct {
+	TenantID string `json:"tid"`
+	ObjectID string `json:"oid"`
+}
@@ -0,0 +1,186 @@
+// Package oidc provides a generic OpenID Connect identity provider for issuers such
+// as Okta, Auth0, Keycloak and Ping.
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"conformitea/infrastructure/config"
+	"conformitea/infrastructure/gateway/idp"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var _ idp.IdentityProvider = (*Client)(nil)
+
+func Initialize(name string, oidcConfigValues config.OAuthProviderConfig) (*Client, error) {
+	if err := oidcConfigValues.Validate(name); err != nil {
+		return nil, fmt.Errorf("invalid OpenID Connect configuration: %w", err)
+	}
+
+	return newClient(context.Background(), name, oidcConfigValues, &http.Client{
+		Timeout: 30 * time.Second,
+	})
+}
+
+// Reads the issuer's discovery document using httpClient for every request made to
+// the issuer, so the client can be pointed at a local test server.
+func newClient(ctx context.Context, name string, oidcConfigValues config.OAuthProviderConfig, httpClient *http.Client) (*Client, error) {
+	// The key set fetched through the provider keeps using this context to
+	// refresh the cached JWKS, so it must outlive the call.
+	ctx = gooidc.ClientContext(ctx, httpClient)
+
+	provider, err := gooidc.NewProvider(ctx, oidcConfigValues.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read discovery document of %s: %w", oidcConfigValues.Issuer, err)
+	}
+
+	var discovery discoveryDocument
+	if err := provider.Claims(&discovery); err != nil {
+		return nil, fmt.Errorf("failed to decode discovery document of %s: %w", oidcConfigValues.Issuer, err)
+	}
+
+	return &Client{
+		name:       name,
+		issuer:     discovery.Issuer,
+		httpClient: httpClient,
+		provider:   provider,
+		// Only the signature is checked by the verifier so that every failed claim
+		// check is reported with its own error.
+		verifier: provider.Verifier(&gooidc.Config{
+			SkipClientIDCheck: true,
+			SkipIssuerCheck:   true,
+			SkipExpiryCheck:   true,
+		}),
+		config: oauth2.Config{
+			ClientID:     oidcConfigValues.ClientID,
+			ClientSecret: oidcConfigValues.ClientSecret,
+			RedirectURL:  oidcConfigValues.RedirectURL,
+			Scopes:       oidcConfigValues.Scopes,
+			Endpoint:     provider.Endpoint(),
+		},
+	}, nil
+}
+
+// Returns the name the provider is registered under.
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Creates an authorization URL with state and nonce parameters.
+func (c *Client) GenerateAuthURL(state, nonce string) (string, error) {
+	if state == "" {
+		return "", fmt.Errorf("state parameter cannot be empty")
+	}
+
+	if nonce == "" {
+		return "", fmt.Errorf("nonce parameter cannot be empty")
+	}
+
+	return c.config.AuthCodeURL(state, gooidc.Nonce(nonce)), nil
+}
+
+// Exchanges an authorization code for an OAuth2 token.
+func (c *Client) ExchangeCodeForToken(ctx context.Context, code string) (*oauth2.Token, error) {
+	token, err := c.config.Exchange(c.clientContext(ctx), code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	return token, nil
+}
+
+// Verifies the ID token against the issuer's cached JWKS and checks that it was
+// issued by the configured issuer, for this client and for the given nonce.
+func (c *Client) VerifyIDToken(ctx context.Context, token *oauth2.Token, nonce string) (idp.IDTokenClaims, error) {
+	idToken, err := c.verifyIDToken(c.clientContext(ctx), token)
+	if err != nil {
+		return idp.IDTokenClaims{}, err
+	}
+
+	claims := idp.IDTokenClaims{
+		Issuer:    idToken.Issuer,
+		Subject:   idToken.Subject,
+		Audience:  idToken.Audience,
+		Nonce:     idToken.Nonce,
+		ExpiresAt: idToken.Expiry,
+	}
+
+	if err := claims.Validate(c.issuer, c.config.ClientID, nonce); err != nil {
+		return idp.IDTokenClaims{}, err
+	}
+
+	return claims, nil
+}
+
+// Builds the user profile from the ID token, falling back to the userinfo endpoint
+// when the ID token carries no email.
+func (c *Client) GetUserProfile(ctx context.Context, token *oauth2.Token) (idp.UserProfile, error) {
+	ctx = c.clientContext(ctx)
+
+	idToken, err := c.verifyIDToken(ctx, token)
+	if err != nil {
+		return idp.UserProfile{}, err
+	}
+
+	var claims standardClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return idp.UserProfile{}, fmt.Errorf("failed to decode id_token claims: %w", err)
+	}
+
+	if claims.Email == "" && c.provider.UserInfoEndpoint() != "" {
+		userInfo, err := c.provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			return idp.UserProfile{}, fmt.Errorf("failed to get userinfo: %w", err)
+		}
+
+		if userInfo.Subject != idToken.Subject {
+			return idp.UserProfile{}, errors.New("userinfo subject does not match id_token subject")
+		}
+
+		if err := userInfo.Claims(&claims); err != nil {
+			return idp.UserProfile{}, fmt.Errorf("failed to decode userinfo claims: %w", err)
+		}
+	}
+
+	// The preferred username is chosen by the user, it is only used as email to
+	// show and is never verified
+	email := claims.Email
+	if email == "" {
+		email = claims.PreferredUsername
+	}
+
+	return idp.UserProfile{
+		ID:            idToken.Subject,
+		DisplayName:   claims.Name,
+		GivenName:     claims.GivenName,
+		Surname:       claims.FamilyName,
+		Email:         email,
+		EmailVerified: claims.Email != "" && bool(claims.EmailVerified),
+	}, nil
+}
+
+// Checks the signature of the token's ID token.
+func (c *Client) verifyIDToken(ctx context.Context, token *oauth2.Token) (*gooidc.IDToken, error) {
+	rawIDToken, err := idp.RawIDToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := c.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", idp.ErrIDTokenSignature, err)
+	}
+
+	return idToken, nil
+}
+
+// Makes the oauth2 and go-oidc packages use the client's HTTP client.
+func (c *Client) clientContext(ctx context.Context) context.Context {
+	return gooidc.ClientContext(ctx, c.httpClient)
+}
@@ -0,0 +1,331 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"conformitea/infrastructure/config"
+	"conformitea/infrastructure/gateway/idp"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	testClientID = "conformitea"
+	testKeyID    = "test-key"
+	testNonce    = "nonce-value"
+)
+
+// Issuer serving a discovery document and the key set ID tokens are signed with.
+type testIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	issuer := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		url := issuer.server.URL
+		writeJSON(w, map[string]any{
+			"issuer":                                url,
+			"authorization_endpoint":                url + "/authorize",
+			"token_endpoint":                        url + "/token",
+			"jwks_uri":                              url + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"keys": []map[string]any{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": testKeyID,
+				"n":   encodeSegment(key.N.Bytes()),
+				"e":   encodeSegment(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+// Returns a client of the issuer.
+func (i *testIssuer) client(t *testing.T) *Client {
+	t.Helper()
+
+	client, err := newClient(context.Background(), "test", config.OAuthProviderConfig{
+		Type:         "oidc",
+		Issuer:       i.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/auth/callback",
+		Scopes:       []string{"openid", "email"},
+	}, i.server.Client())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	return client
+}
+
+// Returns valid claims of an ID token of the issuer, for the test client.
+func (i *testIssuer) claims() map[string]any {
+	return map[string]any{
+		"iss":   i.server.URL,
+		"sub":   "user-1",
+		"aud":   testClientID,
+		"nonce": testNonce,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// Returns a token response holding an ID token with claims signed by key.
+func signedToken(t *testing.T, key *rsa.PrivateKey, claims map[string]any) *oauth2.Token {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": testKeyID})
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("failed to encode claims: %v", err)
+	}
+
+	signingInput := encodeSegment(header) + "." + encodeSegment(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	token := &oauth2.Token{AccessToken: "access-token", TokenType: "Bearer"}
+
+	return token.WithExtra(map[string]any{"id_token": signingInput + "." + encodeSegment(signature)})
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+	client := issuer.client(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     *rsa.PrivateKey
+		change  func(claims map[string]any)
+		nonce   string
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			key:   issuer.key,
+			nonce: testNonce,
+		},
+		{
+			name:    "signed by another key",
+			key:     otherKey,
+			nonce:   testNonce,
+			wantErr: idp.ErrIDTokenSignature,
+		},
+		{
+			name:    "other issuer",
+			key:     issuer.key,
+			change:  func(claims map[string]any) { claims["iss"] = "https://issuer.example.com" },
+			nonce:   testNonce,
+			wantErr: idp.ErrIssuerMismatch,
+		},
+		{
+			name:    "other audience",
+			key:     issuer.key,
+			change:  func(claims map[string]any) { claims["aud"] = "another-client" },
+			nonce:   testNonce,
+			wantErr: idp.ErrAudienceMismatch,
+		},
+		{
+			name:    "expired",
+			key:     issuer.key,
+			change:  func(claims map[string]any) { claims["exp"] = time.Now().Add(-time.Minute).Unix() },
+			nonce:   testNonce,
+			wantErr: idp.ErrIDTokenExpired,
+		},
+		{
+			name:    "other nonce",
+			key:     issuer.key,
+			nonce:   "another-nonce",
+			wantErr: idp.ErrNonceMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.claims()
+			if tt.change != nil {
+				tt.change(claims)
+			}
+
+			got, err := client.VerifyIDToken(context.Background(), signedToken(t, tt.key, claims), tt.nonce)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyIDToken() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && got.Subject != "user-1" {
+				t.Errorf("VerifyIDToken() subject = %q, want %q", got.Subject, "user-1")
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenMissing(t *testing.T) {
+	client := newTestIssuer(t).client(t)
+
+	_, err := client.VerifyIDToken(context.Background(), &oauth2.Token{AccessToken: "access-token"}, testNonce)
+	if !errors.Is(err, idp.ErrIDTokenMissing) {
+		t.Fatalf("VerifyIDToken() error = %v, want %v", err, idp.ErrIDTokenMissing)
+	}
+}
+
+func TestGetUserProfile(t *testing.T) {
+	issuer := newTestIssuer(t)
+	client := issuer.client(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	claims := issuer.claims()
+	claims["email"] = "ada@example.com"
+	claims["name"] = "Ada Lovelace"
+
+	tests := []struct {
+		name    string
+		token   *oauth2.Token
+		wantErr bool
+	}{
+		{
+			name:  "signed by the issuer",
+			token: signedToken(t, issuer.key, claims),
+		},
+		{
+			name:    "signed by another key",
+			token:   signedToken(t, otherKey, claims),
+			wantErr: true,
+		},
+		{
+			name:    "without id_token",
+			token:   &oauth2.Token{AccessToken: "access-token"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := client.GetUserProfile(context.Background(), tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetUserProfile() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if profile.ID != "user-1" || profile.Email != "ada@example.com" || profile.DisplayName != "Ada Lovelace" {
+				t.Errorf("GetUserProfile() = %+v, want the subject, email and name of the ID token", profile)
+			}
+		})
+	}
+}
+
+func TestGetUserProfileEmailVerified(t *testing.T) {
+	issuer := newTestIssuer(t)
+	client := issuer.client(t)
+
+	tests := []struct {
+		name         string
+		claims       map[string]any
+		wantEmail    string
+		wantVerified bool
+	}{
+		{
+			name:         "verified",
+			claims:       map[string]any{"email": "ada@example.com", "email_verified": true},
+			wantEmail:    "ada@example.com",
+			wantVerified: true,
+		},
+		{
+			name:         "verified as a string",
+			claims:       map[string]any{"email": "ada@example.com", "email_verified": "true"},
+			wantEmail:    "ada@example.com",
+			wantVerified: true,
+		},
+		{
+			name:      "unverified",
+			claims:    map[string]any{"email": "ada@example.com"},
+			wantEmail: "ada@example.com",
+		},
+		{
+			name:      "preferred username",
+			claims:    map[string]any{"preferred_username": "ada@example.com", "email_verified": true},
+			wantEmail: "ada@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.claims()
+			for name, value := range tt.claims {
+				claims[name] = value
+			}
+
+			profile, err := client.GetUserProfile(context.Background(), signedToken(t, issuer.key, claims))
+			if err != nil {
+				t.Fatalf("GetUserProfile() error = %v", err)
+			}
+
+			if profile.Email != tt.wantEmail || profile.EmailVerified != tt.wantVerified {
+				t.Errorf("GetUserProfile() email = %q verified %t, want %q verified %t",
+					profile.Email, profile.EmailVerified, tt.wantEmail, tt.wantVerified)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Client is a generic OpenID Connect identity provider configured from the
+// issuer's discovery document.
+type Client struct {
+	name       string
+	issuer     string
+	httpClient *http.Client
+	provider   *gooidc.Provider
+	verifier   *gooidc.IDTokenVerifier
+	config     oauth2.Config
+}
+
+// Standard OpenID Connect claims mapped into the user profile.
+type standardClaims struct {
+	Subject           string    `json:"sub"`
+	Name              string    `json:"name"`
+	GivenName         string    `json:"given_name"`
+	FamilyName        string    `json:"family_name"`
+	PreferredUsername string    `json:"preferred_username"`
+	Email             string    `json:"email"`
+	EmailVerified     claimBool `json:"email_verified"`
+}
+
+// Boolean claim some providers send as a string, such as email_verified.
+type claimBool bool
+
+func (b *claimBool) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case bool:
+		*b = claimBool(v)
+	case string:
+		*b = claimBool(v == "true")
+	default:
+		*b = false
+	}
+
+	return nil
+}
+
+// Fields of the discovery document not exposed by go-oidc.
+type discoveryDocument struct {
+	Issuer string `json:"issuer"`
+}
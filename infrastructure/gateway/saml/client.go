@@ -0,0 +1,417 @@
+// Package saml implements the SAML 2.0 service provider organizations sign in to
+// with their own identity provider.
+package saml
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"conformitea/infrastructure/config"
+
+	"github.com/crewjam/saml"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// Largest identity provider metadata document fetched from a URL.
+const maxMetadataSize = 1 << 20
+
+func Initialize(samlConfigValues config.SAMLConfig) (*ServiceProvider, error) {
+	if err := samlConfigValues.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SAML configuration: %w", err)
+	}
+
+	keyPair, err := tls.LoadX509KeyPair(samlConfigValues.CertificateFile, samlConfigValues.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SAML certificate: %w", err)
+	}
+
+	key, ok := keyPair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("SAML private key must be an RSA key")
+	}
+
+	certificate, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SAML certificate: %w", err)
+	}
+
+	return &ServiceProvider{
+		baseURL:     strings.TrimSuffix(samlConfigValues.BaseURL, "/"),
+		key:         key,
+		certificate: certificate,
+		httpClient:  metadataClient(),
+	}, nil
+}
+
+// Returns the client metadata is fetched with. Organization admins choose the
+// metadata URL, so the client only connects to public addresses over HTTPS, and
+// follows no redirect elsewhere, to keep them from reaching internal services.
+func metadataClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: refuseInternalAddress,
+	}
+
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			// A proxy would connect to the address on the client's behalf
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirected to %q", ErrInvalidMetadata, req.URL.String())
+			}
+			if len(via) >= 5 {
+				return fmt.Errorf("%w: too many redirects", ErrInvalidMetadata)
+			}
+
+			return nil
+		},
+	}
+}
+
+// Refuses connections to loopback, private, link-local and other addresses that
+// are not on the public internet. It runs once the host name is resolved, so a
+// name resolving to an internal address is refused too.
+func refuseInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: invalid address %q", ErrInvalidMetadata, address)
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: invalid address %q", ErrInvalidMetadata, address)
+	}
+	ip = ip.Unmap()
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s is not a public address", ErrInvalidMetadata, ip)
+	}
+
+	return nil
+}
+
+// Carrier-grade NAT addresses, which netip does not count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Returns the entity ID of the organization's service provider, which is also the
+// URL its metadata is served at.
+func (s *ServiceProvider) EntityID(organizationID string) string {
+	return fmt.Sprintf("%s/auth/saml/%s/metadata", s.baseURL, organizationID)
+}
+
+// Returns the URL of the organization's assertion consumer service.
+func (s *ServiceProvider) ACSURL(organizationID string) string {
+	return fmt.Sprintf("%s/auth/saml/%s/acs", s.baseURL, organizationID)
+}
+
+// Returns the metadata of the organization's service provider, to be registered
+// at the identity provider.
+func (s *ServiceProvider) Metadata(organizationID string) ([]byte, error) {
+	sp, err := s.serviceProvider(organizationID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor := sp.Metadata()
+
+	// Responses are only accepted with the HTTP-POST binding
+	for i, d := range descriptor.SPSSODescriptors {
+		acs := d.AssertionConsumerServices[:0]
+		for _, endpoint := range d.AssertionConsumerServices {
+			if endpoint.Binding == saml.HTTPPostBinding {
+				acs = append(acs, endpoint)
+			}
+		}
+		descriptor.SPSSODescriptors[i].AssertionConsumerServices = acs
+	}
+
+	data, err := xml.MarshalIndent(descriptor, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// Downloads identity provider metadata published at metadataURL, which must be
+// an https URL of a public host.
+func (s *ServiceProvider) FetchMetadata(ctx context.Context, metadataURL string) ([]byte, error) {
+	u, err := url.Parse(metadataURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q is not an https URL", ErrInvalidMetadata, metadataURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s returned status %d", ErrInvalidMetadata, metadataURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	if len(data) > maxMetadataSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidMetadata, maxMetadataSize)
+	}
+
+	return data, nil
+}
+
+// Checks that metadata describes an identity provider the service provider can
+// sign in with: one that accepts HTTP-Redirect authentication requests and
+// publishes a certificate its assertions are signed with.
+func (s *ServiceProvider) ParseMetadata(metadata []byte) (IdentityProviderMetadata, error) {
+	descriptor, err := parseMetadata(metadata)
+	if err != nil {
+		return IdentityProviderMetadata{}, err
+	}
+
+	sp := saml.ServiceProvider{IDPMetadata: descriptor}
+
+	ssoURL := sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+	if ssoURL == "" {
+		return IdentityProviderMetadata{}, fmt.Errorf("%w: no single sign-on service with the HTTP-Redirect binding", ErrInvalidMetadata)
+	}
+
+	if !hasSigningCertificate(descriptor) {
+		return IdentityProviderMetadata{}, fmt.Errorf("%w: no signing certificate", ErrInvalidMetadata)
+	}
+
+	return IdentityProviderMetadata{
+		EntityID: descriptor.EntityID,
+		SSOURL:   ssoURL,
+	}, nil
+}
+
+// Creates an authentication request to the identity provider described by
+// metadata, asking it to post the response to the organization's assertion
+// consumer service.
+func (s *ServiceProvider) AuthnRequest(organizationID string, metadata []byte) (AuthnRequest, error) {
+	sp, err := s.identityProviderServiceProvider(organizationID, metadata)
+	if err != nil {
+		return AuthnRequest{}, err
+	}
+
+	req, err := sp.MakeAuthenticationRequest(sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		return AuthnRequest{}, fmt.Errorf("failed to create authentication request: %w", err)
+	}
+
+	redirectURL, err := req.Redirect(req.ID, sp)
+	if err != nil {
+		return AuthnRequest{}, fmt.Errorf("failed to sign authentication request: %w", err)
+	}
+
+	return AuthnRequest{
+		ID:  req.ID,
+		URL: redirectURL.String(),
+	}, nil
+}
+
+// Verifies the base64 encoded SAMLResponse posted to the organization's assertion
+// consumer service in response to requestID. The response or its assertion must
+// be signed with a certificate from the identity provider's metadata.
+func (s *ServiceProvider) ParseResponse(organizationID string, metadata []byte, samlResponse, requestID string) (Assertion, error) {
+	sp, err := s.identityProviderServiceProvider(organizationID, metadata)
+	if err != nil {
+		return Assertion{}, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(samlResponse)
+	if err != nil {
+		return Assertion{}, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+
+	assertion, err := sp.ParseXMLResponse(decoded, []string{requestID})
+	if err != nil {
+		// The error of the response only describes the failure in PrivateErr
+		var invalid *saml.InvalidResponseError
+		if errors.As(err, &invalid) && invalid.PrivateErr != nil {
+			err = invalid.PrivateErr
+		}
+
+		return Assertion{}, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+
+	result := Assertion{
+		Attributes: map[string][]string{},
+	}
+
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		result.NameID = strings.TrimSpace(assertion.Subject.NameID.Value)
+	}
+
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			values := make([]string, 0, len(attribute.Values))
+			for _, v := range attribute.Values {
+				values = append(values, v.Value)
+			}
+
+			// Attributes can be mapped by name or friendly name
+			result.Attributes[attribute.Name] = append(result.Attributes[attribute.Name], values...)
+			if attribute.FriendlyName != "" && attribute.FriendlyName != attribute.Name {
+				result.Attributes[attribute.FriendlyName] = append(result.Attributes[attribute.FriendlyName], values...)
+			}
+		}
+	}
+
+	if result.NameID == "" {
+		return Assertion{}, fmt.Errorf("%w: assertion has no NameID", ErrInvalidResponse)
+	}
+
+	return result, nil
+}
+
+// Returns the organization's service provider talking to the identity provider
+// described by metadata.
+func (s *ServiceProvider) identityProviderServiceProvider(organizationID string, metadata []byte) (*saml.ServiceProvider, error) {
+	descriptor, err := parseMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.serviceProvider(organizationID, descriptor)
+}
+
+func (s *ServiceProvider) serviceProvider(organizationID string, idp *saml.EntityDescriptor) (*saml.ServiceProvider, error) {
+	metadataURL, err := url.Parse(s.EntityID(organizationID))
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata URL: %w", err)
+	}
+
+	acsURL, err := url.Parse(s.ACSURL(organizationID))
+	if err != nil {
+		return nil, fmt.Errorf("invalid assertion consumer service URL: %w", err)
+	}
+
+	return &saml.ServiceProvider{
+		EntityID:    metadataURL.String(),
+		Key:         s.key,
+		Certificate: s.certificate,
+		HTTPClient:  s.httpClient,
+		MetadataURL: *metadataURL,
+		AcsURL:      *acsURL,
+		IDPMetadata: idp,
+		// Identity providers pick the format of the NameID, which should identify
+		// the user persistently
+		AuthnNameIDFormat: saml.UnspecifiedNameIDFormat,
+		SignatureMethod:   dsig.RSASHA256SignatureMethod,
+	}, nil
+}
+
+// Decodes an EntityDescriptor, or the first identity provider of an
+// EntitiesDescriptor as published by federations.
+func parseMetadata(metadata []byte) (*saml.EntityDescriptor, error) {
+	if err := xrv.Validate(bytes.NewReader(metadata)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMetadata, err)
+	}
+
+	root, err := rootElement(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMetadata, err)
+	}
+
+	var descriptor *saml.EntityDescriptor
+
+	switch root {
+	case "EntityDescriptor":
+		descriptor = &saml.EntityDescriptor{}
+		if err := xml.Unmarshal(metadata, descriptor); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMetadata, err)
+		}
+	case "EntitiesDescriptor":
+		var entities saml.EntitiesDescriptor
+		if err := xml.Unmarshal(metadata, &entities); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMetadata, err)
+		}
+
+		for i, e := range entities.EntityDescriptors {
+			if len(e.IDPSSODescriptors) > 0 {
+				descriptor = &entities.EntityDescriptors[i]
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: unexpected root element %s", ErrInvalidMetadata, root)
+	}
+
+	if descriptor == nil || len(descriptor.IDPSSODescriptors) == 0 {
+		return nil, fmt.Errorf("%w: no identity provider descriptor", ErrInvalidMetadata)
+	}
+
+	if descriptor.EntityID == "" {
+		return nil, fmt.Errorf("%w: entityID is missing", ErrInvalidMetadata)
+	}
+
+	return descriptor, nil
+}
+
+// Returns the local name of the document's root element.
+func rootElement(document []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// Reports whether the identity provider publishes a certificate for signing.
+// Keys without a use are used for both signing and encryption.
+func hasSigningCertificate(descriptor *saml.EntityDescriptor) bool {
+	for _, d := range descriptor.IDPSSODescriptors {
+		for _, key := range d.KeyDescriptors {
+			if key.Use != "signing" && key.Use != "" {
+				continue
+			}
+
+			for _, certificate := range key.KeyInfo.X509Data.X509Certificates {
+				if strings.TrimSpace(certificate.Data) != "" {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
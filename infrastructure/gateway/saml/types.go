@@ -0,0 +1,58 @@
+package saml
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Errors reported for identity provider metadata and responses.
+var (
+	ErrInvalidMetadata = errors.New("identity provider metadata is invalid")
+	ErrInvalidResponse = errors.New("SAML response is invalid")
+)
+
+// ServiceProvider is the SAML service provider organizations sign in to with
+// their own identity provider. Each organization is a separate service provider
+// entity, so identity providers can tell them apart.
+type ServiceProvider struct {
+	baseURL     string
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+	httpClient  *http.Client
+}
+
+// IdentityProviderMetadata is what the service provider needs to know about an
+// identity provider, read from its metadata.
+type IdentityProviderMetadata struct {
+	EntityID string
+	// Single sign-on service authentication requests are redirected to
+	SSOURL string
+}
+
+// AuthnRequest is an authentication request to send the user to the identity
+// provider with. Its ID is also the relay state the response comes back with.
+type AuthnRequest struct {
+	ID  string
+	URL string
+}
+
+// Assertion is the verified identity of a user asserted by an identity provider.
+type Assertion struct {
+	NameID string
+	// Attribute values keyed by attribute name
+	Attributes map[string][]string
+}
+
+// Returns the first value of the named attribute, or an empty string.
+func (a Assertion) Attribute(name string) string {
+	for _, value := range a.Attributes[name] {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -3,14 +3,25 @@ package infrastructure
 import (
 	"fmt"
 
+	domainAPIToken "conformitea/domain/apitoken"
+	domainAudit "conformitea/domain/audit"
+	domainMFA "conformitea/domain/mfa"
 	domainOrganization "conformitea/domain/organization"
 	domainTeam "conformitea/domain/team"
 	domainUser "conformitea/domain/user"
 	"conformitea/infrastructure/config"
 	"conformitea/infrastructure/database"
+	"conformitea/infrastructure/gateway/dns"
 	"conformitea/infrastructure/gateway/hydra"
+	"conformitea/infrastructure/gateway/idp"
+	"conformitea/infrastructure/gateway/mail"
 	"conformitea/infrastructure/gateway/microsoft"
+	"conformitea/infrastructure/gateway/oidc"
+	"conformitea/infrastructure/gateway/saml"
 	"conformitea/infrastructure/logger"
+	"conformitea/infrastructure/persistence/apitoken"
+	"conformitea/infrastructure/persistence/audit"
+	"conformitea/infrastructure/persistence/mfa"
 	"conformitea/infrastructure/persistence/organization"
 	"conformitea/infrastructure/persistence/team"
 	"conformitea/infrastructure/persistence/user"
@@ -23,20 +34,26 @@ type Persistence struct {
 	user         domainUser.UserRepository
 	team         domainTeam.TeamRepository
 	organization domainOrganization.OrganizationRepository
+	mfa          domainMFA.MFARepository
+	apiToken     domainAPIToken.APITokenRepository
+	audit        domainAudit.AuditRepository
 }
 
 type Container struct {
-	config          config.Config
-	logger          *zap.Logger
-	database        *gorm.DB
-	hydraClient     *hydra.HydraClient
-	microsoftClient *microsoft.OAuthClient
-	persistence     Persistence
+	config      config.Config
+	logger      *zap.Logger
+	database    *gorm.DB
+	hydraClient *hydra.HydraClient
+	providers   *idp.Registry
+	saml        *saml.ServiceProvider
+	resolver    dns.Resolver
+	mailer      mail.Mailer
+	persistence Persistence
 }
 
 var container *Container
 
-func Initialize(lc config.LoggerConfig, dc config.DatabaseConfig, hc config.HydraConfig, oc config.OAuthConfig) (*Container, error) {
+func Initialize(lc config.LoggerConfig, dc config.DatabaseConfig, hc config.HydraConfig, oc config.OAuthConfig, sc config.SAMLConfig, rc config.DNSConfig, mc config.MailConfig) (*Container, error) {
 	l, err := logger.Initialize(lc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize logger: %w", err)
@@ -52,9 +69,24 @@ func Initialize(lc config.LoggerConfig, dc config.DatabaseConfig, hc config.Hydr
 		return nil, fmt.Errorf("failed to initialize Hydra client: %w", err)
 	}
 
-	ms, err := microsoft.Initialize(oc.Microsoft)
+	providers, err := initializeIdentityProviders(oc)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Microsoft OAuth client: %w", err)
+		return nil, fmt.Errorf("failed to initialize identity providers: %w", err)
+	}
+
+	sp, err := saml.Initialize(sc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize SAML service provider: %w", err)
+	}
+
+	resolver, err := dns.Initialize(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize DNS resolver: %w", err)
+	}
+
+	mailer, err := mail.Initialize(mc, l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize mailer: %w", err)
 	}
 
 	container = &Container{
@@ -63,21 +95,63 @@ func Initialize(lc config.LoggerConfig, dc config.DatabaseConfig, hc config.Hydr
 			DatabaseConfig: dc,
 			HydraConfig:    hc,
 			OAuthConfig:    oc,
+			SAMLConfig:     sc,
+			DNSConfig:      rc,
+			MailConfig:     mc,
 		},
-		logger:          l,
-		database:        db,
-		hydraClient:     h,
-		microsoftClient: ms,
+		logger:      l,
+		database:    db,
+		hydraClient: h,
+		providers:   providers,
+		saml:        sp,
+		resolver:    resolver,
+		mailer:      mailer,
 		persistence: Persistence{
 			user:         &user.UserRepository{},
 			team:         &team.TeamRepository{},
 			organization: &organization.OrganizationRepository{},
+			mfa:          &mfa.MFARepository{},
+			apiToken:     &apitoken.APITokenRepository{},
+			audit:        &audit.AuditRepository{},
 		},
 	}
 
 	return container, nil
 }
 
+// Builds an identity provider for every [oauth.<name>] section.
+func initializeIdentityProviders(oc config.OAuthConfig) (*idp.Registry, error) {
+	if err := oc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid oauth configuration: %w", err)
+	}
+
+	registry := idp.NewRegistry()
+
+	for name, pc := range oc {
+		var provider idp.IdentityProvider
+		var err error
+
+		switch pc.ProviderType(name) {
+		case "microsoft":
+			provider, err = microsoft.Initialize(name, pc)
+		case "oidc":
+			provider, err = oidc.Initialize(name, pc)
+		default:
+			err = fmt.Errorf("unsupported identity provider type: %s", pc.ProviderType(name))
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize identity provider %q: %w", name, err)
+		}
+
+		if err := registry.Register(provider); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
 func (c *Container) GetLogger() *zap.Logger {
 	return c.logger
 }
@@ -90,8 +164,20 @@ func (c *Container) GetHydraClient() *hydra.HydraClient {
 	return c.hydraClient
 }
 
-func (c *Container) GetMicrosoftClient() *microsoft.OAuthClient {
-	return c.microsoftClient
+func (c *Container) GetIdentityProviders() *idp.Registry {
+	return c.providers
+}
+
+func (c *Container) GetSAMLServiceProvider() *saml.ServiceProvider {
+	return c.saml
+}
+
+func (c *Container) GetDNSResolver() dns.Resolver {
+	return c.resolver
+}
+
+func (c *Container) GetMailer() mail.Mailer {
+	return c.mailer
 }
 
 func (c *Container) GetPersistence() Persistence {
@@ -109,3 +195,15 @@ func (p *Persistence) GetTeamRepository() domainTeam.TeamRepository {
 func (p *Persistence) GetOrganizationRepository() domainOrganization.OrganizationRepository {
 	return p.organization
 }
+
+func (p *Persistence) GetMFARepository() domainMFA.MFARepository {
+	return p.mfa
+}
+
+func (p *Persistence) GetAPITokenRepository() domainAPIToken.APITokenRepository {
+	return p.apiToken
+}
+
+func (p *Persistence) GetAuditRepository() domainAudit.AuditRepository {
+	return p.audit
+}
@@ -0,0 +1,43 @@
+package apitoken
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIToken struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID           *uuid.UUID `gorm:"type:uuid"`
+	ServiceAccountID *uuid.UUID `gorm:"type:uuid"`
+	Name             string     `gorm:"type:text;not null"`
+	DisplayPrefix    string     `gorm:"type:text;not null"`
+	SecretHash       string     `gorm:"type:text;not null;unique"`
+	Scopes           string     `gorm:"type:text;not null"`
+	ExpiresAt        time.Time  `gorm:"type:timestamp;not null"`
+	LastUsedAt       *time.Time `gorm:"type:timestamp"`
+	RevokedAt        *time.Time `gorm:"type:timestamp"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime"`
+}
+
+func (t *APIToken) BeforeCreate(tx *gorm.DB) (err error) {
+	t.ID, _ = uuid.NewV7()
+	return
+}
+
+type ServiceAccount struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null"`
+	Name           string    `gorm:"type:text;not null"`
+	Description    string    `gorm:"type:text;not null"`
+	Role           string    `gorm:"type:text;not null"`
+	CreatedBy      uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (a *ServiceAccount) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID, _ = uuid.NewV7()
+	return
+}
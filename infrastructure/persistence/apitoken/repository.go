@@ -0,0 +1,169 @@
+package apitoken
+
+import (
+	"strings"
+	"time"
+
+	domain "conformitea/domain/apitoken"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APITokenRepository struct{}
+
+func (r *APITokenRepository) CreateToken(DB *gorm.DB, token domain.Token) (domain.Token, error) {
+	model := APIToken{
+		UserID:           token.UserID,
+		ServiceAccountID: token.ServiceAccountID,
+		Name:             token.Name,
+		DisplayPrefix:    token.DisplayPrefix,
+		SecretHash:       token.SecretHash,
+		Scopes:           strings.Join(token.Scopes, " "),
+		ExpiresAt:        token.ExpiresAt,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.Token{}, err
+	}
+
+	return toDomainToken(model), nil
+}
+
+func (r *APITokenRepository) GetTokenByHash(DB *gorm.DB, secretHash string) (domain.Token, error) {
+	var token APIToken
+
+	if err := DB.Where("secret_hash = ?", secretHash).First(&token).Error; err != nil {
+		return domain.Token{}, err
+	}
+
+	return toDomainToken(token), nil
+}
+
+func (r *APITokenRepository) GetUserTokens(DB *gorm.DB, userID uuid.UUID) ([]domain.Token, error) {
+	return r.findTokens(DB.Where("user_id = ?", userID))
+}
+
+func (r *APITokenRepository) GetServiceAccountTokens(DB *gorm.DB, serviceAccountID uuid.UUID) ([]domain.Token, error) {
+	return r.findTokens(DB.Where("service_account_id = ?", serviceAccountID))
+}
+
+func (r *APITokenRepository) RevokeUserToken(DB *gorm.DB, userID, id uuid.UUID) (bool, error) {
+	return r.revoke(DB.Where("user_id = ? AND id = ?", userID, id))
+}
+
+func (r *APITokenRepository) RevokeServiceAccountToken(DB *gorm.DB, serviceAccountID, id uuid.UUID) (bool, error) {
+	return r.revoke(DB.Where("service_account_id = ? AND id = ?", serviceAccountID, id))
+}
+
+func (r *APITokenRepository) TouchToken(DB *gorm.DB, id uuid.UUID, usedBefore time.Time) error {
+	return DB.Model(&APIToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, usedBefore).
+		Update("last_used_at", time.Now()).Error
+}
+
+func (r *APITokenRepository) CreateServiceAccount(DB *gorm.DB, account domain.ServiceAccount) (domain.ServiceAccount, error) {
+	model := ServiceAccount{
+		OrganizationID: account.OrganizationID,
+		Name:           account.Name,
+		Description:    account.Description,
+		Role:           account.Role,
+		CreatedBy:      account.CreatedBy,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.ServiceAccount{}, err
+	}
+
+	return toDomainServiceAccount(model), nil
+}
+
+func (r *APITokenRepository) GetServiceAccount(DB *gorm.DB, id uuid.UUID) (domain.ServiceAccount, error) {
+	var account ServiceAccount
+
+	if err := DB.Where("id = ?", id).First(&account).Error; err != nil {
+		return domain.ServiceAccount{}, err
+	}
+
+	return toDomainServiceAccount(account), nil
+}
+
+func (r *APITokenRepository) GetOrganizationServiceAccounts(DB *gorm.DB, organizationID uuid.UUID) ([]domain.ServiceAccount, error) {
+	var accounts []ServiceAccount
+
+	if err := DB.Where("organization_id = ?", organizationID).Order("created_at").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.ServiceAccount, 0, len(accounts))
+	for _, a := range accounts {
+		result = append(result, toDomainServiceAccount(a))
+	}
+
+	return result, nil
+}
+
+func (r *APITokenRepository) DeleteServiceAccount(DB *gorm.DB, id uuid.UUID) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("service_account_id = ?", id).Delete(&APIToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", id).Delete(&ServiceAccount{}).Error
+	})
+}
+
+func (r *APITokenRepository) findTokens(query *gorm.DB) ([]domain.Token, error) {
+	var tokens []APIToken
+
+	if err := query.Order("created_at").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Token, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, toDomainToken(t))
+	}
+
+	return result, nil
+}
+
+func (r *APITokenRepository) revoke(query *gorm.DB) (bool, error) {
+	result := query.Model(&APIToken{}).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func toDomainToken(token APIToken) domain.Token {
+	return domain.Token{
+		ID:               token.ID,
+		UserID:           token.UserID,
+		ServiceAccountID: token.ServiceAccountID,
+		Name:             token.Name,
+		DisplayPrefix:    token.DisplayPrefix,
+		SecretHash:       token.SecretHash,
+		Scopes:           strings.Fields(token.Scopes),
+		ExpiresAt:        token.ExpiresAt,
+		LastUsedAt:       token.LastUsedAt,
+		RevokedAt:        token.RevokedAt,
+		CreatedAt:        token.CreatedAt,
+	}
+}
+
+func toDomainServiceAccount(account ServiceAccount) domain.ServiceAccount {
+	return domain.ServiceAccount{
+		ID:             account.ID,
+		OrganizationID: account.OrganizationID,
+		Name:           account.Name,
+		Description:    account.Description,
+		Role:           account.Role,
+		CreatedBy:      account.CreatedBy,
+		CreatedAt:      account.CreatedAt,
+		UpdatedAt:      account.UpdatedAt,
+	}
+}
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditEvent struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Action          string     `gorm:"type:text;not null"`
+	ActorID         uuid.UUID  `gorm:"type:uuid;not null"`
+	SubjectID       uuid.UUID  `gorm:"type:uuid;not null"`
+	ImpersonationID *uuid.UUID `gorm:"type:uuid"`
+	Reason          string     `gorm:"type:text;not null"`
+	Method          string     `gorm:"type:text;not null"`
+	Path            string     `gorm:"type:text;not null"`
+	Status          int        `gorm:"not null"`
+	RequestID       string     `gorm:"type:text;not null"`
+	IPAddress       string     `gorm:"type:text;not null"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime"`
+}
+
+func (e *AuditEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID, _ = uuid.NewV7()
+	return
+}
@@ -0,0 +1,78 @@
+package audit
+
+import (
+	domain "conformitea/domain/audit"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct{}
+
+func (r *AuditRepository) CreateEvent(DB *gorm.DB, event domain.Event) (domain.Event, error) {
+	model := AuditEvent{
+		Action:          event.Action,
+		ActorID:         event.ActorID,
+		SubjectID:       event.SubjectID,
+		ImpersonationID: event.ImpersonationID,
+		Reason:          event.Reason,
+		Method:          event.Method,
+		Path:            event.Path,
+		Status:          event.Status,
+		RequestID:       event.RequestID,
+		IPAddress:       event.IPAddress,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.Event{}, err
+	}
+
+	return toDomainEvent(model), nil
+}
+
+func (r *AuditRepository) GetEvents(DB *gorm.DB, query domain.EventQuery) ([]domain.Event, error) {
+	q := DB.Model(&AuditEvent{})
+
+	if query.ActorID != uuid.Nil {
+		q = q.Where("actor_id = ?", query.ActorID)
+	}
+
+	if query.SubjectID != uuid.Nil {
+		q = q.Where("subject_id = ?", query.SubjectID)
+	}
+
+	if query.ImpersonationID != uuid.Nil {
+		q = q.Where("impersonation_id = ?", query.ImpersonationID)
+	}
+
+	var events []AuditEvent
+
+	// IDs are UUIDv7, so they break ties of events recorded at the same time
+	if err := q.Order("created_at DESC, id DESC").Limit(query.Limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Event, 0, len(events))
+	for _, e := range events {
+		result = append(result, toDomainEvent(e))
+	}
+
+	return result, nil
+}
+
+func toDomainEvent(event AuditEvent) domain.Event {
+	return domain.Event{
+		ID:              event.ID,
+		Action:          event.Action,
+		ActorID:         event.ActorID,
+		SubjectID:       event.SubjectID,
+		ImpersonationID: event.ImpersonationID,
+		Reason:          event.Reason,
+		Method:          event.Method,
+		Path:            event.Path,
+		Status:          event.Status,
+		RequestID:       event.RequestID,
+		IPAddress:       event.IPAddress,
+		CreatedAt:       event.CreatedAt,
+	}
+}
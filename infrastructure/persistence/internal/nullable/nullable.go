@@ -0,0 +1,11 @@
+// Package nullable converts values of optional columns for the repositories.
+package nullable
+
+// Returns nil for an empty string, so optional unique columns stay NULL.
+func String(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
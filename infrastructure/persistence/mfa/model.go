@@ -0,0 +1,51 @@
+package mfa
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserTOTPFactor struct {
+	UserID       uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Secret       string     `gorm:"type:text;not null"`
+	ConfirmedAt  *time.Time `gorm:"type:timestamp"`
+	LastUsedStep int64      `gorm:"not null;default:0"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+}
+
+type UserRecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null"`
+	CodeHash  string     `gorm:"type:text;not null"`
+	UsedAt    *time.Time `gorm:"type:timestamp"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+func (c *UserRecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID, _ = uuid.NewV7()
+	return
+}
+
+type UserPasskey struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null"`
+	Name            string     `gorm:"type:text;not null"`
+	CredentialID    []byte     `gorm:"type:bytea;not null;unique"`
+	PublicKey       []byte     `gorm:"type:bytea;not null"`
+	AttestationType string     `gorm:"type:text;not null"`
+	AAGUID          []byte     `gorm:"column:aaguid;type:bytea"`
+	Transports      string     `gorm:"type:text;not null"`
+	SignCount       int64      `gorm:"not null;default:0"`
+	BackupEligible  bool       `gorm:"not null;default:false"`
+	BackupState     bool       `gorm:"not null;default:false"`
+	LastUsedAt      *time.Time `gorm:"type:timestamp"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime"`
+}
+
+func (p *UserPasskey) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID, _ = uuid.NewV7()
+	return
+}
@@ -0,0 +1,195 @@
+package mfa
+
+import (
+	"strings"
+	"time"
+
+	domain "conformitea/domain/mfa"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MFARepository struct{}
+
+func (m *MFARepository) GetTOTPFactor(DB *gorm.DB, userID uuid.UUID) (domain.TOTPFactor, error) {
+	var factor UserTOTPFactor
+
+	if err := DB.Where("user_id = ?", userID).First(&factor).Error; err != nil {
+		return domain.TOTPFactor{}, err
+	}
+
+	return toDomainTOTPFactor(factor), nil
+}
+
+func (m *MFARepository) SaveTOTPFactor(DB *gorm.DB, factor domain.TOTPFactor) (domain.TOTPFactor, error) {
+	model := UserTOTPFactor{
+		UserID:       factor.UserID,
+		Secret:       factor.Secret,
+		ConfirmedAt:  factor.ConfirmedAt,
+		LastUsedStep: factor.LastUsedStep,
+	}
+
+	if err := DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_used_step", "updated_at"}),
+	}).Create(&model).Error; err != nil {
+		return domain.TOTPFactor{}, err
+	}
+
+	return m.GetTOTPFactor(DB, factor.UserID)
+}
+
+func (m *MFARepository) DeleteTOTPFactor(DB *gorm.DB, userID uuid.UUID) error {
+	return DB.Where("user_id = ?", userID).Delete(&UserTOTPFactor{}).Error
+}
+
+func (m *MFARepository) UseTOTPStep(DB *gorm.DB, userID uuid.UUID, step int64) (bool, error) {
+	result := DB.Model(&UserTOTPFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (m *MFARepository) ReplaceRecoveryCodes(DB *gorm.DB, userID uuid.UUID, codeHashes []string) error {
+	if err := DB.Where("user_id = ?", userID).Delete(&UserRecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	codes := make([]UserRecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, UserRecoveryCode{
+			UserID:   userID,
+			CodeHash: hash,
+		})
+	}
+
+	return DB.Create(&codes).Error
+}
+
+func (m *MFARepository) UseRecoveryCode(DB *gorm.DB, userID uuid.UUID, codeHash string) (bool, error) {
+	result := DB.Model(&UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (m *MFARepository) CountUnusedRecoveryCodes(DB *gorm.DB, userID uuid.UUID) (int, error) {
+	var count int64
+
+	if err := DB.Model(&UserRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (m *MFARepository) GetPasskeys(DB *gorm.DB, userID uuid.UUID) ([]domain.Passkey, error) {
+	var passkeys []UserPasskey
+
+	if err := DB.Where("user_id = ?", userID).Order("created_at").Find(&passkeys).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Passkey, 0, len(passkeys))
+	for _, p := range passkeys {
+		result = append(result, toDomainPasskey(p))
+	}
+
+	return result, nil
+}
+
+func (m *MFARepository) CreatePasskey(DB *gorm.DB, passkey domain.Passkey) (domain.Passkey, error) {
+	model := UserPasskey{
+		UserID:          passkey.UserID,
+		Name:            passkey.Name,
+		CredentialID:    passkey.CredentialID,
+		PublicKey:       passkey.PublicKey,
+		AttestationType: passkey.AttestationType,
+		AAGUID:          passkey.AAGUID,
+		Transports:      strings.Join(passkey.Transports, ","),
+		SignCount:       int64(passkey.SignCount),
+		BackupEligible:  passkey.BackupEligible,
+		BackupState:     passkey.BackupState,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.Passkey{}, err
+	}
+
+	return toDomainPasskey(model), nil
+}
+
+func (m *MFARepository) UpdatePasskeyUse(DB *gorm.DB, id uuid.UUID, signCount uint32, backupState bool) error {
+	return DB.Model(&UserPasskey{}).Where("id = ?", id).Updates(map[string]any{
+		"sign_count":   int64(signCount),
+		"backup_state": backupState,
+		"last_used_at": time.Now(),
+	}).Error
+}
+
+func (m *MFARepository) DeletePasskey(DB *gorm.DB, userID, id uuid.UUID) (bool, error) {
+	result := DB.Where("user_id = ? AND id = ?", userID, id).Delete(&UserPasskey{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (m *MFARepository) CountPasskeys(DB *gorm.DB, userID uuid.UUID) (int, error) {
+	var count int64
+
+	if err := DB.Model(&UserPasskey{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func toDomainTOTPFactor(factor UserTOTPFactor) domain.TOTPFactor {
+	return domain.TOTPFactor{
+		UserID:       factor.UserID,
+		Secret:       factor.Secret,
+		ConfirmedAt:  factor.ConfirmedAt,
+		LastUsedStep: factor.LastUsedStep,
+		CreatedAt:    factor.CreatedAt,
+		UpdatedAt:    factor.UpdatedAt,
+	}
+}
+
+func toDomainPasskey(passkey UserPasskey) domain.Passkey {
+	var transports []string
+	if passkey.Transports != "" {
+		transports = strings.Split(passkey.Transports, ",")
+	}
+
+	return domain.Passkey{
+		ID:              passkey.ID,
+		UserID:          passkey.UserID,
+		Name:            passkey.Name,
+		CredentialID:    passkey.CredentialID,
+		PublicKey:       passkey.PublicKey,
+		AttestationType: passkey.AttestationType,
+		AAGUID:          passkey.AAGUID,
+		Transports:      transports,
+		SignCount:       uint32(passkey.SignCount),
+		BackupEligible:  passkey.BackupEligible,
+		BackupState:     passkey.BackupState,
+		LastUsedAt:      passkey.LastUsedAt,
+		CreatedAt:       passkey.CreatedAt,
+	}
+}
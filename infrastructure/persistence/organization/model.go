@@ -8,13 +8,95 @@ import (
 )
 
 type Organization struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
-	Name      string    `gorm:"type:text;not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name          string    `gorm:"type:text;not null"`
+	RequireMFA    bool      `gorm:"not null;default:false"`
+	RequireSSO    bool      `gorm:"not null;default:false"`
+	EntraTenantID *string   `gorm:"type:text"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
 }
 
 func (o *Organization) BeforeCreate(tx *gorm.DB) (err error) {
 	o.ID, _ = uuid.NewV7()
 	return
 }
+
+type UserOrganization struct {
+	UserID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Role           string    `gorm:"type:text;not null"`
+	ExternalID     *string   `gorm:"type:text"`
+	DeactivatedAt  *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+type SAMLConnection struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrganizationID     uuid.UUID `gorm:"type:uuid;not null"`
+	IdPEntityID        string    `gorm:"column:idp_entity_id;type:text;not null"`
+	MetadataURL        string    `gorm:"type:text;not null;default:''"`
+	MetadataXML        string    `gorm:"type:text;not null"`
+	EmailAttribute     string    `gorm:"type:text;not null;default:''"`
+	FirstNameAttribute string    `gorm:"type:text;not null;default:''"`
+	LastNameAttribute  string    `gorm:"type:text;not null;default:''"`
+	CreatedAt          time.Time `gorm:"autoCreateTime"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime"`
+}
+
+func (s *SAMLConnection) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID, _ = uuid.NewV7()
+	return
+}
+
+type OrganizationDomain struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OrganizationID    uuid.UUID  `gorm:"type:uuid;not null"`
+	Domain            string     `gorm:"type:text;not null"`
+	VerificationToken string     `gorm:"type:text;not null"`
+	VerifiedAt        *time.Time `gorm:"type:timestamp"`
+	AutoJoin          bool       `gorm:"not null;default:false"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (d *OrganizationDomain) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID, _ = uuid.NewV7()
+	return
+}
+
+type OrganizationRole struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null"`
+	Name           string    `gorm:"type:text;not null"`
+	Description    string    `gorm:"type:text;not null;default:''"`
+	Permissions    string    `gorm:"type:text;not null;default:''"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (r *OrganizationRole) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID, _ = uuid.NewV7()
+	return
+}
+
+type OrganizationInvitation struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OrganizationID     uuid.UUID  `gorm:"type:uuid;not null"`
+	Email              string     `gorm:"type:text;not null"`
+	Role               string     `gorm:"type:text;not null"`
+	AllowEmailMismatch bool       `gorm:"not null;default:false"`
+	TokenNonce         string     `gorm:"type:text;not null"`
+	InvitedBy          uuid.UUID  `gorm:"type:uuid;not null"`
+	ExpiresAt          time.Time  `gorm:"type:timestamp;not null"`
+	SentAt             time.Time  `gorm:"type:timestamp;not null"`
+	AcceptedAt         *time.Time `gorm:"type:timestamp"`
+	AcceptedBy         *uuid.UUID `gorm:"type:uuid"`
+	RevokedAt          *time.Time `gorm:"type:timestamp"`
+	CreatedAt          time.Time  `gorm:"autoCreateTime"`
+}
+
+func (i *OrganizationInvitation) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID, _ = uuid.NewV7()
+	return
+}
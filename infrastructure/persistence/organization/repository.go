@@ -1,10 +1,15 @@
 package organization
 
 import (
+	"strings"
+	"time"
+
 	domain "conformitea/domain/organization"
+	"conformitea/infrastructure/persistence/internal/nullable"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrganizationRepository struct{}
@@ -16,8 +21,379 @@ func (o *OrganizationRepository) GetOrganizationByID(DB *gorm.DB, id uuid.UUID)
 		return domain.Organization{}, err
 	}
 
-	return domain.Organization{
-		ID:   organization.ID,
-		Name: organization.Name,
-	}, nil
+	return toDomainOrganization(organization), nil
+}
+
+func (o *OrganizationRepository) GetOrganizationByTenantID(DB *gorm.DB, tenantID string) (domain.Organization, error) {
+	var organization Organization
+
+	if err := DB.Where("entra_tenant_id = ?", tenantID).First(&organization).Error; err != nil {
+		return domain.Organization{}, err
+	}
+
+	return toDomainOrganization(organization), nil
+}
+
+func (o *OrganizationRepository) UpdateOrganization(DB *gorm.DB, organization domain.Organization) (domain.Organization, error) {
+	model := Organization{ID: organization.ID}
+
+	if err := DB.Model(&model).Updates(map[string]any{
+		"name":            organization.Name,
+		"require_mfa":     organization.RequireMFA,
+		"require_sso":     organization.RequireSSO,
+		"entra_tenant_id": nullable.String(organization.EntraTenantID),
+	}).Error; err != nil {
+		return domain.Organization{}, err
+	}
+
+	return o.GetOrganizationByID(DB, organization.ID)
+}
+
+func (o *OrganizationRepository) RequiresMFA(DB *gorm.DB, userID uuid.UUID) (bool, error) {
+	var count int64
+
+	if err := DB.Model(&Organization{}).
+		Joins("JOIN user_organizations ON user_organizations.organization_id = organizations.id").
+		Where("user_organizations.user_id = ? AND organizations.require_mfa", userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (o *OrganizationRepository) GetSAMLConnection(DB *gorm.DB, organizationID uuid.UUID) (domain.SAMLConnection, error) {
+	var connection SAMLConnection
+
+	if err := DB.Where("organization_id = ?", organizationID).First(&connection).Error; err != nil {
+		return domain.SAMLConnection{}, err
+	}
+
+	return toDomainSAMLConnection(connection), nil
+}
+
+func (o *OrganizationRepository) SaveSAMLConnection(DB *gorm.DB, connection domain.SAMLConnection) (domain.SAMLConnection, error) {
+	model := SAMLConnection{
+		OrganizationID:     connection.OrganizationID,
+		IdPEntityID:        connection.IdPEntityID,
+		MetadataURL:        connection.MetadataURL,
+		MetadataXML:        connection.MetadataXML,
+		EmailAttribute:     connection.EmailAttribute,
+		FirstNameAttribute: connection.FirstNameAttribute,
+		LastNameAttribute:  connection.LastNameAttribute,
+	}
+
+	// An organization has a single connection, which is replaced in place
+	if err := DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"idp_entity_id",
+			"metadata_url",
+			"metadata_xml",
+			"email_attribute",
+			"first_name_attribute",
+			"last_name_attribute",
+			"updated_at",
+		}),
+	}).Create(&model).Error; err != nil {
+		return domain.SAMLConnection{}, err
+	}
+
+	return o.GetSAMLConnection(DB, connection.OrganizationID)
+}
+
+func (o *OrganizationRepository) DeleteSAMLConnection(DB *gorm.DB, organizationID uuid.UUID) error {
+	return DB.Where("organization_id = ?", organizationID).Delete(&SAMLConnection{}).Error
+}
+
+func (o *OrganizationRepository) GetDomains(DB *gorm.DB, organizationID uuid.UUID) ([]domain.Domain, error) {
+	var models []OrganizationDomain
+
+	if err := DB.Where("organization_id = ?", organizationID).Order("domain").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	domains := make([]domain.Domain, 0, len(models))
+	for _, d := range models {
+		domains = append(domains, toDomainDomain(d))
+	}
+
+	return domains, nil
+}
+
+func (o *OrganizationRepository) GetDomain(DB *gorm.DB, organizationID, id uuid.UUID) (domain.Domain, error) {
+	var model OrganizationDomain
+
+	if err := DB.Where("organization_id = ? AND id = ?", organizationID, id).First(&model).Error; err != nil {
+		return domain.Domain{}, err
+	}
+
+	return toDomainDomain(model), nil
+}
+
+func (o *OrganizationRepository) GetVerifiedDomain(DB *gorm.DB, name string) (domain.Domain, error) {
+	var model OrganizationDomain
+
+	if err := DB.Where("domain = ? AND verified_at IS NOT NULL", name).First(&model).Error; err != nil {
+		return domain.Domain{}, err
+	}
+
+	return toDomainDomain(model), nil
+}
+
+func (o *OrganizationRepository) CreateDomain(DB *gorm.DB, d domain.Domain) (domain.Domain, error) {
+	model := OrganizationDomain{
+		OrganizationID:    d.OrganizationID,
+		Domain:            d.Name,
+		VerificationToken: d.VerificationToken,
+		AutoJoin:          d.AutoJoin,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.Domain{}, err
+	}
+
+	return toDomainDomain(model), nil
+}
+
+func (o *OrganizationRepository) UpdateDomain(DB *gorm.DB, d domain.Domain) (domain.Domain, error) {
+	model := OrganizationDomain{ID: d.ID}
+
+	if err := DB.Model(&model).Updates(map[string]any{
+		"verified_at": d.VerifiedAt,
+		"auto_join":   d.AutoJoin,
+	}).Error; err != nil {
+		return domain.Domain{}, err
+	}
+
+	return o.GetDomain(DB, d.OrganizationID, d.ID)
+}
+
+func (o *OrganizationRepository) DeleteDomain(DB *gorm.DB, organizationID, id uuid.UUID) error {
+	return DB.Where("organization_id = ? AND id = ?", organizationID, id).Delete(&OrganizationDomain{}).Error
+}
+
+func (o *OrganizationRepository) GetRoles(DB *gorm.DB, organizationID uuid.UUID) ([]domain.Role, error) {
+	var models []OrganizationRole
+
+	if err := DB.Where("organization_id = ?", organizationID).Order("name").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	roles := make([]domain.Role, 0, len(models))
+	for _, r := range models {
+		roles = append(roles, toDomainRole(r))
+	}
+
+	return roles, nil
+}
+
+func (o *OrganizationRepository) GetRole(DB *gorm.DB, organizationID, id uuid.UUID) (domain.Role, error) {
+	var model OrganizationRole
+
+	if err := DB.Where("organization_id = ? AND id = ?", organizationID, id).First(&model).Error; err != nil {
+		return domain.Role{}, err
+	}
+
+	return toDomainRole(model), nil
+}
+
+func (o *OrganizationRepository) GetRoleByName(DB *gorm.DB, organizationID uuid.UUID, name string) (domain.Role, error) {
+	var model OrganizationRole
+
+	if err := DB.Where("organization_id = ? AND name = ?", organizationID, name).First(&model).Error; err != nil {
+		return domain.Role{}, err
+	}
+
+	return toDomainRole(model), nil
+}
+
+func (o *OrganizationRepository) CreateRole(DB *gorm.DB, role domain.Role) (domain.Role, error) {
+	model := OrganizationRole{
+		OrganizationID: role.OrganizationID,
+		Name:           role.Name,
+		Description:    role.Description,
+		Permissions:    strings.Join(role.Permissions, " "),
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.Role{}, err
+	}
+
+	return toDomainRole(model), nil
+}
+
+func (o *OrganizationRepository) UpdateRole(DB *gorm.DB, role domain.Role) (domain.Role, error) {
+	model := OrganizationRole{ID: role.ID}
+
+	if err := DB.Model(&model).Updates(map[string]any{
+		"description": role.Description,
+		"permissions": strings.Join(role.Permissions, " "),
+	}).Error; err != nil {
+		return domain.Role{}, err
+	}
+
+	return o.GetRole(DB, role.OrganizationID, role.ID)
+}
+
+func (o *OrganizationRepository) DeleteRole(DB *gorm.DB, organizationID, id uuid.UUID) error {
+	return DB.Where("organization_id = ? AND id = ?", organizationID, id).Delete(&OrganizationRole{}).Error
+}
+
+func (o *OrganizationRepository) GetInvitations(DB *gorm.DB, organizationID uuid.UUID) ([]domain.Invitation, error) {
+	var models []OrganizationInvitation
+
+	if err := DB.Where("organization_id = ?", organizationID).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	invitations := make([]domain.Invitation, 0, len(models))
+	for _, i := range models {
+		invitations = append(invitations, toDomainInvitation(i))
+	}
+
+	return invitations, nil
+}
+
+func (o *OrganizationRepository) GetInvitation(DB *gorm.DB, organizationID, id uuid.UUID) (domain.Invitation, error) {
+	var model OrganizationInvitation
+
+	if err := DB.Where("organization_id = ? AND id = ?", organizationID, id).First(&model).Error; err != nil {
+		return domain.Invitation{}, err
+	}
+
+	return toDomainInvitation(model), nil
+}
+
+func (o *OrganizationRepository) GetInvitationByID(DB *gorm.DB, id uuid.UUID) (domain.Invitation, error) {
+	var model OrganizationInvitation
+
+	if err := DB.Where("id = ?", id).First(&model).Error; err != nil {
+		return domain.Invitation{}, err
+	}
+
+	return toDomainInvitation(model), nil
+}
+
+func (o *OrganizationRepository) CreateInvitation(DB *gorm.DB, invitation domain.Invitation) (domain.Invitation, error) {
+	model := OrganizationInvitation{
+		OrganizationID:     invitation.OrganizationID,
+		Email:              invitation.Email,
+		Role:               invitation.Role,
+		AllowEmailMismatch: invitation.AllowEmailMismatch,
+		TokenNonce:         invitation.TokenNonce,
+		InvitedBy:          invitation.InvitedBy,
+		ExpiresAt:          invitation.ExpiresAt,
+		SentAt:             invitation.SentAt,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.Invitation{}, err
+	}
+
+	return toDomainInvitation(model), nil
+}
+
+func (o *OrganizationRepository) UpdateInvitation(DB *gorm.DB, invitation domain.Invitation) (domain.Invitation, error) {
+	model := OrganizationInvitation{ID: invitation.ID}
+
+	if err := DB.Model(&model).Updates(map[string]any{
+		"token_nonce": invitation.TokenNonce,
+		"expires_at":  invitation.ExpiresAt,
+		"sent_at":     invitation.SentAt,
+		"revoked_at":  invitation.RevokedAt,
+	}).Error; err != nil {
+		return domain.Invitation{}, err
+	}
+
+	return o.GetInvitation(DB, invitation.OrganizationID, invitation.ID)
+}
+
+func (o *OrganizationRepository) AcceptInvitation(DB *gorm.DB, id, userID uuid.UUID, at time.Time) (bool, error) {
+	result := DB.Model(&OrganizationInvitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Updates(map[string]any{
+			"accepted_at": at,
+			"accepted_by": userID,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func toDomainOrganization(organization Organization) domain.Organization {
+	result := domain.Organization{
+		ID:         organization.ID,
+		Name:       organization.Name,
+		RequireMFA: organization.RequireMFA,
+		RequireSSO: organization.RequireSSO,
+		CreatedAt:  organization.CreatedAt,
+		UpdatedAt:  organization.UpdatedAt,
+	}
+
+	if organization.EntraTenantID != nil {
+		result.EntraTenantID = *organization.EntraTenantID
+	}
+
+	return result
+}
+
+func toDomainSAMLConnection(connection SAMLConnection) domain.SAMLConnection {
+	return domain.SAMLConnection{
+		ID:                 connection.ID,
+		OrganizationID:     connection.OrganizationID,
+		IdPEntityID:        connection.IdPEntityID,
+		MetadataURL:        connection.MetadataURL,
+		MetadataXML:        connection.MetadataXML,
+		EmailAttribute:     connection.EmailAttribute,
+		FirstNameAttribute: connection.FirstNameAttribute,
+		LastNameAttribute:  connection.LastNameAttribute,
+		CreatedAt:          connection.CreatedAt,
+		UpdatedAt:          connection.UpdatedAt,
+	}
+}
+
+func toDomainDomain(d OrganizationDomain) domain.Domain {
+	return domain.Domain{
+		ID:                d.ID,
+		OrganizationID:    d.OrganizationID,
+		Name:              d.Domain,
+		VerificationToken: d.VerificationToken,
+		VerifiedAt:        d.VerifiedAt,
+		AutoJoin:          d.AutoJoin,
+		CreatedAt:         d.CreatedAt,
+		UpdatedAt:         d.UpdatedAt,
+	}
+}
+
+func toDomainRole(role OrganizationRole) domain.Role {
+	return domain.Role{
+		ID:             role.ID,
+		OrganizationID: role.OrganizationID,
+		Name:           role.Name,
+		Description:    role.Description,
+		Permissions:    strings.Fields(role.Permissions),
+		CreatedAt:      role.CreatedAt,
+		UpdatedAt:      role.UpdatedAt,
+	}
+}
+
+func toDomainInvitation(invitation OrganizationInvitation) domain.Invitation {
+	return domain.Invitation{
+		ID:                 invitation.ID,
+		OrganizationID:     invitation.OrganizationID,
+		Email:              invitation.Email,
+		Role:               invitation.Role,
+		AllowEmailMismatch: invitation.AllowEmailMismatch,
+		TokenNonce:         invitation.TokenNonce,
+		InvitedBy:          invitation.InvitedBy,
+		ExpiresAt:          invitation.ExpiresAt,
+		SentAt:             invitation.SentAt,
+		AcceptedAt:         invitation.AcceptedAt,
+		AcceptedBy:         invitation.AcceptedBy,
+		RevokedAt:          invitation.RevokedAt,
+		CreatedAt:          invitation.CreatedAt,
+	}
 }
@@ -0,0 +1,69 @@
+// Package persistencetest opens databases for tests of the repositories and of
+// the services using them.
+package persistencetest
+
+import (
+	"testing"
+
+	"conformitea/infrastructure/persistence/apitoken"
+	"conformitea/infrastructure/persistence/audit"
+	"conformitea/infrastructure/persistence/mfa"
+	"conformitea/infrastructure/persistence/organization"
+	"conformitea/infrastructure/persistence/team"
+	"conformitea/infrastructure/persistence/user"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Returns an empty in-memory SQLite database with the tables of every model. The
+// tables are created from the models rather than the PostgreSQL migrations, so
+// constraints only declared in the migrations are not enforced.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database connection: %v", err)
+	}
+
+	// Every connection to an in-memory database opens a database of its own
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.SetupJoinTable(&user.User{}, "Organizations", &organization.UserOrganization{}); err != nil {
+		t.Fatalf("failed to set up memberships: %v", err)
+	}
+
+	if err := db.AutoMigrate(
+		&user.User{},
+		&user.UserIdentity{},
+		&user.UserMerge{},
+		&organization.Organization{},
+		&organization.UserOrganization{},
+		&organization.SAMLConnection{},
+		&organization.OrganizationDomain{},
+		&organization.OrganizationRole{},
+		&organization.OrganizationInvitation{},
+		&team.Team{},
+		&team.TeamMember{},
+		&mfa.UserTOTPFactor{},
+		&mfa.UserRecoveryCode{},
+		&mfa.UserPasskey{},
+		&apitoken.APIToken{},
+		&apitoken.ServiceAccount{},
+		&audit.AuditEvent{},
+	); err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+
+	return db
+}
@@ -1,6 +1,8 @@
 package team
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -9,9 +11,18 @@ type Team struct {
 	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
 	OrganizationID uuid.UUID `gorm:"type:uuid;not null"`
 	Name           string    `gorm:"type:text;not null"`
+	ExternalID     *string   `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
 }
 
 func (u *Team) BeforeCreate(tx *gorm.DB) (err error) {
 	u.ID, _ = uuid.NewV7()
 	return
 }
+
+type TeamMember struct {
+	TeamID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
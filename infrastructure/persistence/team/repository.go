@@ -2,9 +2,11 @@ package team
 
 import (
 	domain "conformitea/domain/team"
+	"conformitea/infrastructure/persistence/internal/nullable"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TeamRepository struct{}
@@ -16,8 +18,123 @@ func (t *TeamRepository) GetTeamByID(DB *gorm.DB, id uuid.UUID) (domain.Team, er
 		return domain.Team{}, err
 	}
 
-	return domain.Team{
-		ID:   team.ID,
-		Name: team.Name,
-	}, nil
+	return toDomainTeam(team), nil
+}
+
+func (t *TeamRepository) GetTeams(DB *gorm.DB, query domain.TeamQuery) ([]domain.Team, int64, error) {
+	scope := DB.Model(&Team{}).Where("organization_id = ?", query.OrganizationID)
+
+	if query.TeamID != uuid.Nil {
+		scope = scope.Where("id = ?", query.TeamID)
+	}
+
+	if query.Name != "" {
+		scope = scope.Where("name = ?", query.Name)
+	}
+
+	if query.ExternalID != "" {
+		scope = scope.Where("external_id = ?", query.ExternalID)
+	}
+
+	// The scope is shared by the count and the page query
+	scope = scope.Session(&gorm.Session{})
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var teams []Team
+	if err := scope.Order("created_at, id").Offset(query.Offset).Limit(query.Limit).Find(&teams).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]domain.Team, 0, len(teams))
+	for _, team := range teams {
+		result = append(result, toDomainTeam(team))
+	}
+
+	return result, total, nil
+}
+
+func (t *TeamRepository) CreateTeam(DB *gorm.DB, team domain.Team) (domain.Team, error) {
+	model := Team{
+		OrganizationID: team.OrganizationID,
+		Name:           team.Name,
+		ExternalID:     nullable.String(team.ExternalID),
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.Team{}, err
+	}
+
+	return toDomainTeam(model), nil
+}
+
+func (t *TeamRepository) UpdateTeam(DB *gorm.DB, team domain.Team) (domain.Team, error) {
+	model := Team{ID: team.ID}
+
+	if err := DB.Model(&model).Updates(map[string]any{
+		"name":        team.Name,
+		"external_id": nullable.String(team.ExternalID),
+	}).Error; err != nil {
+		return domain.Team{}, err
+	}
+
+	return t.GetTeamByID(DB, team.ID)
+}
+
+func (t *TeamRepository) DeleteTeam(DB *gorm.DB, id uuid.UUID) error {
+	return DB.Where("id = ?", id).Delete(&Team{}).Error
+}
+
+func (t *TeamRepository) GetTeamMembers(DB *gorm.DB, teamID uuid.UUID) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+
+	if err := DB.Model(&TeamMember{}).Where("team_id = ?", teamID).Order("created_at").Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+func (t *TeamRepository) AddTeamMembers(DB *gorm.DB, teamID uuid.UUID, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	members := make([]TeamMember, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, TeamMember{TeamID: teamID, UserID: userID})
+	}
+
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+func (t *TeamRepository) RemoveTeamMembers(DB *gorm.DB, teamID uuid.UUID, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	return DB.Where("team_id = ? AND user_id IN ?", teamID, userIDs).Delete(&TeamMember{}).Error
+}
+
+func (t *TeamRepository) RemoveAllTeamMembers(DB *gorm.DB, teamID uuid.UUID) error {
+	return DB.Where("team_id = ?", teamID).Delete(&TeamMember{}).Error
+}
+
+func toDomainTeam(team Team) domain.Team {
+	result := domain.Team{
+		ID:             team.ID,
+		OrganizationID: team.OrganizationID,
+		Name:           team.Name,
+		CreatedAt:      team.CreatedAt,
+		UpdatedAt:      team.UpdatedAt,
+	}
+
+	if team.ExternalID != nil {
+		result.ExternalID = *team.ExternalID
+	}
+
+	return result
 }
@@ -14,6 +14,9 @@ type User struct {
 	Email         string                      `gorm:"type:text;not null;unique"`
 	FirstName     string                      `gorm:"type:text"`
 	LastName      string                      `gorm:"type:text"`
+	DeactivatedAt *time.Time                  `gorm:"type:timestamp"`
+	IsStaff       bool                        `gorm:"not null;default:false"`
+	MergedIntoID  *uuid.UUID                  `gorm:"type:uuid"`
 	CreatedAt     time.Time                   `gorm:"autoCreateTime"`
 	UpdatedAt     time.Time                   `gorm:"autoUpdateTime"`
 	Organizations []organization.Organization `gorm:"many2many:user_organizations;"`
@@ -23,3 +26,36 @@ func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	u.ID, _ = uuid.NewV7()
 	return
 }
+
+type UserIdentity struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null"`
+	Provider  string    `gorm:"type:text;not null"`
+	Subject   string    `gorm:"type:text;not null"`
+	Email     string    `gorm:"type:text"`
+	TenantID  string    `gorm:"type:text;not null;default:''"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID, _ = uuid.NewV7()
+	return
+}
+
+type UserMerge struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	SourceUserID uuid.UUID  `gorm:"type:uuid;not null"`
+	TargetUserID uuid.UUID  `gorm:"type:uuid;not null"`
+	MergedBy     *uuid.UUID `gorm:"type:uuid"`
+	Reason       string     `gorm:"type:text;not null"`
+	Identities   int        `gorm:"not null"`
+	Memberships  int        `gorm:"not null"`
+	Teams        int        `gorm:"not null"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
+
+func (m *UserMerge) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID, _ = uuid.NewV7()
+	return
+}
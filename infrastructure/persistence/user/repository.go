@@ -1,7 +1,13 @@
 package user
 
 import (
+	"time"
+
+	domainOrganization "conformitea/domain/organization"
 	domain "conformitea/domain/user"
+	"conformitea/infrastructure/persistence/internal/nullable"
+	"conformitea/infrastructure/persistence/organization"
+	"conformitea/infrastructure/persistence/team"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -9,17 +15,15 @@ import (
 
 type UserRepository struct{}
 
+// Rows stored before emails were normalized may have uppercase letters.
 func (u *UserRepository) GetUserByEmail(DB *gorm.DB, email string) (domain.User, error) {
 	var user User
 
-	if err := DB.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := DB.Where("lower(email) = ?", email).First(&user).Error; err != nil {
 		return domain.User{}, err
 	}
 
-	return domain.User{
-		ID:    user.ID,
-		Email: user.Email,
-	}, nil
+	return toDomainUser(user), nil
 }
 
 func (u *UserRepository) GetUserByID(DB *gorm.DB, id uuid.UUID) (domain.User, error) {
@@ -29,8 +33,343 @@ func (u *UserRepository) GetUserByID(DB *gorm.DB, id uuid.UUID) (domain.User, er
 		return domain.User{}, err
 	}
 
-	return domain.User{
-		ID:    user.ID,
-		Email: user.Email,
+	return toDomainUser(user), nil
+}
+
+func (u *UserRepository) CreateUser(DB *gorm.DB, user domain.User) (domain.User, error) {
+	model := User{
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		DeactivatedAt: user.DeactivatedAt,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.User{}, err
+	}
+
+	return toDomainUser(model), nil
+}
+
+func (u *UserRepository) UpdateUser(DB *gorm.DB, user domain.User) (domain.User, error) {
+	model := User{ID: user.ID}
+
+	if err := DB.Model(&model).Updates(map[string]any{
+		"email":          user.Email,
+		"first_name":     user.FirstName,
+		"last_name":      user.LastName,
+		"deactivated_at": user.DeactivatedAt,
+		"is_staff":       user.IsStaff,
+		"merged_into_id": user.MergedIntoID,
+	}).Error; err != nil {
+		return domain.User{}, err
+	}
+
+	return u.GetUserByID(DB, user.ID)
+}
+
+func (u *UserRepository) GetMemberships(DB *gorm.DB, userID uuid.UUID) ([]domainOrganization.Membership, error) {
+	var memberships []organization.UserOrganization
+
+	if err := DB.Where("user_id = ?", userID).Order("created_at").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]domainOrganization.Membership, 0, len(memberships))
+	for _, m := range memberships {
+		result = append(result, toDomainMembership(m))
+	}
+
+	return result, nil
+}
+
+func (u *UserRepository) CreateMembership(DB *gorm.DB, membership domainOrganization.Membership) (domainOrganization.Membership, error) {
+	model := organization.UserOrganization{
+		UserID:         membership.UserID,
+		OrganizationID: membership.OrganizationID,
+		Role:           membership.Role,
+		ExternalID:     nullable.String(membership.ExternalID),
+		DeactivatedAt:  membership.DeactivatedAt,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domainOrganization.Membership{}, err
+	}
+
+	return toDomainMembership(model), nil
+}
+
+func (u *UserRepository) UpdateMembership(DB *gorm.DB, membership domainOrganization.Membership) (domainOrganization.Membership, error) {
+	if err := DB.Model(&organization.UserOrganization{}).
+		Where("user_id = ? AND organization_id = ?", membership.UserID, membership.OrganizationID).
+		Updates(map[string]any{
+			"role":           membership.Role,
+			"external_id":    nullable.String(membership.ExternalID),
+			"deactivated_at": membership.DeactivatedAt,
+		}).Error; err != nil {
+		return domainOrganization.Membership{}, err
+	}
+
+	return membership, nil
+}
+
+func (u *UserRepository) GetMember(DB *gorm.DB, organizationID, userID uuid.UUID) (domain.Member, error) {
+	var membership organization.UserOrganization
+
+	if err := DB.Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&membership).Error; err != nil {
+		return domain.Member{}, err
+	}
+
+	user, err := u.GetUserByID(DB, userID)
+	if err != nil {
+		return domain.Member{}, err
+	}
+
+	return domain.Member{
+		User:       user,
+		Membership: toDomainMembership(membership),
+	}, nil
+}
+
+func (u *UserRepository) GetMembers(DB *gorm.DB, query domain.MemberQuery) ([]domain.Member, int64, error) {
+	scope := DB.Model(&organization.UserOrganization{}).
+		Joins("JOIN users ON users.id = user_organizations.user_id").
+		Where("user_organizations.organization_id = ?", query.OrganizationID)
+
+	if query.UserID != uuid.Nil {
+		scope = scope.Where("user_organizations.user_id = ?", query.UserID)
+	}
+
+	if query.Email != "" {
+		scope = scope.Where("LOWER(users.email) = LOWER(?)", query.Email)
+	}
+
+	if query.ExternalID != "" {
+		scope = scope.Where("user_organizations.external_id = ?", query.ExternalID)
+	}
+
+	if query.Role != "" {
+		scope = scope.Where("user_organizations.role = ?", query.Role)
+	}
+
+	// The scope is shared by the count and the page query
+	scope = scope.Session(&gorm.Session{})
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		User
+		Role                    string
+		ExternalID              *string
+		MembershipDeactivatedAt *time.Time
+		JoinedAt                time.Time
+	}
+
+	if err := scope.
+		Select("users.*, user_organizations.role, user_organizations.external_id, " +
+			"user_organizations.deactivated_at AS membership_deactivated_at, user_organizations.created_at AS joined_at").
+		Order("user_organizations.created_at, users.id").
+		Offset(query.Offset).
+		Limit(query.Limit).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]domain.Member, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, domain.Member{
+			User: toDomainUser(row.User),
+			Membership: toDomainMembership(organization.UserOrganization{
+				UserID:         row.ID,
+				OrganizationID: query.OrganizationID,
+				Role:           row.Role,
+				ExternalID:     row.ExternalID,
+				DeactivatedAt:  row.MembershipDeactivatedAt,
+				CreatedAt:      row.JoinedAt,
+			}),
+		})
+	}
+
+	return result, total, nil
+}
+
+func (u *UserRepository) GetIdentity(DB *gorm.DB, provider, subject string) (domain.Identity, error) {
+	var identity UserIdentity
+
+	if err := DB.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return domain.Identity{}, err
+	}
+
+	return toDomainIdentity(identity), nil
+}
+
+func (u *UserRepository) GetIdentities(DB *gorm.DB, userID uuid.UUID) ([]domain.Identity, error) {
+	var identities []UserIdentity
+
+	if err := DB.Where("user_id = ?", userID).Order("updated_at DESC").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Identity, 0, len(identities))
+	for _, identity := range identities {
+		result = append(result, toDomainIdentity(identity))
+	}
+
+	return result, nil
+}
+
+func (u *UserRepository) CreateIdentity(DB *gorm.DB, identity domain.Identity) (domain.Identity, error) {
+	model := UserIdentity{
+		UserID:   identity.UserID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		TenantID: identity.TenantID,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.Identity{}, err
+	}
+
+	return toDomainIdentity(model), nil
+}
+
+func (u *UserRepository) UpdateIdentity(DB *gorm.DB, identity domain.Identity) (domain.Identity, error) {
+	model := UserIdentity{ID: identity.ID}
+
+	if err := DB.Model(&model).Updates(map[string]any{
+		"user_id":   identity.UserID,
+		"email":     identity.Email,
+		"tenant_id": identity.TenantID,
+	}).Error; err != nil {
+		return domain.Identity{}, err
+	}
+
+	return identity, nil
+}
+
+func (u *UserRepository) DeleteIdentity(DB *gorm.DB, userID, id uuid.UUID) (bool, error) {
+	result := DB.Where("id = ? AND user_id = ?", id, userID).Delete(&UserIdentity{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (u *UserRepository) MoveIdentities(DB *gorm.DB, fromUserID, toUserID uuid.UUID) (int64, error) {
+	// The identities keep the time they were last used
+	result := DB.Model(&UserIdentity{}).Where("user_id = ?", fromUserID).UpdateColumn("user_id", toUserID)
+
+	return result.RowsAffected, result.Error
+}
+
+func (u *UserRepository) MoveMembership(DB *gorm.DB, organizationID, fromUserID, toUserID uuid.UUID) error {
+	return DB.Model(&organization.UserOrganization{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, fromUserID).
+		Update("user_id", toUserID).Error
+}
+
+func (u *UserRepository) DeleteMembership(DB *gorm.DB, organizationID, userID uuid.UUID) error {
+	return DB.Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(&organization.UserOrganization{}).Error
+}
+
+func (u *UserRepository) MoveTeamMemberships(DB *gorm.DB, fromUserID, toUserID uuid.UUID) (int64, error) {
+	var moved int64
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		// Teams both users are in keep the target's membership only
+		deleted := tx.
+			Where("user_id = ? AND team_id IN (SELECT team_id FROM team_members WHERE user_id = ?)", fromUserID, toUserID).
+			Delete(&team.TeamMember{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+
+		updated := tx.Model(&team.TeamMember{}).Where("user_id = ?", fromUserID).Update("user_id", toUserID)
+		if updated.Error != nil {
+			return updated.Error
+		}
+
+		moved = deleted.RowsAffected + updated.RowsAffected
+
+		return nil
+	})
+
+	return moved, err
+}
+
+func (u *UserRepository) CreateMerge(DB *gorm.DB, merge domain.Merge) (domain.Merge, error) {
+	model := UserMerge{
+		SourceUserID: merge.SourceUserID,
+		TargetUserID: merge.TargetUserID,
+		MergedBy:     merge.MergedBy,
+		Reason:       merge.Reason,
+		Identities:   merge.Identities,
+		Memberships:  merge.Memberships,
+		Teams:        merge.Teams,
+	}
+
+	if err := DB.Create(&model).Error; err != nil {
+		return domain.Merge{}, err
+	}
+
+	return domain.Merge{
+		ID:           model.ID,
+		SourceUserID: model.SourceUserID,
+		TargetUserID: model.TargetUserID,
+		MergedBy:     model.MergedBy,
+		Reason:       model.Reason,
+		Identities:   model.Identities,
+		Memberships:  model.Memberships,
+		Teams:        model.Teams,
+		CreatedAt:    model.CreatedAt,
 	}, nil
 }
+
+func toDomainUser(user User) domain.User {
+	return domain.User{
+		ID:            user.ID,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		DeactivatedAt: user.DeactivatedAt,
+		IsStaff:       user.IsStaff,
+		MergedIntoID:  user.MergedIntoID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+}
+
+func toDomainMembership(membership organization.UserOrganization) domainOrganization.Membership {
+	result := domainOrganization.Membership{
+		UserID:         membership.UserID,
+		OrganizationID: membership.OrganizationID,
+		Role:           membership.Role,
+		DeactivatedAt:  membership.DeactivatedAt,
+		CreatedAt:      membership.CreatedAt,
+	}
+
+	if membership.ExternalID != nil {
+		result.ExternalID = *membership.ExternalID
+	}
+
+	return result
+}
+
+func toDomainIdentity(identity UserIdentity) domain.Identity {
+	return domain.Identity{
+		ID:        identity.ID,
+		UserID:    identity.UserID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		TenantID:  identity.TenantID,
+		CreatedAt: identity.CreatedAt,
+		UpdatedAt: identity.UpdatedAt,
+	}
+}
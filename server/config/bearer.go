@@ -0,0 +1,18 @@
+package config
+
+import (
+	"fmt"
+)
+
+type BearerConfig struct {
+	// Seconds an introspected access token is cached before Hydra is asked again
+	CacheTTL int `mapstructure:"cache_ttl"`
+}
+
+func (b *BearerConfig) Validate() error {
+	if b.CacheTTL <= 0 {
+		return fmt.Errorf("server.bearer.cache_ttl must be positive")
+	}
+
+	return nil
+}
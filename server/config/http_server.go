@@ -8,6 +8,9 @@ import (
 type HTTPServerConfig struct {
 	Port    string        `mapstructure:"port"`
 	Session SessionConfig `mapstructure:"session"`
+	Bearer  BearerConfig  `mapstructure:"bearer"`
+	// Optional, every route but reads is blocked while impersonating by default
+	Impersonation ImpersonationConfig `mapstructure:"impersonation"`
 }
 
 func (h *HTTPServerConfig) Validate() error {
@@ -20,6 +23,14 @@ func (h *HTTPServerConfig) Validate() error {
 		errs = append(errs, err)
 	}
 
+	if err := h.Bearer.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := h.Impersonation.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type ImpersonationConfig struct {
+	// Routes staff users may call while impersonating besides reads, which are
+	// always allowed, as "METHOD /route", e.g. "POST /organizations/:id/join".
+	// Other routes are blocked, as they could change the subject's data.
+	AllowedRoutes []string `mapstructure:"allowed_routes"`
+}
+
+func (i *ImpersonationConfig) Validate() error {
+	var errs []error
+
+	for _, route := range i.AllowedRoutes {
+		method, path, ok := strings.Cut(route, " ")
+		if !ok || method == "" || method != strings.ToUpper(method) || !strings.HasPrefix(path, "/") {
+			errs = append(errs, fmt.Errorf("server.impersonation.allowed_routes: %q is not of the form \"METHOD /route\"", route))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
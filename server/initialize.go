@@ -8,6 +8,6 @@ import (
 	"go.uber.org/zap"
 )
 
-func Initialize(c config.Config, l *zap.Logger, appAuth types.AppAuth) (types.Server, error) {
-	return internal.Initialize(c, l, appAuth)
+func Initialize(c config.Config, l *zap.Logger, appAuth types.AppAuth, appOrganization types.AppOrganization, appSCIM types.AppSCIM, appClients types.AppClients, appImpersonation types.AppImpersonation, appAccounts types.AppAccounts) (types.Server, error) {
+	return internal.Initialize(c, l, appAuth, appOrganization, appSCIM, appClients, appImpersonation, appAccounts)
 }
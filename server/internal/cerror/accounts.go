@@ -0,0 +1,54 @@
+package cerror
+
+import (
+	"net/http"
+)
+
+// AccountsError represents an error of the user account administration endpoints with ConformiTea error codes.
+type AccountsError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *AccountsError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Code
+}
+
+// ConformiTea account administration error codes
+const (
+	AccountsUserNotFound     = "CT_ACC_000"
+	AccountsPermissionDenied = "CT_ACC_001"
+	AccountsInvalidRequest   = "CT_ACC_002"
+	AccountsMergeConflict    = "CT_ACC_003"
+	AccountsMergeFailed      = "CT_ACC_004"
+)
+
+// NewAccountsError creates a new AccountsError with code, message, and optional details.
+func NewAccountsError(code, message string, details map[string]any) *AccountsError {
+	return &AccountsError{
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+// HTTPStatusCode returns the appropriate HTTP status code for the error.
+func (e *AccountsError) HTTPStatusCode() int {
+	switch e.Code {
+	case AccountsUserNotFound:
+		return http.StatusNotFound
+	case AccountsPermissionDenied:
+		return http.StatusForbidden
+	case AccountsInvalidRequest:
+		return http.StatusBadRequest
+	case AccountsMergeConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
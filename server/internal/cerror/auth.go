@@ -34,6 +34,45 @@ const (
 	AuthTokenIntrospectFailed = "CT_AUTH_008"
 	AuthSessionExpired        = "CT_AUTH_009"
 	AuthInvalidToken          = "CT_AUTH_010"
+	AuthStateMismatch         = "CT_AUTH_011"
+	AuthIDTokenMissing        = "CT_AUTH_012"
+	AuthIDTokenSignature      = "CT_AUTH_013"
+	AuthIDTokenExpired        = "CT_AUTH_014"
+	AuthIDTokenIssuer         = "CT_AUTH_015"
+	AuthIDTokenAudience       = "CT_AUTH_016"
+	AuthIDTokenNonce          = "CT_AUTH_017"
+	AuthIDTokenClaims         = "CT_AUTH_018"
+	AuthEmailMissing          = "CT_AUTH_020"
+	AuthLogoutFailed          = "CT_AUTH_021"
+	AuthInvalidLogoutToken    = "CT_AUTH_022"
+	AuthInsufficientScope     = "CT_AUTH_023"
+	AuthSessionExchange       = "CT_AUTH_024"
+	AuthMFACodeInvalid        = "CT_AUTH_025"
+	AuthMFANotEnrolled        = "CT_AUTH_026"
+	AuthMFAAlreadyEnrolled    = "CT_AUTH_027"
+	AuthMFANotPending         = "CT_AUTH_028"
+	AuthMFARequired           = "CT_AUTH_029"
+	AuthPasskeyInvalid        = "CT_AUTH_030"
+	AuthPasskeyNotFound       = "CT_AUTH_031"
+	AuthAPITokenNotFound      = "CT_AUTH_032"
+	AuthAPITokenInvalid       = "CT_AUTH_033"
+	AuthUserDeactivated       = "CT_AUTH_034"
+	AuthSAMLNotConfigured     = "CT_AUTH_035"
+	AuthSAMLResponseInvalid   = "CT_AUTH_036"
+	AuthSAMLUserConflict      = "CT_AUTH_037"
+	AuthSSORequired           = "CT_AUTH_038"
+	AuthTenantNotAllowed      = "CT_AUTH_039"
+	AuthUserSessionNotFound   = "CT_AUTH_040"
+	AuthUserCodeInvalid       = "CT_AUTH_041"
+	AuthConsentScopeInvalid   = "CT_AUTH_042"
+	AuthConnectedAppNotFound  = "CT_AUTH_043"
+	AuthIdentityNotFound      = "CT_AUTH_044"
+	AuthIdentityConflict      = "CT_AUTH_045"
+	AuthIdentityRequired      = "CT_AUTH_046"
+	AuthEmailTaken            = "CT_AUTH_047"
+	AuthStaffRequired         = "CT_AUTH_048"
+	AuthAuthorizationFailed   = "CT_AUTH_049"
+	AuthMFALocked             = "CT_AUTH_050"
 )
 
 // NewAuthError creates a new AuthError with the specified code and optional details.
@@ -56,13 +95,33 @@ func NewAuthErrorWithMessage(code, message string, details map[string]interface{
 // HTTPStatusCode returns the appropriate HTTP status code for the error.
 func (e *AuthError) HTTPStatusCode() int {
 	switch e.Code {
-	case AuthInvalidState, AuthProviderNotSupported:
+	case AuthInvalidState, AuthProviderNotSupported, AuthStateMismatch, AuthInvalidLogoutToken:
 		return http.StatusBadRequest
 	case AuthSessionNotFound, AuthSessionExpired, AuthInvalidToken:
 		return http.StatusUnauthorized
-	case AuthMicrosoftExchange, AuthMicrosoftProfile, AuthHydraAcceptFailed:
+	case AuthIDTokenMissing, AuthIDTokenSignature, AuthIDTokenExpired, AuthIDTokenIssuer,
+		AuthIDTokenAudience, AuthIDTokenNonce, AuthIDTokenClaims, AuthEmailMissing,
+		AuthSAMLResponseInvalid:
+		return http.StatusUnauthorized
+	case AuthInsufficientScope, AuthMFARequired, AuthUserDeactivated, AuthSAMLUserConflict, AuthSSORequired,
+		AuthTenantNotAllowed, AuthStaffRequired:
+		return http.StatusForbidden
+	case AuthMFACodeInvalid, AuthMFANotPending, AuthPasskeyInvalid:
+		return http.StatusUnauthorized
+	case AuthMFALocked:
+		return http.StatusTooManyRequests
+	case AuthPasskeyNotFound, AuthAPITokenNotFound, AuthSAMLNotConfigured, AuthUserSessionNotFound,
+		AuthConnectedAppNotFound, AuthIdentityNotFound:
+		return http.StatusNotFound
+	case AuthAPITokenInvalid, AuthUserCodeInvalid, AuthConsentScopeInvalid:
+		return http.StatusBadRequest
+	case AuthMFANotEnrolled:
+		return http.StatusBadRequest
+	case AuthMFAAlreadyEnrolled, AuthIdentityConflict, AuthIdentityRequired, AuthEmailTaken:
+		return http.StatusConflict
+	case AuthMicrosoftExchange, AuthMicrosoftProfile, AuthHydraAcceptFailed, AuthLogoutFailed, AuthSessionExchange:
 		return http.StatusBadGateway
-	case AuthSessionCreateFailed, AuthTokenIntrospectFailed:
+	case AuthSessionCreateFailed, AuthTokenIntrospectFailed, AuthAuthorizationFailed:
 		return http.StatusInternalServerError
 	default:
 		return http.StatusInternalServerError
@@ -0,0 +1,51 @@
+package cerror
+
+import (
+	"net/http"
+)
+
+// ClientError represents an error of the OAuth2 client admin endpoints with ConformiTea error codes.
+type ClientError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *ClientError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Code
+}
+
+// ConformiTea OAuth2 client error codes
+const (
+	ClientNotFound         = "CT_CLIENT_000"
+	ClientPermissionDenied = "CT_CLIENT_001"
+	ClientInvalidRequest   = "CT_CLIENT_002"
+	ClientRequestFailed    = "CT_CLIENT_003"
+)
+
+// NewClientError creates a new ClientError with code, message, and optional details.
+func NewClientError(code, message string, details map[string]any) *ClientError {
+	return &ClientError{
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+// HTTPStatusCode returns the appropriate HTTP status code for the error.
+func (e *ClientError) HTTPStatusCode() int {
+	switch e.Code {
+	case ClientNotFound:
+		return http.StatusNotFound
+	case ClientPermissionDenied:
+		return http.StatusForbidden
+	case ClientInvalidRequest:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
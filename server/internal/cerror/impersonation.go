@@ -0,0 +1,57 @@
+package cerror
+
+import (
+	"net/http"
+)
+
+// ImpersonationError represents an error of the impersonation and audit endpoints with ConformiTea error codes.
+type ImpersonationError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *ImpersonationError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Code
+}
+
+// ConformiTea impersonation error codes
+const (
+	ImpersonationUserNotFound     = "CT_IMP_000"
+	ImpersonationPermissionDenied = "CT_IMP_001"
+	ImpersonationInvalidRequest   = "CT_IMP_002"
+	ImpersonationActive           = "CT_IMP_003"
+	ImpersonationNotActive        = "CT_IMP_004"
+	ImpersonationBlocked          = "CT_IMP_005"
+	ImpersonationExpired          = "CT_IMP_006"
+	ImpersonationRequestFailed    = "CT_IMP_007"
+)
+
+// NewImpersonationError creates a new ImpersonationError with code, message, and optional details.
+func NewImpersonationError(code, message string, details map[string]any) *ImpersonationError {
+	return &ImpersonationError{
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+// HTTPStatusCode returns the appropriate HTTP status code for the error.
+func (e *ImpersonationError) HTTPStatusCode() int {
+	switch e.Code {
+	case ImpersonationUserNotFound:
+		return http.StatusNotFound
+	case ImpersonationPermissionDenied, ImpersonationBlocked:
+		return http.StatusForbidden
+	case ImpersonationInvalidRequest:
+		return http.StatusBadRequest
+	case ImpersonationActive, ImpersonationNotActive, ImpersonationExpired:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,76 @@
+package cerror
+
+import (
+	"net/http"
+)
+
+// OrganizationError represents an error of the organization endpoints with ConformiTea error codes.
+type OrganizationError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *OrganizationError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Code
+}
+
+// ConformiTea organization error codes
+const (
+	OrganizationNotFound         = "CT_ORG_000"
+	OrganizationPermissionDenied = "CT_ORG_001"
+	OrganizationInvalidRequest   = "CT_ORG_002"
+	OrganizationUpdateFailed     = "CT_ORG_003"
+	ServiceAccountNotFound       = "CT_ORG_004"
+	ServiceAccountTokenNotFound  = "CT_ORG_005"
+	ServiceAccountRequestFailed  = "CT_ORG_006"
+	SAMLConnectionNotFound       = "CT_ORG_007"
+	SAMLMetadataInvalid          = "CT_ORG_008"
+	SAMLConnectionRequestFailed  = "CT_ORG_009"
+	DomainNotFound               = "CT_ORG_010"
+	DomainConflict               = "CT_ORG_011"
+	DomainNotVerified            = "CT_ORG_012"
+	DomainRequestFailed          = "CT_ORG_013"
+	TenantConflict               = "CT_ORG_014"
+	MemberNotFound               = "CT_ORG_015"
+	MemberRequestFailed          = "CT_ORG_016"
+	RoleNotFound                 = "CT_ORG_017"
+	RoleConflict                 = "CT_ORG_018"
+	RoleRequestFailed            = "CT_ORG_019"
+	AuthorizationFailed          = "CT_ORG_020"
+	InvitationNotFound           = "CT_ORG_021"
+	InvitationInvalid            = "CT_ORG_022"
+	InvitationConflict           = "CT_ORG_023"
+	InvitationEmailMismatch      = "CT_ORG_024"
+	InvitationRequestFailed      = "CT_ORG_025"
+)
+
+// NewOrganizationError creates a new OrganizationError with code, message, and optional details.
+func NewOrganizationError(code, message string, details map[string]any) *OrganizationError {
+	return &OrganizationError{
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+// HTTPStatusCode returns the appropriate HTTP status code for the error.
+func (e *OrganizationError) HTTPStatusCode() int {
+	switch e.Code {
+	case OrganizationNotFound, ServiceAccountNotFound, ServiceAccountTokenNotFound, SAMLConnectionNotFound, DomainNotFound,
+		MemberNotFound, RoleNotFound, InvitationNotFound:
+		return http.StatusNotFound
+	case OrganizationPermissionDenied, InvitationEmailMismatch:
+		return http.StatusForbidden
+	case OrganizationInvalidRequest, SAMLMetadataInvalid, DomainNotVerified, InvitationInvalid:
+		return http.StatusBadRequest
+	case DomainConflict, TenantConflict, RoleConflict, InvitationConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
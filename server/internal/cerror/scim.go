@@ -0,0 +1,51 @@
+package cerror
+
+import (
+	"strconv"
+)
+
+// Schema of SCIM error responses.
+const SCIMErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+// SCIMError represents an error of the SCIM endpoints. SCIM clients expect the
+// error format of RFC 7644 section 3.12 rather than ConformiTea error codes.
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *SCIMError) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return e.ScimType
+}
+
+// SCIM error types
+const (
+	SCIMInvalidFilter = "invalidFilter"
+	SCIMInvalidSyntax = "invalidSyntax"
+	SCIMInvalidPath   = "invalidPath"
+	SCIMInvalidValue  = "invalidValue"
+	SCIMUniqueness    = "uniqueness"
+)
+
+// NewSCIMError creates a new SCIMError with an HTTP status, an optional SCIM error
+// type and a detail message.
+func NewSCIMError(status int, scimType, detail string) *SCIMError {
+	return &SCIMError{
+		Schemas:  []string{SCIMErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+// HTTPStatusCode returns the HTTP status code of the error.
+func (e *SCIMError) HTTPStatusCode() int {
+	status, _ := strconv.Atoi(e.Status)
+	return status
+}
@@ -0,0 +1,54 @@
+package gin_session
+
+import "strings"
+
+// User agent tokens of browsers and operating systems, checked in order because
+// user agents also name the engines they are compatible with.
+var (
+	browserTokens = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"CriOS/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	}
+	osTokens = []struct{ token, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// Returns a readable name of the browser and operating system of a user agent,
+// e.g. "Firefox on Windows".
+func describeDevice(userAgent string) string {
+	browser := matchToken(userAgent, browserTokens)
+	os := matchToken(userAgent, osTokens)
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return "Unknown device"
+	}
+}
+
+func matchToken(userAgent string, tokens []struct{ token, name string }) string {
+	for _, t := range tokens {
+		if strings.Contains(userAgent, t.token) {
+			return t.name
+		}
+	}
+
+	return ""
+}
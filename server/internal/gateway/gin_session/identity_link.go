@@ -0,0 +1,64 @@
+package gin_session
+
+import (
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+)
+
+// Session keys of the identity provider account a signed in user is linking.
+const (
+	identityLinkUserKey     = "identity_link_user_id"
+	identityLinkProviderKey = "identity_link_provider"
+	identityLinkStateKey    = "identity_link_state"
+	identityLinkNonceKey    = "identity_link_nonce"
+)
+
+// IdentityLink is the account linking a user started, until the identity provider
+// calls back.
+type IdentityLink struct {
+	UserID   string
+	Provider string
+	State    string
+	Nonce    string
+}
+
+// Stores the account linking the user started in their session.
+func SetIdentityLink(session sessions.Session, userID string, link types.IdentityLinkResult) {
+	session.Set(identityLinkUserKey, userID)
+	session.Set(identityLinkProviderKey, link.Provider)
+	session.Set(identityLinkStateKey, link.State)
+	session.Set(identityLinkNonceKey, link.Nonce)
+}
+
+// Returns the account linking stored in the session. Reports false when the user
+// is not linking an account.
+func GetIdentityLink(session sessions.Session) (IdentityLink, bool) {
+	userID, _ := session.Get(identityLinkUserKey).(string)
+	state, _ := session.Get(identityLinkStateKey).(string)
+	if userID == "" || state == "" {
+		return IdentityLink{}, false
+	}
+
+	provider, _ := session.Get(identityLinkProviderKey).(string)
+	nonce, _ := session.Get(identityLinkNonceKey).(string)
+
+	return IdentityLink{
+		UserID:   userID,
+		Provider: provider,
+		State:    state,
+		Nonce:    nonce,
+	}, true
+}
+
+// Removes the account linking from the session so its callback cannot be replayed.
+func ClearIdentityLink(session sessions.Session) {
+	for _, key := range []string{
+		identityLinkUserKey,
+		identityLinkProviderKey,
+		identityLinkStateKey,
+		identityLinkNonceKey,
+	} {
+		session.Delete(key)
+	}
+}
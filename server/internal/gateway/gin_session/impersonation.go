@@ -0,0 +1,81 @@
+package gin_session
+
+import (
+	"time"
+
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+)
+
+// Session keys of the impersonation a staff user started. The staff user stays
+// signed in under the user keys set at sign in.
+const (
+	impersonationIDKey        = "impersonation_id"
+	impersonatorEmailKey      = "impersonator_email"
+	impersonationSubjectKey   = "impersonation_subject_id"
+	impersonationEmailKey     = "impersonation_subject_email"
+	impersonationNameKey      = "impersonation_subject_name"
+	impersonationReasonKey    = "impersonation_reason"
+	impersonationStartedAtKey = "impersonation_started_at"
+	impersonationExpiresAtKey = "impersonation_expires_at"
+	signedInUserIDKey         = "user_id"
+)
+
+// Stores the impersonation in the session of the staff user who started it.
+func SetImpersonation(session sessions.Session, impersonation types.Impersonation) {
+	session.Set(impersonationIDKey, impersonation.ID)
+	session.Set(impersonatorEmailKey, impersonation.ImpersonatorEmail)
+	session.Set(impersonationSubjectKey, impersonation.SubjectID)
+	session.Set(impersonationEmailKey, impersonation.SubjectEmail)
+	session.Set(impersonationNameKey, impersonation.SubjectName)
+	session.Set(impersonationReasonKey, impersonation.Reason)
+	session.Set(impersonationStartedAtKey, impersonation.StartedAt.Unix())
+	session.Set(impersonationExpiresAtKey, impersonation.ExpiresAt.Unix())
+}
+
+// Returns the impersonation stored in the session, expired or not. Reports false
+// when the session is not impersonating.
+func GetImpersonation(session sessions.Session) (types.Impersonation, bool) {
+	id, _ := session.Get(impersonationIDKey).(string)
+	subjectID, _ := session.Get(impersonationSubjectKey).(string)
+	if id == "" || subjectID == "" {
+		return types.Impersonation{}, false
+	}
+
+	impersonatorID, _ := session.Get(signedInUserIDKey).(string)
+	impersonatorEmail, _ := session.Get(impersonatorEmailKey).(string)
+	email, _ := session.Get(impersonationEmailKey).(string)
+	name, _ := session.Get(impersonationNameKey).(string)
+	reason, _ := session.Get(impersonationReasonKey).(string)
+	startedAt, _ := session.Get(impersonationStartedAtKey).(int64)
+	expiresAt, _ := session.Get(impersonationExpiresAtKey).(int64)
+
+	return types.Impersonation{
+		ID:                id,
+		ImpersonatorID:    impersonatorID,
+		ImpersonatorEmail: impersonatorEmail,
+		SubjectID:         subjectID,
+		SubjectEmail:      email,
+		SubjectName:       name,
+		Reason:            reason,
+		StartedAt:         time.Unix(startedAt, 0),
+		ExpiresAt:         time.Unix(expiresAt, 0),
+	}, true
+}
+
+// Ends the impersonation of the session, the staff user acts as themself again.
+func ClearImpersonation(session sessions.Session) {
+	for _, key := range []string{
+		impersonationIDKey,
+		impersonatorEmailKey,
+		impersonationSubjectKey,
+		impersonationEmailKey,
+		impersonationNameKey,
+		impersonationReasonKey,
+		impersonationStartedAtKey,
+		impersonationExpiresAtKey,
+	} {
+		session.Delete(key)
+	}
+}
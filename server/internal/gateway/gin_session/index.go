@@ -0,0 +1,363 @@
+package gin_session
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gomodule/redigo/redis"
+	gsessions "github.com/gorilla/sessions"
+)
+
+// SessionInfo describes a signed in session, so its user can tell their sessions
+// apart.
+type SessionInfo struct {
+	ID        string
+	UserAgent string
+	// Browser and operating system read from the user agent
+	Device string
+	// Address of the most recent request
+	IP string
+	// Hydra login session the session's tokens were issued in
+	HydraSessionID string
+	CreatedAt      time.Time
+	LastSeenAt     time.Time
+}
+
+// Index tracks the sessions of every subject so they can be listed and revoked,
+// e.g. when the subject signs out in another application.
+type Index struct {
+	pool *redis.Pool
+	// Seconds an index entry outlives the last session added to it
+	ttl int
+}
+
+func NewIndex(pool *redis.Pool, ttl int) *Index {
+	return &Index{
+		pool: pool,
+		ttl:  ttl,
+	}
+}
+
+// Records that sessionID belongs to subject.
+func (i *Index) Add(subject, sessionID string, info SessionInfo) error {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	now := time.Now()
+
+	if _, err := conn.Do("HSET", redis.Args{}.Add(infoKey(sessionID)).AddFlat(map[string]any{
+		"user_agent":       info.UserAgent,
+		"device":           describeDevice(info.UserAgent),
+		"ip":               info.IP,
+		"hydra_session_id": info.HydraSessionID,
+		"created_at":       now.Unix(),
+		"last_seen_at":     now.Unix(),
+	})...); err != nil {
+		return fmt.Errorf("failed to store session info: %w", err)
+	}
+
+	if _, err := conn.Do("EXPIRE", infoKey(sessionID), i.ttl); err != nil {
+		return fmt.Errorf("failed to set session info expiry: %w", err)
+	}
+
+	key := indexKey(subject)
+
+	if _, err := conn.Do("SADD", key, sessionID); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+
+	if _, err := conn.Do("EXPIRE", key, i.ttl); err != nil {
+		return fmt.Errorf("failed to set session index expiry: %w", err)
+	}
+
+	return nil
+}
+
+// Records a request of the session from ip.
+func (i *Index) Touch(sessionID, ip string) error {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	key := infoKey(sessionID)
+
+	// Sessions revoked while the request was in flight stay gone
+	exists, err := redis.Bool(conn.Do("EXISTS", key))
+	if err != nil || !exists {
+		return err
+	}
+
+	if _, err := conn.Do("HSET", key, "ip", ip, "last_seen_at", time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to update session info: %w", err)
+	}
+
+	if _, err := conn.Do("EXPIRE", key, i.ttl); err != nil {
+		return fmt.Errorf("failed to set session info expiry: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the sessions of subject, most recently seen first. Expired sessions are
+// dropped from the index.
+func (i *Index) List(subject string) ([]SessionInfo, error) {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	sessionIDs, err := redis.Strings(conn.Do("SMEMBERS", indexKey(subject)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed sessions: %w", err)
+	}
+
+	result := make([]SessionInfo, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		info, found, err := i.info(conn, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			if err := i.forget(conn, subject, id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		result = append(result, info)
+	}
+
+	slices.SortFunc(result, func(a, b SessionInfo) int {
+		return b.LastSeenAt.Compare(a.LastSeenAt)
+	})
+
+	return result, nil
+}
+
+// Returns a session of subject and the tokens stored in it.
+func (i *Index) Get(subject, sessionID string) (SessionInfo, types.SessionTokens, bool, error) {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	member, err := redis.Bool(conn.Do("SISMEMBER", indexKey(subject), sessionID))
+	if err != nil {
+		return SessionInfo{}, types.SessionTokens{}, false, fmt.Errorf("failed to look up indexed session: %w", err)
+	}
+	if !member {
+		return SessionInfo{}, types.SessionTokens{}, false, nil
+	}
+
+	info, found, err := i.info(conn, sessionID)
+	if err != nil || !found {
+		return SessionInfo{}, types.SessionTokens{}, false, err
+	}
+
+	values, found, err := storedValues(conn, sessionID)
+	if err != nil || !found {
+		return SessionInfo{}, types.SessionTokens{}, false, err
+	}
+
+	return info, tokensFrom(func(key any) any { return values[key] }), true, nil
+}
+
+// Forgets that sessionID belongs to subject.
+func (i *Index) Remove(subject, sessionID string) error {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	return i.forget(conn, subject, sessionID)
+}
+
+// Deletes a session of subject.
+func (i *Index) Revoke(subject, sessionID string) error {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("DEL", sessionKeyPrefix+sessionID); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return i.forget(conn, subject, sessionID)
+}
+
+// Gives the session a new ID when it is saved next and deletes what was stored
+// under its current one, so an ID planted in the browser before the user signed
+// in does not identify their session. A signed in session is forgotten as well.
+func (i *Index) Renew(session sessions.Session) error {
+	stored, ok := session.(interface{ Session() *gsessions.Session })
+	if !ok {
+		return errors.New("session does not expose its store session")
+	}
+
+	s := stored.Session()
+	if s == nil || s.ID == "" {
+		return nil
+	}
+
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("DEL", sessionKeyPrefix+s.ID); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	if subject, _ := session.Get("user_id").(string); subject != "" {
+		if err := i.forget(conn, subject, s.ID); err != nil {
+			return err
+		}
+	}
+
+	s.ID = ""
+
+	return nil
+}
+
+// Deletes the sessions of subject whose tokens were issued in a Hydra login
+// session and returns how many were deleted. Sessions of an unknown Hydra login
+// session are deleted as well.
+func (i *Index) RevokeHydraSession(subject, hydraSessionID string) (int, error) {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	sessionIDs, err := redis.Strings(conn.Do("SMEMBERS", indexKey(subject)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list indexed sessions: %w", err)
+	}
+
+	revoked := 0
+	for _, id := range sessionIDs {
+		info, found, err := i.info(conn, id)
+		if err != nil {
+			return revoked, err
+		}
+
+		if !found {
+			if err := i.forget(conn, subject, id); err != nil {
+				return revoked, err
+			}
+			continue
+		}
+
+		if info.HydraSessionID != "" && info.HydraSessionID != hydraSessionID {
+			continue
+		}
+
+		if _, err := conn.Do("DEL", sessionKeyPrefix+id); err != nil {
+			return revoked, fmt.Errorf("failed to delete session: %w", err)
+		}
+
+		if err := i.forget(conn, subject, id); err != nil {
+			return revoked, err
+		}
+
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+// Deletes every session of subject and returns how many were deleted.
+func (i *Index) RevokeSubject(subject string) (int, error) {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	key := indexKey(subject)
+
+	sessionIDs, err := redis.Strings(conn.Do("SMEMBERS", key))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list indexed sessions: %w", err)
+	}
+
+	keys := redis.Args{}.Add(key)
+	for _, id := range sessionIDs {
+		keys = keys.Add(sessionKeyPrefix+id, infoKey(id))
+	}
+
+	if _, err := conn.Do("DEL", keys...); err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	return len(sessionIDs), nil
+}
+
+// Returns the stored info of a session. Sessions that expired are not found.
+func (i *Index) info(conn redis.Conn, sessionID string) (SessionInfo, bool, error) {
+	exists, err := redis.Bool(conn.Do("EXISTS", sessionKeyPrefix+sessionID))
+	if err != nil {
+		return SessionInfo{}, false, fmt.Errorf("failed to look up session: %w", err)
+	}
+	if !exists {
+		return SessionInfo{}, false, nil
+	}
+
+	fields, err := redis.StringMap(conn.Do("HGETALL", infoKey(sessionID)))
+	if err != nil {
+		return SessionInfo{}, false, fmt.Errorf("failed to get session info: %w", err)
+	}
+
+	// Sessions indexed before their info was stored are listed without it
+	return SessionInfo{
+		ID:             sessionID,
+		UserAgent:      fields["user_agent"],
+		Device:         fields["device"],
+		IP:             fields["ip"],
+		HydraSessionID: fields["hydra_session_id"],
+		CreatedAt:      unixField(fields["created_at"]),
+		LastSeenAt:     unixField(fields["last_seen_at"]),
+	}, true, nil
+}
+
+// Returns the values stored in a session. Sessions that expired are not found.
+func storedValues(conn redis.Conn, sessionID string) (map[any]any, bool, error) {
+	data, err := redis.Bytes(conn.Do("GET", sessionKeyPrefix+sessionID))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	// The store serializes session values with gob
+	var values map[any]any
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+		return nil, false, fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	return values, true, nil
+}
+
+func (i *Index) forget(conn redis.Conn, subject, sessionID string) error {
+	if _, err := conn.Do("SREM", indexKey(subject), sessionID); err != nil {
+		return fmt.Errorf("failed to remove session from index: %w", err)
+	}
+
+	if _, err := conn.Do("DEL", infoKey(sessionID)); err != nil {
+		return fmt.Errorf("failed to delete session info: %w", err)
+	}
+
+	return nil
+}
+
+func indexKey(subject string) string {
+	return "subject_sessions:" + subject
+}
+
+func infoKey(sessionID string) string {
+	return "session_info:" + sessionID
+}
+
+// Returns the time of a Unix timestamp field, or the zero time when it is unset.
+func unixField(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
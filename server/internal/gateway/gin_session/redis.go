@@ -7,19 +7,27 @@ import (
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/redis"
+	redigo "github.com/gomodule/redigo/redis"
 )
 
-func NewStore(cfg config.Config) (sessions.Store, error) {
+// Prefix of the Redis keys holding session data.
+const sessionKeyPrefix = "session_"
+
+func NewStore(cfg config.Config, pool *redigo.Pool) (sessions.Store, error) {
 	var keyBytes [][]byte
 	for _, k := range cfg.HTTPServer.Session.KeyPairs {
 		keyBytes = append(keyBytes, []byte(k))
 	}
 
-	store, err := redis.NewStore(10, "tcp", cfg.Redis.Address, cfg.Redis.User, cfg.Redis.Password, keyBytes...)
+	store, err := redis.NewStoreWithPool(pool, keyBytes...)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := redis.SetKeyPrefix(store, sessionKeyPrefix); err != nil {
+		return nil, err
+	}
+
 	store.Options(sessions.Options{
 		Path:     "/",
 		Secure:   true,
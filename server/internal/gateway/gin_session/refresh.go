@@ -0,0 +1,103 @@
+package gin_session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"conformitea/server/types"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// How long a request holds the refresh lock of a session at most, so a request
+// that dies holding it does not block the session for longer.
+const refreshLockTTL = 10 * time.Second
+
+// How often a request waiting for the refresh lock of a session tries to take it.
+const refreshLockRetry = 50 * time.Millisecond
+
+// Deletes the lock only when it is still held with the given token, so a request
+// whose lock expired does not release the lock of another request.
+var unlockScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Takes the lock on refreshing the tokens of a session, waiting for the request
+// holding it to finish. Refresh tokens are single use, so concurrent requests of
+// the session would otherwise refresh with the same token and all but one fail.
+// The returned function releases the lock.
+func (i *Index) LockRefresh(ctx context.Context, sessionID string) (func(), error) {
+	var value [16]byte
+	if _, err := rand.Read(value[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(value[:])
+	key := refreshLockKey(sessionID)
+
+	ctx, cancel := context.WithTimeout(ctx, refreshLockTTL)
+	defer cancel()
+
+	for {
+		acquired, err := i.tryLock(key, token)
+		if err != nil {
+			return nil, err
+		}
+
+		if acquired {
+			return func() { i.unlock(key, token) }, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to lock session refresh: %w", ctx.Err())
+		case <-time.After(refreshLockRetry):
+		}
+	}
+}
+
+// Returns the tokens stored in a session by the last request that saved it.
+func (i *Index) StoredTokens(sessionID string) (types.SessionTokens, bool, error) {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	values, found, err := storedValues(conn, sessionID)
+	if err != nil || !found {
+		return types.SessionTokens{}, false, err
+	}
+
+	return tokensFrom(func(key any) any { return values[key] }), true, nil
+}
+
+func (i *Index) tryLock(key, token string) (bool, error) {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	_, err := redis.String(conn.Do("SET", key, token, "NX", "PX", refreshLockTTL.Milliseconds()))
+	if errors.Is(err, redis.ErrNil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock session refresh: %w", err)
+	}
+
+	return true, nil
+}
+
+// Releases the lock. A lock that cannot be released expires on its own.
+func (i *Index) unlock(key, token string) {
+	conn := i.pool.Get()
+	defer conn.Close()
+
+	_, _ = unlockScript.Do(conn, key, token)
+}
+
+func refreshLockKey(sessionID string) string {
+	return "session_refresh_lock:" + sessionID
+}
@@ -0,0 +1,49 @@
+package gin_session
+
+import (
+	"time"
+
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+)
+
+// Session keys of the tokens Hydra issued for the signed in user.
+const (
+	accessTokenKey  = "access_token"
+	refreshTokenKey = "refresh_token"
+	idTokenKey      = "id_token"
+	tokenExpiryKey  = "token_expiry"
+)
+
+// Stores the tokens in the session. An empty ID token keeps the stored one, as
+// Hydra does not always return one on refresh.
+func SetTokens(session sessions.Session, tokens types.SessionTokens) {
+	session.Set(accessTokenKey, tokens.AccessToken)
+	session.Set(refreshTokenKey, tokens.RefreshToken)
+	session.Set(tokenExpiryKey, tokens.ExpiresAt.Unix())
+
+	if tokens.IDToken != "" {
+		session.Set(idTokenKey, tokens.IDToken)
+	}
+}
+
+// Returns the tokens stored in the session.
+func GetTokens(session sessions.Session) types.SessionTokens {
+	return tokensFrom(session.Get)
+}
+
+// Returns the tokens among the session values get returns.
+func tokensFrom(get func(key any) any) types.SessionTokens {
+	accessToken, _ := get(accessTokenKey).(string)
+	refreshToken, _ := get(refreshTokenKey).(string)
+	idToken, _ := get(idTokenKey).(string)
+	expiry, _ := get(tokenExpiryKey).(int64)
+
+	return types.SessionTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		IDToken:      idToken,
+		ExpiresAt:    time.Unix(expiry, 0),
+	}
+}
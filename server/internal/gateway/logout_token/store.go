@@ -0,0 +1,47 @@
+package logout_token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Store remembers the identifiers of the back-channel logout tokens Hydra sent,
+// so a token that was intercepted cannot be replayed.
+type Store struct {
+	pool *redis.Pool
+}
+
+func NewStore(pool *redis.Pool) *Store {
+	return &Store{
+		pool: pool,
+	}
+}
+
+// Records the use of the token with the given identifier until the token is no
+// longer accepted. Reports whether the token was used before.
+func (s *Store) Use(tokenID string, validUntil time.Time) (bool, error) {
+	ttl := time.Until(validUntil).Milliseconds()
+	if ttl <= 0 {
+		return true, nil
+	}
+
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	_, err := redis.String(conn.Do("SET", tokenKey(tokenID), 1, "NX", "PX", ttl))
+	if errors.Is(err, redis.ErrNil) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record logout token: %w", err)
+	}
+
+	return false, nil
+}
+
+func tokenKey(tokenID string) string {
+	return "logout_token:" + tokenID
+}
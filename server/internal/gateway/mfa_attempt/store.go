@@ -0,0 +1,83 @@
+package mfa_attempt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Counts a failure and starts the window on the first one. Once the user reaches
+// the limit, the window restarts with every failure, so the lockout lasts until
+// the user stops trying.
+var failScript = redis.NewScript(1, `
+local failures = redis.call("INCR", KEYS[1])
+if failures == 1 or failures >= tonumber(ARGV[1]) then
+	redis.call("EXPIRE", KEYS[1], ARGV[2])
+end
+return failures
+`)
+
+// Store counts the wrong second factors each user gave, across sessions and
+// logins, so they cannot be guessed by starting new logins. A user who gives too
+// many within the lockout window cannot pass the second factor until it ends.
+type Store struct {
+	pool        *redis.Pool
+	maxFailures int
+	// Seconds the failures of a user are counted, and the lockout lasts
+	lockout int
+}
+
+func NewStore(pool *redis.Pool, maxFailures int, lockout int) *Store {
+	return &Store{
+		pool:        pool,
+		maxFailures: maxFailures,
+		lockout:     lockout,
+	}
+}
+
+// Reports whether the user gave too many wrong second factors.
+func (s *Store) Locked(userID string) (bool, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	failures, err := redis.Int(conn.Do("GET", failuresKey(userID)))
+	if errors.Is(err, redis.ErrNil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get second factor failures: %w", err)
+	}
+
+	return failures >= s.maxFailures, nil
+}
+
+// Counts a wrong second factor of the user and returns how many more they may
+// give before they are locked out.
+func (s *Store) Fail(userID string) (int, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	failures, err := redis.Int(failScript.Do(conn, failuresKey(userID), s.maxFailures, s.lockout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count second factor failure: %w", err)
+	}
+
+	return max(s.maxFailures-failures, 0), nil
+}
+
+// Forgets the failures of the user once they passed the second factor.
+func (s *Store) Reset(userID string) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("DEL", failuresKey(userID)); err != nil {
+		return fmt.Errorf("failed to reset second factor failures: %w", err)
+	}
+
+	return nil
+}
+
+func failuresKey(userID string) string {
+	return "mfa_failures:" + userID
+}
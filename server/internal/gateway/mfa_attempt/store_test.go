@@ -0,0 +1,159 @@
+package mfa_attempt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	testMaxFailures = 3
+	testLockout     = 60
+)
+
+// Returns a store backed by an in-memory Redis server, and the server.
+func newTestStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", server.Addr())
+		},
+	}
+	t.Cleanup(func() { _ = pool.Close() })
+
+	return NewStore(pool, testMaxFailures, testLockout), server
+}
+
+// Step of a test against the store and its server.
+type step func(t *testing.T, s *Store, server *miniredis.Miniredis)
+
+// Counts a failure of the test user.
+func fail(t *testing.T, s *Store, _ *miniredis.Miniredis) {
+	if _, err := s.Fail("user"); err != nil {
+		t.Fatalf("Fail() error = %v", err)
+	}
+}
+
+// Forgets the failures of the test user.
+func reset(t *testing.T, s *Store, _ *miniredis.Miniredis) {
+	if err := s.Reset("user"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+}
+
+// Lets d pass on the server.
+func wait(d time.Duration) step {
+	return func(_ *testing.T, _ *Store, server *miniredis.Miniredis) {
+		server.FastForward(d)
+	}
+}
+
+func TestStoreLockout(t *testing.T) {
+	lockout := testLockout * time.Second
+
+	tests := []struct {
+		name          string
+		steps         []step
+		wantLocked    bool
+		wantRemaining int
+	}{
+		{
+			name:          "no failures",
+			wantRemaining: testMaxFailures,
+		},
+		{
+			name:          "failures below the limit",
+			steps:         []step{fail, fail},
+			wantRemaining: 1,
+		},
+		{
+			name:       "failures reaching the limit",
+			steps:      []step{fail, fail, fail},
+			wantLocked: true,
+		},
+		{
+			name:       "failures beyond the limit",
+			steps:      []step{fail, fail, fail, fail},
+			wantLocked: true,
+		},
+		{
+			name:          "reset below the limit",
+			steps:         []step{fail, fail, reset},
+			wantRemaining: testMaxFailures,
+		},
+		{
+			name:          "reset after reaching the limit",
+			steps:         []step{fail, fail, fail, reset},
+			wantRemaining: testMaxFailures,
+		},
+		{
+			name:       "failures before the window ends",
+			steps:      []step{fail, fail, wait(lockout - time.Second), fail},
+			wantLocked: true,
+		},
+		{
+			name:          "failures after the window ends",
+			steps:         []step{fail, fail, wait(lockout + time.Second), fail},
+			wantRemaining: testMaxFailures - 1,
+		},
+		{
+			name:          "lockout ending",
+			steps:         []step{fail, fail, fail, wait(lockout + time.Second)},
+			wantRemaining: testMaxFailures,
+		},
+		{
+			name:       "failures during the lockout",
+			steps:      []step{fail, fail, fail, wait(lockout - time.Second), fail, wait(lockout - time.Second)},
+			wantLocked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, server := newTestStore(t)
+
+			for _, step := range tt.steps {
+				step(t, store, server)
+			}
+
+			locked, err := store.Locked("user")
+			if err != nil {
+				t.Fatalf("Locked() error = %v", err)
+			}
+			if locked != tt.wantLocked {
+				t.Errorf("Locked() = %t, want %t", locked, tt.wantLocked)
+			}
+
+			// Another failure reports what remains after it
+			remaining, err := store.Fail("user")
+			if err != nil {
+				t.Fatalf("Fail() error = %v", err)
+			}
+			if want := max(tt.wantRemaining-1, 0); remaining != want {
+				t.Errorf("Fail() = %d, want %d", remaining, want)
+			}
+		})
+	}
+}
+
+func TestStoreUsersCountedApart(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	for range testMaxFailures {
+		if _, err := store.Fail("locked"); err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+	}
+
+	locked, err := store.Locked("other")
+	if err != nil {
+		t.Fatalf("Locked() error = %v", err)
+	}
+	if locked {
+		t.Error("Locked() = true for a user without failures")
+	}
+}
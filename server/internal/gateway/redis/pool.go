@@ -0,0 +1,28 @@
+package redis
+
+import (
+	"time"
+
+	"conformitea/server/config"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// NewPool creates the Redis connection pool shared by the session store and the
+// other Redis-backed components of the server.
+func NewPool(cfg config.RedisConfig) *redis.Pool {
+	return &redis.Pool{
+		MaxIdle:     10,
+		IdleTimeout: 240 * time.Second,
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			_, err := c.Do("PING")
+			return err
+		},
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", cfg.Address,
+				redis.DialUsername(cfg.User),
+				redis.DialPassword(cfg.Password),
+			)
+		},
+	}
+}
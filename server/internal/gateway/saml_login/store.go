@@ -0,0 +1,83 @@
+package saml_login
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Login is a sign in with the SAML identity provider of an organization. The
+// browser returns from the identity provider with a cross-site POST, which the
+// session cookie is not sent with, so the login is kept in Redis instead.
+type Login struct {
+	LoginChallenge string `json:"login_challenge"`
+	OrganizationID string `json:"organization_id"`
+	// Authentication request the identity provider responds to
+	RequestID string `json:"request_id,omitempty"`
+	// Set once the response was accepted and the login waits for a second factor
+	Subject               string `json:"subject,omitempty"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+}
+
+// Store keeps SAML logins until the browser comes back for them. Each login can
+// only be taken once.
+type Store struct {
+	pool *redis.Pool
+	// Seconds a login is kept
+	ttl int
+}
+
+func NewStore(pool *redis.Pool, ttl int) *Store {
+	return &Store{
+		pool: pool,
+		ttl:  ttl,
+	}
+}
+
+// Keeps login under key.
+func (s *Store) Save(key string, login Login) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return fmt.Errorf("failed to encode saml login: %w", err)
+	}
+
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("SET", loginKey(key), data, "EX", s.ttl); err != nil {
+		return fmt.Errorf("failed to save saml login: %w", err)
+	}
+
+	return nil
+}
+
+// Returns and forgets the login kept under key, if any.
+func (s *Store) Take(key string) (Login, bool, error) {
+	if key == "" {
+		return Login{}, false, nil
+	}
+
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("GETDEL", loginKey(key)))
+	if errors.Is(err, redis.ErrNil) {
+		return Login{}, false, nil
+	}
+	if err != nil {
+		return Login{}, false, fmt.Errorf("failed to read saml login: %w", err)
+	}
+
+	var login Login
+	if err := json.Unmarshal(data, &login); err != nil {
+		return Login{}, false, fmt.Errorf("failed to decode saml login: %w", err)
+	}
+
+	return login, true, nil
+}
+
+func loginKey(key string) string {
+	return "saml_login:" + key
+}
@@ -0,0 +1,82 @@
+package token_cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"conformitea/server/types"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Cache keeps the principals of introspected access tokens in Redis so Hydra is
+// not asked on every request. Tokens are stored hashed.
+type Cache struct {
+	pool *redis.Pool
+	// Longest time in seconds a principal is cached
+	ttl int
+}
+
+func NewCache(pool *redis.Pool, ttl int) *Cache {
+	return &Cache{
+		pool: pool,
+		ttl:  ttl,
+	}
+}
+
+// Returns the cached principal of token, if any.
+func (c *Cache) Get(token string) (types.Principal, bool, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("GET", cacheKey(token)))
+	if errors.Is(err, redis.ErrNil) {
+		return types.Principal{}, false, nil
+	}
+	if err != nil {
+		return types.Principal{}, false, fmt.Errorf("failed to read cached token: %w", err)
+	}
+
+	var principal types.Principal
+	if err := json.Unmarshal(data, &principal); err != nil {
+		return types.Principal{}, false, fmt.Errorf("failed to decode cached token: %w", err)
+	}
+
+	return principal, true, nil
+}
+
+// Caches the principal of token, never beyond the token's expiry.
+func (c *Cache) Set(token string, principal types.Principal) error {
+	ttl := c.ttl
+	if remaining := int(time.Until(principal.ExpiresAt).Seconds()); remaining < ttl {
+		ttl = remaining
+	}
+
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(principal)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("SET", cacheKey(token), data, "EX", ttl); err != nil {
+		return fmt.Errorf("failed to cache token: %w", err)
+	}
+
+	return nil
+}
+
+func cacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return "introspected_token:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,30 @@
+package accounts
+
+import (
+	"errors"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+)
+
+// ConformiTea error codes of the errors returned by AppAccounts.
+var appAccountsErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{types.ErrUserNotFound, cerror.AccountsUserNotFound},
+	{types.ErrPermissionDenied, cerror.AccountsPermissionDenied},
+	{types.ErrInvalidRequest, cerror.AccountsInvalidRequest},
+	{types.ErrMergeConflict, cerror.AccountsMergeConflict},
+}
+
+// Returns the error code for an AppAccounts error, or fallback if it is not a known one.
+func errorCode(err error, fallback string) string {
+	for _, e := range appAccountsErrorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+
+	return fallback
+}
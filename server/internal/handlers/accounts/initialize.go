@@ -0,0 +1,15 @@
+package accounts
+
+import (
+	"conformitea/server/types"
+)
+
+type AccountsHandlers struct {
+	appAccounts types.AppAccounts
+}
+
+func Initialize(appAccounts types.AppAccounts) *AccountsHandlers {
+	return &AccountsHandlers{
+		appAccounts: appAccounts,
+	}
+}
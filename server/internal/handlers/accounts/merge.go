@@ -0,0 +1,108 @@
+package accounts
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MergeUsersRequest is the body of the user merge endpoint.
+type MergeUsersRequest struct {
+	// ID or email of the duplicate user, deactivated by the merge
+	Source string `json:"source" binding:"required"`
+	// ID or email of the user the source is merged into
+	Target string `json:"target" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+	// Report what the merge would move without merging
+	DryRun bool `json:"dry_run"`
+}
+
+// UserMergeResponse represents the merge of a duplicate user into another user.
+type UserMergeResponse struct {
+	ID           string    `json:"id,omitempty"`
+	SourceUserID string    `json:"source_user_id"`
+	SourceEmail  string    `json:"source_email"`
+	TargetUserID string    `json:"target_user_id"`
+	TargetEmail  string    `json:"target_email"`
+	MergedBy     string    `json:"merged_by,omitempty"`
+	Reason       string    `json:"reason"`
+	Identities   int       `json:"identities"`
+	Memberships  int       `json:"memberships"`
+	Teams        int       `json:"teams"`
+	DryRun       bool      `json:"dry_run"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// MergeUsers merges a duplicate user into the user the same person keeps signing
+// in as.
+func (h *AccountsHandlers) MergeUsers(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	actor, ok := middlewares.CurrentActor(c)
+	if !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	var body MergeUsersRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		accErr := cerror.NewAccountsError(cerror.AccountsInvalidRequest, err.Error(), nil)
+		c.JSON(accErr.HTTPStatusCode(), accErr)
+		return
+	}
+
+	merge, err := h.appAccounts.MergeUsers(c.Request.Context(), types.MergeUsersRequest{
+		Actor:  actor,
+		Source: body.Source,
+		Target: body.Target,
+		Reason: body.Reason,
+		DryRun: body.DryRun,
+	})
+	if err != nil {
+		accErr := cerror.NewAccountsError(errorCode(err, cerror.AccountsMergeFailed), err.Error(), nil)
+
+		logger.Warn("failed to merge users",
+			zap.Error(err),
+			zap.String("error_code", accErr.Code))
+
+		c.JSON(accErr.HTTPStatusCode(), accErr)
+		return
+	}
+
+	if merge.DryRun {
+		c.JSON(http.StatusOK, toUserMergeResponse(merge))
+		return
+	}
+
+	logger.Info("users merged",
+		zap.String("merge_id", merge.ID),
+		zap.String("source_user_id", merge.SourceUserID),
+		zap.String("target_user_id", merge.TargetUserID),
+		zap.String("actor_id", actor.ID))
+
+	c.JSON(http.StatusCreated, toUserMergeResponse(merge))
+}
+
+func toUserMergeResponse(m types.UserMerge) UserMergeResponse {
+	return UserMergeResponse{
+		ID:           m.ID,
+		SourceUserID: m.SourceUserID,
+		SourceEmail:  m.SourceEmail,
+		TargetUserID: m.TargetUserID,
+		TargetEmail:  m.TargetEmail,
+		MergedBy:     m.MergedBy,
+		Reason:       m.Reason,
+		Identities:   m.Identities,
+		Memberships:  m.Memberships,
+		Teams:        m.Teams,
+		DryRun:       m.DryRun,
+		CreatedAt:    m.CreatedAt,
+	}
+}
@@ -1,9 +1,11 @@
 package auth
 
 import (
+	"crypto/subtle"
 	"net/http"
 
 	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/gateway/gin_session"
 	"conformitea/server/types"
 
 	"github.com/gin-contrib/sessions"
@@ -16,32 +18,69 @@ func (a *AuthHandlers) Callback(c *gin.Context) {
 	logger := c.MustGet("logger").(*zap.Logger)
 	logger.Info("processing oauth2 callback")
 
-	code := c.Query("code")
+	session := sessions.Default(c)
+
+	// The signed in user is linking another account rather than signing in
+	if link, ok := gin_session.GetIdentityLink(session); ok && subtle.ConstantTimeCompare([]byte(c.Query("state")), []byte(link.State)) == 1 {
+		a.linkIdentity(c, link)
+		return
+	}
+
+	hydraLoginChallenge, exists := session.Get("hydra_login_challenge").(string)
+	if !exists || hydraLoginChallenge == "" {
+		logger.Warn("oauth2 callback without hydra login challenge")
+
+		authErr := cerror.NewAuthError(cerror.AuthSessionNotFound, map[string]any{
+			"session_key": "hydra_login_challenge",
+		})
+
+		a.redirectToErrorPage(c, authErr)
+		return
+	}
+
+	// Only callbacks answering the login this session started are trusted, so a
+	// forged callback cannot reject the login with an error
 	state := c.Query("state")
+	if subtle.ConstantTimeCompare([]byte(state), []byte(hydraLoginChallenge)) != 1 {
+		logger.Warn("oauth2 callback state does not match the login challenge")
 
-	if code == "" {
-		logger.Warn("oauth2 callback without code")
+		a.redirectToErrorPage(c, cerror.NewAuthError(cerror.AuthStateMismatch, nil))
+		return
+	}
+
+	// The identity provider refused or the user cancelled the sign in
+	if providerError := c.Query("error"); providerError != "" {
+		logger.Warn("identity provider returned an error",
+			zap.String("error", providerError),
+			zap.String("error_description", c.Query("error_description")))
 
 		authErr := cerror.NewAuthError(cerror.AuthInvalidState, map[string]any{
-			"parameter": "code",
-			"reason":    "missing",
+			"parameter": "error",
+			"reason":    providerError,
 		})
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		rejectError := types.OAuthAccessDenied
+		if loginRequiredProviderErrors[providerError] {
+			rejectError = types.OAuthLoginRequired
+		}
+
+		a.clearLoginState(c)
+		a.rejectLogin(c, hydraLoginChallenge, rejectError, authErr)
 		return
 	}
 
-	session := sessions.Default(c)
+	code := c.Query("code")
 
-	hydraLoginChallenge, exists := session.Get("hydra_login_challenge").(string)
-	if !exists || hydraLoginChallenge == "" {
-		logger.Warn("oauth2 callback without hydra login challenge")
+	if code == "" {
+		logger.Warn("oauth2 callback without code")
 
-		authErr := cerror.NewAuthError(cerror.AuthSessionNotFound, map[string]any{
-			"session_key": "hydra_login_challenge",
+		authErr := cerror.NewAuthError(cerror.AuthInvalidState, map[string]any{
+			"parameter": "code",
+			"reason":    "missing",
 		})
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		a.clearLoginState(c)
+		a.rejectLogin(c, hydraLoginChallenge, types.OAuthAccessDenied, authErr)
 		return
 	}
 
@@ -53,7 +92,8 @@ func (a *AuthHandlers) Callback(c *gin.Context) {
 			"session_key": "idp_provider",
 		})
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		a.clearLoginState(c)
+		a.rejectLogin(c, hydraLoginChallenge, types.OAuthLoginRequired, authErr)
 		return
 	}
 
@@ -65,7 +105,8 @@ func (a *AuthHandlers) Callback(c *gin.Context) {
 			"session_key": "auth_nonce",
 		})
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		a.clearLoginState(c)
+		a.rejectLogin(c, hydraLoginChallenge, types.OAuthLoginRequired, authErr)
 		return
 	}
 
@@ -81,25 +122,34 @@ func (a *AuthHandlers) Callback(c *gin.Context) {
 	if err != nil {
 		logger.Error("failed to process oauth2 callback", zap.Error(err))
 
-		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthMicrosoftExchange, err.Error(), map[string]any{
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthMicrosoftExchange), err.Error(), map[string]any{
 			"provider": provider,
 		})
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		a.clearLoginState(c)
+		a.rejectLogin(c, hydraLoginChallenge, oauthError(err), authErr)
 		return
 	}
 
-	// Clear temporary auth data
+	// The session is established once Hydra redirects back to the first-party client
+	a.clearLoginState(c)
+
+	if result.MFARequired {
+		a.startMFA(c, hydraLoginChallenge, result)
+		return
+	}
+
+	c.Redirect(http.StatusFound, result.RedirectTo)
+}
+
+// Removes the temporary login data so a rejected challenge cannot be replayed.
+func (a *AuthHandlers) clearLoginState(c *gin.Context) {
+	session := sessions.Default(c)
 	session.Delete("hydra_login_challenge")
 	session.Delete("idp_provider")
 	session.Delete("auth_nonce")
 
 	if err := session.Save(); err != nil {
-		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil)
-
-		c.JSON(authErr.HTTPStatusCode(), authErr)
-		return
+		c.MustGet("logger").(*zap.Logger).Warn("failed to clear login state", zap.Error(err))
 	}
-
-	c.Redirect(http.StatusFound, result.RedirectTo)
 }
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"conformitea/server/config"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	testFrontendURL    = "https://app.example.com"
+	testLoginChallenge = "login-challenge"
+	testNonce          = "nonce-value"
+)
+
+// Records the callbacks and rejections the handlers pass on to the app.
+type stubAuth struct {
+	types.AppAuth
+	callbacks  []types.CallbackRequest
+	rejections []types.RejectRequest
+}
+
+func (s *stubAuth) ProcessCallback(ctx context.Context, req types.CallbackRequest) (types.CallbackResult, error) {
+	s.callbacks = append(s.callbacks, req)
+
+	return types.CallbackResult{RedirectTo: "https://hydra.example.com/login/accepted"}, nil
+}
+
+func (s *stubAuth) RejectLogin(ctx context.Context, req types.RejectRequest) (types.RejectResult, error) {
+	s.rejections = append(s.rejections, req)
+
+	return types.RejectResult{RedirectTo: "https://client.example.com/callback?error=" + req.Error}, nil
+}
+
+// Calls the callback handler with query in a session holding a pending login.
+func callback(t *testing.T, appAuth types.AppAuth, query url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	handlers := &AuthHandlers{
+		appAuth: appAuth,
+		config: config.Config{
+			General: config.GeneralConfig{FrontendURL: testFrontendURL},
+		},
+	}
+
+	router := gin.New()
+	router.Use(sessions.Sessions("session", cookie.NewStore([]byte("secret"))))
+	router.Use(func(c *gin.Context) {
+		c.Set("logger", zap.NewNop())
+
+		session := sessions.Default(c)
+		session.Set("hydra_login_challenge", testLoginChallenge)
+		session.Set("idp_provider", "microsoft")
+		session.Set("auth_nonce", testNonce)
+	})
+	router.GET("/auth/callback", handlers.Callback)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/auth/callback?"+query.Encode(), nil))
+
+	return recorder
+}
+
+func TestCallbackState(t *testing.T) {
+	errorPage := testFrontendURL + "/auth/error?code=CT_AUTH_011"
+
+	tests := []struct {
+		name          string
+		query         url.Values
+		wantLocation  string
+		wantCallbacks int
+		wantRejection string
+	}{
+		{
+			name:          "code with the state of the login",
+			query:         url.Values{"code": {"code"}, "state": {testLoginChallenge}},
+			wantLocation:  "https://hydra.example.com/login/accepted",
+			wantCallbacks: 1,
+		},
+		{
+			name:         "code with another state",
+			query:        url.Values{"code": {"code"}, "state": {"another-challenge"}},
+			wantLocation: errorPage,
+		},
+		{
+			name:         "code without state",
+			query:        url.Values{"code": {"code"}},
+			wantLocation: errorPage,
+		},
+		{
+			name:          "error with the state of the login",
+			query:         url.Values{"error": {"access_denied"}, "state": {testLoginChallenge}},
+			wantLocation:  "https://client.example.com/callback?error=" + types.OAuthAccessDenied,
+			wantRejection: types.OAuthAccessDenied,
+		},
+		{
+			name:         "error with another state",
+			query:        url.Values{"error": {"access_denied"}, "state": {"another-challenge"}},
+			wantLocation: errorPage,
+		},
+		{
+			name:         "error without state",
+			query:        url.Values{"error": {"access_denied"}},
+			wantLocation: errorPage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appAuth := &stubAuth{}
+
+			recorder := callback(t, appAuth, tt.query)
+
+			if recorder.Code != http.StatusFound {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusFound)
+			}
+
+			if location := recorder.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("location = %q, want %q", location, tt.wantLocation)
+			}
+
+			if len(appAuth.callbacks) != tt.wantCallbacks {
+				t.Errorf("callbacks processed = %d, want %d", len(appAuth.callbacks), tt.wantCallbacks)
+			}
+
+			switch {
+			case tt.wantRejection == "" && len(appAuth.rejections) > 0:
+				t.Errorf("login rejected with %q, want it kept", appAuth.rejections[0].Error)
+			case tt.wantRejection != "" && (len(appAuth.rejections) != 1 || appAuth.rejections[0].Error != tt.wantRejection):
+				t.Errorf("rejections = %v, want one with %q", appAuth.rejections, tt.wantRejection)
+			}
+		})
+	}
+}
+
+func TestCallbackPassesNonce(t *testing.T) {
+	appAuth := &stubAuth{}
+
+	recorder := callback(t, appAuth, url.Values{"code": {"code"}, "state": {testLoginChallenge}})
+
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusFound)
+	}
+
+	if len(appAuth.callbacks) != 1 {
+		t.Fatalf("callbacks processed = %d, want 1", len(appAuth.callbacks))
+	}
+
+	req := appAuth.callbacks[0]
+	if req.Nonce != testNonce || req.State != testLoginChallenge || req.HydraLoginChallenge != testLoginChallenge {
+		t.Errorf("callback request = %+v, want the nonce and challenge of the session", req)
+	}
+}
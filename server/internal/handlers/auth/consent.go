@@ -2,6 +2,7 @@ package auth
 
 import (
 	"net/http"
+	"net/url"
 
 	"conformitea/server/internal/cerror"
 	"conformitea/server/types"
@@ -10,6 +11,45 @@ import (
 	"go.uber.org/zap"
 )
 
+// ConsentResponse describes a consent request to the consent page.
+type ConsentResponse struct {
+	ClientID   string                 `json:"client_id"`
+	ClientName string                 `json:"client_name"`
+	ClientURI  string                 `json:"client_uri,omitempty"`
+	LogoURI    string                 `json:"logo_uri,omitempty"`
+	Scopes     []ConsentScopeResponse `json:"scopes"`
+	Audience   []string               `json:"audience"`
+}
+
+// ConsentScopeResponse is a scope requested by the client.
+type ConsentScopeResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Granted whenever the request is accepted
+	Required bool `json:"required"`
+}
+
+// AcceptConsentRequest is the body of the consent accept endpoint.
+type AcceptConsentRequest struct {
+	ConsentChallenge string   `json:"consent_challenge" binding:"required"`
+	GrantScope       []string `json:"grant_scope"`
+	// Skip the consent page while the client requests no other scopes
+	Remember bool `json:"remember"`
+}
+
+// RejectConsentRequest is the body of the consent reject endpoint.
+type RejectConsentRequest struct {
+	ConsentChallenge string `json:"consent_challenge" binding:"required"`
+}
+
+// ConsentDecisionResponse continues the authorization in the browser.
+type ConsentDecisionResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// Handles Hydra's consent redirect. Requests of first-party clients, and requests
+// the user decided on before, are accepted right away; the user decides on other
+// requests on the frontend's consent page.
 func (a *AuthHandlers) Consent(c *gin.Context) {
 	logger := c.MustGet("logger").(*zap.Logger)
 	logger.Info("processing oauth2 consent")
@@ -23,7 +63,7 @@ func (a *AuthHandlers) Consent(c *gin.Context) {
 			"reason":    "missing",
 		})
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		a.redirectToErrorPage(c, authErr)
 		return
 	}
 
@@ -37,12 +77,22 @@ func (a *AuthHandlers) Consent(c *gin.Context) {
 			zap.Error(err),
 			zap.String("consent_challenge", consentChallenge))
 
-		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthHydraAcceptFailed, err.Error(), map[string]any{
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthHydraAcceptFailed), err.Error(), map[string]any{
 			"consent_challenge": consentChallenge,
 			"operation":         "consent",
 		})
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		a.rejectConsent(c, consentChallenge, oauthError(err), authErr)
+		return
+	}
+
+	if result.ConsentRequired {
+		query := url.Values{}
+		query.Set("consent_challenge", consentChallenge)
+
+		logger.Info("consent required, redirecting to consent page")
+
+		c.Redirect(http.StatusFound, a.config.General.FrontendURL+"/auth/consent?"+query.Encode())
 		return
 	}
 
@@ -50,3 +100,105 @@ func (a *AuthHandlers) Consent(c *gin.Context) {
 
 	c.Redirect(http.StatusFound, result.RedirectTo)
 }
+
+// Returns the client and scopes of a consent request for the consent page.
+func (a *AuthHandlers) ConsentDetails(c *gin.Context) {
+	consentChallenge := c.Query("consent_challenge")
+	if consentChallenge == "" {
+		authErr := cerror.NewAuthError(cerror.AuthInvalidState, map[string]any{
+			"parameter": "consent_challenge",
+			"reason":    "missing",
+		})
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	details, err := a.appAuth.GetConsentDetails(c.Request.Context(), types.ConsentRequest{
+		ConsentChallenge: consentChallenge,
+	})
+	if err != nil {
+		c.MustGet("logger").(*zap.Logger).Warn("failed to get consent request", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthHydraAcceptFailed), err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	scopes := make([]ConsentScopeResponse, 0, len(details.Scopes))
+	for _, scope := range details.Scopes {
+		scopes = append(scopes, ConsentScopeResponse{
+			Name:        scope.Name,
+			Description: scope.Description,
+			Required:    scope.Required,
+		})
+	}
+
+	c.JSON(http.StatusOK, ConsentResponse{
+		ClientID:   details.ClientID,
+		ClientName: details.ClientName,
+		ClientURI:  details.ClientURI,
+		LogoURI:    details.LogoURI,
+		Scopes:     scopes,
+		Audience:   details.Audience,
+	})
+}
+
+// Grants the scopes the user selected on the consent page.
+func (a *AuthHandlers) ConsentAccept(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	var body AcceptConsentRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidState, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	result, err := a.appAuth.AcceptConsent(c.Request.Context(), types.AcceptConsentRequest{
+		ConsentChallenge: body.ConsentChallenge,
+		GrantScope:       body.GrantScope,
+		Remember:         body.Remember,
+	})
+	if err != nil {
+		logger.Warn("failed to accept consent", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthHydraAcceptFailed), err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	logger.Info("consent accepted on consent page",
+		zap.Strings("grant_scope", body.GrantScope),
+		zap.Bool("remember", body.Remember))
+
+	c.JSON(http.StatusOK, ConsentDecisionResponse{RedirectTo: result.RedirectTo})
+}
+
+// Denies a consent request the user declined on the consent page.
+func (a *AuthHandlers) ConsentReject(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	var body RejectConsentRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidState, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	result, err := a.appAuth.RejectConsent(c.Request.Context(), types.RejectRequest{
+		Challenge:        body.ConsentChallenge,
+		Error:            types.OAuthAccessDenied,
+		ErrorDescription: "The user denied the request",
+	})
+	if err != nil {
+		logger.Error("failed to reject consent", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthHydraAcceptFailed, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	logger.Info("consent denied on consent page")
+
+	c.JSON(http.StatusOK, ConsentDecisionResponse{RedirectTo: result.RedirectTo})
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DeviceVerificationRequest is the body of the device verification endpoint.
+type DeviceVerificationRequest struct {
+	DeviceChallenge string `json:"device_challenge" binding:"required"`
+	UserCode        string `json:"user_code" binding:"required"`
+}
+
+// DeviceVerificationResponse continues the sign in of a device in the browser.
+type DeviceVerificationResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// Handles Hydra's device verification redirect. A user code carried by the
+// verification URL is accepted right away; otherwise the user enters the code the
+// device shows on the frontend's device page.
+func (a *AuthHandlers) Device(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	deviceChallenge := c.Query("device_challenge")
+	if deviceChallenge == "" {
+		authErr := cerror.NewAuthError(cerror.AuthInvalidState, map[string]any{
+			"parameter": "device_challenge",
+			"reason":    "missing",
+		})
+
+		a.redirectToErrorPage(c, authErr)
+		return
+	}
+
+	userCode := c.Query("user_code")
+	if userCode == "" {
+		query := url.Values{}
+		query.Set("device_challenge", deviceChallenge)
+
+		c.Redirect(http.StatusFound, a.config.General.FrontendURL+"/auth/device?"+query.Encode())
+		return
+	}
+
+	result, err := a.appAuth.VerifyDevice(c.Request.Context(), types.DeviceVerificationRequest{
+		DeviceChallenge: deviceChallenge,
+		UserCode:        userCode,
+	})
+	if err != nil {
+		logger.Warn("failed to verify device", zap.Error(err))
+
+		a.redirectToErrorPage(c, cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthHydraAcceptFailed), err.Error(), nil))
+		return
+	}
+
+	logger.Info("device verified, redirecting")
+
+	c.Redirect(http.StatusFound, result.RedirectTo)
+}
+
+// Accepts the user code entered on the frontend's device page.
+func (a *AuthHandlers) DeviceVerify(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	var body DeviceVerificationRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidState, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	result, err := a.appAuth.VerifyDevice(c.Request.Context(), types.DeviceVerificationRequest{
+		DeviceChallenge: body.DeviceChallenge,
+		UserCode:        body.UserCode,
+	})
+	if err != nil {
+		logger.Warn("failed to verify device", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthHydraAcceptFailed), err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, DeviceVerificationResponse{RedirectTo: result.RedirectTo})
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+)
+
+// ConformiTea error codes and OAuth2 error codes of the errors returned by AppAuth.
+var appAuthErrorCodes = []struct {
+	err        error
+	code       string
+	oauthError string
+}{
+	{types.ErrProviderNotSupported, cerror.AuthProviderNotSupported, types.OAuthAccessDenied},
+	{types.ErrStateMismatch, cerror.AuthStateMismatch, types.OAuthAccessDenied},
+	{types.ErrIDTokenMissing, cerror.AuthIDTokenMissing, types.OAuthAccessDenied},
+	{types.ErrIDTokenSignature, cerror.AuthIDTokenSignature, types.OAuthAccessDenied},
+	{types.ErrIDTokenExpired, cerror.AuthIDTokenExpired, types.OAuthLoginRequired},
+	{types.ErrIDTokenIssuer, cerror.AuthIDTokenIssuer, types.OAuthAccessDenied},
+	{types.ErrIDTokenAudience, cerror.AuthIDTokenAudience, types.OAuthAccessDenied},
+	{types.ErrIDTokenNonce, cerror.AuthIDTokenNonce, types.OAuthAccessDenied},
+	{types.ErrIDTokenClaims, cerror.AuthIDTokenClaims, types.OAuthAccessDenied},
+	{types.ErrEmailMissing, cerror.AuthEmailMissing, types.OAuthAccessDenied},
+	{types.ErrInvalidLogoutToken, cerror.AuthInvalidLogoutToken, types.OAuthAccessDenied},
+	{types.ErrMFACodeInvalid, cerror.AuthMFACodeInvalid, types.OAuthAccessDenied},
+	{types.ErrMFANotEnrolled, cerror.AuthMFANotEnrolled, types.OAuthAccessDenied},
+	{types.ErrMFAAlreadyEnrolled, cerror.AuthMFAAlreadyEnrolled, types.OAuthAccessDenied},
+	{types.ErrMFARequired, cerror.AuthMFARequired, types.OAuthAccessDenied},
+	{types.ErrPasskeyInvalid, cerror.AuthPasskeyInvalid, types.OAuthAccessDenied},
+	{types.ErrPasskeyNotFound, cerror.AuthPasskeyNotFound, types.OAuthAccessDenied},
+	{types.ErrUserDeactivated, cerror.AuthUserDeactivated, types.OAuthAccessDenied},
+	{types.ErrSAMLNotConfigured, cerror.AuthSAMLNotConfigured, types.OAuthAccessDenied},
+	{types.ErrSAMLResponseInvalid, cerror.AuthSAMLResponseInvalid, types.OAuthAccessDenied},
+	{types.ErrSAMLUserConflict, cerror.AuthSAMLUserConflict, types.OAuthAccessDenied},
+	{types.ErrSSORequired, cerror.AuthSSORequired, types.OAuthAccessDenied},
+	{types.ErrTenantNotAllowed, cerror.AuthTenantNotAllowed, types.OAuthAccessDenied},
+	{types.ErrUserCodeInvalid, cerror.AuthUserCodeInvalid, types.OAuthAccessDenied},
+	{types.ErrConsentScopeInvalid, cerror.AuthConsentScopeInvalid, types.OAuthAccessDenied},
+	{types.ErrIdentityConflict, cerror.AuthIdentityConflict, types.OAuthAccessDenied},
+	{types.ErrEmailTaken, cerror.AuthEmailTaken, types.OAuthAccessDenied},
+}
+
+// Returns the error code for an AppAuth error, or fallback if it is not a known one.
+func errorCode(err error, fallback string) string {
+	for _, e := range appAuthErrorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+
+	return fallback
+}
+
+// Returns the OAuth2 error code for an AppAuth error, server_error if it is not a known one.
+func oauthError(err error) string {
+	for _, e := range appAuthErrorCodes {
+		if errors.Is(err, e.err) {
+			return e.oauthError
+		}
+	}
+
+	return types.OAuthServerError
+}
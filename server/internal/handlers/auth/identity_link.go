@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Links the account the user signed in with at the identity provider to them,
+// then sends them back to their identities on the frontend, with the error code
+// when linking failed.
+func (a *AuthHandlers) linkIdentity(c *gin.Context, link gin_session.IdentityLink) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	session := sessions.Default(c)
+	gin_session.ClearIdentityLink(session)
+	if err := session.Save(); err != nil {
+		logger.Warn("failed to clear identity link", zap.Error(err))
+	}
+
+	// The link was started by the user still signed in, and not on behalf of
+	// someone they impersonate
+	userID, ok := middlewares.SignedInUserID(c)
+	if _, impersonating := gin_session.GetImpersonation(session); !ok || impersonating || userID != link.UserID {
+		logger.Warn("identity link callback for another session", zap.String("user_id", link.UserID))
+
+		a.redirectToIdentities(c, "", cerror.NewAuthError(cerror.AuthSessionNotFound, nil))
+		return
+	}
+
+	if providerError := c.Query("error"); providerError != "" {
+		logger.Warn("identity provider returned an error",
+			zap.String("error", providerError),
+			zap.String("error_description", c.Query("error_description")))
+
+		a.redirectToIdentities(c, "", cerror.NewAuthError(cerror.AuthInvalidState, nil))
+		return
+	}
+
+	identity, err := a.appAuth.CompleteIdentityLink(c.Request.Context(), types.IdentityLinkCallbackRequest{
+		UserID:        link.UserID,
+		Provider:      link.Provider,
+		Code:          c.Query("code"),
+		State:         c.Query("state"),
+		ExpectedState: link.State,
+		Nonce:         link.Nonce,
+	})
+	if err != nil {
+		logger.Warn("failed to link identity",
+			zap.Error(err),
+			zap.String("user_id", link.UserID),
+			zap.String("provider", link.Provider))
+
+		a.redirectToIdentities(c, "", cerror.NewAuthError(errorCode(err, cerror.AuthMicrosoftExchange), nil))
+		return
+	}
+
+	logger.Info("identity linked",
+		zap.String("user_id", link.UserID),
+		zap.String("identity_id", identity.ID),
+		zap.String("provider", identity.Provider))
+
+	a.redirectToIdentities(c, identity.Provider, nil)
+}
+
+func (a *AuthHandlers) redirectToIdentities(c *gin.Context, provider string, authErr *cerror.AuthError) {
+	query := url.Values{}
+	if authErr != nil {
+		query.Set("error", authErr.Code)
+	} else {
+		query.Set("linked", provider)
+	}
+
+	c.Redirect(http.StatusFound, a.config.General.FrontendURL+"/settings/identities?"+query.Encode())
+}
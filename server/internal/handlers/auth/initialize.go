@@ -2,17 +2,29 @@ package auth
 
 import (
 	"conformitea/server/config"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/internal/gateway/logout_token"
+	"conformitea/server/internal/gateway/mfa_attempt"
+	"conformitea/server/internal/gateway/saml_login"
 	"conformitea/server/types"
 )
 
 type AuthHandlers struct {
-	appAuth types.AppAuth
-	config  config.Config
+	appAuth      types.AppAuth
+	config       config.Config
+	sessionIndex *gin_session.Index
+	samlLogins   *saml_login.Store
+	logoutTokens *logout_token.Store
+	mfaAttempts  *mfa_attempt.Store
 }
 
-func Initialize(appAuth types.AppAuth, cfg config.Config) *AuthHandlers {
+func Initialize(appAuth types.AppAuth, cfg config.Config, si *gin_session.Index, samlLogins *saml_login.Store, logoutTokens *logout_token.Store, mfaAttempts *mfa_attempt.Store) *AuthHandlers {
 	return &AuthHandlers{
-		appAuth: appAuth,
-		config:  cfg,
+		appAuth:      appAuth,
+		config:       cfg,
+		sessionIndex: si,
+		samlLogins:   samlLogins,
+		logoutTokens: logoutTokens,
+		mfaAttempts:  mfaAttempts,
 	}
 }
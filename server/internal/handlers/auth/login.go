@@ -27,15 +27,18 @@ func (a *AuthHandlers) Login(c *gin.Context) {
 			zap.String("error_code", string(authErr.Code)),
 		)
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		a.redirectToErrorPage(c, authErr)
 		return
 	}
 
 	logger.Info("login initiated", zap.String("login_challenge", loginChallenge))
 
-	result, err := a.appAuth.InitiateLogin(types.LoginRequest{LoginChallenge: loginChallenge})
+	result, err := a.appAuth.InitiateLogin(types.LoginRequest{
+		LoginChallenge: loginChallenge,
+		ProviderHint:   c.Query("provider"),
+	})
 	if err != nil {
-		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), map[string]any{
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthSessionCreateFailed), err.Error(), map[string]any{
 			"login_challenge": loginChallenge,
 		})
 
@@ -45,7 +48,26 @@ func (a *AuthHandlers) Login(c *gin.Context) {
 			zap.String("error_code", string(authErr.Code)),
 		)
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		a.rejectLogin(c, loginChallenge, oauthError(err), authErr)
+		return
+	}
+
+	if result.RedirectTo != "" {
+		logger.Info("login remembered by hydra, skipping identity provider",
+			zap.String("login_challenge", loginChallenge),
+		)
+
+		c.Redirect(http.StatusFound, result.RedirectTo)
+		return
+	}
+
+	if result.PasskeyLogin {
+		a.startPasskeyLogin(c, loginChallenge)
+		return
+	}
+
+	if result.SAMLRequestID != "" {
+		a.startSAMLLogin(c, result)
 		return
 	}
 
@@ -63,7 +85,7 @@ func (a *AuthHandlers) Login(c *gin.Context) {
 			zap.String("error_code", string(authErr.Code)),
 		)
 
-		c.JSON(authErr.HTTPStatusCode(), authErr)
+		a.rejectLogin(c, loginChallenge, types.OAuthServerError, authErr)
 		return
 	}
 
@@ -74,3 +96,15 @@ func (a *AuthHandlers) Login(c *gin.Context) {
 
 	c.Redirect(http.StatusFound, result.AuthURL)
 }
+
+// ProvidersResponse lists the identity providers users can sign in with.
+type ProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// Providers returns the identity providers users can sign in with or link.
+func (a *AuthHandlers) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, ProvidersResponse{
+		Providers: a.appAuth.ListProviders(c.Request.Context()),
+	})
+}
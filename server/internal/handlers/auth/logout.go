@@ -4,13 +4,17 @@ import (
 	"net/http"
 
 	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-// Logout clears the user's session and logs them out.
+// Logout clears the user's session and returns the URL where the browser ends
+// its Hydra session.
 func (a *AuthHandlers) Logout(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
 	session := sessions.Default(c)
 
 	// Check if user is authenticated
@@ -24,6 +28,21 @@ func (a *AuthHandlers) Logout(c *gin.Context) {
 		return
 	}
 
+	result, err := a.appAuth.InitiateLogout(c.Request.Context())
+	if err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthLogoutFailed, err.Error(), map[string]any{
+			"operation": "logout",
+		})
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	if userID, _ := session.Get("user_id").(string); userID != "" {
+		if err := a.sessionIndex.Remove(userID, session.ID()); err != nil {
+			logger.Warn("failed to remove session from index", zap.Error(err))
+		}
+	}
+
 	// Clear all session data
 	session.Clear()
 	if err := session.Save(); err != nil {
@@ -37,5 +56,116 @@ func (a *AuthHandlers) Logout(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "Successfully logged out",
 		"authenticated": false,
+		"logout_url":    result.RedirectTo,
+	})
+}
+
+// Handles Hydra's logout challenge: ends the Hydra session and every session of
+// the subject on this server.
+func (a *AuthHandlers) LogoutChallenge(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	logoutChallenge := c.Query("logout_challenge")
+	if logoutChallenge == "" {
+		authErr := cerror.NewAuthError(cerror.AuthInvalidState, map[string]any{
+			"parameter": "logout_challenge",
+			"reason":    "missing",
+		})
+
+		a.redirectToErrorPage(c, authErr)
+		return
+	}
+
+	result, err := a.appAuth.ProcessLogout(c.Request.Context(), types.LogoutRequest{
+		LogoutChallenge: logoutChallenge,
+	})
+	if err != nil {
+		logger.Error("failed to process logout",
+			zap.Error(err),
+			zap.String("logout_challenge", logoutChallenge))
+
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthLogoutFailed, err.Error(), map[string]any{
+			"logout_challenge": logoutChallenge,
+		})
+
+		a.redirectToErrorPage(c, authErr)
+		return
+	}
+
+	if result.Subject != "" {
+		if _, err := a.sessionIndex.RevokeSubject(result.Subject); err != nil {
+			logger.Error("failed to revoke sessions", zap.Error(err))
+		}
+	}
+
+	session := sessions.Default(c)
+	session.Clear()
+	if err := session.Save(); err != nil {
+		logger.Warn("failed to clear session", zap.Error(err))
+	}
+
+	logger.Info("logout accepted, redirecting")
+
+	c.Redirect(http.StatusFound, result.RedirectTo)
+}
+
+// Handles OpenID Connect back-channel logout notifications from Hydra by deleting
+// the sessions of the Hydra login session the token names, or else every session
+// of the subject.
+func (a *AuthHandlers) BackChannelLogout(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	c.Header("Cache-Control", "no-store")
+
+	result, err := a.appAuth.ProcessBackChannelLogout(c.Request.Context(), types.BackChannelLogoutRequest{
+		LogoutToken: c.PostForm("logout_token"),
 	})
+	if err != nil {
+		logger.Warn("rejected back-channel logout", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthInvalidLogoutToken), err.Error(), nil)
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	replayed, err := a.logoutTokens.Use(result.TokenID, result.ValidUntil)
+	if err != nil {
+		logger.Error("failed to record logout token", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthLogoutFailed, err.Error(), nil)
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	if replayed {
+		logger.Warn("rejected replayed back-channel logout", zap.String("jti", result.TokenID))
+
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidLogoutToken, "logout token was already used", nil)
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	var revoked int
+	if result.SessionID != "" {
+		revoked, err = a.sessionIndex.RevokeHydraSession(result.Subject, result.SessionID)
+	} else {
+		revoked, err = a.sessionIndex.RevokeSubject(result.Subject)
+	}
+	if err != nil {
+		logger.Error("failed to revoke sessions", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthLogoutFailed, err.Error(), nil)
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	logger.Info("back-channel logout processed",
+		zap.String("subject", result.Subject),
+		zap.Int("revoked_sessions", revoked))
+
+	c.Status(http.StatusOK)
 }
@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MFACodeRequest is the body of the endpoints checking a second factor code.
+type MFACodeRequest struct {
+	Method string `json:"method"`
+	Code   string `json:"code" binding:"required"`
+}
+
+// MFAChallengeResponse describes the second factor a pending login waits for.
+type MFAChallengeResponse struct {
+	EnrollmentRequired bool     `json:"enrollment_required"`
+	Methods            []string `json:"methods"`
+}
+
+// TOTPEnrollmentResponse is a new authenticator to add to an authenticator app.
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+	QRCode     string `json:"qr_code"`
+}
+
+// MFACompleteResponse ends the second factor step of a login.
+type MFACompleteResponse struct {
+	RedirectTo    string   `json:"redirect_to"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// Keeps the login pending in the session and sends the user to the second factor page.
+func (a *AuthHandlers) startMFA(c *gin.Context, loginChallenge string, result types.CallbackResult) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	session := sessions.Default(c)
+	session.Set("mfa_login_challenge", loginChallenge)
+	session.Set("mfa_user_id", result.Subject)
+	session.Set("mfa_enrollment_required", result.MFAEnrollmentRequired)
+
+	if err := session.Save(); err != nil {
+		logger.Error("failed to save session", zap.Error(err))
+
+		a.rejectLogin(c, loginChallenge, types.OAuthServerError, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	logger.Info("login pending second factor",
+		zap.String("login_challenge", loginChallenge),
+		zap.Bool("enrollment_required", result.MFAEnrollmentRequired))
+
+	c.Redirect(http.StatusFound, a.config.General.FrontendURL+"/auth/mfa")
+}
+
+// Describes the second factor the pending login waits for.
+func (a *AuthHandlers) MFAChallenge(c *gin.Context) {
+	_, userID, enrollmentRequired, ok := pendingMFA(c)
+	if !ok {
+		respondMFANotPending(c)
+		return
+	}
+
+	methods := []string{}
+	if !enrollmentRequired {
+		status, err := a.appAuth.GetMFAStatus(c.Request.Context(), userID)
+		if err != nil {
+			authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthSessionCreateFailed), err.Error(), nil)
+			c.JSON(authErr.HTTPStatusCode(), authErr)
+			return
+		}
+
+		if status.TOTPEnabled {
+			methods = append(methods, types.MFAMethodTOTP)
+		}
+
+		if status.Passkeys > 0 {
+			methods = append(methods, types.MFAMethodPasskey)
+		}
+
+		methods = append(methods, types.MFAMethodRecoveryCode)
+	}
+
+	c.JSON(http.StatusOK, MFAChallengeResponse{
+		EnrollmentRequired: enrollmentRequired,
+		Methods:            methods,
+	})
+}
+
+// Generates an authenticator for a pending login whose user must enroll one.
+func (a *AuthHandlers) MFABeginEnrollment(c *gin.Context) {
+	_, userID, enrollmentRequired, ok := pendingMFA(c)
+	if !ok || !enrollmentRequired {
+		respondMFANotPending(c)
+		return
+	}
+
+	enrollment, err := a.appAuth.BeginTOTPEnrollment(c.Request.Context(), userID)
+	if err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthSessionCreateFailed), err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, TOTPEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURL: enrollment.URL,
+		QRCode:     enrollment.QRCode,
+	})
+}
+
+// Confirms the authenticator enrolled during a pending login and accepts the login.
+func (a *AuthHandlers) MFAConfirmEnrollment(c *gin.Context) {
+	loginChallenge, userID, enrollmentRequired, ok := pendingMFA(c)
+	if !ok || !enrollmentRequired {
+		respondMFANotPending(c)
+		return
+	}
+
+	if a.mfaLocked(c, loginChallenge, userID) {
+		return
+	}
+
+	var body MFACodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidState, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	result, err := a.appAuth.ConfirmTOTPEnrollment(c.Request.Context(), types.TOTPConfirmRequest{
+		UserID:         userID,
+		Code:           body.Code,
+		LoginChallenge: loginChallenge,
+	})
+	if err != nil {
+		a.mfaFailed(c, loginChallenge, userID, err)
+		return
+	}
+
+	a.mfaPassed(c, userID)
+
+	c.JSON(http.StatusOK, MFACompleteResponse{
+		RedirectTo:    result.RedirectTo,
+		RecoveryCodes: result.RecoveryCodes,
+	})
+}
+
+// Checks the second factor of the pending login and accepts the login.
+func (a *AuthHandlers) MFAVerify(c *gin.Context) {
+	loginChallenge, userID, enrollmentRequired, ok := pendingMFA(c)
+	if !ok || enrollmentRequired {
+		respondMFANotPending(c)
+		return
+	}
+
+	if a.mfaLocked(c, loginChallenge, userID) {
+		return
+	}
+
+	var body MFACodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidState, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	if body.Method == "" {
+		body.Method = types.MFAMethodTOTP
+	}
+
+	result, err := a.appAuth.VerifyMFA(c.Request.Context(), types.MFAVerifyRequest{
+		LoginChallenge: loginChallenge,
+		UserID:         userID,
+		Method:         body.Method,
+		Code:           body.Code,
+	})
+	if err != nil {
+		a.mfaFailed(c, loginChallenge, userID, err)
+		return
+	}
+
+	a.mfaPassed(c, userID)
+
+	c.JSON(http.StatusOK, MFACompleteResponse{
+		RedirectTo: result.RedirectTo,
+	})
+}
+
+// Rejects the pending login when its user gave too many wrong second factors,
+// in this login or others, within the lockout window.
+func (a *AuthHandlers) mfaLocked(c *gin.Context, loginChallenge, userID string) bool {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	locked, err := a.mfaAttempts.Locked(userID)
+	if err != nil {
+		logger.Error("failed to check second factor lockout", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return true
+	}
+
+	if !locked {
+		return false
+	}
+
+	logger.Warn("second factor locked out, rejecting login",
+		zap.String("login_challenge", loginChallenge),
+		zap.String("user_id", userID))
+
+	a.rejectMFALogin(c, loginChallenge, cerror.NewAuthError(cerror.AuthMFALocked, nil))
+	return true
+}
+
+// Reports a failed second factor. Wrong codes and passkeys are counted for the
+// user and may be retried a few times, after which the login is rejected, the
+// user is sent back to the client and their second factor is locked out.
+func (a *AuthHandlers) mfaFailed(c *gin.Context, loginChallenge, userID string, err error) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthHydraAcceptFailed), err.Error(), nil)
+
+	if !errors.Is(err, types.ErrMFACodeInvalid) && !errors.Is(err, types.ErrPasskeyInvalid) {
+		logger.Error("failed to complete second factor", zap.Error(err))
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	remaining, err := a.mfaAttempts.Fail(userID)
+	if err != nil {
+		logger.Error("failed to count second factor failure", zap.Error(err))
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	if remaining > 0 {
+		authErr.Details = map[string]any{"attempts_remaining": remaining}
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	logger.Warn("too many wrong second factor codes, rejecting login",
+		zap.String("login_challenge", loginChallenge),
+		zap.String("user_id", userID))
+
+	a.rejectMFALogin(c, loginChallenge, authErr)
+}
+
+// Rejects the pending login and returns where the frontend sends the user.
+func (a *AuthHandlers) rejectMFALogin(c *gin.Context, loginChallenge string, authErr *cerror.AuthError) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	a.clearMFAState(c)
+
+	result, err := a.appAuth.RejectLogin(c.Request.Context(), types.RejectRequest{
+		Challenge:        loginChallenge,
+		Error:            types.OAuthAccessDenied,
+		ErrorDescription: errorDescription(authErr),
+	})
+	if err != nil {
+		logger.Error("failed to reject login", zap.Error(err))
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, MFACompleteResponse{
+		RedirectTo: result.RedirectTo,
+	})
+}
+
+// Ends the second factor step of a login the user passed and forgets the wrong
+// second factors they gave before.
+func (a *AuthHandlers) mfaPassed(c *gin.Context, userID string) {
+	if err := a.mfaAttempts.Reset(userID); err != nil {
+		c.MustGet("logger").(*zap.Logger).Warn("failed to reset second factor failures", zap.Error(err))
+	}
+
+	a.clearMFAState(c)
+}
+
+// Returns the login pending a second factor in the session.
+func pendingMFA(c *gin.Context) (loginChallenge, userID string, enrollmentRequired bool, ok bool) {
+	session := sessions.Default(c)
+
+	loginChallenge, _ = session.Get("mfa_login_challenge").(string)
+	userID, _ = session.Get("mfa_user_id").(string)
+	enrollmentRequired, _ = session.Get("mfa_enrollment_required").(bool)
+
+	return loginChallenge, userID, enrollmentRequired, loginChallenge != "" && userID != ""
+}
+
+func (a *AuthHandlers) clearMFAState(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Delete("mfa_login_challenge")
+	session.Delete("mfa_user_id")
+	session.Delete("mfa_enrollment_required")
+	session.Delete("mfa_passkey_state")
+
+	a.saveSession(c, session)
+}
+
+func respondMFANotPending(c *gin.Context) {
+	authErr := cerror.NewAuthError(cerror.AuthMFANotPending, nil)
+	c.JSON(authErr.HTTPStatusCode(), authErr)
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"net/http"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PasskeyLoginResponse ends a passkey sign in.
+type PasskeyLoginResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// Keeps the login pending in the session and sends the user to the passkey page.
+func (a *AuthHandlers) startPasskeyLogin(c *gin.Context, loginChallenge string) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	session := sessions.Default(c)
+	session.Set("passkey_login_challenge", loginChallenge)
+	session.Delete("passkey_login_state")
+
+	if err := session.Save(); err != nil {
+		logger.Error("failed to save session", zap.Error(err))
+
+		a.rejectLogin(c, loginChallenge, types.OAuthServerError, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	logger.Info("login pending passkey", zap.String("login_challenge", loginChallenge))
+
+	c.Redirect(http.StatusFound, a.config.General.FrontendURL+"/auth/passkey")
+}
+
+// Starts the passkey assertion of a login signing in with a passkey alone.
+func (a *AuthHandlers) PasskeyBegin(c *gin.Context) {
+	session := sessions.Default(c)
+
+	if challenge, _ := session.Get("passkey_login_challenge").(string); challenge == "" {
+		respondMFANotPending(c)
+		return
+	}
+
+	a.beginPasskeyCeremony(c, "", "passkey_login_state")
+}
+
+// Verifies the passkey of a login signing in with a passkey alone and accepts
+// the login.
+func (a *AuthHandlers) PasskeyFinish(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+	session := sessions.Default(c)
+
+	loginChallenge, _ := session.Get("passkey_login_challenge").(string)
+	if loginChallenge == "" {
+		respondMFANotPending(c)
+		return
+	}
+
+	state, _ := session.Get("passkey_login_state").(string)
+
+	response, err := c.GetRawData()
+	if err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidState, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	// A challenge is only answered once
+	session.Delete("passkey_login_state")
+
+	result, err := a.appAuth.FinishPasskeyLogin(c.Request.Context(), types.PasskeyLoginRequest{
+		LoginChallenge: loginChallenge,
+		State:          state,
+		Response:       response,
+	})
+	if err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthHydraAcceptFailed), err.Error(), nil)
+
+		logger.Warn("passkey sign in failed",
+			zap.String("login_challenge", loginChallenge),
+			zap.Error(err),
+			zap.String("error_code", string(authErr.Code)))
+
+		a.saveSession(c, session)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	logger.Info("signed in with passkey",
+		zap.String("login_challenge", loginChallenge),
+		zap.String("user_id", result.Subject))
+
+	session.Delete("passkey_login_challenge")
+	a.saveSession(c, session)
+
+	c.JSON(http.StatusOK, PasskeyLoginResponse{
+		RedirectTo: result.RedirectTo,
+	})
+}
+
+// Starts the passkey assertion checking the second factor of the pending login.
+func (a *AuthHandlers) MFAPasskeyBegin(c *gin.Context) {
+	_, userID, enrollmentRequired, ok := pendingMFA(c)
+	if !ok || enrollmentRequired {
+		respondMFANotPending(c)
+		return
+	}
+
+	a.beginPasskeyCeremony(c, userID, "mfa_passkey_state")
+}
+
+// Checks the passkey of the pending login and accepts the login.
+func (a *AuthHandlers) MFAPasskeyFinish(c *gin.Context) {
+	loginChallenge, userID, enrollmentRequired, ok := pendingMFA(c)
+	if !ok || enrollmentRequired {
+		respondMFANotPending(c)
+		return
+	}
+
+	if a.mfaLocked(c, loginChallenge, userID) {
+		return
+	}
+
+	session := sessions.Default(c)
+	state, _ := session.Get("mfa_passkey_state").(string)
+
+	response, err := c.GetRawData()
+	if err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidState, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	// A challenge is only answered once, whatever the answer
+	session.Delete("mfa_passkey_state")
+
+	if err := session.Save(); err != nil {
+		c.MustGet("logger").(*zap.Logger).Error("failed to save session", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	result, err := a.appAuth.FinishPasskeyLogin(c.Request.Context(), types.PasskeyLoginRequest{
+		LoginChallenge: loginChallenge,
+		UserID:         userID,
+		State:          state,
+		Response:       response,
+	})
+	if err != nil {
+		a.mfaFailed(c, loginChallenge, userID, err)
+		return
+	}
+
+	a.mfaPassed(c, userID)
+
+	c.JSON(http.StatusOK, MFACompleteResponse{
+		RedirectTo: result.RedirectTo,
+	})
+}
+
+// Starts a passkey assertion for userID, or for any user when empty, and keeps
+// its state in the session under key.
+func (a *AuthHandlers) beginPasskeyCeremony(c *gin.Context, userID, key string) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	ceremony, err := a.appAuth.BeginPasskeyLogin(c.Request.Context(), userID)
+	if err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthSessionCreateFailed), err.Error(), nil)
+
+		logger.Error("failed to begin passkey assertion", zap.Error(err))
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set(key, ceremony.State)
+
+	if err := session.Save(); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil)
+
+		logger.Error("failed to save session", zap.Error(err))
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", ceremony.Options)
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"conformitea/server/internal/gateway/mfa_attempt"
+	"conformitea/server/types"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/gomodule/redigo/redis"
+	"go.uber.org/zap"
+)
+
+const testUserID = "00000000-0000-4000-8000-000000000001"
+
+// Answers passkey assertions with err, or accepts the login without one.
+type stubPasskeyAuth struct {
+	stubAuth
+	err error
+}
+
+func (s *stubPasskeyAuth) FinishPasskeyLogin(ctx context.Context, req types.PasskeyLoginRequest) (types.PasskeyLoginResult, error) {
+	if s.err != nil {
+		return types.PasskeyLoginResult{}, s.err
+	}
+
+	return types.PasskeyLoginResult{RedirectTo: "https://hydra.example.com/login/accepted", Subject: req.UserID}, nil
+}
+
+// Returns a router serving the passkey step of a login pending a second factor,
+// and routes to start the step and read its state back from the session.
+func passkeyRouter(t *testing.T, appAuth types.AppAuth, maxFailures int) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", server.Addr())
+		},
+	}
+	t.Cleanup(func() { _ = pool.Close() })
+
+	handlers := &AuthHandlers{
+		appAuth:     appAuth,
+		mfaAttempts: mfa_attempt.NewStore(pool, maxFailures, 60),
+	}
+
+	router := gin.New()
+	router.Use(sessions.Sessions("session", cookie.NewStore([]byte("secret"))))
+	router.Use(func(c *gin.Context) {
+		c.Set("logger", zap.NewNop())
+	})
+	router.POST("/start", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set("mfa_login_challenge", testLoginChallenge)
+		session.Set("mfa_user_id", testUserID)
+		session.Set("mfa_passkey_state", "passkey-state")
+		_ = session.Save()
+	})
+	router.POST("/auth/mfa/passkey/finish", handlers.MFAPasskeyFinish)
+	router.GET("/state", func(c *gin.Context) {
+		state, _ := sessions.Default(c).Get("mfa_passkey_state").(string)
+		c.String(http.StatusOK, state)
+	})
+
+	return router
+}
+
+// Serves a request carrying the session cookie and returns the response, with
+// the session cookie replaced when the response set a new one.
+func serveWithSession(router *gin.Engine, req *http.Request, session *http.Cookie) (*httptest.ResponseRecorder, *http.Cookie) {
+	if session != nil {
+		req.AddCookie(session)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	for _, c := range recorder.Result().Cookies() {
+		if c.Name == "session" {
+			session = c
+		}
+	}
+
+	return recorder, session
+}
+
+func TestMFAPasskeyFinishUsesChallengeOnce(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		maxFailures int
+		wantStatus  int
+	}{
+		{
+			name:        "passkey accepted",
+			maxFailures: 5,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "wrong passkey with attempts remaining",
+			err:         types.ErrPasskeyInvalid,
+			maxFailures: 5,
+			wantStatus:  http.StatusUnauthorized,
+		},
+		{
+			name:        "wrong passkey locking the user out",
+			err:         types.ErrPasskeyInvalid,
+			maxFailures: 1,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "passkey that could not be checked",
+			err:         errors.New("database unavailable"),
+			maxFailures: 5,
+			wantStatus:  http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := passkeyRouter(t, &stubPasskeyAuth{err: tt.err}, tt.maxFailures)
+
+			_, session := serveWithSession(router, httptest.NewRequest(http.MethodPost, "/start", nil), nil)
+
+			recorder, session := serveWithSession(router, httptest.NewRequest(http.MethodPost, "/auth/mfa/passkey/finish", strings.NewReader("{}")), session)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+
+			recorder, _ = serveWithSession(router, httptest.NewRequest(http.MethodGet, "/state", nil), session)
+			if state := recorder.Body.String(); state != "" {
+				t.Errorf("passkey state after the answer = %q, want it removed", state)
+			}
+		})
+	}
+}
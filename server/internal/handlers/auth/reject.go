@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OAuth2 errors an identity provider may return that mean the user has to sign in
+// interactively. Any other provider error is reported as access_denied.
+var loginRequiredProviderErrors = map[string]bool{
+	"login_required":       true,
+	"interaction_required": true,
+	"consent_required":     true,
+}
+
+// Sends the browser to the frontend error page. Used when there is no challenge
+// to reject or Hydra could not be reached.
+func (a *AuthHandlers) redirectToErrorPage(c *gin.Context, authErr *cerror.AuthError) {
+	query := url.Values{}
+	query.Set("code", authErr.Code)
+
+	c.Redirect(http.StatusFound, a.config.General.FrontendURL+"/auth/error?"+query.Encode())
+}
+
+// Rejects the Hydra login request and redirects the browser back to the OAuth2
+// client with the error.
+func (a *AuthHandlers) rejectLogin(c *gin.Context, loginChallenge string, oauthError string, authErr *cerror.AuthError) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	result, err := a.appAuth.RejectLogin(c.Request.Context(), types.RejectRequest{
+		Challenge:        loginChallenge,
+		Error:            oauthError,
+		ErrorDescription: errorDescription(authErr),
+	})
+	if err != nil {
+		logger.Error("failed to reject login",
+			zap.String("login_challenge", loginChallenge),
+			zap.Error(err))
+
+		a.redirectToErrorPage(c, authErr)
+		return
+	}
+
+	logger.Info("login rejected, redirecting",
+		zap.String("login_challenge", loginChallenge),
+		zap.String("oauth_error", oauthError),
+		zap.String("error_code", authErr.Code))
+
+	c.Redirect(http.StatusFound, result.RedirectTo)
+}
+
+// Rejects the Hydra consent request and redirects the browser back to the OAuth2
+// client with the error.
+func (a *AuthHandlers) rejectConsent(c *gin.Context, consentChallenge string, oauthError string, authErr *cerror.AuthError) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	result, err := a.appAuth.RejectConsent(c.Request.Context(), types.RejectRequest{
+		Challenge:        consentChallenge,
+		Error:            oauthError,
+		ErrorDescription: errorDescription(authErr),
+	})
+	if err != nil {
+		logger.Error("failed to reject consent",
+			zap.String("consent_challenge", consentChallenge),
+			zap.Error(err))
+
+		a.redirectToErrorPage(c, authErr)
+		return
+	}
+
+	logger.Info("consent rejected, redirecting",
+		zap.String("consent_challenge", consentChallenge),
+		zap.String("oauth_error", oauthError),
+		zap.String("error_code", authErr.Code))
+
+	c.Redirect(http.StatusFound, result.RedirectTo)
+}
+
+// Describes the error to the OAuth2 client without exposing internal details.
+func errorDescription(authErr *cerror.AuthError) string {
+	return fmt.Sprintf("Authentication failed (%s)", authErr.Code)
+}
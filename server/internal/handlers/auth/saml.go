@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/gateway/saml_login"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Content type of SAML metadata documents.
+const samlMetadataContentType = "application/samlmetadata+xml"
+
+// Keeps the login under the ID of its SAML authentication request, which is the
+// relay state the identity provider posts back, and sends the user to the
+// identity provider.
+func (a *AuthHandlers) startSAMLLogin(c *gin.Context, result types.LoginResult) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	err := a.samlLogins.Save(result.SAMLRequestID, saml_login.Login{
+		LoginChallenge: result.HydraLoginChallenge,
+		OrganizationID: strings.TrimPrefix(result.IDPProvider, types.SAMLProviderPrefix),
+		RequestID:      result.SAMLRequestID,
+	})
+	if err != nil {
+		logger.Error("failed to save saml login", zap.Error(err))
+
+		a.rejectLogin(c, result.HydraLoginChallenge, types.OAuthServerError, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	logger.Info("redirecting to SAML identity provider",
+		zap.String("provider", result.IDPProvider),
+		zap.String("login_challenge", result.HydraLoginChallenge),
+	)
+
+	c.Redirect(http.StatusFound, result.AuthURL)
+}
+
+// Handles the SAML response an organization's identity provider posts to its
+// assertion consumer service and completes the Hydra flow.
+func (a *AuthHandlers) SAMLAssertionConsumer(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+	logger.Info("processing saml response")
+
+	organizationID := c.Param("organization_id")
+
+	login, found, err := a.samlLogins.Take(c.PostForm("RelayState"))
+	if err != nil {
+		logger.Error("failed to read saml login", zap.Error(err))
+
+		a.redirectToErrorPage(c, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	// Unsolicited responses are not accepted, and a response can only be used once
+	if !found {
+		logger.Warn("saml response without pending login")
+
+		a.redirectToErrorPage(c, cerror.NewAuthError(cerror.AuthSessionNotFound, map[string]any{
+			"parameter": "RelayState",
+		}))
+		return
+	}
+
+	if login.OrganizationID != organizationID {
+		logger.Warn("saml response posted for another organization",
+			zap.String("organization_id", organizationID),
+			zap.String("expected_organization_id", login.OrganizationID))
+
+		a.rejectLogin(c, login.LoginChallenge, types.OAuthAccessDenied, cerror.NewAuthError(cerror.AuthInvalidState, map[string]any{
+			"parameter": "organization_id",
+			"reason":    "mismatch",
+		}))
+		return
+	}
+
+	samlResponse := c.PostForm("SAMLResponse")
+	if samlResponse == "" {
+		logger.Warn("saml response missing")
+
+		a.rejectLogin(c, login.LoginChallenge, types.OAuthAccessDenied, cerror.NewAuthError(cerror.AuthInvalidState, map[string]any{
+			"parameter": "SAMLResponse",
+			"reason":    "missing",
+		}))
+		return
+	}
+
+	result, err := a.appAuth.ProcessSAMLResponse(c.Request.Context(), types.SAMLResponseRequest{
+		OrganizationID:      organizationID,
+		SAMLResponse:        samlResponse,
+		RequestID:           login.RequestID,
+		HydraLoginChallenge: login.LoginChallenge,
+	})
+	if err != nil {
+		logger.Error("failed to process saml response", zap.Error(err))
+
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthSessionCreateFailed), err.Error(), map[string]any{
+			"provider": types.SAMLProviderPrefix + organizationID,
+		})
+
+		a.rejectLogin(c, login.LoginChallenge, oauthError(err), authErr)
+		return
+	}
+
+	if result.MFARequired {
+		a.continueSAMLLogin(c, login, result)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, result.RedirectTo)
+}
+
+// Picks up a SAML login waiting for a second factor. The browser is redirected
+// here from the assertion consumer service so that the session cookie is sent.
+func (a *AuthHandlers) SAMLContinue(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	login, found, err := a.samlLogins.Take(c.Query("state"))
+	if err != nil {
+		logger.Error("failed to read saml login", zap.Error(err))
+
+		a.redirectToErrorPage(c, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	if !found || login.Subject == "" {
+		logger.Warn("saml login not pending a second factor")
+
+		a.redirectToErrorPage(c, cerror.NewAuthError(cerror.AuthSessionNotFound, map[string]any{
+			"parameter": "state",
+		}))
+		return
+	}
+
+	a.startMFA(c, login.LoginChallenge, types.CallbackResult{
+		Subject:               login.Subject,
+		MFARequired:           true,
+		MFAEnrollmentRequired: login.MFAEnrollmentRequired,
+	})
+}
+
+// SAMLMetadata serves the metadata of an organization's SAML service provider.
+func (a *AuthHandlers) SAMLMetadata(c *gin.Context) {
+	metadata, err := a.appAuth.GetSAMLMetadata(c.Request.Context(), c.Param("organization_id"))
+	if err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthSessionCreateFailed), err.Error(), nil)
+
+		c.MustGet("logger").(*zap.Logger).Warn("failed to get saml metadata",
+			zap.Error(err),
+			zap.String("error_code", authErr.Code))
+
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	c.Data(http.StatusOK, samlMetadataContentType, metadata)
+}
+
+// Keeps a login waiting for a second factor under a new key and redirects the
+// browser to SAMLContinue with it.
+func (a *AuthHandlers) continueSAMLLogin(c *gin.Context, login saml_login.Login, result types.CallbackResult) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		logger.Error("failed to generate saml login key", zap.Error(err))
+
+		a.rejectLogin(c, login.LoginChallenge, types.OAuthServerError, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	state := hex.EncodeToString(key)
+
+	login.RequestID = ""
+	login.Subject = result.Subject
+	login.MFAEnrollmentRequired = result.MFAEnrollmentRequired
+
+	if err := a.samlLogins.Save(state, login); err != nil {
+		logger.Error("failed to save saml login", zap.Error(err))
+
+		a.rejectLogin(c, login.LoginChallenge, types.OAuthServerError, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/auth/saml/continue?state="+state)
+}
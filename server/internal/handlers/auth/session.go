@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Starts signing the user in to the frontend by sending the browser to Hydra with
+// the server as the OAuth2 client. Invitees pass the token of their invitation
+// link, to be sent back to it once signed in.
+func (a *AuthHandlers) SessionLogin(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	result, err := a.appAuth.StartSession(c.Request.Context(), types.SessionLoginRequest{
+		ProviderHint: c.Query("provider"),
+	})
+	if err != nil {
+		logger.Error("failed to start session login", zap.Error(err))
+
+		a.redirectToErrorPage(c, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set("oauth_state", result.State)
+	session.Set("oauth_nonce", result.Nonce)
+	session.Set("oauth_code_verifier", result.CodeVerifier)
+
+	if invitation := c.Query("invitation"); invitation != "" {
+		session.Set("invitation_token", invitation)
+	} else {
+		session.Delete("invitation_token")
+	}
+
+	if err := session.Save(); err != nil {
+		logger.Error("failed to save session", zap.Error(err))
+
+		a.redirectToErrorPage(c, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	c.Redirect(http.StatusFound, result.AuthURL)
+}
+
+// Completes the sign in when Hydra redirects back to the first-party client, storing
+// the tokens and the user's identity in the session.
+func (a *AuthHandlers) SessionCallback(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+	session := sessions.Default(c)
+
+	expectedState, _ := session.Get("oauth_state").(string)
+	nonce, _ := session.Get("oauth_nonce").(string)
+	codeVerifier, _ := session.Get("oauth_code_verifier").(string)
+	invitation, _ := session.Get("invitation_token").(string)
+
+	session.Delete("oauth_state")
+	session.Delete("oauth_nonce")
+	session.Delete("oauth_code_verifier")
+	session.Delete("invitation_token")
+
+	// Hydra reports rejected logins and consents as an OAuth2 error
+	if oauthError := c.Query("error"); oauthError != "" {
+		logger.Warn("sign in rejected",
+			zap.String("error", oauthError),
+			zap.String("error_description", c.Query("error_description")))
+
+		a.saveSession(c, session)
+
+		query := url.Values{}
+		query.Set("error", oauthError)
+		c.Redirect(http.StatusFound, a.config.General.FrontendURL+"/auth/error?"+query.Encode())
+		return
+	}
+
+	result, err := a.appAuth.CompleteSession(c.Request.Context(), types.SessionCallbackRequest{
+		Code:          c.Query("code"),
+		State:         c.Query("state"),
+		ExpectedState: expectedState,
+		Nonce:         nonce,
+		CodeVerifier:  codeVerifier,
+	})
+	if err != nil {
+		logger.Error("failed to complete session login", zap.Error(err))
+
+		a.saveSession(c, session)
+		a.redirectToErrorPage(c, cerror.NewAuthErrorWithMessage(errorCode(err, cerror.AuthSessionExchange), err.Error(), nil))
+		return
+	}
+
+	// The signed in session gets a new ID, so one fixed before the sign in is useless
+	if err := a.sessionIndex.Renew(session); err != nil {
+		logger.Error("failed to renew session", zap.Error(err))
+
+		a.redirectToErrorPage(c, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	// Signing in again ends an impersonation the session was in
+	gin_session.ClearImpersonation(session)
+	gin_session.SetTokens(session, result.Tokens)
+	session.Set("user_id", result.UserID)
+	session.Set("email", result.Email)
+	session.Set("name", result.Name)
+	session.Set("provider", result.Provider)
+	session.Set("authenticated", true)
+
+	if err := session.Save(); err != nil {
+		logger.Error("failed to save session", zap.Error(err))
+
+		a.redirectToErrorPage(c, cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil))
+		return
+	}
+
+	// Track the session so it ends when the subject signs out elsewhere
+	if err := a.sessionIndex.Add(result.UserID, session.ID(), gin_session.SessionInfo{
+		UserAgent:      c.Request.UserAgent(),
+		IP:             c.ClientIP(),
+		HydraSessionID: result.HydraSessionID,
+	}); err != nil {
+		logger.Warn("failed to index session", zap.Error(err))
+	}
+
+	logger.Info("session established", zap.String("user_id", result.UserID))
+
+	// The invitee accepts the invitation now that they are signed in
+	if invitation != "" {
+		query := url.Values{}
+		query.Set("token", invitation)
+		c.Redirect(http.StatusFound, a.config.General.FrontendURL+"/invitations/accept?"+query.Encode())
+		return
+	}
+
+	c.Redirect(http.StatusFound, a.config.General.FrontendURL)
+}
+
+func (a *AuthHandlers) saveSession(c *gin.Context, session sessions.Session) {
+	if err := session.Save(); err != nil {
+		c.MustGet("logger").(*zap.Logger).Warn("failed to save session", zap.Error(err))
+	}
+}
@@ -0,0 +1,266 @@
+package clients
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateClientRequest is the body of the client creation endpoint.
+type CreateClientRequest struct {
+	// Generated when empty
+	ID                     string   `json:"id"`
+	Name                   string   `json:"name" binding:"required"`
+	RedirectURIs           []string `json:"redirect_uris"`
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris"`
+	GrantTypes             []string `json:"grant_types"`
+	ResponseTypes          []string `json:"response_types"`
+	Scopes                 []string `json:"scopes"`
+	Audience               []string `json:"audience"`
+	// "none" for public clients, defaults to client_secret_basic
+	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method"`
+	IdentityProvider        string `json:"identity_provider"`
+	OrganizationID          string `json:"organization_id"`
+	// Skip the consent page for the client
+	FirstParty bool `json:"first_party"`
+}
+
+// UpdateClientRequest is the body of the client update endpoint. Fields left out
+// are not changed.
+type UpdateClientRequest struct {
+	Name                   *string   `json:"name"`
+	RedirectURIs           *[]string `json:"redirect_uris"`
+	PostLogoutRedirectURIs *[]string `json:"post_logout_redirect_uris"`
+	GrantTypes             *[]string `json:"grant_types"`
+	ResponseTypes          *[]string `json:"response_types"`
+	Scopes                 *[]string `json:"scopes"`
+	Audience               *[]string `json:"audience"`
+	// An empty string removes the identity provider or organization
+	IdentityProvider *string `json:"identity_provider"`
+	OrganizationID   *string `json:"organization_id"`
+	FirstParty       *bool   `json:"first_party"`
+}
+
+// ClientResponse represents an OAuth2 client. The secret is only returned when
+// the client is created or its secret is rotated.
+type ClientResponse struct {
+	ID                      string    `json:"id"`
+	Name                    string    `json:"name"`
+	RedirectURIs            []string  `json:"redirect_uris"`
+	PostLogoutRedirectURIs  []string  `json:"post_logout_redirect_uris"`
+	GrantTypes              []string  `json:"grant_types"`
+	ResponseTypes           []string  `json:"response_types"`
+	Scopes                  []string  `json:"scopes"`
+	Audience                []string  `json:"audience"`
+	TokenEndpointAuthMethod string    `json:"token_endpoint_auth_method"`
+	IdentityProvider        string    `json:"identity_provider,omitempty"`
+	OrganizationID          string    `json:"organization_id,omitempty"`
+	FirstParty              bool      `json:"first_party"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+	Secret                  string    `json:"secret,omitempty"`
+}
+
+// ListClients returns every OAuth2 client registered at Hydra.
+func (h *ClientsHandlers) ListClients(c *gin.Context) {
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	clients, err := h.appClients.ListClients(c.Request.Context(), actor)
+	if err != nil {
+		respondClientError(c, err, "failed to list clients")
+		return
+	}
+
+	response := make([]ClientResponse, 0, len(clients))
+	for _, client := range clients {
+		response = append(response, toClientResponse(client, ""))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateClient registers an OAuth2 client at Hydra.
+func (h *ClientsHandlers) CreateClient(c *gin.Context) {
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var body CreateClientRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		clientErr := cerror.NewClientError(cerror.ClientInvalidRequest, err.Error(), nil)
+		c.JSON(clientErr.HTTPStatusCode(), clientErr)
+		return
+	}
+
+	issued, err := h.appClients.CreateClient(c.Request.Context(), types.CreateOAuthClientRequest{
+		Actor: actor,
+		Client: types.OAuthClient{
+			ID:                      body.ID,
+			Name:                    body.Name,
+			RedirectURIs:            body.RedirectURIs,
+			PostLogoutRedirectURIs:  body.PostLogoutRedirectURIs,
+			GrantTypes:              body.GrantTypes,
+			ResponseTypes:           body.ResponseTypes,
+			Scopes:                  body.Scopes,
+			Audience:                body.Audience,
+			TokenEndpointAuthMethod: body.TokenEndpointAuthMethod,
+			IdentityProvider:        body.IdentityProvider,
+			OrganizationID:          body.OrganizationID,
+			FirstParty:              body.FirstParty,
+		},
+	})
+	if err != nil {
+		respondClientError(c, err, "failed to create client")
+		return
+	}
+
+	c.MustGet("logger").(*zap.Logger).Info("oauth2 client created",
+		zap.String("client_id", issued.Client.ID),
+		zap.String("actor_id", actor.ID))
+
+	c.JSON(http.StatusCreated, toClientResponse(issued.Client, issued.Secret))
+}
+
+// GetClient returns an OAuth2 client.
+func (h *ClientsHandlers) GetClient(c *gin.Context) {
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	client, err := h.appClients.GetClient(c.Request.Context(), actor, c.Param("id"))
+	if err != nil {
+		respondClientError(c, err, "failed to get client")
+		return
+	}
+
+	c.JSON(http.StatusOK, toClientResponse(client, ""))
+}
+
+// UpdateClient changes the fields of an OAuth2 client given in the body.
+func (h *ClientsHandlers) UpdateClient(c *gin.Context) {
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var body UpdateClientRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		clientErr := cerror.NewClientError(cerror.ClientInvalidRequest, err.Error(), nil)
+		c.JSON(clientErr.HTTPStatusCode(), clientErr)
+		return
+	}
+
+	client, err := h.appClients.UpdateClient(c.Request.Context(), types.UpdateOAuthClientRequest{
+		Actor:                  actor,
+		ClientID:               c.Param("id"),
+		Name:                   body.Name,
+		RedirectURIs:           body.RedirectURIs,
+		PostLogoutRedirectURIs: body.PostLogoutRedirectURIs,
+		GrantTypes:             body.GrantTypes,
+		ResponseTypes:          body.ResponseTypes,
+		Scopes:                 body.Scopes,
+		Audience:               body.Audience,
+		IdentityProvider:       body.IdentityProvider,
+		OrganizationID:         body.OrganizationID,
+		FirstParty:             body.FirstParty,
+	})
+	if err != nil {
+		respondClientError(c, err, "failed to update client")
+		return
+	}
+
+	c.JSON(http.StatusOK, toClientResponse(client, ""))
+}
+
+// DeleteClient deletes an OAuth2 client and the tokens issued to it.
+func (h *ClientsHandlers) DeleteClient(c *gin.Context) {
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.appClients.DeleteClient(c.Request.Context(), actor, c.Param("id")); err != nil {
+		respondClientError(c, err, "failed to delete client")
+		return
+	}
+
+	c.MustGet("logger").(*zap.Logger).Info("oauth2 client deleted",
+		zap.String("client_id", c.Param("id")),
+		zap.String("actor_id", actor.ID))
+
+	c.Status(http.StatusNoContent)
+}
+
+// RotateClientSecret replaces the secret of a confidential OAuth2 client and
+// returns the new one.
+func (h *ClientsHandlers) RotateClientSecret(c *gin.Context) {
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	issued, err := h.appClients.RotateClientSecret(c.Request.Context(), actor, c.Param("id"))
+	if err != nil {
+		respondClientError(c, err, "failed to rotate client secret")
+		return
+	}
+
+	c.MustGet("logger").(*zap.Logger).Info("oauth2 client secret rotated",
+		zap.String("client_id", issued.Client.ID),
+		zap.String("actor_id", actor.ID))
+
+	c.JSON(http.StatusOK, toClientResponse(issued.Client, issued.Secret))
+}
+
+func requireActor(c *gin.Context) (types.Actor, bool) {
+	actor, ok := middlewares.CurrentActor(c)
+	if !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+	}
+
+	return actor, ok
+}
+
+func respondClientError(c *gin.Context, err error, message string) {
+	clientErr := cerror.NewClientError(errorCode(err, cerror.ClientRequestFailed), err.Error(), map[string]any{
+		"client_id": c.Param("id"),
+	})
+
+	c.MustGet("logger").(*zap.Logger).Warn(message,
+		zap.Error(err),
+		zap.String("error_code", clientErr.Code))
+
+	c.JSON(clientErr.HTTPStatusCode(), clientErr)
+}
+
+func toClientResponse(client types.OAuthClient, secret string) ClientResponse {
+	return ClientResponse{
+		ID:                      client.ID,
+		Name:                    client.Name,
+		RedirectURIs:            client.RedirectURIs,
+		PostLogoutRedirectURIs:  client.PostLogoutRedirectURIs,
+		GrantTypes:              client.GrantTypes,
+		ResponseTypes:           client.ResponseTypes,
+		Scopes:                  client.Scopes,
+		Audience:                client.Audience,
+		TokenEndpointAuthMethod: client.TokenEndpointAuthMethod,
+		IdentityProvider:        client.IdentityProvider,
+		OrganizationID:          client.OrganizationID,
+		FirstParty:              client.FirstParty,
+		CreatedAt:               client.CreatedAt,
+		UpdatedAt:               client.UpdatedAt,
+		Secret:                  secret,
+	}
+}
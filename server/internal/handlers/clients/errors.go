@@ -0,0 +1,29 @@
+package clients
+
+import (
+	"errors"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+)
+
+// ConformiTea error codes of the errors returned by AppClients.
+var appClientsErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{types.ErrClientNotFound, cerror.ClientNotFound},
+	{types.ErrPermissionDenied, cerror.ClientPermissionDenied},
+	{types.ErrClientInvalid, cerror.ClientInvalidRequest},
+}
+
+// Returns the error code for an AppClients error, or fallback if it is not a known one.
+func errorCode(err error, fallback string) string {
+	for _, e := range appClientsErrorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+
+	return fallback
+}
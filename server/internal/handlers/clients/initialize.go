@@ -0,0 +1,15 @@
+package clients
+
+import (
+	"conformitea/server/types"
+)
+
+type ClientsHandlers struct {
+	appClients types.AppClients
+}
+
+func Initialize(appClients types.AppClients) *ClientsHandlers {
+	return &ClientsHandlers{
+		appClients: appClients,
+	}
+}
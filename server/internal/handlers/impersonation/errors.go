@@ -0,0 +1,29 @@
+package impersonation
+
+import (
+	"errors"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+)
+
+// ConformiTea error codes of the errors returned by AppImpersonation.
+var appImpersonationErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{types.ErrUserNotFound, cerror.ImpersonationUserNotFound},
+	{types.ErrPermissionDenied, cerror.ImpersonationPermissionDenied},
+	{types.ErrInvalidRequest, cerror.ImpersonationInvalidRequest},
+}
+
+// Returns the error code for an AppImpersonation error, or fallback if it is not a known one.
+func errorCode(err error, fallback string) string {
+	for _, e := range appImpersonationErrorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,226 @@
+package impersonation
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// StartImpersonationRequest is the body of the impersonation endpoint.
+type StartImpersonationRequest struct {
+	// ID or email of the user to impersonate
+	User   string `json:"user" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+	// Seconds the impersonation lasts, the default duration when omitted
+	Duration int `json:"duration"`
+}
+
+// ImpersonationResponse represents the impersonation of a session.
+type ImpersonationResponse struct {
+	ID                string    `json:"id"`
+	ImpersonatorID    string    `json:"impersonator_id"`
+	ImpersonatorEmail string    `json:"impersonator_email"`
+	SubjectID         string    `json:"subject_id"`
+	SubjectEmail      string    `json:"subject_email"`
+	SubjectName       string    `json:"subject_name"`
+	Reason            string    `json:"reason"`
+	StartedAt         time.Time `json:"started_at"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// AuditEventResponse represents an entry of the audit trail.
+type AuditEventResponse struct {
+	ID              string    `json:"id"`
+	Action          string    `json:"action"`
+	ActorID         string    `json:"actor_id"`
+	SubjectID       string    `json:"subject_id"`
+	ImpersonationID string    `json:"impersonation_id,omitempty"`
+	Reason          string    `json:"reason"`
+	Method          string    `json:"method,omitempty"`
+	Path            string    `json:"path,omitempty"`
+	Status          int       `json:"status,omitempty"`
+	RequestID       string    `json:"request_id,omitempty"`
+	IPAddress       string    `json:"ip_address,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// StartImpersonation lets the signed in staff user act as another user in their
+// session until the impersonation ends or expires.
+func (h *ImpersonationHandlers) StartImpersonation(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+	session := sessions.Default(c)
+
+	// The impersonation lives in the session, bearer tokens cannot start one
+	staffID, ok := middlewares.SignedInUserID(c)
+	if _, bearer := middlewares.GetPrincipal(c); bearer || !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	if current, ok := gin_session.GetImpersonation(session); ok {
+		impErr := cerror.NewImpersonationError(cerror.ImpersonationActive, "session already impersonating a user", map[string]any{
+			"impersonation_id": current.ID,
+		})
+		c.JSON(impErr.HTTPStatusCode(), impErr)
+		return
+	}
+
+	var body StartImpersonationRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		impErr := cerror.NewImpersonationError(cerror.ImpersonationInvalidRequest, err.Error(), nil)
+		c.JSON(impErr.HTTPStatusCode(), impErr)
+		return
+	}
+
+	impersonation, err := h.appImpersonation.StartImpersonation(c.Request.Context(), types.StartImpersonationRequest{
+		Actor:    types.Actor{ID: staffID, Kind: types.PrincipalUser},
+		Subject:  body.User,
+		Reason:   body.Reason,
+		Duration: time.Duration(body.Duration) * time.Second,
+		Request:  middlewares.AuditRequest(c),
+	})
+	if err != nil {
+		respondImpersonationError(c, err, "failed to start impersonation")
+		return
+	}
+
+	gin_session.SetImpersonation(session, impersonation)
+	if err := session.Save(); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	logger.Info("impersonation started",
+		zap.String("impersonation_id", impersonation.ID),
+		zap.String("impersonator_id", impersonation.ImpersonatorID),
+		zap.String("subject_id", impersonation.SubjectID))
+
+	c.JSON(http.StatusCreated, toImpersonationResponse(impersonation))
+}
+
+// EndImpersonation ends the impersonation of the session, the staff user acts as
+// themself again.
+func (h *ImpersonationHandlers) EndImpersonation(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+	session := sessions.Default(c)
+
+	impersonation, ok := gin_session.GetImpersonation(session)
+	if !ok {
+		impErr := cerror.NewImpersonationError(cerror.ImpersonationNotActive, "session is not impersonating a user", nil)
+		c.JSON(impErr.HTTPStatusCode(), impErr)
+		return
+	}
+
+	gin_session.ClearImpersonation(session)
+	if err := session.Save(); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	if err := h.appImpersonation.EndImpersonation(c.Request.Context(), types.EndImpersonationRequest{
+		Impersonation: impersonation,
+		Request:       middlewares.AuditRequest(c),
+	}); err != nil {
+		logger.Error("failed to record impersonation end", zap.Error(err))
+	}
+
+	logger.Info("impersonation ended",
+		zap.String("impersonation_id", impersonation.ID),
+		zap.String("subject_id", impersonation.SubjectID))
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAuditEvents returns the most recent entries of the audit trail, filtered
+// by the actor_id, subject_id and impersonation_id query parameters.
+func (h *ImpersonationHandlers) ListAuditEvents(c *gin.Context) {
+	actor, ok := middlewares.CurrentActor(c)
+	if !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	var limit int
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			impErr := cerror.NewImpersonationError(cerror.ImpersonationInvalidRequest, "limit must be a positive integer", nil)
+			c.JSON(impErr.HTTPStatusCode(), impErr)
+			return
+		}
+		limit = n
+	}
+
+	events, err := h.appImpersonation.ListAuditEvents(c.Request.Context(), types.AuditEventQuery{
+		Actor:           actor,
+		ActorID:         c.Query("actor_id"),
+		SubjectID:       c.Query("subject_id"),
+		ImpersonationID: c.Query("impersonation_id"),
+		Limit:           limit,
+	})
+	if err != nil {
+		respondImpersonationError(c, err, "failed to list audit events")
+		return
+	}
+
+	response := make([]AuditEventResponse, 0, len(events))
+	for _, e := range events {
+		response = append(response, toAuditEventResponse(e))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func respondImpersonationError(c *gin.Context, err error, message string) {
+	impErr := cerror.NewImpersonationError(errorCode(err, cerror.ImpersonationRequestFailed), err.Error(), nil)
+
+	c.MustGet("logger").(*zap.Logger).Warn(message,
+		zap.Error(err),
+		zap.String("error_code", impErr.Code))
+
+	c.JSON(impErr.HTTPStatusCode(), impErr)
+}
+
+func toImpersonationResponse(i types.Impersonation) ImpersonationResponse {
+	return ImpersonationResponse{
+		ID:                i.ID,
+		ImpersonatorID:    i.ImpersonatorID,
+		ImpersonatorEmail: i.ImpersonatorEmail,
+		SubjectID:         i.SubjectID,
+		SubjectEmail:      i.SubjectEmail,
+		SubjectName:       i.SubjectName,
+		Reason:            i.Reason,
+		StartedAt:         i.StartedAt,
+		ExpiresAt:         i.ExpiresAt,
+	}
+}
+
+func toAuditEventResponse(e types.AuditEvent) AuditEventResponse {
+	return AuditEventResponse{
+		ID:              e.ID,
+		Action:          e.Action,
+		ActorID:         e.ActorID,
+		SubjectID:       e.SubjectID,
+		ImpersonationID: e.ImpersonationID,
+		Reason:          e.Reason,
+		Method:          e.Method,
+		Path:            e.Path,
+		Status:          e.Status,
+		RequestID:       e.RequestID,
+		IPAddress:       e.IPAddress,
+		CreatedAt:       e.CreatedAt,
+	}
+}
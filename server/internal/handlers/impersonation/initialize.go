@@ -0,0 +1,15 @@
+package impersonation
+
+import (
+	"conformitea/server/types"
+)
+
+type ImpersonationHandlers struct {
+	appImpersonation types.AppImpersonation
+}
+
+func Initialize(appImpersonation types.AppImpersonation) *ImpersonationHandlers {
+	return &ImpersonationHandlers{
+		appImpersonation: appImpersonation,
+	}
+}
@@ -0,0 +1,240 @@
+package organizations
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AddDomainRequest is the body of the domain endpoint.
+type AddDomainRequest struct {
+	Domain string `json:"domain" binding:"required"`
+	// Add users at the domain as members instead of offering them to join
+	AutoJoin bool `json:"auto_join"`
+}
+
+// UpdateDomainRequest is the body of the domain update endpoint. Omitted fields
+// are left unchanged.
+type UpdateDomainRequest struct {
+	AutoJoin *bool `json:"auto_join"`
+}
+
+// DomainResponse represents an email domain claimed by an organization, with the
+// TXT record to publish to verify it.
+type DomainResponse struct {
+	ID             string               `json:"id"`
+	OrganizationID string               `json:"organization_id"`
+	Domain         string               `json:"domain"`
+	Verified       bool                 `json:"verified"`
+	VerifiedAt     *time.Time           `json:"verified_at"`
+	AutoJoin       bool                 `json:"auto_join"`
+	Record         DomainRecordResponse `json:"verification_record"`
+	CreatedAt      time.Time            `json:"created_at"`
+}
+
+// DomainRecordResponse is a DNS record.
+type DomainRecordResponse struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// JoinableOrganizationResponse represents an organization the user can join
+// because it verified the domain of their email.
+type JoinableOrganizationResponse struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+}
+
+// ListDomains returns the domains claimed by an organization.
+func (o *OrganizationsHandlers) ListDomains(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	domains, err := o.appOrganization.ListDomains(c.Request.Context(), actor, c.Param("id"))
+	if err != nil {
+		respondDomainError(c, err, "failed to list domains")
+		return
+	}
+
+	response := make([]DomainResponse, 0, len(domains))
+	for _, d := range domains {
+		response = append(response, toDomainResponse(d))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AddDomain claims an email domain for an organization.
+func (o *OrganizationsHandlers) AddDomain(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	var body AddDomainRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	domain, err := o.appOrganization.AddDomain(c.Request.Context(), types.AddDomainRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		Domain:         body.Domain,
+		AutoJoin:       body.AutoJoin,
+	})
+	if err != nil {
+		respondDomainError(c, err, "failed to add domain")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toDomainResponse(domain))
+}
+
+// VerifyDomain checks the verification record of a domain.
+func (o *OrganizationsHandlers) VerifyDomain(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	domain, err := o.appOrganization.VerifyDomain(c.Request.Context(), domainRequest(c, actor))
+	if err != nil {
+		respondDomainError(c, err, "failed to verify domain")
+		return
+	}
+
+	c.JSON(http.StatusOK, toDomainResponse(domain))
+}
+
+// UpdateDomain changes the settings of a domain.
+func (o *OrganizationsHandlers) UpdateDomain(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	var body UpdateDomainRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	domain, err := o.appOrganization.UpdateDomain(c.Request.Context(), types.UpdateDomainRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		DomainID:       c.Param("domain_id"),
+		AutoJoin:       body.AutoJoin,
+	})
+	if err != nil {
+		respondDomainError(c, err, "failed to update domain")
+		return
+	}
+
+	c.JSON(http.StatusOK, toDomainResponse(domain))
+}
+
+// DeleteDomain releases an organization's claim of a domain.
+func (o *OrganizationsHandlers) DeleteDomain(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	if err := o.appOrganization.DeleteDomain(c.Request.Context(), domainRequest(c, actor)); err != nil {
+		respondDomainError(c, err, "failed to delete domain")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListJoinable returns the organizations the signed in user can join through the
+// domain of their email.
+func (o *OrganizationsHandlers) ListJoinable(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	organizations, err := o.appOrganization.ListJoinableOrganizations(c.Request.Context(), actor)
+	if err != nil {
+		respondDomainError(c, err, "failed to list joinable organizations")
+		return
+	}
+
+	response := make([]JoinableOrganizationResponse, 0, len(organizations))
+	for _, org := range organizations {
+		response = append(response, JoinableOrganizationResponse{
+			ID:     org.ID,
+			Name:   org.Name,
+			Domain: org.Domain,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Join adds the signed in user to an organization that verified the domain of
+// their email.
+func (o *OrganizationsHandlers) Join(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	if err := o.appOrganization.JoinOrganization(c.Request.Context(), actor, c.Param("id")); err != nil {
+		respondDomainError(c, err, "failed to join organization")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func domainRequest(c *gin.Context, actor types.Actor) types.DomainRequest {
+	return types.DomainRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		DomainID:       c.Param("domain_id"),
+	}
+}
+
+func respondDomainError(c *gin.Context, err error, message string) {
+	orgErr := cerror.NewOrganizationError(errorCode(err, cerror.DomainRequestFailed), err.Error(), map[string]any{
+		"organization_id": c.Param("id"),
+	})
+
+	c.MustGet("logger").(*zap.Logger).Warn(message,
+		zap.Error(err),
+		zap.String("error_code", orgErr.Code))
+
+	c.JSON(orgErr.HTTPStatusCode(), orgErr)
+}
+
+func toDomainResponse(d types.OrganizationDomain) DomainResponse {
+	return DomainResponse{
+		ID:             d.ID,
+		OrganizationID: d.OrganizationID,
+		Domain:         d.Domain,
+		Verified:       d.VerifiedAt != nil,
+		VerifiedAt:     d.VerifiedAt,
+		AutoJoin:       d.AutoJoin,
+		Record: DomainRecordResponse{
+			Type:  "TXT",
+			Name:  d.RecordName,
+			Value: d.RecordValue,
+		},
+		CreatedAt: d.CreatedAt,
+	}
+}
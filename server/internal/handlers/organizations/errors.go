@@ -0,0 +1,45 @@
+package organizations
+
+import (
+	"errors"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+)
+
+// ConformiTea error codes of the errors returned by AppOrganization.
+var appOrganizationErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{types.ErrOrganizationNotFound, cerror.OrganizationNotFound},
+	{types.ErrPermissionDenied, cerror.OrganizationPermissionDenied},
+	{types.ErrServiceAccountNotFound, cerror.ServiceAccountNotFound},
+	{types.ErrAPITokenNotFound, cerror.ServiceAccountTokenNotFound},
+	{types.ErrAPITokenInvalid, cerror.OrganizationInvalidRequest},
+	{types.ErrInvalidRequest, cerror.OrganizationInvalidRequest},
+	{types.ErrSAMLNotConfigured, cerror.SAMLConnectionNotFound},
+	{types.ErrSAMLMetadataInvalid, cerror.SAMLMetadataInvalid},
+	{types.ErrDomainNotFound, cerror.DomainNotFound},
+	{types.ErrDomainConflict, cerror.DomainConflict},
+	{types.ErrDomainNotVerified, cerror.DomainNotVerified},
+	{types.ErrTenantConflict, cerror.TenantConflict},
+	{types.ErrMemberNotFound, cerror.MemberNotFound},
+	{types.ErrRoleNotFound, cerror.RoleNotFound},
+	{types.ErrRoleConflict, cerror.RoleConflict},
+	{types.ErrInvitationNotFound, cerror.InvitationNotFound},
+	{types.ErrInvitationInvalid, cerror.InvitationInvalid},
+	{types.ErrInvitationConflict, cerror.InvitationConflict},
+	{types.ErrInvitationEmail, cerror.InvitationEmailMismatch},
+}
+
+// Returns the error code for an AppOrganization error, or fallback if it is not a known one.
+func errorCode(err error, fallback string) string {
+	for _, e := range appOrganizationErrorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,18 @@
+package organizations
+
+import (
+	"conformitea/server/config"
+	"conformitea/server/types"
+)
+
+type OrganizationsHandlers struct {
+	appOrganization types.AppOrganization
+	config          config.Config
+}
+
+func Initialize(appOrganization types.AppOrganization, cfg config.Config) *OrganizationsHandlers {
+	return &OrganizationsHandlers{
+		appOrganization: appOrganization,
+		config:          cfg,
+	}
+}
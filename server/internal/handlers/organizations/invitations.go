@@ -0,0 +1,220 @@
+package organizations
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateInvitationRequest is the body of the invitation endpoint.
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required"`
+	// Defaults to member
+	Role string `json:"role"`
+	// Let the invitee accept with an account whose email differs
+	AllowEmailMismatch bool `json:"allow_email_mismatch"`
+}
+
+// AcceptInvitationRequest is the body of the invitation accept endpoint.
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// InvitationResponse represents an invitation to join an organization.
+type InvitationResponse struct {
+	ID                 string     `json:"id"`
+	OrganizationID     string     `json:"organization_id"`
+	Email              string     `json:"email"`
+	Role               string     `json:"role"`
+	AllowEmailMismatch bool       `json:"allow_email_mismatch"`
+	InvitedBy          string     `json:"invited_by"`
+	Status             string     `json:"status"`
+	ExpiresAt          time.Time  `json:"expires_at"`
+	SentAt             time.Time  `json:"sent_at"`
+	AcceptedAt         *time.Time `json:"accepted_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// InvitationPreviewResponse describes an invitation to the invitee following its
+// link.
+type InvitationPreviewResponse struct {
+	OrganizationID     string    `json:"organization_id"`
+	OrganizationName   string    `json:"organization_name"`
+	Email              string    `json:"email"`
+	Role               string    `json:"role"`
+	AllowEmailMismatch bool      `json:"allow_email_mismatch"`
+	InviterName        string    `json:"inviter_name"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+// ListInvitations returns the invitations of an organization.
+func (o *OrganizationsHandlers) ListInvitations(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := o.appOrganization.ListInvitations(c.Request.Context(), actor, c.Param("id"))
+	if err != nil {
+		respondInvitationError(c, err, "failed to list invitations")
+		return
+	}
+
+	response := make([]InvitationResponse, 0, len(invitations))
+	for _, i := range invitations {
+		response = append(response, toInvitationResponse(i))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateInvitation emails an invitation to join an organization.
+func (o *OrganizationsHandlers) CreateInvitation(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	var body CreateInvitationRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	invitation, err := o.appOrganization.CreateInvitation(c.Request.Context(), types.CreateInvitationRequest{
+		Actor:              actor,
+		OrganizationID:     c.Param("id"),
+		Email:              body.Email,
+		Role:               body.Role,
+		AllowEmailMismatch: body.AllowEmailMismatch,
+	})
+	if err != nil {
+		respondInvitationError(c, err, "failed to create invitation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toInvitationResponse(invitation))
+}
+
+// ResendInvitation emails an invitation again with a new link.
+func (o *OrganizationsHandlers) ResendInvitation(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := o.appOrganization.ResendInvitation(c.Request.Context(), invitationRequest(c, actor))
+	if err != nil {
+		respondInvitationError(c, err, "failed to resend invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, toInvitationResponse(invitation))
+}
+
+// RevokeInvitation revokes an invitation so its link stops working.
+func (o *OrganizationsHandlers) RevokeInvitation(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	if err := o.appOrganization.RevokeInvitation(c.Request.Context(), invitationRequest(c, actor)); err != nil {
+		respondInvitationError(c, err, "failed to revoke invitation")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PreviewInvitation describes the invitation of a link to its invitee, who may
+// not be signed in yet.
+func (o *OrganizationsHandlers) PreviewInvitation(c *gin.Context) {
+	preview, err := o.appOrganization.PreviewInvitation(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		respondInvitationError(c, err, "failed to preview invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, toInvitationPreviewResponse(preview))
+}
+
+// AcceptInvitation adds the signed in user to the organization of an invitation
+// link.
+func (o *OrganizationsHandlers) AcceptInvitation(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	var body AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	preview, err := o.appOrganization.AcceptInvitation(c.Request.Context(), actor, body.Token)
+	if err != nil {
+		respondInvitationError(c, err, "failed to accept invitation")
+		return
+	}
+
+	c.MustGet("logger").(*zap.Logger).Info("invitation accepted",
+		zap.String("organization_id", preview.OrganizationID),
+		zap.String("user_id", actor.ID))
+
+	c.JSON(http.StatusOK, toInvitationPreviewResponse(preview))
+}
+
+func invitationRequest(c *gin.Context, actor types.Actor) types.InvitationRequest {
+	return types.InvitationRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		InvitationID:   c.Param("invitation_id"),
+	}
+}
+
+func respondInvitationError(c *gin.Context, err error, message string) {
+	orgErr := cerror.NewOrganizationError(errorCode(err, cerror.InvitationRequestFailed), err.Error(), nil)
+
+	c.MustGet("logger").(*zap.Logger).Warn(message,
+		zap.Error(err),
+		zap.String("error_code", orgErr.Code))
+
+	c.JSON(orgErr.HTTPStatusCode(), orgErr)
+}
+
+func toInvitationResponse(i types.Invitation) InvitationResponse {
+	return InvitationResponse{
+		ID:                 i.ID,
+		OrganizationID:     i.OrganizationID,
+		Email:              i.Email,
+		Role:               i.Role,
+		AllowEmailMismatch: i.AllowEmailMismatch,
+		InvitedBy:          i.InvitedBy,
+		Status:             i.Status,
+		ExpiresAt:          i.ExpiresAt,
+		SentAt:             i.SentAt,
+		AcceptedAt:         i.AcceptedAt,
+		CreatedAt:          i.CreatedAt,
+	}
+}
+
+func toInvitationPreviewResponse(p types.InvitationPreview) InvitationPreviewResponse {
+	return InvitationPreviewResponse{
+		OrganizationID:     p.OrganizationID,
+		OrganizationName:   p.OrganizationName,
+		Email:              p.Email,
+		Role:               p.Role,
+		AllowEmailMismatch: p.AllowEmailMismatch,
+		InviterName:        p.InviterName,
+		ExpiresAt:          p.ExpiresAt,
+	}
+}
@@ -0,0 +1,52 @@
+package organizations
+
+import (
+	"net/http"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SignOutMember forces a member of an organization to sign in to the
+// organization's clients again: the consents they granted them and the tokens
+// issued to them are revoked.
+func (o *OrganizationsHandlers) SignOutMember(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	actor, ok := middlewares.CurrentActor(c)
+	if !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	userID := c.Param("user_id")
+
+	if err := o.appOrganization.SignOutMember(c.Request.Context(), types.MemberRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		UserID:         userID,
+	}); err != nil {
+		orgErr := cerror.NewOrganizationError(errorCode(err, cerror.MemberRequestFailed), err.Error(), map[string]any{
+			"organization_id": c.Param("id"),
+			"user_id":         userID,
+		})
+
+		logger.Warn("failed to sign out member",
+			zap.Error(err),
+			zap.String("error_code", orgErr.Code))
+
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	logger.Info("member signed out",
+		zap.String("organization_id", c.Param("id")),
+		zap.String("user_id", userID))
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,221 @@
+package organizations
+
+import (
+	"net/http"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateRoleRequest is the body of the role endpoint.
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// UpdateRoleRequest is the body of the role update endpoint. Omitted fields are
+// left unchanged.
+type UpdateRoleRequest struct {
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateMemberRequest is the body of the member update endpoint.
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// PermissionResponse represents an entry of the permission catalog.
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// RoleResponse represents a role members and service accounts of an organization
+// can hold.
+type RoleResponse struct {
+	// Omitted for built-in roles
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	BuiltIn     bool     `json:"built_in"`
+}
+
+// ListPermissions returns the permission catalog roles are composed of.
+func (o *OrganizationsHandlers) ListPermissions(c *gin.Context) {
+	permissions := o.appOrganization.ListPermissions(c.Request.Context())
+
+	response := make([]PermissionResponse, 0, len(permissions))
+	for _, p := range permissions {
+		response = append(response, PermissionResponse{
+			Name:        p.Name,
+			Description: p.Description,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListRoles returns the built-in and custom roles of an organization.
+func (o *OrganizationsHandlers) ListRoles(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	roles, err := o.appOrganization.ListRoles(c.Request.Context(), actor, c.Param("id"))
+	if err != nil {
+		respondRoleError(c, err, "failed to list roles")
+		return
+	}
+
+	response := make([]RoleResponse, 0, len(roles))
+	for _, r := range roles {
+		response = append(response, toRoleResponse(r))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateRole creates a custom role in an organization.
+func (o *OrganizationsHandlers) CreateRole(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	var body CreateRoleRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	role, err := o.appOrganization.CreateRole(c.Request.Context(), types.CreateRoleRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		Name:           body.Name,
+		Description:    body.Description,
+		Permissions:    body.Permissions,
+	})
+	if err != nil {
+		respondRoleError(c, err, "failed to create role")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toRoleResponse(role))
+}
+
+// UpdateRole changes the description or permissions of a custom role.
+func (o *OrganizationsHandlers) UpdateRole(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	var body UpdateRoleRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	role, err := o.appOrganization.UpdateRole(c.Request.Context(), types.UpdateRoleRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		RoleID:         c.Param("role_id"),
+		Description:    body.Description,
+		Permissions:    body.Permissions,
+	})
+	if err != nil {
+		respondRoleError(c, err, "failed to update role")
+		return
+	}
+
+	c.JSON(http.StatusOK, toRoleResponse(role))
+}
+
+// DeleteRole deletes a custom role nobody holds.
+func (o *OrganizationsHandlers) DeleteRole(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	if err := o.appOrganization.DeleteRole(c.Request.Context(), types.RoleRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		RoleID:         c.Param("role_id"),
+	}); err != nil {
+		respondRoleError(c, err, "failed to delete role")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateMember gives a member of an organization another role.
+func (o *OrganizationsHandlers) UpdateMember(c *gin.Context) {
+	actor, ok := middlewares.CurrentActor(c)
+	if !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	var body UpdateMemberRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	if err := o.appOrganization.UpdateMemberRole(c.Request.Context(), types.UpdateMemberRoleRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		UserID:         c.Param("user_id"),
+		Role:           body.Role,
+	}); err != nil {
+		orgErr := cerror.NewOrganizationError(errorCode(err, cerror.MemberRequestFailed), err.Error(), map[string]any{
+			"organization_id": c.Param("id"),
+			"user_id":         c.Param("user_id"),
+		})
+
+		c.MustGet("logger").(*zap.Logger).Warn("failed to update member role",
+			zap.Error(err),
+			zap.String("error_code", orgErr.Code))
+
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondRoleError(c *gin.Context, err error, message string) {
+	orgErr := cerror.NewOrganizationError(errorCode(err, cerror.RoleRequestFailed), err.Error(), map[string]any{
+		"organization_id": c.Param("id"),
+	})
+
+	c.MustGet("logger").(*zap.Logger).Warn(message,
+		zap.Error(err),
+		zap.String("error_code", orgErr.Code))
+
+	c.JSON(orgErr.HTTPStatusCode(), orgErr)
+}
+
+func toRoleResponse(r types.OrganizationRole) RoleResponse {
+	return RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: r.Permissions,
+		BuiltIn:     r.BuiltIn,
+	}
+}
@@ -0,0 +1,137 @@
+package organizations
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ConfigureSAMLRequest is the body of the SAML connection endpoint. Exactly one
+// of metadata_xml and metadata_url must be given.
+type ConfigureSAMLRequest struct {
+	MetadataXML string `json:"metadata_xml"`
+	MetadataURL string `json:"metadata_url"`
+	// Assertion attributes mapped to user fields, read from commonly used
+	// attributes when empty
+	EmailAttribute     string `json:"email_attribute"`
+	FirstNameAttribute string `json:"first_name_attribute"`
+	LastNameAttribute  string `json:"last_name_attribute"`
+}
+
+// SAMLConnectionResponse represents the SAML identity provider of an organization
+// and the service provider to register at it.
+type SAMLConnectionResponse struct {
+	OrganizationID     string    `json:"organization_id"`
+	IdPEntityID        string    `json:"idp_entity_id"`
+	MetadataURL        string    `json:"metadata_url"`
+	EmailAttribute     string    `json:"email_attribute"`
+	FirstNameAttribute string    `json:"first_name_attribute"`
+	LastNameAttribute  string    `json:"last_name_attribute"`
+	SPEntityID         string    `json:"sp_entity_id"`
+	SPMetadataURL      string    `json:"sp_metadata_url"`
+	ACSURL             string    `json:"acs_url"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ConfigureSAML sets up or replaces the SAML identity provider of an organization.
+func (o *OrganizationsHandlers) ConfigureSAML(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	var body ConfigureSAMLRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	if (body.MetadataXML == "") == (body.MetadataURL == "") {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, "exactly one of metadata_xml and metadata_url is required", nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	connection, err := o.appOrganization.ConfigureSAML(c.Request.Context(), types.ConfigureSAMLRequest{
+		Actor:              actor,
+		OrganizationID:     c.Param("id"),
+		MetadataXML:        body.MetadataXML,
+		MetadataURL:        body.MetadataURL,
+		EmailAttribute:     body.EmailAttribute,
+		FirstNameAttribute: body.FirstNameAttribute,
+		LastNameAttribute:  body.LastNameAttribute,
+	})
+	if err != nil {
+		respondSAMLError(c, err, "failed to configure saml connection")
+		return
+	}
+
+	c.JSON(http.StatusOK, toSAMLConnectionResponse(connection))
+}
+
+// GetSAMLConnection returns the SAML identity provider of an organization.
+func (o *OrganizationsHandlers) GetSAMLConnection(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	connection, err := o.appOrganization.GetSAMLConnection(c.Request.Context(), actor, c.Param("id"))
+	if err != nil {
+		respondSAMLError(c, err, "failed to get saml connection")
+		return
+	}
+
+	c.JSON(http.StatusOK, toSAMLConnectionResponse(connection))
+}
+
+// DeleteSAMLConnection removes the SAML identity provider of an organization.
+// Members keep their accounts.
+func (o *OrganizationsHandlers) DeleteSAMLConnection(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	if err := o.appOrganization.DeleteSAMLConnection(c.Request.Context(), actor, c.Param("id")); err != nil {
+		respondSAMLError(c, err, "failed to delete saml connection")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondSAMLError(c *gin.Context, err error, message string) {
+	orgErr := cerror.NewOrganizationError(errorCode(err, cerror.SAMLConnectionRequestFailed), err.Error(), map[string]any{
+		"organization_id": c.Param("id"),
+	})
+
+	c.MustGet("logger").(*zap.Logger).Warn(message,
+		zap.Error(err),
+		zap.String("error_code", orgErr.Code))
+
+	c.JSON(orgErr.HTTPStatusCode(), orgErr)
+}
+
+func toSAMLConnectionResponse(s types.SAMLConnection) SAMLConnectionResponse {
+	return SAMLConnectionResponse{
+		OrganizationID:     s.OrganizationID,
+		IdPEntityID:        s.IdPEntityID,
+		MetadataURL:        s.MetadataURL,
+		EmailAttribute:     s.EmailAttribute,
+		FirstNameAttribute: s.FirstNameAttribute,
+		LastNameAttribute:  s.LastNameAttribute,
+		SPEntityID:         s.SPEntityID,
+		SPMetadataURL:      s.SPEntityID,
+		ACSURL:             s.ACSURL,
+		CreatedAt:          s.CreatedAt,
+		UpdatedAt:          s.UpdatedAt,
+	}
+}
@@ -0,0 +1,243 @@
+package organizations
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateServiceAccountRequest is the body of the service account endpoint.
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	// admin or member, defaults to member
+	Role string `json:"role"`
+}
+
+// ServiceAccountResponse represents a service account of an organization.
+type ServiceAccountResponse struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CreateTokenRequest is the body of the service account token endpoint.
+type CreateTokenRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// Defaults to 90 days
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// TokenResponse represents a service account token. The secret is only returned
+// when the token is created.
+type TokenResponse struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	DisplayPrefix string     `json:"display_prefix"`
+	Scopes        []string   `json:"scopes"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Secret        string     `json:"secret,omitempty"`
+}
+
+// ListServiceAccounts returns the service accounts of an organization.
+func (o *OrganizationsHandlers) ListServiceAccounts(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := o.appOrganization.ListServiceAccounts(c.Request.Context(), actor, c.Param("id"))
+	if err != nil {
+		respondOrganizationError(c, err, "failed to list service accounts")
+		return
+	}
+
+	response := make([]ServiceAccountResponse, 0, len(accounts))
+	for _, a := range accounts {
+		response = append(response, toServiceAccountResponse(a))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateServiceAccount creates a service account in an organization.
+func (o *OrganizationsHandlers) CreateServiceAccount(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	var body CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	account, err := o.appOrganization.CreateServiceAccount(c.Request.Context(), types.CreateServiceAccountRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		Name:           body.Name,
+		Description:    body.Description,
+		Role:           body.Role,
+	})
+	if err != nil {
+		respondOrganizationError(c, err, "failed to create service account")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toServiceAccountResponse(account))
+}
+
+// DeleteServiceAccount deletes a service account of an organization and its tokens.
+func (o *OrganizationsHandlers) DeleteServiceAccount(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	if err := o.appOrganization.DeleteServiceAccount(c.Request.Context(), serviceAccountRequest(c, actor)); err != nil {
+		respondOrganizationError(c, err, "failed to delete service account")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListServiceAccountTokens returns the tokens of a service account.
+func (o *OrganizationsHandlers) ListServiceAccountTokens(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := o.appOrganization.ListServiceAccountTokens(c.Request.Context(), serviceAccountRequest(c, actor))
+	if err != nil {
+		respondOrganizationError(c, err, "failed to list service account tokens")
+		return
+	}
+
+	response := make([]TokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		response = append(response, toTokenResponse(t))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateServiceAccountToken issues a token to a service account.
+func (o *OrganizationsHandlers) CreateServiceAccountToken(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	var body CreateTokenRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	issued, err := o.appOrganization.CreateServiceAccountToken(c.Request.Context(), types.CreateAPITokenRequest{
+		Actor:            actor,
+		OrganizationID:   c.Param("id"),
+		ServiceAccountID: c.Param("service_account_id"),
+		Name:             body.Name,
+		Scopes:           body.Scopes,
+		ExpiresInDays:    body.ExpiresInDays,
+	})
+	if err != nil {
+		respondOrganizationError(c, err, "failed to create service account token")
+		return
+	}
+
+	response := toTokenResponse(issued.Token)
+	response.Secret = issued.Secret
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// RevokeServiceAccountToken revokes a token of a service account.
+func (o *OrganizationsHandlers) RevokeServiceAccountToken(c *gin.Context) {
+	actor, ok := requireSessionActor(c)
+	if !ok {
+		return
+	}
+
+	if err := o.appOrganization.RevokeServiceAccountToken(c.Request.Context(), serviceAccountRequest(c, actor), c.Param("token_id")); err != nil {
+		respondOrganizationError(c, err, "failed to revoke service account token")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Returns the user signed in with the session cookie. Service accounts and their
+// tokens cannot be managed with bearer tokens.
+func requireSessionActor(c *gin.Context) (types.Actor, bool) {
+	userID, ok := middlewares.SessionUserID(c)
+	if !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+	}
+
+	return types.Actor{ID: userID, Kind: types.PrincipalUser}, ok
+}
+
+func serviceAccountRequest(c *gin.Context, actor types.Actor) types.ServiceAccountRequest {
+	return types.ServiceAccountRequest{
+		Actor:            actor,
+		OrganizationID:   c.Param("id"),
+		ServiceAccountID: c.Param("service_account_id"),
+	}
+}
+
+func respondOrganizationError(c *gin.Context, err error, message string) {
+	orgErr := cerror.NewOrganizationError(errorCode(err, cerror.ServiceAccountRequestFailed), err.Error(), map[string]any{
+		"organization_id": c.Param("id"),
+	})
+
+	c.MustGet("logger").(*zap.Logger).Warn(message,
+		zap.Error(err),
+		zap.String("error_code", orgErr.Code))
+
+	c.JSON(orgErr.HTTPStatusCode(), orgErr)
+}
+
+func toServiceAccountResponse(a types.ServiceAccount) ServiceAccountResponse {
+	return ServiceAccountResponse{
+		ID:             a.ID,
+		OrganizationID: a.OrganizationID,
+		Name:           a.Name,
+		Description:    a.Description,
+		Role:           a.Role,
+		CreatedAt:      a.CreatedAt,
+	}
+}
+
+func toTokenResponse(t types.APIToken) TokenResponse {
+	return TokenResponse{
+		ID:            t.ID,
+		Name:          t.Name,
+		DisplayPrefix: t.DisplayPrefix,
+		Scopes:        t.Scopes,
+		ExpiresAt:     t.ExpiresAt,
+		LastUsedAt:    t.LastUsedAt,
+		RevokedAt:     t.RevokedAt,
+		CreatedAt:     t.CreatedAt,
+	}
+}
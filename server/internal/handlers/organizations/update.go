@@ -0,0 +1,141 @@
+package organizations
+
+import (
+	"net/http"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UpdateOrganizationRequest is the body of the update endpoint. Omitted fields are
+// left unchanged.
+type UpdateOrganizationRequest struct {
+	Name       *string `json:"name"`
+	RequireMFA *bool   `json:"require_mfa"`
+	RequireSSO *bool   `json:"require_sso"`
+	// Only empty, to unlink the tenant. Staff link tenants with the admin API.
+	EntraTenantID *string `json:"entra_tenant_id"`
+}
+
+// OrganizationResponse represents an organization returned by the organization endpoints.
+type OrganizationResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	RequireMFA bool   `json:"require_mfa"`
+	RequireSSO bool   `json:"require_sso"`
+	// Entra tenant whose users are members of the organization
+	EntraTenantID string `json:"entra_tenant_id"`
+}
+
+// Update changes the settings of an organization.
+func (o *OrganizationsHandlers) Update(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	actor, ok := middlewares.CurrentActor(c)
+	if !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	var body UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	if body.Name != nil && *body.Name == "" {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, "name must not be empty", nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	result, err := o.appOrganization.UpdateOrganization(c.Request.Context(), types.UpdateOrganizationRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		Name:           body.Name,
+		RequireMFA:     body.RequireMFA,
+		RequireSSO:     body.RequireSSO,
+		EntraTenantID:  body.EntraTenantID,
+	})
+	if err != nil {
+		orgErr := cerror.NewOrganizationError(errorCode(err, cerror.OrganizationUpdateFailed), err.Error(), map[string]any{
+			"organization_id": c.Param("id"),
+		})
+
+		logger.Warn("failed to update organization",
+			zap.Error(err),
+			zap.String("error_code", orgErr.Code))
+
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, OrganizationResponse{
+		ID:            result.ID,
+		Name:          result.Name,
+		RequireMFA:    result.RequireMFA,
+		RequireSSO:    result.RequireSSO,
+		EntraTenantID: result.EntraTenantID,
+	})
+}
+
+// LinkTenantRequest is the body of the tenant endpoint.
+type LinkTenantRequest struct {
+	TenantID string `json:"tenant_id" binding:"required"`
+}
+
+// LinkTenant links an Entra tenant to an organization. Staff only use it once an
+// administrator of the tenant confirmed that the organization uses the tenant.
+func (o *OrganizationsHandlers) LinkTenant(c *gin.Context) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	actor, ok := middlewares.CurrentActor(c)
+	if !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	var body LinkTenantRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		orgErr := cerror.NewOrganizationError(cerror.OrganizationInvalidRequest, err.Error(), nil)
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	result, err := o.appOrganization.LinkTenant(c.Request.Context(), types.LinkTenantRequest{
+		Actor:          actor,
+		OrganizationID: c.Param("id"),
+		TenantID:       body.TenantID,
+	})
+	if err != nil {
+		orgErr := cerror.NewOrganizationError(errorCode(err, cerror.OrganizationUpdateFailed), err.Error(), map[string]any{
+			"organization_id": c.Param("id"),
+		})
+
+		logger.Warn("failed to link tenant",
+			zap.Error(err),
+			zap.String("error_code", orgErr.Code))
+
+		c.JSON(orgErr.HTTPStatusCode(), orgErr)
+		return
+	}
+
+	logger.Info("tenant linked",
+		zap.String("organization_id", result.ID),
+		zap.String("tenant_id", result.EntraTenantID))
+
+	c.JSON(http.StatusOK, OrganizationResponse{
+		ID:            result.ID,
+		Name:          result.Name,
+		RequireMFA:    result.RequireMFA,
+		RequireSSO:    result.RequireSSO,
+		EntraTenantID: result.EntraTenantID,
+	})
+}
@@ -0,0 +1,121 @@
+package scim
+
+import (
+	"net/http"
+
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceProviderConfig describes the SCIM features the server supports.
+func (s *SCIMHandlers) ServiceProviderConfig(c *gin.Context) {
+	respond(c, http.StatusOK, gin.H{
+		"schemas":        []string{serviceProviderConfigSchema},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": types.SCIMMaxResults},
+		"changePassword": gin.H{"supported": false},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "Service account token",
+			"description": "Token of a service account of the organization, granted the " + types.SCIMScope + " scope",
+			"primary":     true,
+		}},
+		"meta": gin.H{"resourceType": "ServiceProviderConfig"},
+	})
+}
+
+// ResourceTypes lists the resources the server provisions.
+func (s *SCIMHandlers) ResourceTypes(c *gin.Context) {
+	resourceTypes := []gin.H{
+		{
+			"schemas":     []string{resourceTypeSchema},
+			"id":          "User",
+			"name":        "User",
+			"endpoint":    "/Users",
+			"description": "Members of the organization",
+			"schema":      userSchema,
+			"meta":        gin.H{"resourceType": "ResourceType"},
+		},
+		{
+			"schemas":     []string{resourceTypeSchema},
+			"id":          "Group",
+			"name":        "Group",
+			"endpoint":    "/Groups",
+			"description": "Teams of the organization",
+			"schema":      groupSchema,
+			"meta":        gin.H{"resourceType": "ResourceType"},
+		},
+	}
+
+	respond(c, http.StatusOK, listResponse(len(resourceTypes), 1, len(resourceTypes), resourceTypes))
+}
+
+// Schemas describes the attributes of the resources the server provisions.
+func (s *SCIMHandlers) Schemas(c *gin.Context) {
+	schemas := []gin.H{
+		{
+			"schemas":     []string{schemaSchema},
+			"id":          userSchema,
+			"name":        "User",
+			"description": "Member of the organization",
+			"attributes": []gin.H{
+				attribute("userName", "string", true, "server"),
+				{
+					"name":        "name",
+					"type":        "complex",
+					"multiValued": false,
+					"required":    false,
+					"mutability":  "readWrite",
+					"returned":    "default",
+					"subAttributes": []gin.H{
+						attribute("givenName", "string", false, "none"),
+						attribute("familyName", "string", false, "none"),
+					},
+				},
+				attribute("active", "boolean", false, "none"),
+			},
+			"meta": gin.H{"resourceType": "Schema"},
+		},
+		{
+			"schemas":     []string{schemaSchema},
+			"id":          groupSchema,
+			"name":        "Group",
+			"description": "Team of the organization",
+			"attributes": []gin.H{
+				attribute("displayName", "string", true, "none"),
+				{
+					"name":        "members",
+					"type":        "complex",
+					"multiValued": true,
+					"required":    false,
+					"mutability":  "readWrite",
+					"returned":    "default",
+					"subAttributes": []gin.H{
+						attribute("value", "string", true, "none"),
+					},
+				},
+			},
+			"meta": gin.H{"resourceType": "Schema"},
+		},
+	}
+
+	respond(c, http.StatusOK, listResponse(len(schemas), 1, len(schemas), schemas))
+}
+
+// Describes a single-valued, writable attribute of a schema.
+func attribute(name, attributeType string, required bool, uniqueness string) gin.H {
+	return gin.H{
+		"name":        name,
+		"type":        attributeType,
+		"multiValued": false,
+		"required":    required,
+		"caseExact":   false,
+		"mutability":  "readWrite",
+		"returned":    "default",
+		"uniqueness":  uniqueness,
+	}
+}
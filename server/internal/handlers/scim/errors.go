@@ -0,0 +1,78 @@
+package scim
+
+import (
+	"errors"
+	"net/http"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Content type of SCIM requests and responses.
+const contentType = "application/scim+json"
+
+// HTTP statuses and SCIM error types of the errors returned by AppSCIM.
+var appSCIMErrors = []struct {
+	err      error
+	status   int
+	scimType string
+}{
+	{types.ErrPermissionDenied, http.StatusForbidden, ""},
+	{types.ErrSCIMNotFound, http.StatusNotFound, ""},
+	{types.ErrSCIMInvalidFilter, http.StatusBadRequest, cerror.SCIMInvalidFilter},
+	{types.ErrSCIMInvalidSyntax, http.StatusBadRequest, cerror.SCIMInvalidSyntax},
+	{types.ErrSCIMInvalidPath, http.StatusBadRequest, cerror.SCIMInvalidPath},
+	{types.ErrSCIMInvalidValue, http.StatusBadRequest, cerror.SCIMInvalidValue},
+	{types.ErrSCIMUniqueness, http.StatusConflict, cerror.SCIMUniqueness},
+	{types.ErrSCIMUserInvited, http.StatusConflict, ""},
+}
+
+// Returns the organization the request provisions. SCIM requests must be made
+// with a token of a service account of that organization.
+func tenant(c *gin.Context) (types.SCIMTenant, bool) {
+	principal, ok := middlewares.GetPrincipal(c)
+	if !ok || !principal.IsServiceAccount() {
+		respondError(c, cerror.NewSCIMError(http.StatusForbidden, "", "SCIM requests must be made with a service account token"))
+		return types.SCIMTenant{}, false
+	}
+
+	return types.SCIMTenant{
+		Actor: types.Actor{
+			ID:   principal.Subject,
+			Kind: principal.Kind,
+		},
+		OrganizationID: principal.OrganizationID,
+	}, true
+}
+
+func respond(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", contentType)
+	c.JSON(status, body)
+}
+
+func respondError(c *gin.Context, scimErr *cerror.SCIMError) {
+	respond(c, scimErr.HTTPStatusCode(), scimErr)
+}
+
+// Responds with the SCIM error of an AppSCIM error. Unknown errors are logged and
+// reported as internal errors.
+func respondAppError(c *gin.Context, err error, message string) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	for _, e := range appSCIMErrors {
+		if errors.Is(err, e.err) {
+			logger.Info(message, zap.Error(err))
+
+			respondError(c, cerror.NewSCIMError(e.status, e.scimType, err.Error()))
+			return
+		}
+	}
+
+	logger.Error(message, zap.Error(err))
+
+	respondError(c, cerror.NewSCIMError(http.StatusInternalServerError, "", message))
+}
@@ -0,0 +1,144 @@
+package scim
+
+import (
+	"net/http"
+
+	"conformitea/server/internal/cerror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListGroups returns the organization's teams matching the filter.
+func (s *SCIMHandlers) ListGroups(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	req, ok := listRequest(c, tenant)
+	if !ok {
+		return
+	}
+
+	result, err := s.appSCIM.ListGroups(c.Request.Context(), req)
+	if err != nil {
+		respondAppError(c, err, "failed to list scim groups")
+		return
+	}
+
+	resources := make([]GroupResource, 0, len(result.Groups))
+	for _, g := range result.Groups {
+		resource := toGroupResource(g)
+		if req.ExcludeMembers {
+			resource.Members = nil
+		}
+
+		resources = append(resources, resource)
+	}
+
+	respond(c, http.StatusOK, listResponse(result.TotalResults, result.StartIndex, len(resources), resources))
+}
+
+// GetGroup returns a team of the organization.
+func (s *SCIMHandlers) GetGroup(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	group, err := s.appSCIM.GetGroup(c.Request.Context(), tenant, c.Param("id"))
+	if err != nil {
+		respondAppError(c, err, "failed to get scim group")
+		return
+	}
+
+	resource := toGroupResource(group)
+	if excludesMembers(c) {
+		resource.Members = nil
+	}
+
+	respond(c, http.StatusOK, resource)
+}
+
+// CreateGroup creates a team in the organization.
+func (s *SCIMHandlers) CreateGroup(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	var body GroupResource
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, cerror.NewSCIMError(http.StatusBadRequest, cerror.SCIMInvalidSyntax, err.Error()))
+		return
+	}
+
+	group, err := s.appSCIM.CreateGroup(c.Request.Context(), tenant, fromGroupResource(body))
+	if err != nil {
+		respondAppError(c, err, "failed to create scim group")
+		return
+	}
+
+	respond(c, http.StatusCreated, toGroupResource(group))
+}
+
+// ReplaceGroup replaces the name and members of a team of the organization.
+func (s *SCIMHandlers) ReplaceGroup(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	var body GroupResource
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, cerror.NewSCIMError(http.StatusBadRequest, cerror.SCIMInvalidSyntax, err.Error()))
+		return
+	}
+
+	g := fromGroupResource(body)
+	g.ID = c.Param("id")
+
+	group, err := s.appSCIM.ReplaceGroup(c.Request.Context(), tenant, g)
+	if err != nil {
+		respondAppError(c, err, "failed to replace scim group")
+		return
+	}
+
+	respond(c, http.StatusOK, toGroupResource(group))
+}
+
+// PatchGroup applies PATCH operations to a team of the organization. The team is
+// not returned, since its member list may be large.
+func (s *SCIMHandlers) PatchGroup(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	ops, ok := patchOperations(c)
+	if !ok {
+		return
+	}
+
+	if err := s.appSCIM.PatchGroup(c.Request.Context(), tenant, c.Param("id"), ops); err != nil {
+		respondAppError(c, err, "failed to patch scim group")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteGroup deletes a team of the organization. Its members are kept.
+func (s *SCIMHandlers) DeleteGroup(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	if err := s.appSCIM.DeleteGroup(c.Request.Context(), tenant, c.Param("id")); err != nil {
+		respondAppError(c, err, "failed to delete scim group")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,18 @@
+package scim
+
+import (
+	"conformitea/server/config"
+	"conformitea/server/types"
+)
+
+type SCIMHandlers struct {
+	appSCIM types.AppSCIM
+	config  config.Config
+}
+
+func Initialize(appSCIM types.AppSCIM, cfg config.Config) *SCIMHandlers {
+	return &SCIMHandlers{
+		appSCIM: appSCIM,
+		config:  cfg,
+	}
+}
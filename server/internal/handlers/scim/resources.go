@@ -0,0 +1,241 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SCIM schemas of the resources and messages exchanged.
+const (
+	userSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	groupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	listResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	serviceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	resourceTypeSchema          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	schemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+)
+
+// UserResource represents a SCIM User. Attributes ConformiTea does not store are
+// ignored in requests.
+type UserResource struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *NameResource   `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Emails      []EmailResource `json:"emails,omitempty"`
+	// Defaults to true in requests
+	Active *bool         `json:"active,omitempty"`
+	Meta   *MetaResource `json:"meta,omitempty"`
+}
+
+type NameResource struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	Formatted  string `json:"formatted,omitempty"`
+}
+
+type EmailResource struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// GroupResource represents a SCIM Group, which is a team of the organization.
+type GroupResource struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []MemberResource `json:"members,omitempty"`
+	Meta        *MetaResource    `json:"meta,omitempty"`
+}
+
+// MemberResource references a user in a group's members.
+type MemberResource struct {
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+type MetaResource struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// ListResponse is a page of the resources matching a filter.
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+// PatchRequest is the body of a SCIM PATCH request.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Parses the filter and pagination parameters of a list request.
+func listRequest(c *gin.Context, tenant types.SCIMTenant) (types.SCIMListRequest, bool) {
+	req := types.SCIMListRequest{
+		Tenant:     tenant,
+		Filter:     c.Query("filter"),
+		StartIndex: 1,
+		Count:      types.SCIMMaxResults,
+	}
+
+	for name, target := range map[string]*int{"startIndex": &req.StartIndex, "count": &req.Count} {
+		value, ok := c.GetQuery(name)
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			respondError(c, cerror.NewSCIMError(http.StatusBadRequest, cerror.SCIMInvalidValue, name+" must be an integer"))
+			return types.SCIMListRequest{}, false
+		}
+
+		*target = n
+	}
+
+	req.ExcludeMembers = excludesMembers(c)
+
+	return req, true
+}
+
+// Reports whether the client asked to leave group members out of the response.
+func excludesMembers(c *gin.Context) bool {
+	for _, attribute := range strings.Split(c.Query("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attribute), "members") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Binds the body of a PATCH request.
+func patchOperations(c *gin.Context) ([]types.SCIMPatchOperation, bool) {
+	var body PatchRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, cerror.NewSCIMError(http.StatusBadRequest, cerror.SCIMInvalidSyntax, err.Error()))
+		return nil, false
+	}
+
+	ops := make([]types.SCIMPatchOperation, 0, len(body.Operations))
+	for _, op := range body.Operations {
+		ops = append(ops, types.SCIMPatchOperation{
+			Op:    op.Op,
+			Path:  op.Path,
+			Value: op.Value,
+		})
+	}
+
+	return ops, true
+}
+
+func listResponse(total, startIndex, count int, resources any) ListResponse {
+	return ListResponse{
+		Schemas:      []string{listResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: count,
+		Resources:    resources,
+	}
+}
+
+func fromUserResource(r UserResource) types.SCIMUser {
+	u := types.SCIMUser{
+		ExternalID: r.ExternalID,
+		UserName:   r.UserName,
+		Active:     r.Active == nil || *r.Active,
+	}
+
+	if r.Name != nil {
+		u.GivenName = r.Name.GivenName
+		u.FamilyName = r.Name.FamilyName
+	}
+
+	return u
+}
+
+func toUserResource(u types.SCIMUser) UserResource {
+	active := u.Active
+	name := strings.TrimSpace(u.GivenName + " " + u.FamilyName)
+
+	return UserResource{
+		Schemas:    []string{userSchema},
+		ID:         u.ID,
+		ExternalID: u.ExternalID,
+		UserName:   u.UserName,
+		Name: &NameResource{
+			GivenName:  u.GivenName,
+			FamilyName: u.FamilyName,
+			Formatted:  name,
+		},
+		DisplayName: name,
+		Emails: []EmailResource{
+			{Value: u.UserName, Type: "work", Primary: true},
+		},
+		Active: &active,
+		Meta: &MetaResource{
+			ResourceType: "User",
+			Created:      u.Created,
+			LastModified: u.LastModified,
+		},
+	}
+}
+
+func fromGroupResource(r GroupResource) types.SCIMGroup {
+	g := types.SCIMGroup{
+		ExternalID:  r.ExternalID,
+		DisplayName: r.DisplayName,
+		Members:     make([]string, 0, len(r.Members)),
+	}
+
+	for _, m := range r.Members {
+		g.Members = append(g.Members, m.Value)
+	}
+
+	return g
+}
+
+func toGroupResource(g types.SCIMGroup) GroupResource {
+	r := GroupResource{
+		Schemas:     []string{groupSchema},
+		ID:          g.ID,
+		ExternalID:  g.ExternalID,
+		DisplayName: g.DisplayName,
+		Members:     make([]MemberResource, 0, len(g.Members)),
+		Meta: &MetaResource{
+			ResourceType: "Group",
+			Created:      g.Created,
+			LastModified: g.LastModified,
+		},
+	}
+
+	for _, m := range g.Members {
+		r.Members = append(r.Members, MemberResource{Value: m, Type: "User"})
+	}
+
+	return r
+}
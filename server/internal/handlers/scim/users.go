@@ -0,0 +1,136 @@
+package scim
+
+import (
+	"net/http"
+
+	"conformitea/server/internal/cerror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListUsers returns the organization's members matching the filter.
+func (s *SCIMHandlers) ListUsers(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	req, ok := listRequest(c, tenant)
+	if !ok {
+		return
+	}
+
+	result, err := s.appSCIM.ListUsers(c.Request.Context(), req)
+	if err != nil {
+		respondAppError(c, err, "failed to list scim users")
+		return
+	}
+
+	resources := make([]UserResource, 0, len(result.Users))
+	for _, u := range result.Users {
+		resources = append(resources, toUserResource(u))
+	}
+
+	respond(c, http.StatusOK, listResponse(result.TotalResults, result.StartIndex, len(resources), resources))
+}
+
+// GetUser returns a member of the organization.
+func (s *SCIMHandlers) GetUser(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	user, err := s.appSCIM.GetUser(c.Request.Context(), tenant, c.Param("id"))
+	if err != nil {
+		respondAppError(c, err, "failed to get scim user")
+		return
+	}
+
+	respond(c, http.StatusOK, toUserResource(user))
+}
+
+// CreateUser provisions a user into the organization.
+func (s *SCIMHandlers) CreateUser(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	var body UserResource
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, cerror.NewSCIMError(http.StatusBadRequest, cerror.SCIMInvalidSyntax, err.Error()))
+		return
+	}
+
+	user, err := s.appSCIM.CreateUser(c.Request.Context(), tenant, fromUserResource(body))
+	if err != nil {
+		respondAppError(c, err, "failed to create scim user")
+		return
+	}
+
+	respond(c, http.StatusCreated, toUserResource(user))
+}
+
+// ReplaceUser replaces the profile of a member of the organization.
+func (s *SCIMHandlers) ReplaceUser(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	var body UserResource
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, cerror.NewSCIMError(http.StatusBadRequest, cerror.SCIMInvalidSyntax, err.Error()))
+		return
+	}
+
+	u := fromUserResource(body)
+	u.ID = c.Param("id")
+
+	user, err := s.appSCIM.ReplaceUser(c.Request.Context(), tenant, u)
+	if err != nil {
+		respondAppError(c, err, "failed to replace scim user")
+		return
+	}
+
+	respond(c, http.StatusOK, toUserResource(user))
+}
+
+// PatchUser applies PATCH operations to a member of the organization. Setting
+// active to false deprovisions the user.
+func (s *SCIMHandlers) PatchUser(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	ops, ok := patchOperations(c)
+	if !ok {
+		return
+	}
+
+	user, err := s.appSCIM.PatchUser(c.Request.Context(), tenant, c.Param("id"), ops)
+	if err != nil {
+		respondAppError(c, err, "failed to patch scim user")
+		return
+	}
+
+	respond(c, http.StatusOK, toUserResource(user))
+}
+
+// DeleteUser deprovisions a member of the organization. The user is deactivated
+// rather than deleted.
+func (s *SCIMHandlers) DeleteUser(c *gin.Context) {
+	tenant, ok := tenant(c)
+	if !ok {
+		return
+	}
+
+	if err := s.appSCIM.DeactivateUser(c.Request.Context(), tenant, c.Param("id")); err != nil {
+		respondAppError(c, err, "failed to deactivate scim user")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package users
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ConnectedAppResponse represents a third-party client the user granted access to.
+type ConnectedAppResponse struct {
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	ClientURI  string    `json:"client_uri,omitempty"`
+	LogoURI    string    `json:"logo_uri,omitempty"`
+	Scopes     []string  `json:"scopes"`
+	Audience   []string  `json:"audience"`
+	GrantedAt  time.Time `json:"granted_at"`
+}
+
+// ListConnectedApps returns the third-party clients the user granted access to.
+func (a *UsersHandlers) ListConnectedApps(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	apps, err := a.appAuth.ListConnectedApps(c.Request.Context(), userID)
+	if err != nil {
+		c.MustGet("logger").(*zap.Logger).Error("failed to list connected apps", zap.Error(err))
+
+		respondAuthError(c, err, cerror.AuthHydraAcceptFailed)
+		return
+	}
+
+	response := make([]ConnectedAppResponse, 0, len(apps))
+	for _, app := range apps {
+		response = append(response, ConnectedAppResponse{
+			ClientID:   app.ClientID,
+			ClientName: app.ClientName,
+			ClientURI:  app.ClientURI,
+			LogoURI:    app.LogoURI,
+			Scopes:     app.Scopes,
+			Audience:   app.Audience,
+			GrantedAt:  app.GrantedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeConnectedApp revokes the access the user granted to a third-party client,
+// including the tokens issued to it.
+func (a *UsersHandlers) RevokeConnectedApp(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	if err := a.appAuth.RevokeConnectedApp(c.Request.Context(), userID, c.Param("client_id")); err != nil {
+		c.MustGet("logger").(*zap.Logger).Warn("failed to revoke connected app", zap.Error(err))
+
+		respondAuthError(c, err, cerror.AuthHydraAcceptFailed)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
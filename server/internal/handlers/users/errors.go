@@ -0,0 +1,40 @@
+package users
+
+import (
+	"errors"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+)
+
+// ConformiTea error codes of the errors returned by AppAuth.
+var appAuthErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{types.ErrMFACodeInvalid, cerror.AuthMFACodeInvalid},
+	{types.ErrMFANotEnrolled, cerror.AuthMFANotEnrolled},
+	{types.ErrMFAAlreadyEnrolled, cerror.AuthMFAAlreadyEnrolled},
+	{types.ErrMFARequired, cerror.AuthMFARequired},
+	{types.ErrPasskeyInvalid, cerror.AuthPasskeyInvalid},
+	{types.ErrPasskeyNotFound, cerror.AuthPasskeyNotFound},
+	{types.ErrAPITokenNotFound, cerror.AuthAPITokenNotFound},
+	{types.ErrAPITokenInvalid, cerror.AuthAPITokenInvalid},
+	{types.ErrConnectedAppNotFound, cerror.AuthConnectedAppNotFound},
+	{types.ErrIdentityNotFound, cerror.AuthIdentityNotFound},
+	{types.ErrIdentityConflict, cerror.AuthIdentityConflict},
+	{types.ErrIdentityRequired, cerror.AuthIdentityRequired},
+	{types.ErrProviderNotSupported, cerror.AuthProviderNotSupported},
+	{types.ErrUserDeactivated, cerror.AuthUserDeactivated},
+}
+
+// Returns the error code for an AppAuth error, or fallback if it is not a known one.
+func errorCode(err error, fallback string) string {
+	for _, e := range appAuthErrorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,136 @@
+package users
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LinkIdentityRequest is the body of the identity link endpoint.
+type LinkIdentityRequest struct {
+	Provider string `json:"provider" binding:"required"`
+}
+
+// LinkIdentityResponse sends the user to the identity provider to sign in with
+// the account they link.
+type LinkIdentityResponse struct {
+	AuthURL string `json:"auth_url"`
+}
+
+// IdentityResponse represents an identity provider account the user can sign in
+// with.
+type IdentityResponse struct {
+	ID         string    `json:"id"`
+	Provider   string    `json:"provider"`
+	Email      string    `json:"email"`
+	TenantID   string    `json:"tenant_id,omitempty"`
+	LinkedAt   time.Time `json:"linked_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// ListIdentities returns the identity provider accounts linked to the user.
+func (a *UsersHandlers) ListIdentities(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	identities, err := a.appAuth.ListIdentities(c.Request.Context(), userID)
+	if err != nil {
+		c.MustGet("logger").(*zap.Logger).Error("failed to list identities", zap.Error(err))
+
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	response := make([]IdentityResponse, 0, len(identities))
+	for _, i := range identities {
+		response = append(response, toIdentityResponse(i))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// LinkIdentity starts linking an account at another identity provider to the
+// user. The provider calls back to the auth callback, which links the account
+// the user signed in with.
+func (a *UsersHandlers) LinkIdentity(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	session := sessions.Default(c)
+
+	// Staff impersonating a user cannot sign in as them elsewhere
+	if _, impersonating := gin_session.GetImpersonation(session); impersonating {
+		impErr := cerror.NewImpersonationError(cerror.ImpersonationBlocked, "accounts cannot be linked while impersonating", nil)
+		c.JSON(impErr.HTTPStatusCode(), impErr)
+		return
+	}
+
+	var body LinkIdentityRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthProviderNotSupported, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	link, err := a.appAuth.BeginIdentityLink(c.Request.Context(), userID, body.Provider)
+	if err != nil {
+		c.MustGet("logger").(*zap.Logger).Warn("failed to begin identity link", zap.Error(err))
+
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	gin_session.SetIdentityLink(session, userID, link)
+	if err := session.Save(); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthSessionCreateFailed, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, LinkIdentityResponse{AuthURL: link.AuthURL})
+}
+
+// UnlinkIdentity unlinks an identity provider account from the user, who can no
+// longer sign in with it.
+func (a *UsersHandlers) UnlinkIdentity(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	if err := a.appAuth.UnlinkIdentity(c.Request.Context(), userID, c.Param("id")); err != nil {
+		c.MustGet("logger").(*zap.Logger).Warn("failed to unlink identity", zap.Error(err))
+
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.MustGet("logger").(*zap.Logger).Info("identity unlinked",
+		zap.String("user_id", userID),
+		zap.String("identity_id", c.Param("id")))
+
+	c.Status(http.StatusNoContent)
+}
+
+// Converts a linked identity to its response.
+func toIdentityResponse(i types.LinkedIdentity) IdentityResponse {
+	return IdentityResponse{
+		ID:         i.ID,
+		Provider:   i.Provider,
+		Email:      i.Email,
+		TenantID:   i.TenantID,
+		LinkedAt:   i.LinkedAt,
+		LastUsedAt: i.LastUsedAt,
+	}
+}
@@ -2,14 +2,20 @@ package users
 
 import (
 	"conformitea/server/config"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/types"
 )
 
 type UsersHandlers struct {
-	config config.Config
+	appAuth      types.AppAuth
+	config       config.Config
+	sessionIndex *gin_session.Index
 }
 
-func Initialize(cfg config.Config) *UsersHandlers {
+func Initialize(appAuth types.AppAuth, cfg config.Config, si *gin_session.Index) *UsersHandlers {
 	return &UsersHandlers{
-		config: cfg,
+		appAuth:      appAuth,
+		config:       cfg,
+		sessionIndex: si,
 	}
 }
@@ -2,8 +2,11 @@ package users
 
 import (
 	"net/http"
+	"time"
 
 	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/internal/middlewares"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -16,10 +19,36 @@ type MeResponse struct {
 	Name          string `json:"name"`
 	Provider      string `json:"provider"`
 	Authenticated bool   `json:"authenticated"`
+	// Client and scopes of the access token the request was authenticated with
+	ClientID string   `json:"client_id,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	// A staff user is impersonating the user, the frontend shows a banner
+	Impersonated  bool             `json:"impersonated"`
+	Impersonation *MeImpersonation `json:"impersonation,omitempty"`
+}
+
+// MeImpersonation describes who impersonates the user, and why.
+type MeImpersonation struct {
+	ID                string    `json:"id"`
+	ImpersonatorID    string    `json:"impersonator_id"`
+	ImpersonatorEmail string    `json:"impersonator_email"`
+	Reason            string    `json:"reason"`
+	ExpiresAt         time.Time `json:"expires_at"`
 }
 
 // Me returns the current user's session information.
 func (a *UsersHandlers) Me(c *gin.Context) {
+	// Bearer tokens only identify the subject, the profile lives in the session
+	if principal, ok := middlewares.GetPrincipal(c); ok {
+		c.JSON(http.StatusOK, MeResponse{
+			UserID:        principal.Subject,
+			Authenticated: true,
+			ClientID:      principal.ClientID,
+			Scopes:        principal.Scopes,
+		})
+		return
+	}
+
 	session := sessions.Default(c)
 
 	// Extract user data from session
@@ -45,5 +74,23 @@ func (a *UsersHandlers) Me(c *gin.Context) {
 		Authenticated: true,
 	}
 
+	// Impersonating staff users see the subject's profile
+	if impersonation, ok := gin_session.GetImpersonation(session); ok {
+		user = MeResponse{
+			UserID:        impersonation.SubjectID,
+			Email:         impersonation.SubjectEmail,
+			Name:          impersonation.SubjectName,
+			Authenticated: true,
+			Impersonated:  true,
+			Impersonation: &MeImpersonation{
+				ID:                impersonation.ID,
+				ImpersonatorID:    impersonation.ImpersonatorID,
+				ImpersonatorEmail: impersonation.ImpersonatorEmail,
+				Reason:            impersonation.Reason,
+				ExpiresAt:         impersonation.ExpiresAt,
+			},
+		}
+	}
+
 	c.JSON(http.StatusOK, user)
 }
@@ -0,0 +1,173 @@
+package users
+
+import (
+	"net/http"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MFAStatusResponse represents the second factors of the signed in user.
+type MFAStatusResponse struct {
+	TOTPEnabled            bool `json:"totp_enabled"`
+	Passkeys               int  `json:"passkeys"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+	Required               bool `json:"required"`
+}
+
+// MFACodeRequest carries a current code of the user's authenticator.
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TOTPEnrollmentResponse is a new authenticator to add to an authenticator app.
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+	QRCode     string `json:"qr_code"`
+}
+
+// RecoveryCodesResponse carries recovery codes, shown to the user once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// GetMFA returns the second factors of the signed in user.
+func (a *UsersHandlers) GetMFA(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	status, err := a.appAuth.GetMFAStatus(c.Request.Context(), userID)
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, MFAStatusResponse{
+		TOTPEnabled:            status.TOTPEnabled,
+		Passkeys:               status.Passkeys,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+		Required:               status.Required,
+	})
+}
+
+// BeginTOTP generates a new authenticator for the signed in user.
+func (a *UsersHandlers) BeginTOTP(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := a.appAuth.BeginTOTPEnrollment(c.Request.Context(), userID)
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, TOTPEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURL: enrollment.URL,
+		QRCode:     enrollment.QRCode,
+	})
+}
+
+// ConfirmTOTP confirms the new authenticator with a code and returns recovery codes.
+func (a *UsersHandlers) ConfirmTOTP(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	var body MFACodeRequest
+	if !bindCode(c, &body) {
+		return
+	}
+
+	result, err := a.appAuth.ConfirmTOTPEnrollment(c.Request.Context(), types.TOTPConfirmRequest{
+		UserID: userID,
+		Code:   body.Code,
+	})
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{
+		RecoveryCodes: result.RecoveryCodes,
+	})
+}
+
+// DisableTOTP removes the authenticator and recovery codes of the signed in user.
+func (a *UsersHandlers) DisableTOTP(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	var body MFACodeRequest
+	if !bindCode(c, &body) {
+		return
+	}
+
+	if err := a.appAuth.DisableTOTP(c.Request.Context(), userID, body.Code); err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the signed in user.
+func (a *UsersHandlers) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	var body MFACodeRequest
+	if !bindCode(c, &body) {
+		return
+	}
+
+	codes, err := a.appAuth.RegenerateRecoveryCodes(c.Request.Context(), userID, body.Code)
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// Returns the user signed in with the session cookie. Second factors cannot be
+// managed with bearer tokens.
+func requireSessionUser(c *gin.Context) (string, bool) {
+	userID, ok := middlewares.SessionUserID(c)
+	if !ok {
+		authErr := cerror.NewAuthError(cerror.AuthSessionExpired, nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+	}
+
+	return userID, ok
+}
+
+func bindCode(c *gin.Context, body *MFACodeRequest) bool {
+	if err := c.ShouldBindJSON(body); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidState, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return false
+	}
+
+	return true
+}
+
+func respondAuthError(c *gin.Context, err error, fallback string) {
+	authErr := cerror.NewAuthErrorWithMessage(errorCode(err, fallback), err.Error(), nil)
+	c.JSON(authErr.HTTPStatusCode(), authErr)
+}
@@ -0,0 +1,147 @@
+package users
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PasskeyResponse represents a passkey of the signed in user.
+type PasskeyResponse struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	BackupEligible bool       `json:"backup_eligible"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// PasskeyRegistrationRequest carries the credential the browser created.
+type PasskeyRegistrationRequest struct {
+	Name       string          `json:"name"`
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+// PasskeyRegistrationResponse is the new passkey, with recovery codes when it is
+// the user's first second factor.
+type PasskeyRegistrationResponse struct {
+	Passkey       PasskeyResponse `json:"passkey"`
+	RecoveryCodes []string        `json:"recovery_codes,omitempty"`
+}
+
+// ListPasskeys returns the passkeys of the signed in user.
+func (a *UsersHandlers) ListPasskeys(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	passkeys, err := a.appAuth.ListPasskeys(c.Request.Context(), userID)
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	response := make([]PasskeyResponse, 0, len(passkeys))
+	for _, p := range passkeys {
+		response = append(response, toPasskeyResponse(p))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// BeginPasskeyRegistration returns the options the browser creates a new passkey
+// of the signed in user with.
+func (a *UsersHandlers) BeginPasskeyRegistration(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	ceremony, err := a.appAuth.BeginPasskeyRegistration(c.Request.Context(), userID)
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set("passkey_registration_state", ceremony.State)
+
+	if err := session.Save(); err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", ceremony.Options)
+}
+
+// FinishPasskeyRegistration stores the passkey the browser created.
+func (a *UsersHandlers) FinishPasskeyRegistration(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	var body PasskeyRegistrationRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthInvalidState, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	session := sessions.Default(c)
+	state, _ := session.Get("passkey_registration_state").(string)
+
+	// A challenge is only answered once
+	session.Delete("passkey_registration_state")
+	if err := session.Save(); err != nil {
+		c.MustGet("logger").(*zap.Logger).Warn("failed to save session", zap.Error(err))
+	}
+
+	result, err := a.appAuth.FinishPasskeyRegistration(c.Request.Context(), types.PasskeyRegistrationRequest{
+		UserID:   userID,
+		Name:     body.Name,
+		State:    state,
+		Response: body.Credential,
+	})
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.JSON(http.StatusCreated, PasskeyRegistrationResponse{
+		Passkey:       toPasskeyResponse(result.Passkey),
+		RecoveryCodes: result.RecoveryCodes,
+	})
+}
+
+// DeletePasskey removes a passkey of the signed in user.
+func (a *UsersHandlers) DeletePasskey(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	if err := a.appAuth.DeletePasskey(c.Request.Context(), userID, c.Param("id")); err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func toPasskeyResponse(p types.Passkey) PasskeyResponse {
+	return PasskeyResponse{
+		ID:             p.ID,
+		Name:           p.Name,
+		BackupEligible: p.BackupEligible,
+		LastUsedAt:     p.LastUsedAt,
+		CreatedAt:      p.CreatedAt,
+	}
+}
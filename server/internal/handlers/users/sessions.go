@@ -0,0 +1,97 @@
+package users
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SessionResponse represents a signed in session of the user.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// The session the request was made with
+	Current bool `json:"current"`
+}
+
+// ListSessions returns the signed in sessions of the user, most recently seen first.
+func (a *UsersHandlers) ListSessions(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	infos, err := a.sessionIndex.List(userID)
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	currentID := sessions.Default(c).ID()
+
+	response := make([]SessionResponse, 0, len(infos))
+	for _, info := range infos {
+		response = append(response, SessionResponse{
+			ID:         info.ID,
+			Device:     info.Device,
+			UserAgent:  info.UserAgent,
+			IP:         info.IP,
+			CreatedAt:  info.CreatedAt,
+			LastSeenAt: info.LastSeenAt,
+			Current:    info.ID == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeSession signs out a session of the user, revoking the tokens Hydra issued
+// to it and its Hydra login session.
+func (a *UsersHandlers) RevokeSession(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	sessionID := c.Param("id")
+
+	info, tokens, found, err := a.sessionIndex.Get(userID, sessionID)
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+	if !found {
+		authErr := cerror.NewAuthError(cerror.AuthUserSessionNotFound, map[string]any{
+			"session_id": sessionID,
+		})
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	if err := a.appAuth.RevokeSession(c.Request.Context(), types.RevokeSessionRequest{
+		RefreshToken:   tokens.RefreshToken,
+		HydraSessionID: info.HydraSessionID,
+	}); err != nil {
+		c.MustGet("logger").(*zap.Logger).Error("failed to revoke hydra session", zap.Error(err))
+
+		respondAuthError(c, err, cerror.AuthLogoutFailed)
+		return
+	}
+
+	if err := a.sessionIndex.Revoke(userID, sessionID); err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
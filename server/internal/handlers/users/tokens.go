@@ -0,0 +1,115 @@
+package users
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTokenRequest is the body of the personal access token endpoint.
+type CreateTokenRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// Defaults to 90 days
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// TokenResponse represents an API token. The secret is only returned when the
+// token is created.
+type TokenResponse struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	DisplayPrefix string     `json:"display_prefix"`
+	Scopes        []string   `json:"scopes"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Secret        string     `json:"secret,omitempty"`
+}
+
+// ListTokens returns the personal access tokens of the signed in user.
+func (a *UsersHandlers) ListTokens(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := a.appAuth.ListPersonalTokens(c.Request.Context(), userID)
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	response := make([]TokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		response = append(response, toTokenResponse(t))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateToken issues a personal access token to the signed in user. Tokens
+// cannot be created with bearer tokens.
+func (a *UsersHandlers) CreateToken(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	var body CreateTokenRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		authErr := cerror.NewAuthErrorWithMessage(cerror.AuthAPITokenInvalid, err.Error(), nil)
+		c.JSON(authErr.HTTPStatusCode(), authErr)
+		return
+	}
+
+	issued, err := a.appAuth.CreatePersonalToken(c.Request.Context(), types.CreateAPITokenRequest{
+		Actor:         types.Actor{ID: userID, Kind: types.PrincipalUser},
+		Name:          body.Name,
+		Scopes:        body.Scopes,
+		ExpiresInDays: body.ExpiresInDays,
+	})
+	if err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	response := toTokenResponse(issued.Token)
+	response.Secret = issued.Secret
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// RevokeToken revokes a personal access token of the signed in user.
+func (a *UsersHandlers) RevokeToken(c *gin.Context) {
+	userID, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	if err := a.appAuth.RevokePersonalToken(c.Request.Context(), userID, c.Param("id")); err != nil {
+		respondAuthError(c, err, cerror.AuthSessionCreateFailed)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Converts an API token to its response.
+func toTokenResponse(t types.APIToken) TokenResponse {
+	return TokenResponse{
+		ID:            t.ID,
+		Name:          t.Name,
+		DisplayPrefix: t.DisplayPrefix,
+		Scopes:        t.Scopes,
+		ExpiresAt:     t.ExpiresAt,
+		LastUsedAt:    t.LastUsedAt,
+		RevokedAt:     t.RevokedAt,
+		CreatedAt:     t.CreatedAt,
+	}
+}
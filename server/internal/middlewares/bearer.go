@@ -0,0 +1,139 @@
+package middlewares
+
+import (
+	"errors"
+	"strings"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/gateway/token_cache"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Context key of the principal authenticated by a bearer token.
+const principalKey = "principal"
+
+// BearerAuthMiddleware authenticates requests carrying an Authorization: Bearer
+// header. API tokens are recognized by their prefix and looked up in the database,
+// other tokens are OAuth2 access tokens introspected with Hydra and cached.
+// Requests without the header are passed through so cookie sessions keep working.
+func BearerAuthMiddleware(appAuth types.AppAuth, cache *token_cache.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		logger := c.MustGet("logger").(*zap.Logger)
+
+		scheme, token, found := strings.Cut(header, " ")
+		token = strings.TrimSpace(token)
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			abortWithAuthError(c, cerror.NewAuthError(cerror.AuthInvalidToken, map[string]any{
+				"reason": "malformed_authorization_header",
+			}))
+			return
+		}
+
+		principal, err := authenticate(c, appAuth, cache, token)
+		if errors.Is(err, types.ErrTokenInactive) {
+			abortWithAuthError(c, cerror.NewAuthError(cerror.AuthInvalidToken, map[string]any{
+				"reason": "inactive",
+			}))
+			return
+		}
+		if err != nil {
+			logger.Error("failed to authenticate token", zap.Error(err))
+
+			abortWithAuthError(c, cerror.NewAuthErrorWithMessage(cerror.AuthTokenIntrospectFailed, err.Error(), nil))
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Set("logger", logger.With(zap.String("subject", principal.Subject)))
+
+		c.Next()
+	}
+}
+
+// Returns the principal of an API token or an OAuth2 access token. API tokens
+// are looked up on every request, so revoking them, or deactivating their user
+// or service account, takes effect at once and their use is recorded. Access
+// tokens are introspected once and cached.
+func authenticate(c *gin.Context, appAuth types.AppAuth, cache *token_cache.Cache, token string) (types.Principal, error) {
+	if strings.HasPrefix(token, types.PersonalTokenPrefix) || strings.HasPrefix(token, types.ServiceAccountTokenPrefix) {
+		return appAuth.AuthenticateAPIToken(c.Request.Context(), token)
+	}
+
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	principal, cached, err := cache.Get(token)
+	if err != nil {
+		logger.Warn("failed to read token cache", zap.Error(err))
+	}
+	if cached {
+		return principal, nil
+	}
+
+	principal, err = appAuth.IntrospectToken(c.Request.Context(), token)
+	if err != nil {
+		return types.Principal{}, err
+	}
+
+	if err := cache.Set(token, principal); err != nil {
+		logger.Warn("failed to cache token", zap.Error(err))
+	}
+
+	return principal, nil
+}
+
+// RequireScopes rejects bearer tokens that were not granted every one of scopes.
+// Requests authenticated by the cookie session come from the first-party frontend
+// and are let through; anonymous requests are rejected.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			if authenticated, _ := sessions.Default(c).Get("authenticated").(bool); authenticated {
+				c.Next()
+				return
+			}
+
+			c.Header("WWW-Authenticate", "Bearer")
+			abortWithAuthError(c, cerror.NewAuthError(cerror.AuthInvalidToken, map[string]any{
+				"reason": "missing",
+			}))
+			return
+		}
+
+		if !principal.HasScopes(scopes...) {
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+strings.Join(scopes, " ")+`"`)
+			abortWithAuthError(c, cerror.NewAuthError(cerror.AuthInsufficientScope, map[string]any{
+				"required_scopes": scopes,
+			}))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetPrincipal returns the principal authenticated by a bearer token, if any.
+func GetPrincipal(c *gin.Context) (types.Principal, bool) {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return types.Principal{}, false
+	}
+
+	principal, ok := value.(types.Principal)
+
+	return principal, ok
+}
+
+func abortWithAuthError(c *gin.Context, authErr *cerror.AuthError) {
+	c.AbortWithStatusJSON(authErr.HTTPStatusCode(), authErr)
+}
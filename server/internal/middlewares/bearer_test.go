@@ -0,0 +1,246 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/internal/gateway/token_cache"
+	"conformitea/server/types"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/gomodule/redigo/redis"
+	"go.uber.org/zap"
+)
+
+// Authenticates the active tokens and counts the lookups of each kind.
+type stubAuth struct {
+	types.AppAuth
+	active         map[string]types.Principal
+	apiTokenChecks int
+	introspections int
+}
+
+func (s *stubAuth) AuthenticateAPIToken(ctx context.Context, secret string) (types.Principal, error) {
+	s.apiTokenChecks++
+
+	return s.lookup(secret)
+}
+
+func (s *stubAuth) IntrospectToken(ctx context.Context, token string) (types.Principal, error) {
+	s.introspections++
+
+	return s.lookup(token)
+}
+
+func (s *stubAuth) lookup(token string) (types.Principal, error) {
+	principal, ok := s.active[token]
+	if !ok {
+		return types.Principal{}, types.ErrTokenInactive
+	}
+
+	return principal, nil
+}
+
+// Returns a router authenticating bearer tokens with appAuth and a cache backed
+// by an in-memory Redis server, or else the session cookie. Requests to
+// /resource go through guards first.
+func bearerRouter(t *testing.T, appAuth types.AppAuth, guards ...gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", server.Addr())
+		},
+	}
+	t.Cleanup(func() { _ = pool.Close() })
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("logger", zap.NewNop())
+	})
+	router.Use(sessions.Sessions("session", cookie.NewStore([]byte("secret"))))
+	router.Use(BearerAuthMiddleware(appAuth, token_cache.NewCache(pool, 30)))
+
+	// Signs the session in as the user of the query, impersonating the subject
+	// of the query if any
+	router.GET("/sign-in", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set("authenticated", true)
+		session.Set("user_id", c.Query("user_id"))
+		if subject := c.Query("subject_id"); subject != "" {
+			gin_session.SetImpersonation(session, types.Impersonation{ID: "impersonation", SubjectID: subject})
+		}
+		_ = session.Save()
+	})
+
+	router.GET("/resource", append(guards, func(c *gin.Context) {
+		principal, _ := GetPrincipal(c)
+		c.String(http.StatusOK, principal.Subject)
+	})...)
+
+	return router
+}
+
+// Returns the session cookie of the user signed in, impersonating subjectID
+// unless it is empty.
+func signIn(t *testing.T, router *gin.Engine, userID, subjectID string) *http.Cookie {
+	t.Helper()
+
+	query := url.Values{"user_id": {userID}, "subject_id": {subjectID}}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sign-in?"+query.Encode(), nil))
+
+	for _, c := range recorder.Result().Cookies() {
+		if c.Name == "session" {
+			return c
+		}
+	}
+
+	t.Fatal("signing in set no session cookie")
+
+	return nil
+}
+
+func getWithToken(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	return get(router, token, nil)
+}
+
+// Requests the resource with the bearer token and the session cookie, either of
+// which may be left empty.
+func get(router *gin.Engine, token string, session *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if session != nil {
+		req.AddCookie(session)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestBearerAuthRevokedTokens(t *testing.T) {
+	tests := []struct {
+		name               string
+		token              string
+		wantStatus         int
+		wantAPITokenChecks int
+		wantIntrospections int
+	}{
+		{
+			name:               "personal token",
+			token:              types.PersonalTokenPrefix + "secret",
+			wantStatus:         http.StatusUnauthorized,
+			wantAPITokenChecks: 2,
+		},
+		{
+			name:               "service account token",
+			token:              types.ServiceAccountTokenPrefix + "secret",
+			wantStatus:         http.StatusUnauthorized,
+			wantAPITokenChecks: 2,
+		},
+		{
+			// Access tokens are cached, their revocation applies once the cached
+			// introspection expires
+			name:               "access token",
+			token:              "ory_at_secret",
+			wantStatus:         http.StatusOK,
+			wantIntrospections: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appAuth := &stubAuth{active: map[string]types.Principal{
+				tt.token: {Subject: "subject", ExpiresAt: time.Now().Add(time.Hour)},
+			}}
+			router := bearerRouter(t, appAuth)
+
+			if recorder := getWithToken(router, tt.token); recorder.Code != http.StatusOK {
+				t.Fatalf("status before revocation = %d, want %d", recorder.Code, http.StatusOK)
+			}
+
+			delete(appAuth.active, tt.token)
+
+			if recorder := getWithToken(router, tt.token); recorder.Code != tt.wantStatus {
+				t.Errorf("status after revocation = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if appAuth.apiTokenChecks != tt.wantAPITokenChecks || appAuth.introspections != tt.wantIntrospections {
+				t.Errorf("API token checks = %d, introspections = %d, want %d and %d",
+					appAuth.apiTokenChecks, appAuth.introspections, tt.wantAPITokenChecks, tt.wantIntrospections)
+			}
+		})
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []string
+		signedIn   bool
+		wantStatus int
+	}{
+		{
+			name:       "token granted every scope",
+			scopes:     []string{"openid", types.OrganizationsScope},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "token missing a scope",
+			scopes:     []string{"openid"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "token missing a scope of a signed in user",
+			scopes:     []string{"openid"},
+			signedIn:   true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "signed in user",
+			signedIn:   true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "anonymous",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := bearerRouter(t, &stubAuth{active: map[string]types.Principal{
+				"ory_at_secret": {Subject: "subject", Scopes: tt.scopes, ExpiresAt: time.Now().Add(time.Hour)},
+			}}, RequireScopes("openid", types.OrganizationsScope))
+
+			var token string
+			if tt.scopes != nil {
+				token = "ory_at_secret"
+			}
+
+			var session *http.Cookie
+			if tt.signedIn {
+				session = signIn(t, router, "user", "")
+			}
+
+			if recorder := get(router, token, session); recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
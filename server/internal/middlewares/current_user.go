@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentUserID returns the ID of the user the request is made for, taken from the
+// bearer token or else from the signed in session. Requests of service accounts
+// and of clients acting on their own behalf are not made for a user.
+func CurrentUserID(c *gin.Context) (string, bool) {
+	if principal, ok := GetPrincipal(c); ok {
+		return principal.Subject, principal.IsUser()
+	}
+
+	return SessionUserID(c)
+}
+
+// CurrentActor returns the user, service account or client the request is made by.
+func CurrentActor(c *gin.Context) (types.Actor, bool) {
+	if principal, ok := GetPrincipal(c); ok {
+		kind := principal.Kind
+		if kind == "" {
+			kind = types.PrincipalUser
+		}
+
+		return types.Actor{ID: principal.Subject, Kind: kind}, true
+	}
+
+	userID, ok := SessionUserID(c)
+
+	return types.Actor{ID: userID, Kind: types.PrincipalUser}, ok
+}
+
+// SessionUserID returns the ID of the user signed in with the session cookie, or
+// of the user a staff user signed in with it impersonates. Bearer tokens are
+// ignored, for endpoints only the user themself may call.
+func SessionUserID(c *gin.Context) (string, bool) {
+	userID, ok := SignedInUserID(c)
+	if !ok {
+		return "", false
+	}
+
+	if impersonation, ok := gin_session.GetImpersonation(sessions.Default(c)); ok {
+		return impersonation.SubjectID, true
+	}
+
+	return userID, true
+}
+
+// SignedInUserID returns the ID of the user signed in with the session cookie,
+// the staff user rather than the subject of an impersonation.
+func SignedInUserID(c *gin.Context) (string, bool) {
+	session := sessions.Default(c)
+
+	authenticated, _ := session.Get("authenticated").(bool)
+	userID, _ := session.Get("user_id").(string)
+	if !authenticated || userID == "" {
+		return "", false
+	}
+
+	return userID, true
+}
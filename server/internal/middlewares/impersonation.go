@@ -0,0 +1,121 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"conformitea/server/config"
+	"conformitea/server/internal/cerror"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Routes impersonating staff users can always call besides reads, to end the
+// impersonation or sign out.
+var impersonationRoutes = []string{
+	"DELETE /admin/impersonation",
+	"POST /auth/logout",
+}
+
+// ImpersonationMiddleware enforces the impersonations staff users start in their
+// session and records every request made while impersonating in the audit trail.
+// Requests that could change the subject's data are blocked unless their route
+// is allowed by the configuration. Expired impersonations are ended, after which
+// such requests are rejected rather than made as the staff user.
+func ImpersonationMiddleware(cfg config.Config, appImpersonation types.AppImpersonation) gin.HandlerFunc {
+	allowed := map[string]bool{}
+	for _, route := range impersonationRoutes {
+		allowed[route] = true
+	}
+	for _, route := range cfg.HTTPServer.Impersonation.AllowedRoutes {
+		allowed[route] = true
+	}
+
+	return func(c *gin.Context) {
+		// Bearer tokens are not subject to the impersonation of the session
+		if _, ok := GetPrincipal(c); ok {
+			c.Next()
+			return
+		}
+
+		session := sessions.Default(c)
+
+		impersonation, ok := gin_session.GetImpersonation(session)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		logger := c.MustGet("logger").(*zap.Logger)
+		request := AuditRequest(c)
+		permitted := isReadMethod(c.Request.Method) || allowed[c.Request.Method+" "+c.FullPath()]
+
+		if impersonation.Expired(time.Now()) {
+			gin_session.ClearImpersonation(session)
+			if err := session.Save(); err != nil {
+				logger.Warn("failed to save session", zap.Error(err))
+			}
+
+			if err := appImpersonation.EndImpersonation(c.Request.Context(), types.EndImpersonationRequest{
+				Impersonation: impersonation,
+				Expired:       true,
+				Request:       request,
+			}); err != nil {
+				logger.Error("failed to record impersonation end", zap.Error(err))
+			}
+
+			logger.Info("impersonation expired",
+				zap.String("impersonation_id", impersonation.ID),
+				zap.String("subject_id", impersonation.SubjectID))
+
+			if !permitted {
+				impErr := cerror.NewImpersonationError(cerror.ImpersonationExpired, "impersonation expired", nil)
+				c.AbortWithStatusJSON(impErr.HTTPStatusCode(), impErr)
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		if permitted {
+			c.Next()
+		} else {
+			impErr := cerror.NewImpersonationError(cerror.ImpersonationBlocked, "request blocked while impersonating", map[string]any{
+				"method": c.Request.Method,
+				"route":  c.FullPath(),
+			})
+			c.AbortWithStatusJSON(impErr.HTTPStatusCode(), impErr)
+		}
+
+		if err := appImpersonation.RecordImpersonatedRequest(c.Request.Context(), types.ImpersonatedRequest{
+			Impersonation: impersonation,
+			Request:       request,
+			Status:        c.Writer.Status(),
+		}); err != nil {
+			logger.Error("failed to record impersonated request",
+				zap.Error(err),
+				zap.String("impersonation_id", impersonation.ID))
+		}
+	}
+}
+
+// AuditRequest describes the request for the audit trail. The query string is
+// left out, as it can carry secrets such as invitation tokens.
+func AuditRequest(c *gin.Context) types.AuditRequest {
+	return types.AuditRequest{
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		RequestID: c.GetString("request_id"),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+// Reports whether requests with method only read data.
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
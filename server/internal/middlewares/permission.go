@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"errors"
+
+	"conformitea/server/internal/cerror"
+	"conformitea/server/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RequirePermission rejects requests whose actor's role in the organization named
+// by the :id route parameter does not grant permission. Actors outside the
+// organization are told it does not exist.
+func RequirePermission(appOrganization types.AppOrganization, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, ok := CurrentActor(c)
+		if !ok {
+			abortWithAuthError(c, cerror.NewAuthError(cerror.AuthSessionExpired, nil))
+			return
+		}
+
+		err := appOrganization.Authorize(c.Request.Context(), actor, c.Param("id"), permission)
+		if err == nil {
+			c.Next()
+			return
+		}
+
+		code := cerror.AuthorizationFailed
+		switch {
+		case errors.Is(err, types.ErrOrganizationNotFound):
+			code = cerror.OrganizationNotFound
+		case errors.Is(err, types.ErrPermissionDenied):
+			code = cerror.OrganizationPermissionDenied
+		default:
+			c.MustGet("logger").(*zap.Logger).Error("failed to authorize request", zap.Error(err))
+		}
+
+		orgErr := cerror.NewOrganizationError(code, err.Error(), map[string]any{
+			"organization_id":     c.Param("id"),
+			"required_permission": permission,
+		})
+		c.AbortWithStatusJSON(orgErr.HTTPStatusCode(), orgErr)
+	}
+}
+
+// RequireStaff rejects requests of anyone but operators and users with staff
+// rights, whatever the scopes of their token. The admin use cases authorize the
+// actor again. A staff user impersonating someone is checked as themself, so
+// they can still end the impersonation.
+func RequireStaff(appAccounts types.AppAccounts) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, ok := CurrentActor(c)
+		if _, bearer := GetPrincipal(c); !bearer {
+			var userID string
+			userID, ok = SignedInUserID(c)
+			actor = types.Actor{ID: userID, Kind: types.PrincipalUser}
+		}
+		if !ok {
+			abortWithAuthError(c, cerror.NewAuthError(cerror.AuthSessionExpired, nil))
+			return
+		}
+
+		err := appAccounts.AuthorizeStaff(c.Request.Context(), actor)
+		if err == nil {
+			c.Next()
+			return
+		}
+
+		code := cerror.AuthAuthorizationFailed
+		if errors.Is(err, types.ErrPermissionDenied) {
+			code = cerror.AuthStaffRequired
+		} else {
+			c.MustGet("logger").(*zap.Logger).Error("failed to authorize request", zap.Error(err))
+		}
+
+		abortWithAuthError(c, cerror.NewAuthError(code, nil))
+	}
+}
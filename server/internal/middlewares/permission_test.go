@@ -0,0 +1,254 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"conformitea/server/types"
+)
+
+// Authorizes the actors granted a permission and records who it authorized.
+type stubOrganization struct {
+	types.AppOrganization
+	granted map[string]bool
+	err     error
+	actors  []types.Actor
+}
+
+func (s *stubOrganization) Authorize(ctx context.Context, actor types.Actor, organizationID, permission string) error {
+	s.actors = append(s.actors, actor)
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if !s.granted[actor.ID+" "+permission] {
+		return types.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// Authorizes the staff actors and records who it authorized.
+type stubAccounts struct {
+	types.AppAccounts
+	staff  map[string]bool
+	err    error
+	actors []types.Actor
+}
+
+func (s *stubAccounts) AuthorizeStaff(ctx context.Context, actor types.Actor) error {
+	s.actors = append(s.actors, actor)
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if !s.staff[actor.ID] {
+		return types.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// Returns a stub authenticating the token "ory_at_secret" as the principal.
+func tokenAuth(principal types.Principal) *stubAuth {
+	principal.ExpiresAt = time.Now().Add(time.Hour)
+
+	return &stubAuth{active: map[string]types.Principal{"ory_at_secret": principal}}
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name string
+		// Principal of the bearer token, if any
+		principal *types.Principal
+		// User signed in with the session cookie, if any
+		signedIn   string
+		err        error
+		wantStatus int
+		// Actor authorized, if any
+		wantActor *types.Actor
+	}{
+		{
+			name:       "signed in user granted the permission",
+			signedIn:   "admin",
+			wantStatus: http.StatusOK,
+			wantActor:  &types.Actor{ID: "admin", Kind: types.PrincipalUser},
+		},
+		{
+			name:       "signed in user denied the permission",
+			signedIn:   "member",
+			wantStatus: http.StatusForbidden,
+			wantActor:  &types.Actor{ID: "member", Kind: types.PrincipalUser},
+		},
+		{
+			name:       "service account granted the permission",
+			principal:  &types.Principal{Subject: "directory", Kind: types.PrincipalServiceAccount},
+			wantStatus: http.StatusOK,
+			wantActor:  &types.Actor{ID: "directory", Kind: types.PrincipalServiceAccount},
+		},
+		{
+			name:       "user outside the organization",
+			signedIn:   "stranger",
+			err:        types.ErrOrganizationNotFound,
+			wantStatus: http.StatusNotFound,
+			wantActor:  &types.Actor{ID: "stranger", Kind: types.PrincipalUser},
+		},
+		{
+			name:       "failed authorization",
+			signedIn:   "admin",
+			err:        errors.New("database unavailable"),
+			wantStatus: http.StatusInternalServerError,
+			wantActor:  &types.Actor{ID: "admin", Kind: types.PrincipalUser},
+		},
+		{
+			name:       "anonymous",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appAuth := &stubAuth{}
+			var token string
+			if tt.principal != nil {
+				appAuth = tokenAuth(*tt.principal)
+				token = "ory_at_secret"
+			}
+
+			appOrganization := &stubOrganization{
+				granted: map[string]bool{
+					"admin " + types.PermissionMembersManage:     true,
+					"directory " + types.PermissionMembersManage: true,
+				},
+				err: tt.err,
+			}
+			router := bearerRouter(t, appAuth, RequirePermission(appOrganization, types.PermissionMembersManage))
+
+			var session *http.Cookie
+			if tt.signedIn != "" {
+				session = signIn(t, router, tt.signedIn, "")
+			}
+
+			if recorder := get(router, token, session); recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			checkActors(t, appOrganization.actors, tt.wantActor)
+		})
+	}
+}
+
+func TestRequireStaff(t *testing.T) {
+	tests := []struct {
+		name string
+		// Principal of the bearer token, if any
+		principal *types.Principal
+		// User signed in with the session cookie, if any
+		signedIn string
+		// User the signed in user impersonates, if any
+		impersonated string
+		err          error
+		wantStatus   int
+		// Actor authorized, if any
+		wantActor *types.Actor
+	}{
+		{
+			name:       "signed in staff user",
+			signedIn:   "staff",
+			wantStatus: http.StatusOK,
+			wantActor:  &types.Actor{ID: "staff", Kind: types.PrincipalUser},
+		},
+		{
+			name:       "signed in user without staff rights",
+			signedIn:   "member",
+			wantStatus: http.StatusForbidden,
+			wantActor:  &types.Actor{ID: "member", Kind: types.PrincipalUser},
+		},
+		{
+			name:         "staff user impersonating someone",
+			signedIn:     "staff",
+			impersonated: "member",
+			wantStatus:   http.StatusOK,
+			wantActor:    &types.Actor{ID: "staff", Kind: types.PrincipalUser},
+		},
+		{
+			name:       "token of a staff user",
+			principal:  &types.Principal{Subject: "staff", Kind: types.PrincipalUser},
+			wantStatus: http.StatusOK,
+			wantActor:  &types.Actor{ID: "staff", Kind: types.PrincipalUser},
+		},
+		{
+			name:       "token of an operator",
+			principal:  &types.Principal{Subject: "operator", Kind: types.PrincipalOperator},
+			wantStatus: http.StatusOK,
+			wantActor:  &types.Actor{ID: "operator", Kind: types.PrincipalOperator},
+		},
+		{
+			name:       "token of a user without staff rights",
+			principal:  &types.Principal{Subject: "member", Kind: types.PrincipalUser},
+			wantStatus: http.StatusForbidden,
+			wantActor:  &types.Actor{ID: "member", Kind: types.PrincipalUser},
+		},
+		{
+			name:       "failed authorization",
+			signedIn:   "staff",
+			err:        errors.New("database unavailable"),
+			wantStatus: http.StatusInternalServerError,
+			wantActor:  &types.Actor{ID: "staff", Kind: types.PrincipalUser},
+		},
+		{
+			name:       "anonymous",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appAuth := &stubAuth{}
+			var token string
+			if tt.principal != nil {
+				appAuth = tokenAuth(*tt.principal)
+				token = "ory_at_secret"
+			}
+
+			appAccounts := &stubAccounts{
+				staff: map[string]bool{"staff": true, "operator": true},
+				err:   tt.err,
+			}
+			router := bearerRouter(t, appAuth, RequireStaff(appAccounts))
+
+			var session *http.Cookie
+			if tt.signedIn != "" {
+				session = signIn(t, router, tt.signedIn, tt.impersonated)
+			}
+
+			if recorder := get(router, token, session); recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			checkActors(t, appAccounts.actors, tt.wantActor)
+		})
+	}
+}
+
+// Checks that the only actor authorized is want, or that none was when want is
+// nil.
+func checkActors(t *testing.T, actors []types.Actor, want *types.Actor) {
+	t.Helper()
+
+	if want == nil {
+		if len(actors) != 0 {
+			t.Errorf("authorized %+v, want nobody", actors)
+		}
+		return
+	}
+
+	if len(actors) != 1 || actors[0] != *want {
+		t.Errorf("authorized %+v, want %+v", actors, *want)
+	}
+}
@@ -1,19 +1,18 @@
 package middlewares
 
 import (
-	"fmt"
-
 	"conformitea/server/config"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/internal/gateway/token_cache"
+	"conformitea/server/types"
 
+	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-func RegisterMiddlewares(r *gin.Engine, l *zap.Logger, c config.Config) error {
-	sessionMiddleware, err := SessionMiddleware(c)
-	if err != nil {
-		return fmt.Errorf("failed to initialize session middleware: %w", err)
-	}
+func RegisterMiddlewares(r *gin.Engine, l *zap.Logger, c config.Config, sessionStore sessions.Store, sessionIndex *gin_session.Index, appAuth types.AppAuth, appImpersonation types.AppImpersonation, tokenCache *token_cache.Cache) error {
+	sessionMiddleware := SessionMiddleware(c, sessionStore)
 
 	// Most of the time, the order of middlewares is important.
 	r.Use(LogRequestDetails())
@@ -21,6 +20,10 @@ func RegisterMiddlewares(r *gin.Engine, l *zap.Logger, c config.Config) error {
 	r.Use(RequestIdMiddleware())
 	r.Use(CORSMiddleware())
 	r.Use(sessionMiddleware)
+	r.Use(SessionTokensMiddleware(appAuth, sessionIndex))
+	r.Use(SessionActivityMiddleware(sessionIndex))
+	r.Use(BearerAuthMiddleware(appAuth, tokenCache))
+	r.Use(ImpersonationMiddleware(c, appImpersonation))
 	r.Use(gin.Recovery())
 
 	return nil
@@ -1,22 +1,29 @@
 package middlewares
 
 import (
-	"fmt"
-
 	"conformitea/server/config"
-	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/internal/cerror"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
-func SessionMiddleware(cfg config.Config) (gin.HandlerFunc, error) {
-	sessionStore, err := gin_session.NewStore(cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize session store: %w", err)
-	}
-
+func SessionMiddleware(cfg config.Config, store sessions.Store) gin.HandlerFunc {
 	cookieName := cfg.HTTPServer.Session.CookieName
 
-	return sessions.Sessions(cookieName, sessionStore), nil
+	return sessions.Sessions(cookieName, store)
+}
+
+// RequireSession rejects requests of anyone not signed in with the session
+// cookie, whatever bearer token they carry. It guards the account endpoints only
+// the user themself may call from the first-party frontend.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := SessionUserID(c); !ok {
+			abortWithAuthError(c, cerror.NewAuthError(cerror.AuthSessionExpired, nil))
+			return
+		}
+
+		c.Next()
+	}
 }
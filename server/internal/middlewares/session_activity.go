@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"time"
+
+	"conformitea/server/internal/gateway/gin_session"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Signed in sessions record their activity at most this often.
+const sessionActivityInterval = 60 * time.Second
+
+// SessionActivityMiddleware records when and from which address signed in
+// sessions were last used, so users can tell their sessions apart.
+func SessionActivityMiddleware(index *gin_session.Index) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := sessions.Default(c)
+
+		authenticated, _ := session.Get("authenticated").(bool)
+		lastSeen, _ := session.Get("last_seen_at").(int64)
+		if !authenticated || time.Since(time.Unix(lastSeen, 0)) < sessionActivityInterval {
+			c.Next()
+			return
+		}
+
+		logger := c.MustGet("logger").(*zap.Logger)
+
+		if err := index.Touch(session.ID(), c.ClientIP()); err != nil {
+			logger.Warn("failed to record session activity", zap.Error(err))
+		}
+
+		session.Set("last_seen_at", time.Now().Unix())
+		if err := session.Save(); err != nil {
+			logger.Warn("failed to save session", zap.Error(err))
+		}
+
+		c.Next()
+	}
+}
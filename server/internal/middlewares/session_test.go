@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"net/http"
+	"testing"
+
+	"conformitea/server/types"
+)
+
+func TestRequireSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      bool
+		signedIn   bool
+		wantStatus int
+	}{
+		{
+			name:       "signed in user",
+			signedIn:   true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "signed in user with a token",
+			token:      true,
+			signedIn:   true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			// Tokens cannot manage the second factors, tokens and sessions of
+			// their user
+			name:       "token of a user",
+			token:      true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "anonymous",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := bearerRouter(t, tokenAuth(types.Principal{
+				Subject: "user",
+				Kind:    types.PrincipalUser,
+				Scopes:  []string{"openid"},
+			}), RequireSession())
+
+			var token string
+			if tt.token {
+				token = "ory_at_secret"
+			}
+
+			var session *http.Cookie
+			if tt.signedIn {
+				session = signIn(t, router, "user", "")
+			}
+
+			if recorder := get(router, token, session); recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"errors"
+	"time"
+
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/types"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Access tokens are refreshed this long before they expire.
+const tokenRefreshLeeway = 60 * time.Second
+
+// SessionTokensMiddleware refreshes the Hydra tokens of signed in sessions before
+// the access token expires. Sessions whose refresh token Hydra refuses are signed
+// out; other refresh failures are retried on the next request. Concurrent requests
+// of a session refresh one at a time, later ones use the tokens the first stored.
+func SessionTokensMiddleware(appAuth types.AppAuth, index *gin_session.Index) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := sessions.Default(c)
+
+		authenticated, _ := session.Get("authenticated").(bool)
+		if !authenticated {
+			c.Next()
+			return
+		}
+
+		tokens := gin_session.GetTokens(session)
+		if tokens.RefreshToken == "" || time.Until(tokens.ExpiresAt) > tokenRefreshLeeway {
+			c.Next()
+			return
+		}
+
+		logger := c.MustGet("logger").(*zap.Logger)
+
+		unlock, err := index.LockRefresh(c.Request.Context(), session.ID())
+		if err != nil {
+			logger.Warn("failed to lock session refresh", zap.Error(err))
+
+			c.Next()
+			return
+		}
+
+		refreshSession(c, appAuth, index, session, tokens)
+		unlock()
+
+		c.Next()
+	}
+}
+
+// Refreshes the tokens of the session while holding its refresh lock. The tokens
+// are read again from the store, as the request that held the lock before may
+// have refreshed them.
+func refreshSession(c *gin.Context, appAuth types.AppAuth, index *gin_session.Index, session sessions.Session, tokens types.SessionTokens) {
+	logger := c.MustGet("logger").(*zap.Logger)
+
+	stored, found, err := index.StoredTokens(session.ID())
+	if err != nil {
+		logger.Warn("failed to read session tokens", zap.Error(err))
+		return
+	}
+
+	switch {
+	case !found || stored.RefreshToken == "":
+		// The session was signed out or revoked while the request waited
+		session.Clear()
+	case stored.RefreshToken != tokens.RefreshToken:
+		gin_session.SetTokens(session, stored)
+	default:
+		refreshed, err := appAuth.RefreshSession(c.Request.Context(), tokens.RefreshToken)
+		switch {
+		case errors.Is(err, types.ErrRefreshTokenInvalid):
+			logger.Info("refresh token refused, signing session out", zap.Error(err))
+
+			session.Clear()
+		case err != nil:
+			logger.Warn("failed to refresh session tokens", zap.Error(err))
+			return
+		default:
+			gin_session.SetTokens(session, refreshed)
+		}
+	}
+
+	if err := session.Save(); err != nil {
+		logger.Warn("failed to save session", zap.Error(err))
+	}
+}
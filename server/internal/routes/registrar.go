@@ -2,21 +2,144 @@ package routes
 
 import (
 	"conformitea/server/internal/handlers"
+	"conformitea/server/internal/handlers/accounts"
 	"conformitea/server/internal/handlers/auth"
+	"conformitea/server/internal/handlers/clients"
+	"conformitea/server/internal/handlers/impersonation"
+	"conformitea/server/internal/handlers/organizations"
+	"conformitea/server/internal/handlers/scim"
 	"conformitea/server/internal/handlers/users"
+	"conformitea/server/internal/middlewares"
+	"conformitea/server/types"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(router *gin.Engine, auth *auth.AuthHandlers, users *users.UsersHandlers) {
+func RegisterRoutes(router *gin.Engine, auth *auth.AuthHandlers, users *users.UsersHandlers, organizations *organizations.OrganizationsHandlers, scim *scim.SCIMHandlers, clients *clients.ClientsHandlers, impersonation *impersonation.ImpersonationHandlers, accounts *accounts.AccountsHandlers, appOrganization types.AppOrganization, appAccounts types.AppAccounts) {
 	// Authentication routes
 	router.GET("/auth/callback", auth.Callback)
 	router.GET("/auth/consent", auth.Consent)
+	router.GET("/auth/consent/request", auth.ConsentDetails)
+	router.POST("/auth/consent/accept", auth.ConsentAccept)
+	router.POST("/auth/consent/reject", auth.ConsentReject)
+	router.GET("/auth/device", auth.Device)
+	router.POST("/auth/device/verify", auth.DeviceVerify)
 	router.GET("/auth/login", auth.Login)
+	router.GET("/auth/providers", auth.Providers)
+	router.GET("/auth/logout", auth.LogoutChallenge)
 	router.POST("/auth/logout", auth.Logout)
+	router.POST("/auth/backchannel-logout", auth.BackChannelLogout)
+	router.GET("/auth/session/login", auth.SessionLogin)
+	router.GET("/auth/session/callback", auth.SessionCallback)
+	router.GET("/auth/mfa", auth.MFAChallenge)
+	router.POST("/auth/mfa/totp", auth.MFABeginEnrollment)
+	router.POST("/auth/mfa/totp/confirm", auth.MFAConfirmEnrollment)
+	router.POST("/auth/mfa/verify", auth.MFAVerify)
+	router.POST("/auth/mfa/passkey/begin", auth.MFAPasskeyBegin)
+	router.POST("/auth/mfa/passkey/finish", auth.MFAPasskeyFinish)
+	router.POST("/auth/passkey/begin", auth.PasskeyBegin)
+	router.POST("/auth/passkey/finish", auth.PasskeyFinish)
+	router.GET("/auth/saml/continue", auth.SAMLContinue)
+	router.GET("/auth/saml/:organization_id/metadata", auth.SAMLMetadata)
+	router.POST("/auth/saml/:organization_id/acs", auth.SAMLAssertionConsumer)
 
 	// User routes
-	router.GET("/users/me", users.Me)
+	router.GET("/users/me", middlewares.RequireScopes("openid"), users.Me)
+	// Account routes, which only the user signed in with the session cookie may call
+	accountRoutes := router.Group("/users/me", middlewares.RequireSession())
+	accountRoutes.GET("/mfa", users.GetMFA)
+	accountRoutes.POST("/mfa/totp", users.BeginTOTP)
+	accountRoutes.POST("/mfa/totp/confirm", users.ConfirmTOTP)
+	accountRoutes.DELETE("/mfa/totp", users.DisableTOTP)
+	accountRoutes.POST("/mfa/recovery-codes", users.RegenerateRecoveryCodes)
+	accountRoutes.GET("/passkeys", users.ListPasskeys)
+	accountRoutes.POST("/passkeys/begin", users.BeginPasskeyRegistration)
+	accountRoutes.POST("/passkeys/finish", users.FinishPasskeyRegistration)
+	accountRoutes.DELETE("/passkeys/:id", users.DeletePasskey)
+	accountRoutes.GET("/tokens", users.ListTokens)
+	accountRoutes.POST("/tokens", users.CreateToken)
+	accountRoutes.DELETE("/tokens/:id", users.RevokeToken)
+	accountRoutes.GET("/sessions", users.ListSessions)
+	accountRoutes.DELETE("/sessions/:id", users.RevokeSession)
+	accountRoutes.GET("/connected-apps", users.ListConnectedApps)
+	accountRoutes.DELETE("/connected-apps/:client_id", users.RevokeConnectedApp)
+	accountRoutes.GET("/identities", users.ListIdentities)
+	accountRoutes.POST("/identities/link", users.LinkIdentity)
+	accountRoutes.DELETE("/identities/:id", users.UnlinkIdentity)
+
+	// Organization routes, authorized against the actor's role in the organization
+	permit := func(permission string) gin.HandlerFunc {
+		return middlewares.RequirePermission(appOrganization, permission)
+	}
+	router.GET("/permissions", middlewares.RequireScopes(types.OrganizationsScope), organizations.ListPermissions)
+
+	// Every organization route requires the organizations scope of bearer tokens
+	organizationRoutes := router.Group("/organizations", middlewares.RequireScopes(types.OrganizationsScope))
+	organizationRoutes.PATCH("/:id", permit(types.PermissionOrganizationManage), organizations.Update)
+	organizationRoutes.PATCH("/:id/members/:user_id", permit(types.PermissionMembersManage), organizations.UpdateMember)
+	organizationRoutes.POST("/:id/members/:user_id/sign-out", permit(types.PermissionMembersManage), organizations.SignOutMember)
+	organizationRoutes.GET("/:id/roles", permit(types.PermissionMembersRead), organizations.ListRoles)
+	organizationRoutes.POST("/:id/roles", permit(types.PermissionRolesManage), organizations.CreateRole)
+	organizationRoutes.PATCH("/:id/roles/:role_id", permit(types.PermissionRolesManage), organizations.UpdateRole)
+	organizationRoutes.DELETE("/:id/roles/:role_id", permit(types.PermissionRolesManage), organizations.DeleteRole)
+	organizationRoutes.GET("/:id/invitations", permit(types.PermissionMembersRead), organizations.ListInvitations)
+	organizationRoutes.POST("/:id/invitations", permit(types.PermissionMembersManage), organizations.CreateInvitation)
+	organizationRoutes.POST("/:id/invitations/:invitation_id/resend", permit(types.PermissionMembersManage), organizations.ResendInvitation)
+	organizationRoutes.DELETE("/:id/invitations/:invitation_id", permit(types.PermissionMembersManage), organizations.RevokeInvitation)
+	organizationRoutes.GET("/:id/service-accounts", permit(types.PermissionServiceAccountsRead), organizations.ListServiceAccounts)
+	organizationRoutes.POST("/:id/service-accounts", permit(types.PermissionServiceAccountsManage), organizations.CreateServiceAccount)
+	organizationRoutes.DELETE("/:id/service-accounts/:service_account_id", permit(types.PermissionServiceAccountsManage), organizations.DeleteServiceAccount)
+	organizationRoutes.GET("/:id/service-accounts/:service_account_id/tokens", permit(types.PermissionServiceAccountsRead), organizations.ListServiceAccountTokens)
+	organizationRoutes.POST("/:id/service-accounts/:service_account_id/tokens", permit(types.PermissionServiceAccountsManage), organizations.CreateServiceAccountToken)
+	organizationRoutes.DELETE("/:id/service-accounts/:service_account_id/tokens/:token_id", permit(types.PermissionServiceAccountsManage), organizations.RevokeServiceAccountToken)
+	organizationRoutes.GET("/:id/saml", permit(types.PermissionOrganizationRead), organizations.GetSAMLConnection)
+	organizationRoutes.PUT("/:id/saml", permit(types.PermissionOrganizationManage), organizations.ConfigureSAML)
+	organizationRoutes.DELETE("/:id/saml", permit(types.PermissionOrganizationManage), organizations.DeleteSAMLConnection)
+	organizationRoutes.GET("/:id/domains", permit(types.PermissionOrganizationRead), organizations.ListDomains)
+	organizationRoutes.POST("/:id/domains", permit(types.PermissionOrganizationManage), organizations.AddDomain)
+	organizationRoutes.PATCH("/:id/domains/:domain_id", permit(types.PermissionOrganizationManage), organizations.UpdateDomain)
+	organizationRoutes.DELETE("/:id/domains/:domain_id", permit(types.PermissionOrganizationManage), organizations.DeleteDomain)
+	organizationRoutes.POST("/:id/domains/:domain_id/verify", permit(types.PermissionOrganizationManage), organizations.VerifyDomain)
+	// Users join organizations they are not members of yet
+	organizationRoutes.GET("/joinable", organizations.ListJoinable)
+	organizationRoutes.POST("/:id/join", organizations.Join)
+	// Invitees follow their invitation link before they are members
+	invitationRoutes := router.Group("/invitations", middlewares.RequireScopes(types.OrganizationsScope))
+	invitationRoutes.GET("/preview", organizations.PreviewInvitation)
+	invitationRoutes.POST("/accept", organizations.AcceptInvitation)
+
+	// SCIM provisioning routes, authenticated with a service account token
+	scimRoutes := router.Group("/scim/v2", middlewares.RequireScopes(types.SCIMScope))
+	scimRoutes.GET("/ServiceProviderConfig", scim.ServiceProviderConfig)
+	scimRoutes.GET("/ResourceTypes", scim.ResourceTypes)
+	scimRoutes.GET("/Schemas", scim.Schemas)
+	scimRoutes.GET("/Users", scim.ListUsers)
+	scimRoutes.POST("/Users", scim.CreateUser)
+	scimRoutes.GET("/Users/:id", scim.GetUser)
+	scimRoutes.PUT("/Users/:id", scim.ReplaceUser)
+	scimRoutes.PATCH("/Users/:id", scim.PatchUser)
+	scimRoutes.DELETE("/Users/:id", scim.DeleteUser)
+	scimRoutes.GET("/Groups", scim.ListGroups)
+	scimRoutes.POST("/Groups", scim.CreateGroup)
+	scimRoutes.GET("/Groups/:id", scim.GetGroup)
+	scimRoutes.PUT("/Groups/:id", scim.ReplaceGroup)
+	scimRoutes.PATCH("/Groups/:id", scim.PatchGroup)
+	scimRoutes.DELETE("/Groups/:id", scim.DeleteGroup)
+
+	// Admin routes of staff users and operators. The admin scope only limits what
+	// tokens may be used for; staff rights are checked for every caller.
+	adminRoutes := router.Group("/admin", middlewares.RequireScopes(types.AdminScope), middlewares.RequireStaff(appAccounts))
+	adminRoutes.GET("/clients", clients.ListClients)
+	adminRoutes.POST("/clients", clients.CreateClient)
+	adminRoutes.GET("/clients/:id", clients.GetClient)
+	adminRoutes.PATCH("/clients/:id", clients.UpdateClient)
+	adminRoutes.DELETE("/clients/:id", clients.DeleteClient)
+	adminRoutes.POST("/clients/:id/secret", clients.RotateClientSecret)
+	adminRoutes.POST("/impersonation", impersonation.StartImpersonation)
+	adminRoutes.DELETE("/impersonation", impersonation.EndImpersonation)
+	adminRoutes.GET("/audit-events", impersonation.ListAuditEvents)
+	adminRoutes.POST("/users/merge", accounts.MergeUsers)
+	adminRoutes.PUT("/organizations/:id/tenant", organizations.LinkTenant)
 
 	// Health check
 	router.GET("/ping", handlers.Ping)
@@ -6,7 +6,18 @@ import (
 	"strings"
 
 	"conformitea/server/config"
+	"conformitea/server/internal/gateway/gin_session"
+	"conformitea/server/internal/gateway/logout_token"
+	"conformitea/server/internal/gateway/mfa_attempt"
+	"conformitea/server/internal/gateway/redis"
+	"conformitea/server/internal/gateway/saml_login"
+	"conformitea/server/internal/gateway/token_cache"
+	"conformitea/server/internal/handlers/accounts"
 	"conformitea/server/internal/handlers/auth"
+	"conformitea/server/internal/handlers/clients"
+	"conformitea/server/internal/handlers/impersonation"
+	"conformitea/server/internal/handlers/organizations"
+	"conformitea/server/internal/handlers/scim"
 	"conformitea/server/internal/handlers/users"
 	"conformitea/server/internal/middlewares"
 	"conformitea/server/internal/routes"
@@ -16,6 +27,15 @@ import (
 	"go.uber.org/zap"
 )
 
+// Seconds a user has to sign in with an organization's SAML identity provider.
+const samlLoginTTL = 600
+
+// Wrong second factors a user may give before their second factor is locked out.
+const mfaMaxFailures = 5
+
+// Seconds the wrong second factors of a user are counted, and their lockout lasts.
+const mfaLockout = 900
+
 type server struct {
 	authHandlers *auth.AuthHandlers
 	logger       *zap.Logger
@@ -52,20 +72,42 @@ func (s *server) Start() error {
 	return nil
 }
 
-func Initialize(c config.Config, l *zap.Logger, appAuth types.AppAuth) (types.Server, error) {
+func Initialize(c config.Config, l *zap.Logger, appAuth types.AppAuth, appOrganization types.AppOrganization, appSCIM types.AppSCIM, appClients types.AppClients, appImpersonation types.AppImpersonation, appAccounts types.AppAccounts) (types.Server, error) {
 	if err := c.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid server configuration: %w", err)
 	}
 
 	router := gin.New()
 
-	if err := middlewares.RegisterMiddlewares(router, l, c); err != nil {
+	redisPool := redis.NewPool(c.Redis)
+
+	sessionStore, err := gin_session.NewStore(c, redisPool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize session store: %w", err)
+	}
+
+	sessionIndex := gin_session.NewIndex(redisPool, c.HTTPServer.Session.Timeout)
+
+	tokenCache := token_cache.NewCache(redisPool, c.HTTPServer.Bearer.CacheTTL)
+
+	if err := middlewares.RegisterMiddlewares(router, l, c, sessionStore, sessionIndex, appAuth, appImpersonation, tokenCache); err != nil {
 		return nil, fmt.Errorf("failed to register middlewares: %w", err)
 	}
 
-	authHandlers := auth.Initialize(appAuth, c)
-	usersHandlers := users.Initialize(c)
-	routes.RegisterRoutes(router, authHandlers, usersHandlers)
+	samlLogins := saml_login.NewStore(redisPool, samlLoginTTL)
+
+	logoutTokens := logout_token.NewStore(redisPool)
+
+	mfaAttempts := mfa_attempt.NewStore(redisPool, mfaMaxFailures, mfaLockout)
+
+	authHandlers := auth.Initialize(appAuth, c, sessionIndex, samlLogins, logoutTokens, mfaAttempts)
+	usersHandlers := users.Initialize(appAuth, c, sessionIndex)
+	organizationsHandlers := organizations.Initialize(appOrganization, c)
+	scimHandlers := scim.Initialize(appSCIM, c)
+	clientsHandlers := clients.Initialize(appClients)
+	impersonationHandlers := impersonation.Initialize(appImpersonation)
+	accountsHandlers := accounts.Initialize(appAccounts)
+	routes.RegisterRoutes(router, authHandlers, usersHandlers, organizationsHandlers, scimHandlers, clientsHandlers, impersonationHandlers, accountsHandlers, appOrganization, appAccounts)
 
 	return &server{
 		authHandlers: authHandlers,
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// MergeUsersRequest asks to merge a duplicate user into the user the same person
+// keeps signing in as.
+type MergeUsersRequest struct {
+	Actor Actor
+	// ID or email of the duplicate user, deactivated by the merge
+	Source string
+	// ID or email of the user the source is merged into
+	Target string
+	Reason string
+	// Report what the merge would move without merging
+	DryRun bool
+}
+
+// UserMerge describes the merge of a duplicate user into another user.
+type UserMerge struct {
+	// Empty for dry runs
+	ID           string
+	SourceUserID string
+	SourceEmail  string
+	TargetUserID string
+	TargetEmail  string
+	// Staff user who merged the users, empty for operators
+	MergedBy string
+	Reason   string
+	// Identities, organization memberships and team memberships moved to the target
+	Identities  int
+	Memberships int
+	Teams       int
+	DryRun      bool
+	CreatedAt   time.Time
+}
+
+type AppAccounts interface {
+	MergeUsers(ctx context.Context, req MergeUsersRequest) (UserMerge, error)
+	// AuthorizeStaff checks that the actor is an operator or a user with staff rights.
+	AuthorizeStaff(ctx context.Context, actor Actor) error
+}
@@ -0,0 +1,40 @@
+package types
+
+import "time"
+
+// Prefixes of API token secrets, so secret scanners can recognize leaked tokens.
+const (
+	PersonalTokenPrefix       = "ctp_"
+	ServiceAccountTokenPrefix = "cts_"
+)
+
+type CreateAPITokenRequest struct {
+	// User creating the token
+	Actor Actor
+	// Service account and its organization the token is issued to. Empty for a
+	// personal access token of the actor.
+	OrganizationID   string
+	ServiceAccountID string
+	Name             string
+	Scopes           []string
+	// Days until the token expires, 0 for the default lifetime
+	ExpiresInDays int
+}
+
+type APIToken struct {
+	ID   string
+	Name string
+	// Start of the secret, to recognize the token by
+	DisplayPrefix string
+	Scopes        []string
+	ExpiresAt     time.Time
+	LastUsedAt    *time.Time
+	RevokedAt     *time.Time
+	CreatedAt     time.Time
+}
+
+// IssuedAPIToken is a new API token with its secret, which is shown once.
+type IssuedAPIToken struct {
+	Token  APIToken
+	Secret string
+}
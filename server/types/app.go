@@ -1,18 +1,32 @@
 package types
 
-import "context"
+import (
+	"context"
+	"slices"
+	"time"
+)
 
 type LoginRequest struct {
 	LoginChallenge string
+	// Optional name of the identity provider to sign in with
+	ProviderHint string
 }
 
 type LoginResult struct {
+	// URL to continue the Hydra flow at when the login was accepted without
+	// authentication, because Hydra remembered the subject
+	RedirectTo string
 	// URL to redirect user to for authentication
 	AuthURL string
 	// Session data to store for callback processing
 	HydraLoginChallenge string
 	IDPProvider         string
 	AuthNonce           string
+	// The user signs in with a passkey instead of an identity provider
+	PasskeyLogin bool
+	// SAML authentication request the identity provider responds to, for logins
+	// with the SAML identity provider of an organization
+	SAMLRequestID string
 }
 
 type CallbackRequest struct {
@@ -25,6 +39,23 @@ type CallbackRequest struct {
 
 type CallbackResult struct {
 	RedirectTo string
+	// Hydra subject the login was accepted for, or is pending a second factor for
+	Subject string
+	// The login is only accepted once the user passes a second factor
+	MFARequired bool
+	// The user has to enroll an authenticator before passing the second factor
+	MFAEnrollmentRequired bool
+}
+
+// DeviceVerificationRequest carries the user code a user entered to sign in on a
+// device, such as the CLI, using the device authorization grant.
+type DeviceVerificationRequest struct {
+	DeviceChallenge string
+	UserCode        string
+}
+
+type DeviceVerificationResult struct {
+	RedirectTo string
 }
 
 type ConsentRequest struct {
@@ -33,10 +64,264 @@ type ConsentRequest struct {
 
 type ConsentResult struct {
 	RedirectTo string
+	// The user has to decide on the scopes requested by a third-party client on
+	// the consent page. RedirectTo is empty.
+	ConsentRequired bool
+}
+
+// ConsentDetails describes a consent request to the user deciding on it.
+type ConsentDetails struct {
+	ClientID   string
+	ClientName string
+	ClientURI  string
+	LogoURI    string
+	Scopes     []ConsentScope
+	Audience   []string
+}
+
+// ConsentScope is a scope requested by a client.
+type ConsentScope struct {
+	Name        string
+	Description string
+	// Required scopes are granted whenever the user accepts the request
+	Required bool
+}
+
+// AcceptConsentRequest carries the decision of the user on a consent request.
+type AcceptConsentRequest struct {
+	ConsentChallenge string
+	// Requested scopes the user grants. Required scopes are granted either way.
+	GrantScope []string
+	// Skip the consent page while the client requests no other scopes
+	Remember bool
+}
+
+// ConnectedApp is a third-party client the user granted access to.
+type ConnectedApp struct {
+	ClientID   string
+	ClientName string
+	ClientURI  string
+	LogoURI    string
+	Scopes     []string
+	Audience   []string
+	// Most recent consent of the user
+	GrantedAt time.Time
+}
+
+// LinkedIdentity is an identity provider account the user can sign in with.
+type LinkedIdentity struct {
+	ID       string
+	Provider string
+	Email    string
+	// Directory tenant of the account, for multi-tenant providers
+	TenantID string
+	LinkedAt time.Time
+	// Most recent sign in with the account
+	LastUsedAt time.Time
+}
+
+// IdentityLinkResult starts linking another identity provider account to the
+// user, who is sent to AuthURL to sign in with it.
+type IdentityLinkResult struct {
+	AuthURL  string
+	Provider string
+	// Session data to store for the callback
+	State string
+	Nonce string
+}
+
+// IdentityLinkCallbackRequest carries the identity provider's callback for an
+// account the user is linking.
+type IdentityLinkCallbackRequest struct {
+	UserID   string
+	Provider string
+	Code     string
+	State    string
+	// State and nonce stored when the link was started
+	ExpectedState string
+	Nonce         string
+}
+
+type SessionLoginRequest struct {
+	// Optional name of the identity provider to sign in with
+	ProviderHint string
+}
+
+// SessionLoginResult carries the Hydra authorization URL and the values the
+// callback needs to complete the sign in, which must be kept in the session.
+type SessionLoginResult struct {
+	AuthURL      string
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+type SessionCallbackRequest struct {
+	Code  string
+	State string
+	// Values stored in the session when the sign in started
+	ExpectedState string
+	Nonce         string
+	CodeVerifier  string
+}
+
+// SessionTokens are the tokens Hydra issued to the server for a signed in user.
+type SessionTokens struct {
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	ExpiresAt    time.Time
+}
+
+type SessionResult struct {
+	Tokens SessionTokens
+	UserID string
+	Email  string
+	Name   string
+	// Identity provider the user signed in with most recently
+	Provider string
+	// Hydra login session the tokens were issued in
+	HydraSessionID string
+}
+
+// RevokeSessionRequest names the Hydra artifacts of a session of the server that
+// is signed out remotely.
+type RevokeSessionRequest struct {
+	RefreshToken   string
+	HydraSessionID string
+}
+
+// Kinds of callers a principal can be.
+const (
+	PrincipalUser           = "user"
+	PrincipalServiceAccount = "service_account"
+	// Clients acting on their own behalf, with the client credentials grant
+	PrincipalClient = "client"
+	// Operators run conformitea commands with the server configuration and
+	// administer the installation without signing in
+	PrincipalOperator = "operator"
+)
+
+// Principal is the caller authenticated by an OAuth2 access token or an API token.
+type Principal struct {
+	// User ID, or service account ID for service accounts
+	Subject string `json:"subject"`
+	Kind    string `json:"kind"`
+	// Client the OAuth2 access token was issued to, empty for API tokens
+	ClientID  string    `json:"client_id"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Claims Hydra added to the access token at consent
+	Claims map[string]any `json:"claims,omitempty"`
+	// API token the request was authenticated with
+	TokenID string `json:"token_id,omitempty"`
+	// Organization of a service account
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// Reports whether the principal is a service account rather than a user.
+func (p Principal) IsServiceAccount() bool {
+	return p.Kind == PrincipalServiceAccount
+}
+
+// Reports whether the principal is a user, rather than a service account or a
+// client acting on its own behalf.
+func (p Principal) IsUser() bool {
+	return p.Kind == PrincipalUser || p.Kind == ""
+}
+
+// Reports whether the access token was granted every one of scopes.
+func (p Principal) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(p.Scopes, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// OAuth2 error codes a rejected login or consent request is reported with.
+const (
+	OAuthAccessDenied  = "access_denied"
+	OAuthLoginRequired = "login_required"
+	OAuthServerError   = "server_error"
+)
+
+type RejectRequest struct {
+	// Login or consent challenge to reject
+	Challenge        string
+	Error            string
+	ErrorDescription string
+}
+
+type RejectResult struct {
+	RedirectTo string
+}
+
+type LogoutRequest struct {
+	LogoutChallenge string
+}
+
+type LogoutResult struct {
+	RedirectTo string
+	// Subject whose Hydra session ended, if known
+	Subject string
+}
+
+type BackChannelLogoutRequest struct {
+	LogoutToken string
+}
+
+type BackChannelLogoutResult struct {
+	Subject   string
+	SessionID string
+	// Identifier of the logout token, which is only accepted once
+	TokenID string
+	// The logout token is refused after this time
+	ValidUntil time.Time
 }
 
 type AppAuth interface {
 	InitiateLogin(req LoginRequest) (LoginResult, error)
 	ProcessCallback(ctx context.Context, req CallbackRequest) (CallbackResult, error)
+	ProcessSAMLResponse(ctx context.Context, req SAMLResponseRequest) (CallbackResult, error)
+	GetSAMLMetadata(ctx context.Context, organizationID string) ([]byte, error)
+	VerifyDevice(ctx context.Context, req DeviceVerificationRequest) (DeviceVerificationResult, error)
 	ProcessConsent(ctx context.Context, req ConsentRequest) (ConsentResult, error)
+	GetConsentDetails(ctx context.Context, req ConsentRequest) (ConsentDetails, error)
+	AcceptConsent(ctx context.Context, req AcceptConsentRequest) (ConsentResult, error)
+	ListConnectedApps(ctx context.Context, userID string) ([]ConnectedApp, error)
+	RevokeConnectedApp(ctx context.Context, userID, clientID string) error
+	ListProviders(ctx context.Context) []string
+	ListIdentities(ctx context.Context, userID string) ([]LinkedIdentity, error)
+	BeginIdentityLink(ctx context.Context, userID, provider string) (IdentityLinkResult, error)
+	CompleteIdentityLink(ctx context.Context, req IdentityLinkCallbackRequest) (LinkedIdentity, error)
+	UnlinkIdentity(ctx context.Context, userID, identityID string) error
+	RejectLogin(ctx context.Context, req RejectRequest) (RejectResult, error)
+	RejectConsent(ctx context.Context, req RejectRequest) (RejectResult, error)
+	IntrospectToken(ctx context.Context, token string) (Principal, error)
+	AuthenticateAPIToken(ctx context.Context, token string) (Principal, error)
+	CreatePersonalToken(ctx context.Context, req CreateAPITokenRequest) (IssuedAPIToken, error)
+	ListPersonalTokens(ctx context.Context, userID string) ([]APIToken, error)
+	RevokePersonalToken(ctx context.Context, userID, tokenID string) error
+	StartSession(ctx context.Context, req SessionLoginRequest) (SessionLoginResult, error)
+	CompleteSession(ctx context.Context, req SessionCallbackRequest) (SessionResult, error)
+	RefreshSession(ctx context.Context, refreshToken string) (SessionTokens, error)
+	RevokeSession(ctx context.Context, req RevokeSessionRequest) error
+	GetMFAStatus(ctx context.Context, userID string) (MFAStatus, error)
+	BeginTOTPEnrollment(ctx context.Context, userID string) (TOTPEnrollment, error)
+	ConfirmTOTPEnrollment(ctx context.Context, req TOTPConfirmRequest) (TOTPConfirmResult, error)
+	DisableTOTP(ctx context.Context, userID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	VerifyMFA(ctx context.Context, req MFAVerifyRequest) (MFAVerifyResult, error)
+	ListPasskeys(ctx context.Context, userID string) ([]Passkey, error)
+	BeginPasskeyRegistration(ctx context.Context, userID string) (PasskeyCeremony, error)
+	FinishPasskeyRegistration(ctx context.Context, req PasskeyRegistrationRequest) (PasskeyRegistrationResult, error)
+	DeletePasskey(ctx context.Context, userID, passkeyID string) error
+	BeginPasskeyLogin(ctx context.Context, userID string) (PasskeyCeremony, error)
+	FinishPasskeyLogin(ctx context.Context, req PasskeyLoginRequest) (PasskeyLoginResult, error)
+	InitiateLogout(ctx context.Context) (LogoutResult, error)
+	ProcessLogout(ctx context.Context, req LogoutRequest) (LogoutResult, error)
+	ProcessBackChannelLogout(ctx context.Context, req BackChannelLogoutRequest) (BackChannelLogoutResult, error)
 }
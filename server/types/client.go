@@ -0,0 +1,68 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Scope tokens need to call the admin API, which only staff users can use.
+const AdminScope = "admin"
+
+// OAuthClient is an OAuth2 client registered at Hydra.
+type OAuthClient struct {
+	ID                      string
+	Name                    string
+	RedirectURIs            []string
+	PostLogoutRedirectURIs  []string
+	GrantTypes              []string
+	ResponseTypes           []string
+	Scopes                  []string
+	Audience                []string
+	TokenEndpointAuthMethod string
+	// Identity provider the client signs users in with, empty to let users choose
+	IdentityProvider string
+	// Organization the client belongs to, empty for clients of the installation
+	OrganizationID string
+	// First-party clients are operated by ConformiTea and skip the consent page
+	FirstParty bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type CreateOAuthClientRequest struct {
+	Actor  Actor
+	Client OAuthClient
+}
+
+type UpdateOAuthClientRequest struct {
+	Actor    Actor
+	ClientID string
+	// Fields left nil are not changed
+	Name                   *string
+	RedirectURIs           *[]string
+	PostLogoutRedirectURIs *[]string
+	GrantTypes             *[]string
+	ResponseTypes          *[]string
+	Scopes                 *[]string
+	Audience               *[]string
+	// An empty string removes the identity provider or organization
+	IdentityProvider *string
+	OrganizationID   *string
+	FirstParty       *bool
+}
+
+// IssuedOAuthClient is an OAuth2 client with its secret, which is shown once.
+// Public clients have no secret.
+type IssuedOAuthClient struct {
+	Client OAuthClient
+	Secret string
+}
+
+type AppClients interface {
+	CreateClient(ctx context.Context, req CreateOAuthClientRequest) (IssuedOAuthClient, error)
+	ListClients(ctx context.Context, actor Actor) ([]OAuthClient, error)
+	GetClient(ctx context.Context, actor Actor, clientID string) (OAuthClient, error)
+	UpdateClient(ctx context.Context, req UpdateOAuthClientRequest) (OAuthClient, error)
+	DeleteClient(ctx context.Context, actor Actor, clientID string) error
+	RotateClientSecret(ctx context.Context, actor Actor, clientID string) (IssuedOAuthClient, error)
+}
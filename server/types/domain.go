@@ -0,0 +1,49 @@
+package types
+
+import (
+	"time"
+)
+
+type AddDomainRequest struct {
+	Actor          Actor
+	OrganizationID string
+	Domain         string
+	// Add users at the domain as members instead of offering them to join
+	AutoJoin bool
+}
+
+// DomainRequest identifies a domain claimed by an organization.
+type DomainRequest struct {
+	Actor          Actor
+	OrganizationID string
+	DomainID       string
+}
+
+type UpdateDomainRequest struct {
+	Actor          Actor
+	OrganizationID string
+	DomainID       string
+	// Fields left nil are not changed
+	AutoJoin *bool
+}
+
+// OrganizationDomain is an email domain claimed by an organization. It is
+// verified by publishing a TXT record named RecordName with RecordValue.
+type OrganizationDomain struct {
+	ID             string
+	OrganizationID string
+	Domain         string
+	RecordName     string
+	RecordValue    string
+	VerifiedAt     *time.Time
+	AutoJoin       bool
+	CreatedAt      time.Time
+}
+
+// JoinableOrganization is an organization that verified the domain of the user's
+// email, which the user can join.
+type JoinableOrganization struct {
+	ID     string
+	Name   string
+	Domain string
+}
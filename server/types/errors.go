@@ -0,0 +1,90 @@
+package types
+
+import "errors"
+
+// Errors returned by AppAuth that handlers translate into ConformiTea error codes.
+var (
+	ErrProviderNotSupported = errors.New("identity provider not supported")
+	ErrStateMismatch        = errors.New("oauth2 state does not match the login challenge")
+	ErrIDTokenMissing       = errors.New("id_token missing")
+	ErrIDTokenSignature     = errors.New("id_token signature invalid")
+	ErrIDTokenExpired       = errors.New("id_token expired")
+	ErrIDTokenIssuer        = errors.New("id_token issuer invalid")
+	ErrIDTokenAudience      = errors.New("id_token audience invalid")
+	ErrIDTokenNonce         = errors.New("id_token nonce invalid")
+	ErrIDTokenClaims        = errors.New("id_token claims invalid")
+	ErrEmailMissing         = errors.New("identity provider did not return an email address")
+	ErrInvalidLogoutToken   = errors.New("logout token invalid")
+	ErrTokenInactive        = errors.New("access token inactive")
+	ErrRefreshTokenInvalid  = errors.New("refresh token invalid")
+	ErrMFACodeInvalid       = errors.New("mfa code invalid")
+	ErrMFANotEnrolled       = errors.New("no authenticator enrolled")
+	ErrMFAAlreadyEnrolled   = errors.New("authenticator already enrolled")
+	ErrMFARequired          = errors.New("an organization of the user requires mfa")
+	ErrPasskeyInvalid       = errors.New("passkey assertion invalid")
+	ErrPasskeyNotFound      = errors.New("passkey not found")
+	ErrAPITokenNotFound     = errors.New("api token not found")
+	ErrAPITokenInvalid      = errors.New("api token request invalid")
+	ErrUserDeactivated      = errors.New("user deactivated")
+	ErrSAMLNotConfigured    = errors.New("organization has no saml identity provider")
+	ErrSAMLResponseInvalid  = errors.New("saml response invalid")
+	ErrSAMLUserConflict     = errors.New("email belongs to a user outside the organization")
+	ErrSSORequired          = errors.New("organization requires its identity provider for the email domain")
+	ErrTenantNotAllowed     = errors.New("entra tenant not allowed")
+	ErrUserCodeInvalid      = errors.New("device user code invalid")
+	ErrConsentScopeInvalid  = errors.New("granted scope was not requested")
+	ErrConnectedAppNotFound = errors.New("connected app not found")
+	ErrIdentityNotFound     = errors.New("identity not found")
+	ErrIdentityConflict     = errors.New("identity linked to another user")
+	ErrIdentityRequired     = errors.New("user must keep at least one identity")
+	ErrEmailTaken           = errors.New("email belongs to another user, sign in as them to link the identity")
+)
+
+// Errors returned by AppOrganization.
+var (
+	ErrOrganizationNotFound   = errors.New("organization not found")
+	ErrPermissionDenied       = errors.New("permission denied")
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrInvalidRequest         = errors.New("invalid request")
+	ErrSAMLMetadataInvalid    = errors.New("saml metadata invalid")
+	ErrDomainNotFound         = errors.New("domain not found")
+	ErrDomainConflict         = errors.New("domain already claimed")
+	ErrDomainNotVerified      = errors.New("domain verification record not found")
+	ErrTenantConflict         = errors.New("entra tenant linked to another organization")
+	ErrMemberNotFound         = errors.New("member not found")
+	ErrRoleNotFound           = errors.New("role not found")
+	ErrRoleConflict           = errors.New("role conflicts with an existing role")
+	ErrInvitationNotFound     = errors.New("invitation not found")
+	ErrInvitationInvalid      = errors.New("invitation link invalid, expired or already used")
+	ErrInvitationConflict     = errors.New("invitee already invited or a member")
+	ErrInvitationEmail        = errors.New("invitation was sent to another email")
+)
+
+// Errors returned by AppClients.
+var (
+	ErrClientNotFound = errors.New("oauth2 client not found")
+	ErrClientInvalid  = errors.New("oauth2 client invalid")
+)
+
+// Errors returned by AppImpersonation.
+var (
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// Errors returned by AppAccounts.
+var (
+	ErrMergeConflict = errors.New("users cannot be merged")
+)
+
+// Errors returned by AppSCIM, named after the SCIM error types (RFC 7644 section 3.12).
+var (
+	ErrSCIMNotFound      = errors.New("scim resource not found")
+	ErrSCIMInvalidFilter = errors.New("scim filter invalid")
+	ErrSCIMInvalidSyntax = errors.New("scim request invalid")
+	ErrSCIMInvalidPath   = errors.New("scim path invalid")
+	ErrSCIMInvalidValue  = errors.New("scim value invalid")
+	ErrSCIMUniqueness    = errors.New("scim resource already exists")
+	// The user was invited to join the organization rather than created, because
+	// their email is not at a domain the organization verified
+	ErrSCIMUserInvited = errors.New("scim user invited")
+)
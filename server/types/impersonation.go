@@ -0,0 +1,91 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Impersonation lets a staff user see the application as another user, the
+// subject, does, for a limited time. It is stored in the staff user's session.
+type Impersonation struct {
+	ID                string
+	ImpersonatorID    string
+	ImpersonatorEmail string
+	SubjectID         string
+	SubjectEmail      string
+	SubjectName       string
+	// Why the staff user impersonates the subject, e.g. a support ticket
+	Reason    string
+	StartedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Reports whether the impersonation ran out of time.
+func (i Impersonation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// AuditRequest describes the HTTP request an audit event is recorded for.
+type AuditRequest struct {
+	Method    string
+	Path      string
+	RequestID string
+	IPAddress string
+}
+
+type StartImpersonationRequest struct {
+	Actor Actor
+	// ID or email of the user to impersonate
+	Subject string
+	Reason  string
+	// Zero for the default duration
+	Duration time.Duration
+	Request  AuditRequest
+}
+
+type EndImpersonationRequest struct {
+	Impersonation Impersonation
+	// The impersonation ran out of time rather than being ended by the staff user
+	Expired bool
+	Request AuditRequest
+}
+
+// ImpersonatedRequest is a request made while impersonating, with the status it
+// was answered with.
+type ImpersonatedRequest struct {
+	Impersonation Impersonation
+	Request       AuditRequest
+	Status        int
+}
+
+// AuditEvent is an entry of the audit trail of what staff did on behalf of users.
+type AuditEvent struct {
+	ID              string
+	Action          string
+	ActorID         string
+	SubjectID       string
+	ImpersonationID string
+	Reason          string
+	Method          string
+	Path            string
+	Status          int
+	RequestID       string
+	IPAddress       string
+	CreatedAt       time.Time
+}
+
+// AuditEventQuery filters the audit trail. Empty fields do not filter.
+type AuditEventQuery struct {
+	Actor           Actor
+	ActorID         string
+	SubjectID       string
+	ImpersonationID string
+	Limit           int
+}
+
+type AppImpersonation interface {
+	StartImpersonation(ctx context.Context, req StartImpersonationRequest) (Impersonation, error)
+	EndImpersonation(ctx context.Context, req EndImpersonationRequest) error
+	RecordImpersonatedRequest(ctx context.Context, req ImpersonatedRequest) error
+	ListAuditEvents(ctx context.Context, query AuditEventQuery) ([]AuditEvent, error)
+}
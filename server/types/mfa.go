@@ -0,0 +1,104 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Second factors a pending login can be completed with.
+const (
+	MFAMethodTOTP         = "totp"
+	MFAMethodRecoveryCode = "recovery_code"
+	MFAMethodPasskey      = "passkey"
+)
+
+// Provider hint of logins that sign in with a passkey instead of an identity provider.
+const PasskeyProvider = "passkey"
+
+type MFAStatus struct {
+	TOTPEnabled            bool
+	Passkeys               int
+	RecoveryCodesRemaining int
+	// An organization of the user requires a second factor
+	Required bool
+}
+
+// TOTPEnrollment is a new authenticator secret to be confirmed with a code.
+type TOTPEnrollment struct {
+	Secret string
+	// otpauth:// URL authenticator apps import
+	URL string
+	// PNG QR code of URL as a data URL
+	QRCode string
+}
+
+type TOTPConfirmRequest struct {
+	UserID string
+	Code   string
+	// Login to accept once the authenticator is confirmed, when enrolling while
+	// signing in
+	LoginChallenge string
+}
+
+type TOTPConfirmResult struct {
+	// Shown to the user once, only their hashes are stored
+	RecoveryCodes []string
+	RedirectTo    string
+}
+
+type MFAVerifyRequest struct {
+	LoginChallenge string
+	UserID         string
+	Method         string
+	Code           string
+}
+
+type MFAVerifyResult struct {
+	RedirectTo string
+}
+
+type Passkey struct {
+	ID             string
+	Name           string
+	BackupEligible bool
+	LastUsedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+// PasskeyCeremony is a WebAuthn registration or authentication in progress.
+type PasskeyCeremony struct {
+	// Options passed to navigator.credentials in the browser
+	Options json.RawMessage
+	// Ceremony state kept in the session until the browser responds
+	State string
+}
+
+type PasskeyRegistrationRequest struct {
+	UserID string
+	Name   string
+	State  string
+	// Credential created by the browser, as JSON
+	Response json.RawMessage
+}
+
+type PasskeyRegistrationResult struct {
+	Passkey Passkey
+	// Issued when the passkey is the user's first second factor, shown to the
+	// user once
+	RecoveryCodes []string
+}
+
+type PasskeyLoginRequest struct {
+	LoginChallenge string
+	// User whose second factor is checked. Empty when signing in with a passkey
+	// alone, the user is then identified by the passkey.
+	UserID string
+	State  string
+	// Assertion returned by the browser, as JSON
+	Response json.RawMessage
+}
+
+type PasskeyLoginResult struct {
+	RedirectTo string
+	Subject    string
+}
@@ -0,0 +1,228 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Scope tokens need to call the organization and invitation API.
+const OrganizationsScope = "organizations"
+
+// Actor is the user, service account, client or operator a request is made by.
+type Actor struct {
+	ID string
+	// PrincipalUser, PrincipalServiceAccount, PrincipalClient or PrincipalOperator
+	Kind string
+}
+
+// Permissions checked by RequirePermission on organization routes, from the
+// catalog roles are composed of.
+const (
+	PermissionOrganizationRead      = "organization:read"
+	PermissionOrganizationManage    = "organization:manage"
+	PermissionMembersRead           = "members:read"
+	PermissionMembersManage         = "members:manage"
+	PermissionServiceAccountsRead   = "service_accounts:read"
+	PermissionServiceAccountsManage = "service_accounts:manage"
+	PermissionRolesManage           = "roles:manage"
+)
+
+type UpdateOrganizationRequest struct {
+	Actor          Actor
+	OrganizationID string
+	// Fields left nil are not changed
+	Name       *string
+	RequireMFA *bool
+	RequireSSO *bool
+	// Only an empty string, which unlinks the tenant, is accepted. Staff link
+	// tenants with LinkTenant.
+	EntraTenantID *string
+}
+
+type LinkTenantRequest struct {
+	Actor          Actor
+	OrganizationID string
+	TenantID       string
+}
+
+type OrganizationResult struct {
+	ID            string
+	Name          string
+	RequireMFA    bool
+	RequireSSO    bool
+	EntraTenantID string
+}
+
+// MemberRequest identifies a member of an organization.
+type MemberRequest struct {
+	Actor          Actor
+	OrganizationID string
+	UserID         string
+}
+
+type CreateServiceAccountRequest struct {
+	Actor          Actor
+	OrganizationID string
+	Name           string
+	Description    string
+	// Organization role the service account acts with, defaults to member
+	Role string
+}
+
+// ServiceAccountRequest identifies a service account of an organization.
+type ServiceAccountRequest struct {
+	Actor            Actor
+	OrganizationID   string
+	ServiceAccountID string
+}
+
+type ServiceAccount struct {
+	ID             string
+	OrganizationID string
+	Name           string
+	Description    string
+	Role           string
+	CreatedAt      time.Time
+}
+
+// UpdateMemberRoleRequest gives a member of an organization another role.
+type UpdateMemberRoleRequest struct {
+	Actor          Actor
+	OrganizationID string
+	UserID         string
+	// Name of a built-in or custom role
+	Role string
+}
+
+// Permission is an entry of the catalog roles are composed of.
+type Permission struct {
+	Name        string
+	Description string
+}
+
+// OrganizationRole is a role members and service accounts of an organization can
+// hold.
+type OrganizationRole struct {
+	// Empty for built-in roles
+	ID          string
+	Name        string
+	Description string
+	Permissions []string
+	BuiltIn     bool
+}
+
+type CreateRoleRequest struct {
+	Actor          Actor
+	OrganizationID string
+	// Lowercase letters, digits, dashes and underscores, starting with a letter
+	Name        string
+	Description string
+	Permissions []string
+}
+
+type UpdateRoleRequest struct {
+	Actor          Actor
+	OrganizationID string
+	RoleID         string
+	// Fields left nil are not changed
+	Description *string
+	Permissions []string
+}
+
+// RoleRequest identifies a custom role of an organization.
+type RoleRequest struct {
+	Actor          Actor
+	OrganizationID string
+	RoleID         string
+}
+
+// Statuses of an invitation.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+type CreateInvitationRequest struct {
+	Actor          Actor
+	OrganizationID string
+	Email          string
+	// Name of a built-in or custom role, defaults to member
+	Role string
+	// The invitee may accept with an account whose email differs
+	AllowEmailMismatch bool
+}
+
+// InvitationRequest identifies an invitation of an organization.
+type InvitationRequest struct {
+	Actor          Actor
+	OrganizationID string
+	InvitationID   string
+}
+
+type Invitation struct {
+	ID                 string
+	OrganizationID     string
+	Email              string
+	Role               string
+	AllowEmailMismatch bool
+	InvitedBy          string
+	// InvitationPending, InvitationAccepted, InvitationRevoked or InvitationExpired
+	Status     string
+	ExpiresAt  time.Time
+	SentAt     time.Time
+	AcceptedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// InvitationPreview describes an invitation to the invitee following its link.
+type InvitationPreview struct {
+	OrganizationID     string
+	OrganizationName   string
+	Email              string
+	Role               string
+	AllowEmailMismatch bool
+	// Name of the member who sent the invitation
+	InviterName string
+	ExpiresAt   time.Time
+}
+
+type AppOrganization interface {
+	// Authorize checks that the actor's role in the organization grants permission.
+	Authorize(ctx context.Context, actor Actor, organizationID, permission string) error
+	ListPermissions(ctx context.Context) []Permission
+	ListRoles(ctx context.Context, actor Actor, organizationID string) ([]OrganizationRole, error)
+	CreateRole(ctx context.Context, req CreateRoleRequest) (OrganizationRole, error)
+	UpdateRole(ctx context.Context, req UpdateRoleRequest) (OrganizationRole, error)
+	DeleteRole(ctx context.Context, req RoleRequest) error
+	UpdateMemberRole(ctx context.Context, req UpdateMemberRoleRequest) error
+	CreateInvitation(ctx context.Context, req CreateInvitationRequest) (Invitation, error)
+	ListInvitations(ctx context.Context, actor Actor, organizationID string) ([]Invitation, error)
+	ResendInvitation(ctx context.Context, req InvitationRequest) (Invitation, error)
+	RevokeInvitation(ctx context.Context, req InvitationRequest) error
+	// PreviewInvitation returns the invitation of a link without accepting it.
+	PreviewInvitation(ctx context.Context, token string) (InvitationPreview, error)
+	// AcceptInvitation adds the signed in user to the organization of a link.
+	AcceptInvitation(ctx context.Context, actor Actor, token string) (InvitationPreview, error)
+	UpdateOrganization(ctx context.Context, req UpdateOrganizationRequest) (OrganizationResult, error)
+	// LinkTenant links an Entra tenant to an organization on behalf of staff.
+	LinkTenant(ctx context.Context, req LinkTenantRequest) (OrganizationResult, error)
+	SignOutMember(ctx context.Context, req MemberRequest) error
+	CreateServiceAccount(ctx context.Context, req CreateServiceAccountRequest) (ServiceAccount, error)
+	ListServiceAccounts(ctx context.Context, actor Actor, organizationID string) ([]ServiceAccount, error)
+	DeleteServiceAccount(ctx context.Context, req ServiceAccountRequest) error
+	CreateServiceAccountToken(ctx context.Context, req CreateAPITokenRequest) (IssuedAPIToken, error)
+	ListServiceAccountTokens(ctx context.Context, req ServiceAccountRequest) ([]APIToken, error)
+	RevokeServiceAccountToken(ctx context.Context, req ServiceAccountRequest, tokenID string) error
+	ConfigureSAML(ctx context.Context, req ConfigureSAMLRequest) (SAMLConnection, error)
+	GetSAMLConnection(ctx context.Context, actor Actor, organizationID string) (SAMLConnection, error)
+	DeleteSAMLConnection(ctx context.Context, actor Actor, organizationID string) error
+	AddDomain(ctx context.Context, req AddDomainRequest) (OrganizationDomain, error)
+	ListDomains(ctx context.Context, actor Actor, organizationID string) ([]OrganizationDomain, error)
+	VerifyDomain(ctx context.Context, req DomainRequest) (OrganizationDomain, error)
+	UpdateDomain(ctx context.Context, req UpdateDomainRequest) (OrganizationDomain, error)
+	DeleteDomain(ctx context.Context, req DomainRequest) error
+	ListJoinableOrganizations(ctx context.Context, actor Actor) ([]JoinableOrganization, error)
+	JoinOrganization(ctx context.Context, actor Actor, organizationID string) error
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"time"
+)
+
+// Prefix of the provider hint that signs in with the SAML identity provider of an
+// organization, followed by the organization ID.
+const SAMLProviderPrefix = "saml:"
+
+type SAMLResponseRequest struct {
+	OrganizationID string
+	// Base64 encoded SAMLResponse the identity provider posted
+	SAMLResponse string
+	// Values kept when the login started
+	RequestID           string
+	HydraLoginChallenge string
+}
+
+type ConfigureSAMLRequest struct {
+	Actor          Actor
+	OrganizationID string
+	// Identity provider metadata, uploaded or fetched from MetadataURL. Exactly
+	// one of them must be given.
+	MetadataXML string
+	MetadataURL string
+	// Assertion attributes mapped to user fields. Unmapped fields are read from
+	// commonly used attributes, and the email falls back to a NameID that is an
+	// email address.
+	EmailAttribute     string
+	FirstNameAttribute string
+	LastNameAttribute  string
+}
+
+// SAMLConnection is the SAML identity provider of an organization.
+type SAMLConnection struct {
+	OrganizationID     string
+	IdPEntityID        string
+	MetadataURL        string
+	EmailAttribute     string
+	FirstNameAttribute string
+	LastNameAttribute  string
+	// The organization's service provider to register at the identity provider.
+	// Its entity ID is also the URL of its metadata.
+	SPEntityID string
+	ACSURL     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Scope service account tokens need to provision an organization over SCIM.
+const SCIMScope = "scim"
+
+// Most resources a SCIM list request returns, and the page size when the client
+// does not ask for one.
+const SCIMMaxResults = 200
+
+// SCIMTenant is the organization a SCIM request provisions and the service
+// account making it.
+type SCIMTenant struct {
+	Actor          Actor
+	OrganizationID string
+}
+
+// SCIMUser is a member of the organization as seen by the directory.
+type SCIMUser struct {
+	ID         string
+	ExternalID string
+	// Email address the user signs in with
+	UserName   string
+	GivenName  string
+	FamilyName string
+	// Deprovisioned users are deactivated, they are never deleted
+	Active       bool
+	Created      time.Time
+	LastModified time.Time
+}
+
+// SCIMGroup is a team of the organization as seen by the directory.
+type SCIMGroup struct {
+	ID          string
+	ExternalID  string
+	DisplayName string
+	// IDs of the users in the group
+	Members      []string
+	Created      time.Time
+	LastModified time.Time
+}
+
+// SCIMListRequest pages through the resources matching a SCIM filter.
+type SCIMListRequest struct {
+	Tenant SCIMTenant
+	// Comparisons with eq joined by and, empty for every resource
+	Filter string
+	// 1-based index of the first resource
+	StartIndex int
+	Count      int
+	// Leave out group members, which identity providers rarely need
+	ExcludeMembers bool
+}
+
+type SCIMUserList struct {
+	TotalResults int
+	StartIndex   int
+	Users        []SCIMUser
+}
+
+type SCIMGroupList struct {
+	TotalResults int
+	StartIndex   int
+	Groups       []SCIMGroup
+}
+
+// SCIMPatchOperation is an operation of a SCIM PATCH request (RFC 7644 section 3.5.2).
+type SCIMPatchOperation struct {
+	// add, remove or replace, in any case
+	Op   string
+	Path string
+	// Value as sent by the client, empty for remove
+	Value json.RawMessage
+}
+
+type AppSCIM interface {
+	ListUsers(ctx context.Context, req SCIMListRequest) (SCIMUserList, error)
+	GetUser(ctx context.Context, tenant SCIMTenant, id string) (SCIMUser, error)
+	CreateUser(ctx context.Context, tenant SCIMTenant, user SCIMUser) (SCIMUser, error)
+	ReplaceUser(ctx context.Context, tenant SCIMTenant, user SCIMUser) (SCIMUser, error)
+	PatchUser(ctx context.Context, tenant SCIMTenant, id string, ops []SCIMPatchOperation) (SCIMUser, error)
+	DeactivateUser(ctx context.Context, tenant SCIMTenant, id string) error
+	ListGroups(ctx context.Context, req SCIMListRequest) (SCIMGroupList, error)
+	GetGroup(ctx context.Context, tenant SCIMTenant, id string) (SCIMGroup, error)
+	CreateGroup(ctx context.Context, tenant SCIMTenant, group SCIMGroup) (SCIMGroup, error)
+	ReplaceGroup(ctx context.Context, tenant SCIMTenant, group SCIMGroup) (SCIMGroup, error)
+	PatchGroup(ctx context.Context, tenant SCIMTenant, id string, ops []SCIMPatchOperation) error
+	DeleteGroup(ctx context.Context, tenant SCIMTenant, id string) error
+}